@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sorta/internal/audit"
 	"sorta/internal/config"
 	"sorta/internal/discovery"
@@ -23,16 +25,55 @@ const defaultConfigPath = "sorta-config.json"
 
 // ParseResult holds the result of parsing command line arguments.
 type ParseResult struct {
-	Command       string
-	CmdArgs       []string
-	ConfigPath    string
-	Verbose       bool
-	Validate      bool // For config --validate
-	Depth         int  // For run --depth N (-1 means not set)
-	DryRun        bool // For run --dry-run
-	DiscoverDepth int  // For discover --depth N (-1 means unlimited)
-	Interactive   bool // For discover --interactive
-	Debounce      int  // For watch --debounce N (-1 means not set)
+	Command         string
+	CmdArgs         []string
+	ConfigPath      string
+	Verbose         bool
+	Validate        bool          // For config --validate
+	WithCounts      bool          // For config --with-counts
+	Depth           int           // For run --depth N (-1 means not set)
+	DryRun          bool          // For run --dry-run
+	DiscoverDepth   int           // For discover --depth N (-1 means unlimited)
+	Interactive     bool          // For discover --interactive
+	MergeTarget     string        // For discover --merge-target <dir> (empty means rules point where prefixes were found)
+	PrefixTransform string        // For discover --prefix-transform (empty means prefixes are kept as found)
+	Debounce        int           // For watch --debounce N (-1 means not set)
+	OnlyPrefixes    []string      // For run --only-prefix P (repeatable)
+	MaxDirs         int           // For discover --max-dirs N (-1 means not set)
+	Force           bool          // For discover --force and undo --force
+	NormalizeSpaces bool          // For run --normalize-spaces
+	StripDiacritics bool          // For run --strip-diacritics
+	BatchWindow     time.Duration // For watch --batch-window D (0 means not set)
+	BatchMax        int           // For watch --batch-max N (-1 means not set)
+	ProgressTo      string        // For --progress-to <file> (run, discover, undo)
+	ProgressWidth   int           // For --progress-width N (run, discover, undo; -1 means detect)
+	JSONErrors      bool          // For --json-errors (all commands): write errors to stderr as JSON lines
+	StatusLine      bool          // For run --status-line
+	Explain         bool          // For run --explain
+	Notify          bool          // For run --notify
+	CheckLocks      bool          // For run --check-locks
+	NoCreateDirs    bool          // For run --no-create-dirs
+	NoHashCache     bool          // For run --no-hash-cache and undo --no-hash-cache
+	DedupeWithinRun bool          // For run --dedupe-within-run
+	FailFast        bool          // For run --fail-fast
+	MaxErrors       int           // For run --max-errors N (0 means no limit)
+	SkipOutbound    bool          // For run --skip-outbound-check
+	Transactional   bool          // For run --transactional
+	VerifyAfterMove bool          // For run --verify-after-move
+	TraceTo         string        // For run --trace <file> (empty means no trace)
+	DirPerm         string        // For run --dir-perm <mode> (empty means use the configured dirPermissions)
+	Settle          time.Duration // For run --settle <duration> (0 means no check)
+	Resume          bool          // For run --resume
+	InboundDir      string        // For run --dir <path> (empty means use configured inbound directories)
+	SinceLastRun    bool          // For run --since-last-run
+	Stage           string        // For run --stage <subfolder> (empty means no staging)
+	RelativePaths   string        // For run --relative-paths <root> (empty means use the configured audit.pathRoot)
+	RetryFile       string        // For run --retry-file <file> (empty means no retry file)
+	SummaryJSONTo   string        // For run --summary-json-to <file> (empty means no summary file)
+	RetryFrom       string        // For run --retry-from <file> (empty means scan inbound directories)
+	IncludeFrom     string        // For run --include-from <file> (empty means scan inbound directories)
+	Benchmark       int           // For hidden run --benchmark N (0 means not set)
+	InjectFailures  string        // For hidden run --inject-failures <glob> (empty means no injection)
 }
 
 // parseArgs parses command line arguments and extracts the command, command arguments, config path, and verbose flag.
@@ -44,6 +85,9 @@ func parseArgs(args []string) (ParseResult, error) {
 		Depth:         -1, // -1 means not set
 		DiscoverDepth: -1, // -1 means unlimited depth
 		Debounce:      -1, // -1 means not set (use config default)
+		MaxDirs:       -1, // -1 means not set (use discovery default)
+		BatchMax:      -1, // -1 means not set (batch mode off unless --batch-window is given)
+		ProgressWidth: -1, // -1 means not set (use the terminal width)
 	}
 
 	if len(args) == 0 {
@@ -90,6 +134,28 @@ func parseArgs(args []string) (ParseResult, error) {
 			i++
 			continue
 		}
+		// --json-errors, --progress-to and --progress-width may also be given before the command
+		if arg == "--json-errors" {
+			result.JSONErrors = true
+			i++
+			continue
+		}
+		if arg == "--progress-to" || strings.HasPrefix(arg, "--progress-to=") {
+			n, err := parseProgressTo(args, i, &result)
+			if err != nil {
+				return ParseResult{}, err
+			}
+			i += n
+			continue
+		}
+		if arg == "--progress-width" || strings.HasPrefix(arg, "--progress-width=") {
+			n, err := parseProgressWidth(args, i, &result)
+			if err != nil {
+				return ParseResult{}, err
+			}
+			i += n
+			continue
+		}
 		// Not a flag, must be the command
 		break
 	}
@@ -105,6 +171,13 @@ func parseArgs(args []string) (ParseResult, error) {
 	for i < len(args) {
 		arg := args[i]
 
+		// --json-errors flag for all commands
+		if arg == "--json-errors" {
+			result.JSONErrors = true
+			i++
+			continue
+		}
+
 		// --validate flag for config command
 		if arg == "--validate" {
 			result.Validate = true
@@ -112,6 +185,13 @@ func parseArgs(args []string) (ParseResult, error) {
 			continue
 		}
 
+		// --with-counts flag for config command
+		if arg == "--with-counts" {
+			result.WithCounts = true
+			i++
+			continue
+		}
+
 		// --depth flag for run and discover commands
 		if arg == "--depth" {
 			if i+1 >= len(args) {
@@ -154,6 +234,431 @@ func parseArgs(args []string) (ParseResult, error) {
 			continue
 		}
 
+		// --only-prefix flag for run command (repeatable)
+		if arg == "--only-prefix" {
+			if i+1 >= len(args) {
+				return ParseResult{}, errors.New("missing value for only-prefix flag")
+			}
+			result.OnlyPrefixes = append(result.OnlyPrefixes, args[i+1])
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(arg, "--only-prefix=") {
+			result.OnlyPrefixes = append(result.OnlyPrefixes, strings.TrimPrefix(arg, "--only-prefix="))
+			i++
+			continue
+		}
+
+		// --normalize-spaces flag for run command
+		if arg == "--normalize-spaces" {
+			result.NormalizeSpaces = true
+			i++
+			continue
+		}
+
+		// --strip-diacritics flag for run command
+		if arg == "--strip-diacritics" {
+			result.StripDiacritics = true
+			i++
+			continue
+		}
+
+		// --check-locks flag for run command
+		if arg == "--check-locks" {
+			result.CheckLocks = true
+			i++
+			continue
+		}
+
+		// --no-create-dirs flag for run command
+		if arg == "--no-create-dirs" {
+			result.NoCreateDirs = true
+			i++
+			continue
+		}
+
+		// --no-hash-cache flag for run and undo commands
+		if arg == "--no-hash-cache" {
+			result.NoHashCache = true
+			i++
+			continue
+		}
+
+		// --skip-outbound-check flag for run command
+		if arg == "--skip-outbound-check" {
+			result.SkipOutbound = true
+			i++
+			continue
+		}
+
+		// --transactional flag for run command
+		if arg == "--transactional" {
+			result.Transactional = true
+			i++
+			continue
+		}
+
+		// --verify-after-move flag for run command
+		if arg == "--verify-after-move" {
+			result.VerifyAfterMove = true
+			i++
+			continue
+		}
+
+		// --resume flag for run command
+		if arg == "--resume" {
+			result.Resume = true
+			i++
+			continue
+		}
+
+		// --dedupe-within-run flag for run command
+		if arg == "--dedupe-within-run" {
+			result.DedupeWithinRun = true
+			i++
+			continue
+		}
+
+		// --fail-fast flag for run command
+		if arg == "--fail-fast" {
+			result.FailFast = true
+			i++
+			continue
+		}
+
+		// --dir flag for run command (rename-rule parses its own --dir)
+		if result.Command == "run" && (arg == "--dir" || strings.HasPrefix(arg, "--dir=")) {
+			value, ok := strings.CutPrefix(arg, "--dir=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for dir flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("dir must not be empty")
+			}
+			result.InboundDir = value
+			i += step
+			continue
+		}
+
+		// --stage flag for run command
+		if arg == "--stage" || strings.HasPrefix(arg, "--stage=") {
+			value, ok := strings.CutPrefix(arg, "--stage=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for stage flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("stage must not be empty")
+			}
+			result.Stage = value
+			i += step
+			continue
+		}
+
+		// --relative-paths flag for run command
+		if arg == "--relative-paths" || strings.HasPrefix(arg, "--relative-paths=") {
+			value, ok := strings.CutPrefix(arg, "--relative-paths=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for relative-paths flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("relative-paths root must not be empty")
+			}
+			result.RelativePaths = value
+			i += step
+			continue
+		}
+
+		// --include-from flag for run command
+		if arg == "--include-from" || strings.HasPrefix(arg, "--include-from=") {
+			value, ok := strings.CutPrefix(arg, "--include-from=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for include-from flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("include-from must not be empty")
+			}
+			result.IncludeFrom = value
+			i += step
+			continue
+		}
+
+		// --retry-file and --retry-from flags for run command
+		if arg == "--retry-file" || strings.HasPrefix(arg, "--retry-file=") || arg == "--retry-from" || strings.HasPrefix(arg, "--retry-from=") {
+			name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, fmt.Errorf("missing value for %s flag", name)
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, fmt.Errorf("%s must not be empty", name)
+			}
+			if name == "retry-file" {
+				result.RetryFile = value
+			} else {
+				result.RetryFrom = value
+			}
+			i += step
+			continue
+		}
+
+		// --summary-json-to flag for run command
+		if arg == "--summary-json-to" || strings.HasPrefix(arg, "--summary-json-to=") {
+			value, ok := strings.CutPrefix(arg, "--summary-json-to=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for summary-json-to flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("summary-json-to must not be empty")
+			}
+			result.SummaryJSONTo = value
+			i += step
+			continue
+		}
+
+		// --since-last-run flag for run command
+		if arg == "--since-last-run" {
+			result.SinceLastRun = true
+			i++
+			continue
+		}
+
+		// --status-line flag for run command
+		if arg == "--status-line" {
+			result.StatusLine = true
+			i++
+			continue
+		}
+
+		// --explain flag for run command
+		if arg == "--explain" {
+			result.Explain = true
+			i++
+			continue
+		}
+
+		// --notify flag for run command
+		if arg == "--notify" {
+			result.Notify = true
+			i++
+			continue
+		}
+
+		// --max-errors flag for run command
+		if arg == "--max-errors" || strings.HasPrefix(arg, "--max-errors=") {
+			countStr := strings.TrimPrefix(arg, "--max-errors=")
+			step := 1
+			if arg == "--max-errors" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for max-errors flag")
+				}
+				countStr = args[i+1]
+				step = 2
+			}
+			count, err := parseDepth(countStr)
+			if err != nil || count <= 0 {
+				return ParseResult{}, errors.New("max-errors must be a positive integer")
+			}
+			result.MaxErrors = count
+			i += step
+			continue
+		}
+
+		// Hidden --benchmark flag for run command (not listed in help)
+		if arg == "--benchmark" || strings.HasPrefix(arg, "--benchmark=") {
+			countStr := strings.TrimPrefix(arg, "--benchmark=")
+			step := 1
+			if arg == "--benchmark" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for benchmark flag")
+				}
+				countStr = args[i+1]
+				step = 2
+			}
+			count, err := parseDepth(countStr)
+			if err != nil || count == 0 {
+				return ParseResult{}, errors.New("benchmark must be a positive integer")
+			}
+			result.Benchmark = count
+			i += step
+			continue
+		}
+
+		// Hidden --inject-failures flag for run command (not listed in help)
+		if arg == "--inject-failures" || strings.HasPrefix(arg, "--inject-failures=") {
+			pattern := strings.TrimPrefix(arg, "--inject-failures=")
+			step := 1
+			if arg == "--inject-failures" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for inject-failures flag")
+				}
+				pattern = args[i+1]
+				step = 2
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+				return ParseResult{}, fmt.Errorf("invalid inject-failures pattern: %q", pattern)
+			}
+			result.InjectFailures = pattern
+			i += step
+			continue
+		}
+
+		// --trace flag for run command
+		if arg == "--trace" || strings.HasPrefix(arg, "--trace=") {
+			path := strings.TrimPrefix(arg, "--trace=")
+			step := 1
+			if arg == "--trace" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for trace flag")
+				}
+				path = args[i+1]
+				step = 2
+			}
+			if path == "" {
+				return ParseResult{}, errors.New("trace requires a file path")
+			}
+			result.TraceTo = path
+			i += step
+			continue
+		}
+
+		// --dir-perm flag for run command
+		if arg == "--dir-perm" || strings.HasPrefix(arg, "--dir-perm=") {
+			value, ok := strings.CutPrefix(arg, "--dir-perm=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for dir-perm flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if _, err := config.ParseDirPermissions(value); err != nil || value == "" {
+				return ParseResult{}, fmt.Errorf("invalid dir-perm: %q must be an octal mode such as 0775 giving the owner full access", value)
+			}
+			result.DirPerm = value
+			i += step
+			continue
+		}
+
+		// --settle flag for run command
+		if arg == "--settle" || strings.HasPrefix(arg, "--settle=") {
+			value, ok := strings.CutPrefix(arg, "--settle=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for settle flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			settle, err := time.ParseDuration(value)
+			if err != nil || settle <= 0 {
+				return ParseResult{}, errors.New("settle must be a positive duration (e.g. 2s)")
+			}
+			result.Settle = settle
+			i += step
+			continue
+		}
+
+		// --max-dirs and --force flags for discover command
+		if arg == "--max-dirs" {
+			if i+1 >= len(args) {
+				return ParseResult{}, errors.New("missing value for max-dirs flag")
+			}
+			maxDirs, err := parseDepth(args[i+1]) // reuse parseDepth for integer parsing
+			if err != nil {
+				return ParseResult{}, errors.New("max-dirs must be a non-negative integer")
+			}
+			result.MaxDirs = maxDirs
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(arg, "--max-dirs=") {
+			maxDirs, err := parseDepth(strings.TrimPrefix(arg, "--max-dirs="))
+			if err != nil {
+				return ParseResult{}, errors.New("max-dirs must be a non-negative integer")
+			}
+			result.MaxDirs = maxDirs
+			i++
+			continue
+		}
+		if arg == "--force" {
+			result.Force = true
+			i++
+			continue
+		}
+
+		// --merge-target flag for discover command
+		if arg == "--merge-target" || strings.HasPrefix(arg, "--merge-target=") {
+			value, ok := strings.CutPrefix(arg, "--merge-target=")
+			step := 1
+			if !ok {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for merge-target flag")
+				}
+				value = args[i+1]
+				step = 2
+			}
+			if value == "" {
+				return ParseResult{}, errors.New("merge-target must not be empty")
+			}
+			result.MergeTarget = value
+			i += step
+			continue
+		}
+
+		// --prefix-transform flag for discover command; without a mode it
+		// title-cases prefixes
+		if arg == "--prefix-transform" || strings.HasPrefix(arg, "--prefix-transform=") {
+			value, ok := strings.CutPrefix(arg, "--prefix-transform=")
+			step := 1
+			if !ok {
+				value = string(discovery.PrefixTransformTitle)
+				if i+1 < len(args) {
+					if _, err := discovery.ParsePrefixTransform(args[i+1]); err == nil {
+						value = args[i+1]
+						step = 2
+					}
+				}
+			}
+			transform, err := discovery.ParsePrefixTransform(value)
+			if err != nil {
+				return ParseResult{}, err
+			}
+			result.PrefixTransform = string(transform)
+			i += step
+			continue
+		}
+
 		// --interactive flag for discover command
 		// Requirements: 2.1 - Interactive discovery mode
 		if arg == "--interactive" {
@@ -187,6 +692,64 @@ func parseArgs(args []string) (ParseResult, error) {
 			continue
 		}
 
+		// --batch-window and --batch-max flags for watch command
+		if arg == "--batch-window" || strings.HasPrefix(arg, "--batch-window=") {
+			windowStr := strings.TrimPrefix(arg, "--batch-window=")
+			step := 1
+			if arg == "--batch-window" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for batch-window flag")
+				}
+				windowStr = args[i+1]
+				step = 2
+			}
+			window, err := time.ParseDuration(windowStr)
+			if err != nil || window <= 0 {
+				return ParseResult{}, errors.New("batch-window must be a positive duration (e.g. 10s)")
+			}
+			result.BatchWindow = window
+			i += step
+			continue
+		}
+		if arg == "--batch-max" || strings.HasPrefix(arg, "--batch-max=") {
+			maxStr := strings.TrimPrefix(arg, "--batch-max=")
+			step := 1
+			if arg == "--batch-max" {
+				if i+1 >= len(args) {
+					return ParseResult{}, errors.New("missing value for batch-max flag")
+				}
+				maxStr = args[i+1]
+				step = 2
+			}
+			batchMax, err := parseDepth(maxStr)
+			if err != nil || batchMax == 0 {
+				return ParseResult{}, errors.New("batch-max must be a positive integer")
+			}
+			result.BatchMax = batchMax
+			i += step
+			continue
+		}
+
+		// --progress-to flag for run, discover and undo commands
+		if arg == "--progress-to" || strings.HasPrefix(arg, "--progress-to=") {
+			n, err := parseProgressTo(args, i, &result)
+			if err != nil {
+				return ParseResult{}, err
+			}
+			i += n
+			continue
+		}
+
+		// --progress-width flag for run, discover and undo commands
+		if arg == "--progress-width" || strings.HasPrefix(arg, "--progress-width=") {
+			n, err := parseProgressWidth(args, i, &result)
+			if err != nil {
+				return ParseResult{}, err
+			}
+			i += n
+			continue
+		}
+
 		// Not a recognized flag, add to command args
 		result.CmdArgs = append(result.CmdArgs, arg)
 		i++
@@ -195,6 +758,67 @@ func parseArgs(args []string) (ParseResult, error) {
 	return result, nil
 }
 
+// parseProgressTo parses --progress-to <file> or --progress-to=<file> at args[i]
+// into result and returns the number of arguments consumed.
+func parseProgressTo(args []string, i int, result *ParseResult) (int, error) {
+	if value, ok := strings.CutPrefix(args[i], "--progress-to="); ok {
+		result.ProgressTo = value
+		return 1, nil
+	}
+	if i+1 >= len(args) {
+		return 0, errors.New("missing value for progress-to flag")
+	}
+	result.ProgressTo = args[i+1]
+	return 2, nil
+}
+
+// parseProgressWidth parses --progress-width N or --progress-width=N at args[i]
+// into result and returns the number of arguments consumed.
+func parseProgressWidth(args []string, i int, result *ParseResult) (int, error) {
+	value, ok := strings.CutPrefix(args[i], "--progress-width=")
+	n := 1
+	if !ok {
+		if i+1 >= len(args) {
+			return 0, errors.New("missing value for progress-width flag")
+		}
+		value = args[i+1]
+		n = 2
+	}
+	width, err := parseDepth(value) // reuse parseDepth for integer parsing
+	if err != nil {
+		return 0, errors.New("progress-width must be a non-negative integer")
+	}
+	result.ProgressWidth = width
+	return n, nil
+}
+
+// outputConfig returns the default output configuration with verbose and
+// jsonErrors set and, when progressWidth is not negative, the line width
+// overridden.
+func outputConfig(verbose bool, progressWidth int, jsonErrors bool) output.Config {
+	outConfig := output.DefaultConfig()
+	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
+	if progressWidth >= 0 {
+		outConfig.Width = progressWidth
+	}
+	return outConfig
+}
+
+// attachProgressSink opens path (a regular file or a fifo) and sends every progress
+// update of phase to it as a JSON line. The returned function closes the file.
+func attachProgressSink(out *output.Output, path, phase string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open progress file: %w", err)
+	}
+	out.SetProgressSink(output.NewProgressSink(f, phase))
+	return func() { f.Close() }, nil
+}
+
 // parseDepth parses a depth string into an integer.
 func parseDepth(s string) (int, error) {
 	if s == "" {
@@ -241,7 +865,10 @@ func main() {
 	// Parse command-line arguments (skip program name)
 	parsed, err := parseArgs(os.Args[1:])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		// The flags could not be parsed, so look for --json-errors directly
+		outConfig := output.DefaultConfig()
+		outConfig.JSONErrors = slices.Contains(os.Args[1:], "--json-errors")
+		output.New(outConfig).Error("Error: %v", err)
 		printUsage()
 		os.Exit(1)
 	}
@@ -250,23 +877,31 @@ func main() {
 	var exitCode int
 	switch parsed.Command {
 	case "config":
-		exitCode = runConfigCommand(parsed.ConfigPath, parsed.Verbose, parsed.Validate)
+		exitCode = runConfigCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors, parsed.Validate, parsed.WithCounts)
 	case "add-inbound":
-		exitCode = runAddInboundCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose)
+		exitCode = runAddInboundCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors)
 	case "discover":
-		exitCode = runDiscoverCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.DiscoverDepth, parsed.Interactive)
+		exitCode = runDiscoverCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors, parsed.DiscoverDepth, parsed.Interactive, parsed.MaxDirs, parsed.Force, parsed.ProgressTo, parsed.ProgressWidth, parsed.MergeTarget, discovery.PrefixTransform(parsed.PrefixTransform))
 	case "run":
-		exitCode = runRunCommand(parsed.ConfigPath, parsed.Verbose, parsed.Depth, parsed.DryRun)
+		exitCode = runRunCommand(parsed)
 	case "status":
-		exitCode = runStatusCommand(parsed.ConfigPath, parsed.Verbose)
+		exitCode = runStatusCommand(parsed.ConfigPath, parsed.Verbose, parsed.JSONErrors)
+	case "dedupe-report":
+		exitCode = runDedupeReportCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors)
+	case "rename-rule":
+		exitCode = runRenameRuleCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors)
+	case "promote":
+		exitCode = runPromoteCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors)
 	case "audit":
-		exitCode = runAuditCommand(parsed.CmdArgs, parsed.Verbose)
+		exitCode = runAuditCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors)
 	case "undo":
-		exitCode = runUndoCommand(parsed.CmdArgs, parsed.Verbose)
+		exitCode = runUndoCommand(parsed.ConfigPath, parsed.CmdArgs, parsed.Verbose, parsed.JSONErrors, parsed.ProgressTo, parsed.ProgressWidth, parsed.Force, parsed.NoHashCache)
 	case "watch":
-		exitCode = runWatchCommand(parsed.ConfigPath, parsed.Verbose, parsed.Debounce)
+		exitCode = runWatchCommand(parsed.ConfigPath, parsed.Verbose, parsed.JSONErrors, parsed.Debounce, parsed.BatchWindow, parsed.BatchMax)
 	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown command '%s'\n", parsed.Command)
+		outConfig := output.DefaultConfig()
+		outConfig.JSONErrors = parsed.JSONErrors
+		output.New(outConfig).Error("Error: unknown command '%s'", parsed.Command)
 		printUsage()
 		exitCode = 1
 	}
@@ -276,12 +911,27 @@ func main() {
 
 // runConfigCommand displays the current configuration or validates it.
 // Requirements: 1.1, 1.2, 1.6, 1.7, 1.8 - verbose flag passed to command, validation support
-func runConfigCommand(configPath string, verbose bool, validate bool) int {
+func runConfigCommand(configPath string, args []string, verbose bool, jsonErrors bool, validate bool, withCounts bool) int {
 	// Create output instance with verbose config
 	outConfig := output.DefaultConfig()
 	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
 	out := output.New(outConfig)
 
+	if len(args) > 0 {
+		switch args[0] {
+		case "show-rule":
+			return runShowRuleCommand(configPath, args[1:], out)
+		case "edit":
+			return runConfigEditCommand(configPath, out)
+		case "lint":
+			return runConfigLintCommand(configPath, out)
+		default:
+			out.Error("Error: unknown config subcommand '%s'", args[0])
+			return 1
+		}
+	}
+
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		var configErr *config.ConfigError
@@ -305,7 +955,86 @@ func runConfigCommand(configPath string, verbose bool, validate bool) int {
 		return runValidation(cfg, out)
 	}
 
-	displayConfigWithOutput(cfg, out)
+	// --with-counts adds the number of pending files each rule matches
+	var counts []orchestrator.RuleCount
+	if withCounts {
+		counts, err = orchestrator.NewOrchestrator(cfg).RuleCounts()
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+	}
+
+	displayConfigWithOutput(cfg, counts, out)
+	return 0
+}
+
+// runShowRuleCommand prints the details of a single prefix rule.
+func runShowRuleCommand(configPath string, args []string, out *output.Output) int {
+	if len(args) != 1 {
+		out.Error("Error: expected exactly one prefix")
+		out.Error("Usage: sorta config show-rule <prefix>")
+		return 1
+	}
+
+	details, err := orchestrator.ShowRuleFromPath(configPath, args[0])
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	out.PrintRuleDetails(details)
+	return 0
+}
+
+// runConfigEditCommand opens the configuration in $EDITOR and keeps the edit
+// only if the result is a valid configuration.
+func runConfigEditCommand(configPath string, out *output.Output) int {
+	prompter := discovery.NewInteractivePrompter(os.Stdin, os.Stdout)
+	var invalid error
+	reopen := func(err error) bool {
+		invalid = err
+		out.Error("Error: %v", err)
+		if !discovery.IsInteractive() {
+			return false
+		}
+		again, promptErr := prompter.Confirm("Re-open the editor to fix it?")
+		return promptErr == nil && again
+	}
+
+	if _, err := config.Edit(configPath, os.Getenv("EDITOR"), reopen); err != nil {
+		if invalid == nil || err != invalid {
+			out.Error("Error: %v", err)
+		}
+		if !errors.Is(err, config.ErrNoEditor) {
+			out.Error("Edit discarded; %s left unchanged", configPath)
+		}
+		return 1
+	}
+
+	out.Info("Configuration saved: %s", configPath)
+	return 0
+}
+
+// runConfigLintCommand prints quality warnings for the configuration. Warnings
+// do not change the exit status; only a configuration that cannot be loaded does.
+func runConfigLintCommand(configPath string, out *output.Output) int {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	warnings := config.Lint(cfg)
+	if len(warnings) == 0 {
+		out.Info("No problems found.")
+		return 0
+	}
+
+	out.Info("Warnings:")
+	for _, warning := range warnings {
+		out.Info("  [%s] %s", warning.Field, warning.Message)
+	}
 	return 0
 }
 
@@ -375,7 +1104,7 @@ func displayConfig(cfg *config.Configuration) string {
 }
 
 // displayConfigWithOutput formats and prints the configuration using the output package.
-func displayConfigWithOutput(cfg *config.Configuration, out *output.Output) {
+func displayConfigWithOutput(cfg *config.Configuration, counts []orchestrator.RuleCount, out *output.Output) {
 	out.Info("Configuration:")
 	out.Info("")
 	out.Info("Inbound Directories:")
@@ -392,7 +1121,11 @@ func displayConfigWithOutput(cfg *config.Configuration, out *output.Output) {
 	if len(cfg.PrefixRules) == 0 {
 		out.Info("  (none)")
 	} else {
-		for _, rule := range cfg.PrefixRules {
+		for i, rule := range cfg.PrefixRules {
+			if counts != nil {
+				out.Info("  - %s -> %s (%d pending)", rule.Prefix, rule.OutboundDirectory, counts[i].PendingFiles)
+				continue
+			}
 			out.Info("  - %s -> %s", rule.Prefix, rule.OutboundDirectory)
 		}
 	}
@@ -400,10 +1133,11 @@ func displayConfigWithOutput(cfg *config.Configuration, out *output.Output) {
 
 // runAddInboundCommand adds an inbound directory to the configuration.
 // Requirements: 1.2 - verbose flag passed to command
-func runAddInboundCommand(configPath string, args []string, verbose bool) int {
+func runAddInboundCommand(configPath string, args []string, verbose bool, jsonErrors bool) int {
 	// Create output instance with verbose config
 	outConfig := output.DefaultConfig()
 	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
 	out := output.New(outConfig)
 
 	if len(args) == 0 {
@@ -439,11 +1173,16 @@ func runAddInboundCommand(configPath string, args []string, verbose bool) int {
 
 // runDiscoverCommand scans a directory for prefix patterns and updates the configuration.
 // Requirements: 1.1, 2.1, 2.7, 3.1, 3.2, 3.3, 5.2 - verbose output, progress indicators, depth limiting, interactive mode
-func runDiscoverCommand(configPath string, args []string, verbose bool, depth int, interactive bool) int {
-	// Create output instance with verbose config
-	outConfig := output.DefaultConfig()
-	outConfig.Verbose = verbose
-	out := output.New(outConfig)
+func runDiscoverCommand(configPath string, args []string, verbose bool, jsonErrors bool, depth int, interactive bool, maxDirs int, force bool, progressTo string, progressWidth int, mergeTarget string, prefixTransform discovery.PrefixTransform) int {
+	// Create output instance with verbose config and line width
+	out := output.New(outputConfig(verbose, progressWidth, jsonErrors))
+
+	closeProgress, err := attachProgressSink(out, progressTo, "discover")
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	defer closeProgress()
 
 	if len(args) == 0 {
 		out.Error("Error: missing scan-directory argument")
@@ -468,6 +1207,35 @@ func runDiscoverCommand(configPath string, args []string, verbose bool, depth in
 		actualInteractive = false
 	}
 
+	// Guard against accidentally scanning enormous trees such as / or $HOME
+	if !force {
+		if maxDirs < 0 {
+			maxDirs = discovery.DefaultMaxDirs
+		}
+		if _, err := discovery.CheckScanSize(scanDir, depth, maxDirs); err != nil {
+			var tooLarge *discovery.ScanTooLargeError
+			if !errors.As(err, &tooLarge) {
+				out.Error("Error during discovery: %v", err)
+				return 1
+			}
+			if !discovery.IsInteractive() {
+				out.Error("Error: %v", err)
+				out.Error("Use --force to scan anyway, or --max-dirs N to raise the limit")
+				return 1
+			}
+			prompter := discovery.NewInteractivePrompter(os.Stdin, os.Stdout)
+			proceed, promptErr := prompter.Confirm(fmt.Sprintf("Warning: %v. Continue scanning?", err))
+			if promptErr != nil {
+				out.Error("Error: %v", promptErr)
+				return 1
+			}
+			if !proceed {
+				out.Info("Discovery cancelled")
+				return 1
+			}
+		}
+	}
+
 	// Track progress for non-verbose mode
 	progressStarted := false
 	fileCount := 0
@@ -487,13 +1255,19 @@ func runDiscoverCommand(configPath string, args []string, verbose bool, depth in
 			}
 
 			// Update progress indicator (only shown in non-verbose TTY mode)
-			out.UpdateProgress(event.Current, "Scanning directory")
+			out.UpdateProgressPath(event.Current, "Scanning directory", event.Path)
 
 		case discovery.EventTypeFile:
 			// Requirement 3.2: Display each file being analyzed
 			out.Verbose("  Analyzing file: %s", event.Path)
+			if event.Pattern != "" {
+				out.Verbose("    Matched: prefix %q, date %s", event.Pattern, event.Date)
+			}
 			fileCount++
 
+		case discovery.EventTypeNoMatch:
+			out.Verbose("    No match: %s", event.Reason)
+
 		case discovery.EventTypePattern:
 			// Requirement 3.3: Display detected patterns as they are found
 			out.Verbose("  Found pattern: %s (in %s)", event.Pattern, event.Path)
@@ -503,8 +1277,10 @@ func runDiscoverCommand(configPath string, args []string, verbose bool, depth in
 	// Create discovery options with depth limiting
 	// Requirements: 1.1 - depth limiting for discover command
 	opts := discovery.DiscoverOptions{
-		MaxDepth:    depth, // -1 for unlimited (default), N for N levels deep
-		Interactive: actualInteractive,
+		MaxDepth:        depth, // -1 for unlimited (default), N for N levels deep
+		Interactive:     actualInteractive,
+		MergeTarget:     mergeTarget,
+		PrefixTransform: prefixTransform,
 	}
 
 	// Run discovery with options
@@ -530,10 +1306,13 @@ func runDiscoverCommand(configPath string, args []string, verbose bool, depth in
 	// Non-interactive mode: add all new rules to configuration
 	// Requirements: 2.6 - Add all discovered rules automatically when not in interactive mode
 	for _, rule := range result.NewRules {
-		cfg.AddPrefixRule(config.PrefixRule{
+		if _, err := cfg.AddPrefixRule(config.PrefixRule{
 			Prefix:            rule.Prefix,
 			OutboundDirectory: rule.TargetDirectory,
-		})
+		}); err != nil {
+			out.Error("Error adding rule: %v", err)
+			return 1
+		}
 	}
 
 	// Save the updated configuration if there are new rules
@@ -604,10 +1383,13 @@ func runInteractiveDiscovery(cfg *config.Configuration, result *discovery.Discov
 
 	// Add accepted rules to configuration
 	for _, rule := range acceptedRules {
-		cfg.AddPrefixRule(config.PrefixRule{
+		if _, err := cfg.AddPrefixRule(config.PrefixRule{
 			Prefix:            rule.Prefix,
 			OutboundDirectory: rule.TargetDirectory,
-		})
+		}); err != nil {
+			out.Error("Error adding rule: %v", err)
+			return 1
+		}
 	}
 
 	// Save the updated configuration if there are accepted rules
@@ -631,8 +1413,27 @@ func displayDiscoveryResult(result *discovery.DiscoveryResult) string {
 	sb.WriteString("Discovery Results:\n")
 	sb.WriteString(fmt.Sprintf("  Directories scanned: %d\n", result.ScannedDirs))
 	sb.WriteString(fmt.Sprintf("  Files analyzed: %d\n", result.FilesAnalyzed))
+	if len(result.ScanErrors) > 0 {
+		sb.WriteString(fmt.Sprintf("  Unreadable directories skipped: %d\n", len(result.ScanErrors)))
+		for _, scanErr := range result.ScanErrors {
+			sb.WriteString(fmt.Sprintf("    - %v\n", scanErr))
+		}
+	}
+
+	sb.WriteString("\nCoverage:\n")
+	sb.WriteString(fmt.Sprintf("  Files matched: %d\n", result.Coverage.FilesMatched))
+	sb.WriteString(fmt.Sprintf("  Files unmatched: %d\n", result.Coverage.FilesUnmatched))
+	sb.WriteString(fmt.Sprintf("  Coverage: %.1f%%\n", result.Coverage.Percent()))
+	rulePrefixes := make([]string, 0, len(result.Coverage.Rules))
+	for prefix := range result.Coverage.Rules {
+		rulePrefixes = append(rulePrefixes, prefix)
+	}
+	slices.Sort(rulePrefixes)
+	for _, prefix := range rulePrefixes {
+		sb.WriteString(fmt.Sprintf("    - %s: %d files\n", prefix, result.Coverage.Rules[prefix]))
+	}
 
-	if len(result.NewRules) == 0 && len(result.SkippedRules) == 0 {
+	if len(result.NewRules) == 0 && len(result.SkippedRules) == 0 && len(result.ConflictingRules) == 0 {
 		sb.WriteString("\nNo prefix rules discovered.\n")
 	} else {
 		if len(result.NewRules) > 0 {
@@ -642,36 +1443,156 @@ func displayDiscoveryResult(result *discovery.DiscoveryResult) string {
 			}
 		}
 
-		if len(result.SkippedRules) > 0 {
-			sb.WriteString(fmt.Sprintf("\nSkipped (already configured): %d\n", len(result.SkippedRules)))
-			for _, rule := range result.SkippedRules {
-				sb.WriteString(fmt.Sprintf("  - %s (already configured)\n", rule.Prefix))
-			}
+		if len(result.SkippedRules) > 0 {
+			sb.WriteString(fmt.Sprintf("\nSkipped (already configured): %d\n", len(result.SkippedRules)))
+			for _, rule := range result.SkippedRules {
+				sb.WriteString(fmt.Sprintf("  - %s (already configured)\n", rule.Prefix))
+			}
+		}
+
+		if len(result.ConflictingRules) > 0 {
+			sb.WriteString(fmt.Sprintf("\nConflicting prefixes (not added, choose a target manually): %d\n", len(result.ConflictingRules)))
+			for _, conflict := range result.ConflictingRules {
+				sb.WriteString(fmt.Sprintf("  - %s found in:\n", conflict.Prefix))
+				for _, dir := range conflict.TargetDirectories {
+					sb.WriteString(fmt.Sprintf("      %s\n", dir))
+				}
+			}
+		}
+	}
+
+	output := sb.String()
+	fmt.Print(output)
+	return output
+}
+
+// runRunCommand executes the file organization workflow.
+// Requirements: 2.1, 2.2, 2.3, 2.4, 2.5, 3.5, 4.1, 4.2, 4.3, 4.4, 5.1 - verbose output, progress indicators, depth override, runtime validation
+// Requirements: 1.1, 1.2, 1.3, 1.6 - dry-run mode support
+func runRunCommand(parsed ParseResult) int {
+	// --dir and --retry-from adjust these for the rest of the run
+	inboundDir := parsed.InboundDir
+	retryFile := parsed.RetryFile
+
+	// Create output instance with verbose config and line width
+	out := output.New(outputConfig(parsed.Verbose, parsed.ProgressWidth, parsed.JSONErrors))
+
+	closeProgress, err := attachProgressSink(out, parsed.ProgressTo, "run")
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	defer closeProgress()
+
+	// The benchmark never reads the user's config or audit directory
+	if parsed.Benchmark > 0 {
+		return runBenchmarkMode(parsed.Benchmark, out)
+	}
+
+	// --dir replaces the configured inbound directories for this run
+	if inboundDir != "" {
+		absDir, err := filepath.Abs(inboundDir)
+		if err != nil {
+			out.Error("Error: invalid directory %s: %v", inboundDir, err)
+			return 1
+		}
+		info, err := os.Stat(absDir)
+		if err != nil {
+			out.Error("Error: cannot access directory %s: %v", inboundDir, err)
+			return 1
+		}
+		if !info.IsDir() {
+			out.Error("Error: %s is not a directory", inboundDir)
+			return 1
+		}
+		inboundDir = absDir
+	}
+
+	// --stage moves files into a staging folder below each destination
+	if parsed.Stage != "" {
+		if err := orchestrator.ValidateStage(parsed.Stage); err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+	}
+
+	// --since-last-run skips files older than the end of the last completed run
+	var minModTime time.Time
+	if parsed.SinceLastRun {
+		cutoff, err := lastRunCutoff(parsed.ConfigPath)
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+		if cutoff.IsZero() {
+			out.Verbose("No completed run found; processing all files")
+		} else {
+			out.Verbose("Processing files modified since %s", cutoff.Local().Format(time.RFC3339))
+		}
+		minModTime = cutoff
+	}
+
+	// --retry-from organizes exactly the files listed in a retry file
+	var retryPaths []string
+	if parsed.RetryFrom != "" {
+		if parsed.DryRun || inboundDir != "" {
+			out.Error("Error: --retry-from cannot be combined with --dry-run or --dir")
+			return 1
+		}
+		retryPaths, err = orchestrator.ReadRetryFile(parsed.RetryFrom)
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+		// Files that still fail are written back to the same file by default
+		if retryFile == "" {
+			retryFile = parsed.RetryFrom
+		}
+	}
+
+	// --include-from organizes only the files named in a list
+	var includePaths []string
+	if parsed.IncludeFrom != "" {
+		if parsed.RetryFrom != "" {
+			out.Error("Error: --include-from cannot be combined with --retry-from")
+			return 1
+		}
+		includePaths, err = orchestrator.ReadIncludeList(parsed.IncludeFrom)
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+		// An empty list organizes nothing rather than everything
+		if includePaths == nil {
+			includePaths = []string{}
 		}
 	}
 
-	output := sb.String()
-	fmt.Print(output)
-	return output
-}
+	if parsed.Resume && parsed.DryRun {
+		out.Error("Error: --resume cannot be combined with --dry-run")
+		return 1
+	}
 
-// runRunCommand executes the file organization workflow.
-// Requirements: 2.1, 2.2, 2.3, 2.4, 2.5, 3.5, 4.1, 4.2, 4.3, 4.4, 5.1 - verbose output, progress indicators, depth override, runtime validation
-// Requirements: 1.1, 1.2, 1.3, 1.6 - dry-run mode support
-func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bool) int {
-	// Create output instance with verbose config
-	outConfig := output.DefaultConfig()
-	outConfig.Verbose = verbose
-	out := output.New(outConfig)
+	// --trace writes every matching and routing decision to a file
+	var tracer *orchestrator.Tracer
+	if parsed.TraceTo != "" {
+		traceFile, err := os.Create(parsed.TraceTo)
+		if err != nil {
+			out.Error("Error creating trace file: %v", err)
+			return 1
+		}
+		defer traceFile.Close()
+		tracer = orchestrator.NewTracer(traceFile)
+	}
 
 	// Handle dry-run mode
 	// Requirements: 1.1, 1.2, 1.3, 1.6 - Dry run mode that simulates without modifying filesystem
-	if dryRun {
-		return runDryRunMode(configPath, verbose, depthOverride, out)
+	if parsed.DryRun {
+		return runDryRunMode(parsed, inboundDir, minModTime, includePaths, tracer, out)
 	}
 
 	// Load configuration to get audit settings
-	cfg, err := config.Load(configPath)
+	cfg, err := config.Load(parsed.ConfigPath)
 	if err != nil {
 		out.Error("Error loading config: %v", err)
 		return 1
@@ -682,6 +1603,9 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	if auditConfig.LogDirectory == "" {
 		auditConfig.LogDirectory = getAuditLogDir()
 	}
+	if parsed.RelativePaths != "" {
+		auditConfig.PathRoot = parsed.RelativePaths
+	}
 
 	// Create the audit log directory if it doesn't exist
 	if err := os.MkdirAll(auditConfig.LogDirectory, 0755); err != nil {
@@ -689,6 +1613,11 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 		return 1
 	}
 
+	// --resume finishes transactional runs that stopped before their commit
+	if parsed.Resume {
+		return runResumeStaged(&auditConfig, out)
+	}
+
 	// Track if progress has been started
 	progressStarted := false
 
@@ -702,17 +1631,25 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 		}
 
 		// Update progress indicator (only shown in non-verbose TTY mode)
-		out.UpdateProgress(current, "Processing file")
+		out.UpdateProgressPath(current, "Processing file", file)
+
+		if parsed.Explain {
+			out.PrintExplainLine(result)
+		}
 
 		// Verbose output for each file operation
-		if verbose {
+		if parsed.Verbose {
 			// Requirement 2.1: Display each file being processed with its source path
 			out.Verbose("Processing: %s", result.SourcePath)
 
 			switch result.EventType {
 			case "MOVE", "DUPLICATE_DETECTED":
 				// Requirement 2.2: Display source and destination paths for moves
-				out.Verbose("  Moved to: %s", result.DestinationPath)
+				if result.Copied {
+					out.Verbose("  Copied to: %s (read-only source)", result.DestinationPath)
+				} else {
+					out.Verbose("  Moved to: %s", result.DestinationPath)
+				}
 				if result.IsDuplicate {
 					out.Verbose("  (duplicate renamed from: %s)", result.OriginalName)
 				}
@@ -720,13 +1657,13 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 				// Requirement 2.4: Display review routing reason
 				out.Verbose("  Routed to review: %s", result.DestinationPath)
 				if result.ReasonCode != "" {
-					out.Verbose("  Reason: %s", result.ReasonCode)
+					out.Verbose("  Reason: %s", out.ReasonPhrase(result.ReasonCode))
 				}
 			case "SKIP":
 				// Requirement 2.3: Display skip reason
 				out.Verbose("  Skipped")
 				if result.ReasonCode != "" {
-					out.Verbose("  Reason: %s", result.ReasonCode)
+					out.Verbose("  Reason: %s", out.ReasonPhrase(result.ReasonCode))
 				}
 			case "ERROR":
 				// Requirement 2.5: Display detailed error information
@@ -738,23 +1675,53 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	}
 
 	options := &orchestrator.Options{
-		AuditConfig:      &auditConfig,
-		AppVersion:       "1.0.0",
-		MachineID:        getMachineID(),
-		ProgressCallback: progressCallback,
+		AuditConfig:          &auditConfig,
+		AppVersion:           "1.0.0",
+		MachineID:            getMachineID(),
+		ProgressCallback:     progressCallback,
+		OnlyPrefixes:         parsed.OnlyPrefixes,
+		NormalizeSpaces:      parsed.NormalizeSpaces,
+		StripDiacritics:      parsed.StripDiacritics,
+		CheckLocks:           parsed.CheckLocks,
+		NoCreateDirs:         parsed.NoCreateDirs,
+		DedupeWithinRun:      parsed.DedupeWithinRun,
+		FailFast:             parsed.FailFast,
+		MaxErrors:            parsed.MaxErrors,
+		InboundDirectory:     inboundDir,
+		MinModTime:           minModTime,
+		Stage:                parsed.Stage,
+		IncludePaths:         includePaths,
+		SkipOutboundCheck:    parsed.SkipOutbound,
+		TransactionalStaging: parsed.Transactional,
+		VerifyAfterMove:      parsed.VerifyAfterMove,
+		Tracer:               tracer,
+		DirPermissions:       parsed.DirPerm,
+		Settle:               parsed.Settle,
+	}
+	if !parsed.NoHashCache {
+		cache, saveCache := loadHashCache(auditConfig.LogDirectory, out)
+		defer saveCache()
+		options.HashCache = cache
+	}
+	if parsed.InjectFailures != "" {
+		options.FailurePredicate = orchestrator.GlobFailures(parsed.InjectFailures, errors.New("injected failure"))
 	}
 
 	// Apply depth override if specified via --depth flag
 	// Requirements: 3.5 - --depth N overrides configured scanDepth
-	if depthOverride >= 0 {
-		options.ScanDepth = &depthOverride
+	if parsed.Depth >= 0 {
+		options.ScanDepth = &parsed.Depth
 	}
 
 	// Verbose output for validated directories
 	// Requirements: 4.4 - report which directories were validated in verbose mode
-	if verbose {
+	if parsed.Verbose {
 		out.Verbose("Validating inbound directories...")
-		for _, dir := range cfg.InboundDirectories {
+		inboundDirs := cfg.InboundDirectories
+		if inboundDir != "" {
+			inboundDirs = []string{inboundDir}
+		}
+		for _, dir := range inboundDirs {
 			if _, err := os.Stat(dir); os.IsNotExist(err) {
 				out.Verbose("  [MISSING] %s", dir)
 			} else {
@@ -768,7 +1735,12 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	startTime := time.Now()
 
 	// Run the orchestrator with auditing enabled
-	summary, err := orchestrator.RunWithOptions(configPath, options)
+	var summary *orchestrator.Summary
+	if parsed.RetryFrom != "" {
+		summary, err = orchestrator.RunFilesWithOptions(parsed.ConfigPath, retryPaths, options)
+	} else {
+		summary, err = orchestrator.RunWithOptions(parsed.ConfigPath, options)
+	}
 
 	// Calculate duration
 	duration := time.Since(startTime)
@@ -776,8 +1748,35 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	// End progress indicator before showing results
 	out.EndProgress()
 
-	if err != nil {
+	// Record the files that failed so they can be retried with --retry-from
+	if retryFile != "" && summary != nil {
+		if writeErr := orchestrator.WriteRetryFile(retryFile, summary); writeErr != nil {
+			out.Error("Warning: %v", writeErr)
+		}
+	}
+
+	// A run aborted at the error threshold still reports what it did
+	var maxErrorsErr *orchestrator.MaxErrorsError
+	if err != nil && !errors.As(err, &maxErrorsErr) {
 		out.Error("Error: %v", err)
+		var notWritable *orchestrator.OutboundNotWritableError
+		if errors.As(err, &notWritable) {
+			out.Error("No files were touched. Fix the directory's permissions, or use --skip-outbound-check to run anyway.")
+		}
+		if summary == nil {
+			// The run failed before processing any file; the failure is its error
+			writeSummaryFile(parsed.SummaryJSONTo, "", startTime, &orchestrator.RunSummary{Errors: 1, Duration: duration}, out)
+			return 1
+		}
+		runResult := orchestrator.ConvertSummaryToRunResult(summary)
+		runSummary := orchestrator.GenerateSummary(runResult, duration, false)
+		writeSummaryFile(parsed.SummaryJSONTo, summary.RunID, startTime, runSummary, out)
+		if parsed.Notify {
+			out.NotifyRunSummary(output.CommandNotifier{}, runSummary)
+		}
+		if parsed.StatusLine {
+			out.PrintStatusLine(runSummary, summary.RunID)
+		}
 		return 1
 	}
 
@@ -787,7 +1786,7 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	}
 
 	// Print individual file errors (only in non-verbose mode, verbose already showed them)
-	if !verbose {
+	if !parsed.Verbose {
 		for _, result := range summary.Results {
 			if !result.Success && result.EventType == "ERROR" {
 				out.Error("Error processing %s: %v", result.SourcePath, result.Error)
@@ -798,8 +1797,21 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	// Generate and print run summary
 	// Requirements: 3.1, 3.2, 3.3, 3.4, 3.5, 3.6 - Run summary statistics
 	runResult := orchestrator.ConvertSummaryToRunResult(summary)
-	runSummary := orchestrator.GenerateSummary(runResult, duration, verbose)
+	runSummary := orchestrator.GenerateSummary(runResult, duration, parsed.Verbose)
+	if summary.Aborted {
+		runSummary.AbortedAtErrors = parsed.MaxErrors
+	}
 	out.PrintRunSummary(runSummary)
+	writeSummaryFile(parsed.SummaryJSONTo, summary.RunID, startTime, runSummary, out)
+
+	if parsed.Notify {
+		out.NotifyRunSummary(output.CommandNotifier{}, runSummary)
+	}
+
+	// The status line is always the last line on stdout so scripts can parse it
+	if parsed.StatusLine {
+		out.PrintStatusLine(runSummary, summary.RunID)
+	}
 
 	// Exit with error code if there were any errors
 	if summary.HasErrors() {
@@ -808,26 +1820,109 @@ func runRunCommand(configPath string, verbose bool, depthOverride int, dryRun bo
 	return 0
 }
 
+// writeSummaryFile writes runSummary to path for --summary-json-to, warning
+// when it cannot. An empty path writes nothing.
+func writeSummaryFile(path, runID string, startTime time.Time, runSummary *orchestrator.RunSummary, out *output.Output) {
+	if path == "" {
+		return
+	}
+	if err := orchestrator.WriteSummaryFile(path, runID, startTime, runSummary); err != nil {
+		out.Error("Warning: %v", err)
+	}
+}
+
+// loadHashCache loads the hash cache kept in the audit log directory logDir,
+// warning when it cannot be read and starting with an empty one. The returned
+// function saves the cache back, warning when it cannot.
+func loadHashCache(logDir string, out *output.Output) (*audit.HashCache, func()) {
+	cache, err := audit.LoadHashCache(logDir)
+	if err != nil {
+		out.Error("Warning: %v", err)
+	}
+	return cache, func() {
+		if err := cache.Save(); err != nil {
+			out.Error("Warning: %v", err)
+		}
+	}
+}
+
+// runBenchmarkMode organizes count synthetic files in a temporary directory and
+// reports the throughput of the full organize pipeline.
+func runBenchmarkMode(count int, out *output.Output) int {
+	out.Info("Benchmarking %d synthetic %s...", count, pluralize(count, "file", "files"))
+
+	result, err := orchestrator.Benchmark(count)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	out.Info("Organized: %d files (%.1f MB) in %s", result.Files, float64(result.Bytes)/(1024*1024), formatDuration(result.Duration))
+	out.Info("Throughput: %.1f files/sec, %.1f MB/sec", result.FilesPerSecond(), result.MBPerSecond())
+	if result.Errors > 0 {
+		out.Error("Errors: %d", result.Errors)
+		return 1
+	}
+	return 0
+}
+
+// runResumeStaged moves the staged files of transactional runs that stopped
+// before their commit to their destinations.
+func runResumeStaged(auditConfig *audit.AuditConfig, out *output.Output) int {
+	result, err := orchestrator.ResumeStagedRuns(&orchestrator.Options{AuditConfig: auditConfig})
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	if len(result.Runs) == 0 && len(result.Errors) == 0 {
+		out.Info("No staged files to resume")
+		return 0
+	}
+
+	for _, runID := range result.Runs {
+		out.Verbose("Resumed run %s", runID)
+	}
+	out.Info("Moved %d staged %s to their destinations", result.Moved, pluralize(result.Moved, "file", "files"))
+	for _, resumeErr := range result.Errors {
+		out.Error("Error: %v", resumeErr)
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
 // runDryRunMode executes the dry-run mode for the run command.
 // It simulates file organization without modifying the filesystem.
+// The flags come from parsed; inboundDir, minModTime, includePaths and tracer
+// are the values runRunCommand resolved from them.
 // Requirements: 1.1, 1.2, 1.3, 1.6 - Dry run mode that simulates without modifying filesystem
-func runDryRunMode(configPath string, verbose bool, depthOverride int, out *output.Output) int {
+func runDryRunMode(parsed ParseResult, inboundDir string, minModTime time.Time, includePaths []string, tracer *orchestrator.Tracer, out *output.Output) int {
 	// Build run options for dry-run mode
 	opts := orchestrator.RunOptions{
 		DryRun:  true,
-		Verbose: verbose,
+		Verbose: parsed.Verbose,
 	}
 
-	// Build orchestrator options for depth override
-	var options *orchestrator.Options
-	if depthOverride >= 0 {
-		options = &orchestrator.Options{
-			ScanDepth: &depthOverride,
-		}
+	// Build orchestrator options for depth override and prefix selection
+	options := &orchestrator.Options{
+		OnlyPrefixes:     parsed.OnlyPrefixes,
+		NormalizeSpaces:  parsed.NormalizeSpaces,
+		StripDiacritics:  parsed.StripDiacritics,
+		NoCreateDirs:     parsed.NoCreateDirs,
+		InboundDirectory: inboundDir,
+		MinModTime:       minModTime,
+		Stage:            parsed.Stage,
+		IncludePaths:     includePaths,
+		Tracer:           tracer,
+	}
+	if parsed.Depth >= 0 {
+		depth := parsed.Depth
+		options.ScanDepth = &depth
 	}
 
 	// Run dry-run mode
-	result, err := orchestrator.RunDryRunWithOptions(configPath, opts, options)
+	result, err := orchestrator.RunDryRunWithOptions(parsed.ConfigPath, opts, options)
 	if err != nil {
 		out.Error("Error: %v", err)
 		return 1
@@ -855,10 +1950,11 @@ func runDryRunMode(configPath string, verbose bool, depthOverride int, out *outp
 // runStatusCommand executes the status command to show pending files.
 // It scans all configured inbound directories and displays files grouped by destination.
 // Requirements: 2.1, 2.5, 2.6 - Status command implementation
-func runStatusCommand(configPath string, verbose bool) int {
+func runStatusCommand(configPath string, verbose bool, jsonErrors bool) int {
 	// Create output instance with verbose config
 	outConfig := output.DefaultConfig()
 	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
 	out := output.New(outConfig)
 
 	// Call orchestrator StatusFromPath to get status results
@@ -877,18 +1973,219 @@ func runStatusCommand(configPath string, verbose bool) int {
 	return 0
 }
 
+// runDedupeReportCommand reports files with identical content across inbound directories.
+// No files are moved or deleted.
+func runDedupeReportCommand(configPath string, args []string, verbose bool, jsonErrors bool) int {
+	outConfig := output.DefaultConfig()
+	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
+	out := output.New(outConfig)
+
+	asJSON := false
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		default:
+			out.Error("Error: unknown flag '%s'", arg)
+			return 1
+		}
+	}
+
+	report, err := orchestrator.DedupeReportFromPath(configPath)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			out.Error("Error encoding report: %v", err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+
+	out.PrintDedupeReport(report)
+	return 0
+}
+
+// runRenameRuleCommand changes the outbound directory of a prefix rule and, with
+// --relocate, moves the prefix's existing folders to the new directory.
+func runRenameRuleCommand(configPath string, args []string, verbose bool, jsonErrors bool) int {
+	outConfig := output.DefaultConfig()
+	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
+	out := output.New(outConfig)
+
+	var prefix, newDir string
+	relocate := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--relocate":
+			relocate = true
+		case arg == "--dir" && i+1 < len(args):
+			i++
+			newDir = args[i]
+		case strings.HasPrefix(arg, "--dir="):
+			newDir = strings.TrimPrefix(arg, "--dir=")
+		case strings.HasPrefix(arg, "-"):
+			out.Error("Error: unknown flag '%s'", arg)
+			return 1
+		case prefix == "":
+			prefix = arg
+		default:
+			out.Error("Error: unexpected argument '%s'", arg)
+			return 1
+		}
+	}
+
+	if prefix == "" || newDir == "" {
+		out.Error("Error: missing prefix or --dir")
+		out.Error("Usage: sorta rename-rule <prefix> --dir <new-outbound> [--relocate]")
+		return 1
+	}
+
+	var options *orchestrator.Options
+	if relocate {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			out.Error("Error loading config: %v", err)
+			return 1
+		}
+		auditConfig := *cfg.Audit
+		if auditConfig.LogDirectory == "" {
+			auditConfig.LogDirectory = getAuditLogDir()
+		}
+		if err := os.MkdirAll(auditConfig.LogDirectory, 0755); err != nil {
+			out.Error("Error creating audit directory: %v", err)
+			return 1
+		}
+		options = &orchestrator.Options{
+			AuditConfig: &auditConfig,
+			AppVersion:  "1.0.0",
+			MachineID:   getMachineID(),
+		}
+	}
+
+	result, err := orchestrator.RenameRuleFromPath(configPath, prefix, newDir, relocate, options)
+	if result != nil {
+		out.Info("Updated rule %s: %s -> %s", result.Prefix, result.OldDirectory, result.NewDirectory)
+		for _, folder := range result.Folders {
+			out.Verbose("Relocated %s -> %s (%d %s)", folder.From, folder.To, folder.Files, pluralize(folder.Files, "file", "files"))
+		}
+		if result.Relocated {
+			out.Info("Relocated %d %s from %d %s", result.Moved, pluralize(result.Moved, "file", "files"),
+				len(result.Folders), pluralize(len(result.Folders), "folder", "folders"))
+		}
+		for _, moveErr := range result.Errors {
+			out.Error("Error: %v", moveErr)
+		}
+	}
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runPromoteCommand moves the files a run staged with --stage up one level,
+// out of their staging folders.
+func runPromoteCommand(configPath string, args []string, verbose bool, jsonErrors bool) int {
+	outConfig := output.DefaultConfig()
+	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
+	out := output.New(outConfig)
+
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		out.Error("Error: expected a run ID")
+		out.Error("Usage: sorta promote <run-id>")
+		return 1
+	}
+	runID := audit.RunID(args[0])
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		out.Error("Error loading config: %v", err)
+		return 1
+	}
+	auditConfig := *cfg.Audit
+	if auditConfig.LogDirectory == "" {
+		auditConfig.LogDirectory = getAuditLogDir()
+	}
+	options := &orchestrator.Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   getMachineID(),
+	}
+
+	result, err := orchestrator.PromoteFromPath(configPath, runID, options)
+	if result != nil {
+		for _, path := range result.Missing {
+			out.Verbose("Missing: %s", path)
+		}
+		out.Info("Promoted %d %s from stage %q of run %s", result.Promoted, pluralize(result.Promoted, "file", "files"), result.Stage, result.RunID)
+		if len(result.Missing) > 0 {
+			out.Info("%d staged %s no longer present", len(result.Missing), pluralize(len(result.Missing), "file", "files"))
+		}
+		if result.PromoteRunID != "" {
+			out.Info("Run ID: %s (undo with: sorta undo %s)", result.PromoteRunID, result.PromoteRunID)
+		}
+		for _, moveErr := range result.Errors {
+			out.Error("Error: %v", moveErr)
+		}
+	}
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
 // getAuditLogDir returns the audit log directory path.
 // It uses the default .sorta/audit directory relative to the current working directory.
 func getAuditLogDir() string {
 	return filepath.Join(".sorta", "audit")
 }
 
+// lastRunCutoff returns the end time of the most recent completed organize run
+// in the configured audit log, or the zero time if there is none.
+func lastRunCutoff(configPath string) (time.Time, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loading config: %w", err)
+	}
+	logDir := cfg.Audit.LogDirectory
+	if logDir == "" {
+		logDir = getAuditLogDir()
+	}
+
+	run, err := audit.NewAuditReader(logDir).GetLatestCompletedRun()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading audit log: %w", err)
+	}
+	if run == nil {
+		return time.Time{}, nil
+	}
+	return *run.EndTime, nil
+}
+
 // runAuditCommand handles the audit subcommands.
 // Requirements: 15.1, 15.2, 15.3, 15.4, 15.5, 15.6, 1.2 - verbose flag passed to command
-func runAuditCommand(args []string, verbose bool) int {
+func runAuditCommand(configPath string, args []string, verbose bool, jsonErrors bool) int {
 	// Create output instance with verbose config
 	outConfig := output.DefaultConfig()
 	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
 	out := output.New(outConfig)
 
 	if len(args) == 0 {
@@ -902,13 +2199,21 @@ func runAuditCommand(args []string, verbose bool) int {
 
 	switch subcommand {
 	case "list":
-		return runAuditListCommand(out)
+		loc, listArgs := auditDisplayLocation(configPath, subArgs)
+		return runAuditListCommand(listArgs, out, loc)
 	case "show":
-		return runAuditShowCommand(subArgs, out)
+		loc, showArgs := auditDisplayLocation(configPath, subArgs)
+		return runAuditShowCommand(showArgs, out, loc)
 	case "export":
 		return runAuditExportCommand(subArgs, out)
 	case "stats":
 		return runAuditStatsCommand(subArgs, out)
+	case "verify":
+		return runAuditVerifyCommand(subArgs, out)
+	case "record":
+		return runAuditRecordCommand(configPath, subArgs, out)
+	case "note":
+		return runAuditNoteCommand(configPath, subArgs, out)
 	case "help", "-h", "--help":
 		printAuditUsage()
 		return 0
@@ -919,9 +2224,79 @@ func runAuditCommand(args []string, verbose bool) int {
 	}
 }
 
+// auditDisplayLocation returns the time zone audit timestamps are shown in and
+// args without the --local and --utc flags. --local selects the local zone and
+// --utc selects UTC; without either, the audit.displayLocalTime setting from
+// the configuration decides (UTC if it cannot be loaded).
+func auditDisplayLocation(configPath string, args []string) (*time.Location, []string) {
+	local := false
+	if cfg, err := config.Load(configPath); err == nil && cfg.Audit != nil {
+		local = cfg.Audit.DisplayLocalTime
+	}
+
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "--local":
+			local = true
+		case "--utc":
+			local = false
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if local {
+		return time.Local, rest
+	}
+	return time.UTC, rest
+}
+
 // runAuditListCommand lists all runs with summary statistics.
+// Timestamps are shown in loc.
 // Requirements: 15.1, 15.3
-func runAuditListCommand(out *output.Output) int {
+func runAuditListCommand(args []string, out *output.Output, loc *time.Location) int {
+	var statusFilter audit.RunStatusFilter
+	var since, until time.Time
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--status", "--since", "--until":
+		default:
+			out.Error("Error: unknown option '%s'", args[i])
+			return 1
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				out.Error("Error: %s requires a value", name)
+				return 1
+			}
+			value = args[i+1]
+			i++
+		}
+
+		var err error
+		switch name {
+		case "--status":
+			statusFilter, err = audit.ParseRunStatusFilter(value)
+		case "--since":
+			since, err = parseDateIn(value, loc, false)
+		case "--until":
+			until, err = parseDateIn(value, loc, true)
+		}
+		if err != nil {
+			out.Error("Error: %s: %v", name, err)
+			if name != "--status" {
+				out.Error("Supported formats: 2024-01-01 or 2024-01-01T15:04:05")
+			}
+			return 1
+		}
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		out.Error("Error: --since must be before --until")
+		return 1
+	}
+
 	logDir := getAuditLogDir()
 	reader := audit.NewAuditReader(logDir)
 
@@ -930,26 +2305,37 @@ func runAuditListCommand(out *output.Output) int {
 		out.Error("Error reading audit log: %v", err)
 		return 1
 	}
+	if statusFilter != "" {
+		runs = audit.FilterRuns(runs, statusFilter)
+	}
+	runs = audit.FilterRunsBetween(runs, since, until)
 
 	if len(runs) == 0 {
-		out.Info("No runs found in audit log.")
+		switch {
+		case !since.IsZero() || !until.IsZero():
+			out.Info("No matching runs found in audit log.")
+		case statusFilter != "":
+			out.Info("No %s runs found in audit log.", statusFilter)
+		default:
+			out.Info("No runs found in audit log.")
+		}
 		return 0
 	}
 
 	out.Info("Audit Trail - Run History")
 	out.Info("%s", strings.Repeat("=", 80))
-	out.Info("%-36s  %-20s  %6s  %6s  %6s  %6s  %-10s",
+	out.Info("%-36s  %-25s  %6s  %6s  %6s  %6s  %-10s",
 		"Run ID", "Timestamp", "Moved", "Skip", "Review", "Errors", "Status")
 	out.Info("%s", strings.Repeat("-", 80))
 
 	for _, run := range runs {
-		timestamp := run.StartTime.Format("2006-01-02 15:04:05")
+		timestamp := output.FormatTimestamp(run.StartTime, "2006-01-02 15:04:05", loc)
 		status := string(run.Status)
 		if run.RunType == audit.RunTypeUndo {
 			status = "UNDO"
 		}
 
-		out.Info("%-36s  %-20s  %6d  %6d  %6d  %6d  %-10s",
+		out.Info("%-36s  %-25s  %6d  %6d  %6d  %6d  %-10s",
 			run.RunID,
 			timestamp,
 			run.Summary.Moved,
@@ -967,24 +2353,75 @@ func runAuditListCommand(out *output.Output) int {
 }
 
 // runAuditShowCommand shows detailed events for a specific run.
+// Timestamps are shown in loc.
 // Requirements: 15.2, 15.4, 15.5
-func runAuditShowCommand(args []string, out *output.Output) int {
+func runAuditShowCommand(args []string, out *output.Output, loc *time.Location) int {
 	if len(args) == 0 {
 		out.Error("Error: missing run-id argument")
-		out.Error("Usage: sorta audit show <run-id> [--type <event-type>]")
+		out.Error("Usage: sorta audit show <run-id> [--type <event-type>|--errors-only] [--grep <pattern>] [--context N] [--page N] [--page-size M] [--group-by-type] [--bytes] [--local|--utc]")
 		return 1
 	}
 
 	runID := audit.RunID(args[0])
 	var filterType string
-
-	// Parse optional --type flag
+	page, pageSize := 0, 0
+	rawBytes := false
+	errorsOnly := false
+	groupByType := false
+	contextSize := -1
+	var grep *regexp.Regexp
+
+	// Parse optional --type, --errors-only, --grep, --context, --page,
+	// --page-size, --bytes and --group-by-type flags
 	for i := 1; i < len(args); i++ {
-		if args[i] == "--type" && i+1 < len(args) {
+		switch {
+		case args[i] == "--bytes":
+			rawBytes = true
+		case args[i] == "--group-by-type":
+			groupByType = true
+		case args[i] == "--errors-only":
+			errorsOnly = true
+		case args[i] == "--type" && i+1 < len(args):
 			filterType = strings.ToUpper(args[i+1])
 			i++
+		case args[i] == "--grep" && i+1 < len(args):
+			pattern, err := regexp.Compile(args[i+1])
+			if err != nil {
+				out.Error("Error: invalid --grep pattern: %v", err)
+				return 1
+			}
+			grep = pattern
+			i++
+		case args[i] == "--context" && i+1 < len(args):
+			n, err := parseDepth(args[i+1])
+			if err != nil || n < 0 {
+				out.Error("Error: --context must be a non-negative integer")
+				return 1
+			}
+			contextSize = n
+			i++
+		case (args[i] == "--page" || args[i] == "--page-size") && i+1 < len(args):
+			n, err := parseDepth(args[i+1]) // reuse parseDepth for integer parsing
+			if err != nil || n < 1 {
+				out.Error("Error: %s must be a positive integer", args[i])
+				return 1
+			}
+			if args[i] == "--page" {
+				page = n
+			} else {
+				pageSize = n
+			}
+			i++
 		}
 	}
+	if errorsOnly && filterType != "" {
+		out.Error("Error: --errors-only cannot be combined with --type")
+		return 1
+	}
+	if contextSize >= 0 && (groupByType || page > 0 || pageSize > 0) {
+		out.Error("Error: --context cannot be combined with --group-by-type or pagination")
+		return 1
+	}
 
 	logDir := getAuditLogDir()
 	reader := audit.NewAuditReader(logDir)
@@ -996,13 +2433,36 @@ func runAuditShowCommand(args []string, out *output.Output) int {
 		return 1
 	}
 
-	// Get events with optional filtering
+	// matched reports whether an event passes --grep, on top of the type
+	// filters applied when the events are read
+	matched := func(event audit.AuditEvent) bool {
+		return grep == nil || event.MatchesPattern(grep)
+	}
+
+	// Get events with optional filtering. With --context every event is read,
+	// and the filters pick the events the windows are centred on.
 	var events []audit.AuditEvent
-	if filterType != "" {
+	var windows [][]audit.AuditEvent
+	if contextSize >= 0 {
+		events, err = reader.GetRun(runID)
+		if err == nil {
+			windows = audit.ContextWindows(events, func(event audit.AuditEvent) bool {
+				if filterType != "" && string(event.EventType) != filterType {
+					return false
+				}
+				if errorsOnly && !slices.Contains(audit.ErrorEventTypes, event.EventType) {
+					return false
+				}
+				return matched(event)
+			}, contextSize)
+		}
+	} else if filterType != "" {
 		filter := audit.EventFilter{
 			EventTypes: []audit.EventType{audit.EventType(filterType)},
 		}
 		events, err = reader.FilterEvents(runID, filter)
+	} else if errorsOnly {
+		events, err = reader.FilterEvents(runID, audit.EventFilter{EventTypes: audit.ErrorEventTypes})
 	} else {
 		events, err = reader.GetRun(runID)
 	}
@@ -1011,6 +2471,37 @@ func runAuditShowCommand(args []string, out *output.Output) int {
 		out.Error("Error reading events: %v", err)
 		return 1
 	}
+	if contextSize < 0 && grep != nil {
+		events = slices.DeleteFunc(events, func(event audit.AuditEvent) bool { return !matched(event) })
+	}
+
+	// Group across the whole run before a page is cut, so that pages follow
+	// the grouped order and each header counts the group's events in all pages
+	groupHeaders := make(map[audit.EventType]string)
+	if groupByType {
+		groups := audit.GroupEventsByType(events)
+		for _, group := range groups {
+			groupHeaders[group.Type] = group.Header()
+		}
+		events = audit.GroupedEvents(groups)
+	}
+
+	// Slice to the requested page when either pagination flag is given
+	var eventPage *audit.EventPage
+	if page > 0 || pageSize > 0 {
+		if page == 0 {
+			page = 1
+		}
+		if pageSize == 0 {
+			pageSize = audit.DefaultPageSize
+		}
+		eventPage, err = audit.PaginateEvents(events, page, pageSize)
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+		events = eventPage.Events
+	}
 
 	// Display run header
 	out.Info("Audit Trail - Run Details")
@@ -1018,9 +2509,9 @@ func runAuditShowCommand(args []string, out *output.Output) int {
 	out.Info("Run ID:     %s", runInfo.RunID)
 	out.Info("Type:       %s", runInfo.RunType)
 	out.Info("Status:     %s", runInfo.Status)
-	out.Info("Started:    %s", runInfo.StartTime.Format("2006-01-02 15:04:05"))
+	out.Info("Started:    %s", output.FormatTimestamp(runInfo.StartTime, "2006-01-02 15:04:05", loc))
 	if runInfo.EndTime != nil {
-		out.Info("Ended:      %s", runInfo.EndTime.Format("2006-01-02 15:04:05"))
+		out.Info("Ended:      %s", output.FormatTimestamp(*runInfo.EndTime, "2006-01-02 15:04:05", loc))
 	}
 	if runInfo.UndoTargetID != nil {
 		out.Info("Undo of:    %s", *runInfo.UndoTargetID)
@@ -1040,19 +2531,55 @@ func runAuditShowCommand(args []string, out *output.Output) int {
 	out.Info("")
 
 	// Display events
+	var filters []string
 	if filterType != "" {
-		out.Info("Events (filtered by type: %s):", filterType)
+		filters = append(filters, "filtered by type: "+filterType)
+	} else if errorsOnly {
+		filters = append(filters, "errors only")
+	}
+	if grep != nil {
+		filters = append(filters, "matching: "+grep.String())
+	}
+	if len(filters) > 0 {
+		out.Info("Events (%s):", strings.Join(filters, ", "))
 	} else {
 		out.Info("Events:")
 	}
 	out.Info("%s", strings.Repeat("-", 80))
 
-	for _, event := range events {
-		displayEventWithOutput(event, out)
+	shown := len(events)
+	if contextSize >= 0 {
+		shown = 0
+		for i, window := range windows {
+			if i > 0 {
+				out.Info("--")
+				out.Info("")
+			}
+			for _, event := range window {
+				displayEventWithOutput(event, out, rawBytes, loc)
+			}
+			shown += len(window)
+		}
+	} else if groupByType {
+		for i, event := range events {
+			if i == 0 || events[i-1].EventType != event.EventType {
+				out.Info("%s", groupHeaders[event.EventType])
+				out.Info("")
+			}
+			displayEventWithOutput(event, out, rawBytes, loc)
+		}
+	} else {
+		for _, event := range events {
+			displayEventWithOutput(event, out, rawBytes, loc)
+		}
 	}
 
 	out.Info("%s", strings.Repeat("-", 80))
-	out.Info("Total events shown: %d", len(events))
+	if eventPage != nil {
+		out.Info("%s", eventPage.Footer())
+	} else {
+		out.Info("Total events shown: %d", shown)
+	}
 
 	return 0
 }
@@ -1074,6 +2601,9 @@ func displayEvent(event audit.AuditEvent) {
 	if event.ErrorDetails != nil {
 		fmt.Printf("         Error:  [%s] %s\n", event.ErrorDetails.ErrorType, event.ErrorDetails.ErrorMessage)
 	}
+	if event.EventType == audit.EventNote {
+		fmt.Printf("         Note:   %s\n", event.Metadata["note"])
+	}
 	if event.FileIdentity != nil {
 		fmt.Printf("         Hash:   %s (size: %d)\n", event.FileIdentity.ContentHash[:16]+"...", event.FileIdentity.Size)
 	}
@@ -1081,8 +2611,10 @@ func displayEvent(event audit.AuditEvent) {
 }
 
 // displayEventWithOutput formats and prints a single audit event using the output package.
-func displayEventWithOutput(event audit.AuditEvent, out *output.Output) {
-	timestamp := event.Timestamp.Format("15:04:05")
+// File sizes are shown in binary units, or in bytes when rawBytes is set, and
+// the timestamp in loc.
+func displayEventWithOutput(event audit.AuditEvent, out *output.Output, rawBytes bool, loc *time.Location) {
+	timestamp := output.FormatTimestamp(event.Timestamp, "15:04:05", loc)
 	out.Info("[%s] %-20s %s", timestamp, event.EventType, event.Status)
 
 	if event.SourcePath != "" {
@@ -1097,26 +2629,59 @@ func displayEventWithOutput(event audit.AuditEvent, out *output.Output) {
 	if event.ErrorDetails != nil {
 		out.Info("         Error:  [%s] %s", event.ErrorDetails.ErrorType, event.ErrorDetails.ErrorMessage)
 	}
+	if event.EventType == audit.EventNote {
+		out.Info("         Note:   %s", event.Metadata["note"])
+	}
 	if event.FileIdentity != nil {
-		out.Info("         Hash:   %s (size: %d)", event.FileIdentity.ContentHash[:16]+"...", event.FileIdentity.Size)
+		size := output.FormatBytes(event.FileIdentity.Size)
+		if rawBytes {
+			size = fmt.Sprintf("%d", event.FileIdentity.Size)
+		}
+		out.Info("         Hash:   %s (size: %s)", event.FileIdentity.ContentHash[:16]+"...", size)
 	}
 	out.Info("")
 }
 
 // runAuditExportCommand exports run audit data to a file.
+// With --anonymize, paths and machine IDs are replaced by stable tokens.
 // Requirements: 15.6
 func runAuditExportCommand(args []string, out *output.Output) int {
-	if len(args) == 0 {
+	anonymize := false
+	redactHashes := false
+	outputFile := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--output" && i+1 < len(args):
+			i++
+			outputFile = args[i]
+		case arg == "--anonymize":
+			anonymize = true
+		case arg == "--redact-hashes":
+			redactHashes = true
+		case strings.HasPrefix(arg, "-"):
+			out.Error("Error: unknown flag '%s'", arg)
+			return 1
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
 		out.Error("Error: missing run-id argument")
-		out.Error("Usage: sorta audit export <run-id> [output-file]")
+		out.Error("Usage: sorta audit export <run-id> [output-file] [--anonymize [--redact-hashes]]")
+		return 1
+	}
+	if redactHashes && !anonymize {
+		out.Error("Error: --redact-hashes requires --anonymize")
 		return 1
 	}
 
-	runID := audit.RunID(args[0])
-	outputFile := ""
-	if len(args) > 1 {
-		outputFile = args[1]
-	} else {
+	runID := audit.RunID(positional[0])
+	if len(positional) > 1 {
+		outputFile = positional[1]
+	} else if outputFile == "" {
 		// Default output filename
 		outputFile = fmt.Sprintf("audit-export-%s.json", runID)
 	}
@@ -1138,6 +2703,19 @@ func runAuditExportCommand(args []string, out *output.Output) int {
 		return 1
 	}
 
+	if anonymize {
+		anonymizer, err := audit.NewAnonymizer(nil)
+		if err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+		anonymizer.RedactHashes = redactHashes
+		*runInfo = anonymizer.RunInfo(*runInfo)
+		for i := range events {
+			events[i] = anonymizer.Event(events[i])
+		}
+	}
+
 	// Create export structure
 	export := struct {
 		RunInfo audit.RunInfo      `json:"runInfo"`
@@ -1166,14 +2744,212 @@ func runAuditExportCommand(args []string, out *output.Output) int {
 	return 0
 }
 
+// runAuditVerifyCommand checks audit log files for corrupt lines and runs
+// missing their start or end events, optionally repairing corrupt files.
+func runAuditVerifyCommand(args []string, out *output.Output) int {
+	var runID audit.RunID
+	repair := false
+	for _, arg := range args {
+		switch {
+		case arg == "--all":
+			runID = ""
+		case arg == "--repair":
+			repair = true
+		case strings.HasPrefix(arg, "-"):
+			out.Error("Error: unknown option '%s'", arg)
+			out.Error("Usage: sorta audit verify [run-id | --all] [--repair]")
+			return 1
+		default:
+			runID = audit.RunID(arg)
+		}
+	}
+
+	reader := audit.NewAuditReader(getAuditLogDir())
+	result, err := reader.Verify(runID)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	printVerifyResult(result, out)
+	if result.OK() {
+		return 0
+	}
+	if !repair {
+		out.Info("Run 'sorta audit verify --repair' to truncate corrupt logs at the first bad line and mark unfinished runs as interrupted.")
+		return 1
+	}
+
+	repairedFiles := make(map[string]bool)
+	for _, issue := range result.Issues {
+		if issue.FilePath == "" || repairedFiles[issue.FilePath] {
+			continue
+		}
+		repairedFiles[issue.FilePath] = true
+
+		repaired, err := audit.RepairLog(issue.FilePath)
+		if err != nil {
+			out.Error("Error repairing %s: %v", issue.FilePath, err)
+			return 1
+		}
+		if repaired == nil {
+			continue
+		}
+		out.Info("Repaired %s: truncated at offset %d (%d lines removed)",
+			repaired.FilePath, repaired.Offset, repaired.LinesRemoved)
+		for _, id := range repaired.InterruptedRuns {
+			out.Info("  Marked run %s as %s", id, audit.RunStatusInterrupted)
+		}
+	}
+
+	result, err = reader.Verify(runID)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	out.Info("")
+	printVerifyResult(result, out)
+	if !result.OK() {
+		return 1
+	}
+	return 0
+}
+
+// runAuditRecordCommand writes the moves listed in a plan file into a new
+// audit run without moving any file.
+func runAuditRecordCommand(configPath string, args []string, out *output.Output) int {
+	planPath := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--from-plan" && i+1 < len(args):
+			i++
+			planPath = args[i]
+		default:
+			out.Error("Error: unknown option '%s'", arg)
+			out.Error("Usage: sorta audit record --from-plan <plan.json>")
+			return 1
+		}
+	}
+	if planPath == "" {
+		out.Error("Error: missing --from-plan")
+		out.Error("Usage: sorta audit record --from-plan <plan.json>")
+		return 1
+	}
+
+	moves, err := orchestrator.ReadMovePlan(planPath)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		out.Error("Error loading config: %v", err)
+		return 1
+	}
+	auditConfig := *cfg.Audit
+	if auditConfig.LogDirectory == "" {
+		auditConfig.LogDirectory = getAuditLogDir()
+	}
+	options := &orchestrator.Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   getMachineID(),
+	}
+
+	result, err := orchestrator.RecordMoves(moves, options)
+	if result != nil {
+		out.Info("Recorded %d %s in run %s", result.Recorded, pluralize(result.Recorded, "move", "moves"), result.RunID)
+		for _, recordErr := range result.Errors {
+			out.Error("Error: %v", recordErr)
+		}
+	}
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runAuditNoteCommand appends a NOTE event with free-form text to an existing
+// run, e.g. to record why it was made. The run's events are left unchanged.
+func runAuditNoteCommand(configPath string, args []string, out *output.Output) int {
+	if len(args) != 2 || strings.TrimSpace(args[1]) == "" {
+		out.Error("Error: expected a run-id and the note text")
+		out.Error("Usage: sorta audit note <run-id> \"text\"")
+		return 1
+	}
+	runID := audit.RunID(args[0])
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		out.Error("Error loading config: %v", err)
+		return 1
+	}
+	auditConfig := *cfg.Audit
+	if auditConfig.LogDirectory == "" {
+		auditConfig.LogDirectory = getAuditLogDir()
+	}
+
+	if _, err := audit.NewAuditReader(auditConfig.LogDirectory).GetRunByID(runID); err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	defer writer.Close()
+	if err := writer.RecordNote(runID, args[1]); err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+
+	out.Info("Added note to run %s", runID)
+	return 0
+}
+
+// printVerifyResult prints the outcome of an audit log verification.
+func printVerifyResult(result *audit.VerifyResult, out *output.Output) {
+	out.Info("Checked %d log files, %d events, %d runs", result.FilesChecked, result.EventsChecked, result.RunsChecked)
+	if result.OK() {
+		out.Info("No problems found.")
+		return
+	}
+
+	out.Info("Found %d problems:", len(result.Issues))
+	for _, issue := range result.Issues {
+		if issue.Line > 0 {
+			out.Info("  %s line %d (offset %d): %s", issue.FilePath, issue.Line, issue.Offset, issue.Message)
+		} else {
+			out.Info("  %s run %s: %s", issue.FilePath, issue.RunID, issue.Message)
+		}
+	}
+}
+
 // runAuditStatsCommand displays aggregate statistics across all audit runs.
 // Requirements: 4.1, 4.7
 func runAuditStatsCommand(args []string, out *output.Output) int {
 	var sinceTime *time.Time
+	topN := 10 // Show top 10 prefixes by default
 
-	// Parse --since flag
+	// Parse --since and --top flags
 	for i := 0; i < len(args); i++ {
-		if args[i] == "--since" && i+1 < len(args) {
+		if args[i] == "--top" && i+1 < len(args) {
+			n, err := parseDepth(args[i+1]) // reuse parseDepth for integer parsing
+			if err != nil {
+				out.Error("Error: --top must be a non-negative integer (0 shows every prefix)")
+				return 1
+			}
+			topN = n
+			i++
+		} else if args[i] == "--since" && i+1 < len(args) {
 			t, err := parseSinceDate(args[i+1])
 			if err != nil {
 				out.Error("Error parsing --since date: %v", err)
@@ -1199,7 +2975,7 @@ func runAuditStatsCommand(args []string, out *output.Output) int {
 	// Create stats options
 	opts := audit.StatsOptions{
 		Since: sinceTime,
-		TopN:  10, // Show top 10 prefixes by default
+		TopN:  topN,
 	}
 
 	// Aggregate stats
@@ -1244,27 +3020,10 @@ func runAuditStatsCommand(args []string, out *output.Output) int {
 	out.Info("")
 
 	// Display per-prefix breakdown
-	if len(stats.ByPrefix) > 0 {
-		out.Info("Files by Prefix (top %d):", len(stats.ByPrefix))
-		// Sort prefixes by count for display
-		type prefixCount struct {
-			prefix string
-			count  int
-		}
-		var sorted []prefixCount
-		for prefix, count := range stats.ByPrefix {
-			sorted = append(sorted, prefixCount{prefix, count})
-		}
-		// Sort by count descending
-		for i := 0; i < len(sorted)-1; i++ {
-			for j := i + 1; j < len(sorted); j++ {
-				if sorted[j].count > sorted[i].count {
-					sorted[i], sorted[j] = sorted[j], sorted[i]
-				}
-			}
-		}
-		for _, pc := range sorted {
-			out.Info("  %-20s %d", pc.prefix, pc.count)
+	if len(stats.TopPrefixes) > 0 {
+		out.Info("Files by Prefix (top %d):", len(stats.TopPrefixes))
+		for _, pc := range stats.TopPrefixes {
+			out.Info("  %-20s %d", pc.Prefix, pc.Count)
 		}
 	}
 
@@ -1274,13 +3033,23 @@ func runAuditStatsCommand(args []string, out *output.Output) int {
 // parseSinceDate parses a date string in various formats.
 // Supported formats: 2024-01-01 or 2024-01-01T15:04:05
 func parseSinceDate(s string) (time.Time, error) {
+	return parseDateIn(s, time.UTC, false)
+}
+
+// parseDateIn parses a date in the formats of parseSinceDate, in loc. With
+// endOfDay set, a date without a time stands for the start of the next day,
+// so that a range ending there includes the whole day.
+func parseDateIn(s string, loc *time.Location, endOfDay bool) (time.Time, error) {
 	// Try full datetime format first
-	if t, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", s, loc); err == nil {
 		return t, nil
 	}
 
 	// Try date-only format
-	if t, err := time.Parse("2006-01-02", s); err == nil {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
 		return t, nil
 	}
 
@@ -1289,15 +3058,26 @@ func parseSinceDate(s string) (time.Time, error) {
 
 // runUndoCommand handles the undo command.
 // Requirements: 4.1, 4.2, 4.3, 5.1, 5.3, 6.1, 7.2
-func runUndoCommand(args []string, verbose bool) int {
-	// Create output instance with verbose config
-	outConfig := output.DefaultConfig()
-	outConfig.Verbose = verbose
-	out := output.New(outConfig)
+func runUndoCommand(configPath string, args []string, verbose bool, jsonErrors bool, progressTo string, progressWidth int, force bool, noHashCache bool) int {
+	// Create output instance with verbose config and line width
+	out := output.New(outputConfig(verbose, progressWidth, jsonErrors))
+
+	closeProgress, err := attachProgressSink(out, progressTo, "undo")
+	if err != nil {
+		out.Error("Error: %v", err)
+		return 1
+	}
+	defer closeProgress()
 
 	var runID string
 	var preview bool
+	var continueUndo bool
+	var missingReport string
+	var reportPath string
+	var pathRoot string
 	var pathMappings []audit.PathMapping
+	onCollision := audit.CollisionFail
+	inodeCheck := audit.InodeCheckWarn
 
 	// Parse arguments
 	for i := 0; i < len(args); i++ {
@@ -1305,6 +3085,8 @@ func runUndoCommand(args []string, verbose bool) int {
 		switch {
 		case arg == "--preview":
 			preview = true
+		case arg == "--continue":
+			continueUndo = true
 		case arg == "--path-mapping" && i+1 < len(args):
 			i++
 			mapping, err := parsePathMapping(args[i])
@@ -1313,6 +3095,31 @@ func runUndoCommand(args []string, verbose bool) int {
 				return 1
 			}
 			pathMappings = append(pathMappings, mapping)
+		case arg == "--on-collision" && i+1 < len(args):
+			i++
+			resolution, err := audit.ParseCollisionResolution(args[i])
+			if err != nil {
+				out.Error("Error: %v", err)
+				return 1
+			}
+			onCollision = resolution
+		case arg == "--inode-check" && i+1 < len(args):
+			i++
+			mode, err := audit.ParseInodeCheck(args[i])
+			if err != nil {
+				out.Error("Error: %v", err)
+				return 1
+			}
+			inodeCheck = mode
+		case arg == "--missing-report" && i+1 < len(args):
+			i++
+			missingReport = args[i]
+		case arg == "--report" && i+1 < len(args):
+			i++
+			reportPath = args[i]
+		case arg == "--path-root" && i+1 < len(args):
+			i++
+			pathRoot = args[i]
 		case !strings.HasPrefix(arg, "-"):
 			runID = arg
 		default:
@@ -1322,12 +3129,24 @@ func runUndoCommand(args []string, verbose bool) int {
 		}
 	}
 
+	if continueUndo && (runID != "" || preview) {
+		out.Error("Error: --continue resumes the most recent undo and cannot be combined with a run-id or --preview")
+		return 1
+	}
+
 	logDir := getAuditLogDir()
 	reader := audit.NewAuditReader(logDir)
 
+	var hashCache *audit.HashCache
+	if !noHashCache {
+		var saveCache func()
+		hashCache, saveCache = loadHashCache(logDir, out)
+		defer saveCache()
+	}
+
 	// If preview mode, show what would be undone
 	if preview {
-		return runUndoPreview(reader, runID, pathMappings)
+		return runUndoPreview(reader, runID, pathMappings, pathRoot, hashCache, out)
 	}
 
 	// Create writer for recording undo operations
@@ -1342,6 +3161,9 @@ func runUndoCommand(args []string, verbose bool) int {
 
 	// Create undo engine
 	engine := audit.NewUndoEngine(reader, writer, "1.0.0", getMachineID())
+	if hashCache != nil {
+		engine.SetHashCache(hashCache)
+	}
 
 	// Track if progress has been started
 	progressStarted := false
@@ -1355,8 +3177,13 @@ func runUndoCommand(args []string, verbose bool) int {
 			progressStarted = true
 		}
 
-		// Update progress indicator (only shown in non-verbose TTY mode)
-		out.UpdateProgress(event.Current, "Restoring file")
+		// Update progress indicator (only shown in non-verbose TTY mode),
+		// with the bytes restored so far when file sizes are known
+		message := "Restoring file"
+		if event.BytesTotal > 0 {
+			message = fmt.Sprintf("Restoring file (%s of %s)", output.FormatBytes(event.BytesDone), output.FormatBytes(event.BytesTotal))
+		}
+		out.UpdateProgressPath(event.Current, message, event.SourcePath)
 
 		// Verbose output for each undo operation
 		switch event.Type {
@@ -1365,6 +3192,9 @@ func runUndoCommand(args []string, verbose bool) int {
 			out.Verbose("Restoring: %s", event.SourcePath)
 			out.Verbose("  From: %s", event.DestPath)
 			out.Verbose("  To: %s", event.SourcePath)
+		case "trash":
+			out.Verbose("Moved to trash: %s", event.SourcePath)
+			out.Verbose("  Trash: %s", event.DestPath)
 		case "skip":
 			// Requirement 4.2: Display skip reasons for files that cannot be restored
 			out.Verbose("Skipping: %s", event.SourcePath)
@@ -1394,18 +3224,43 @@ func runUndoCommand(args []string, verbose bool) int {
 	// Set the callback on the engine
 	engine.SetCallback(undoCallback)
 
+	undoConfig := audit.CrossMachineUndoConfig{
+		PathMappings:  pathMappings,
+		OnCollision:   onCollision,
+		InodeCheck:    inodeCheck,
+		Force:         force,
+		MissingReport: missingReport,
+		PathRoot:      pathRoot,
+	}
+	// Undo works without a config; the inbound directory check needs one
+	if cfg, err := config.Load(configPath); err == nil {
+		undoConfig.InboundDirectories = cfg.InboundDirectories
+	}
+
 	var result *audit.UndoResult
-	if runID == "" {
+	if continueUndo {
+		// Resume the most recent interrupted undo
+		result, err = engine.ContinueUndo(undoConfig)
+	} else if runID == "" {
 		// Undo most recent run
-		result, err = engine.UndoLatest(pathMappings)
+		result, err = engine.UndoLatestCrossMachine(undoConfig)
 	} else {
 		// Undo specific run
-		result, err = engine.UndoRun(audit.RunID(runID), pathMappings)
+		result, err = engine.UndoRunCrossMachine(audit.RunID(runID), undoConfig)
 	}
 
 	// End progress indicator before showing results
 	out.EndProgress()
 
+	var inboundErr *audit.InboundRestoreError
+	if errors.As(err, &inboundErr) {
+		out.Error("Warning: %v:", err)
+		for _, path := range inboundErr.Paths {
+			out.Error("  - %s", path)
+		}
+		out.Error("Nothing was restored. Use --force to restore these files anyway.")
+		return 1
+	}
 	if err != nil {
 		out.Error("Error during undo: %v", err)
 		return 1
@@ -1419,6 +3274,9 @@ func runUndoCommand(args []string, verbose bool) int {
 	out.Info("Total Events:   %d", result.TotalEvents)
 	out.Info("Restored:       %d", result.Restored)
 	out.Info("Skipped:        %d", result.Skipped)
+	if continueUndo {
+		out.Info("Already Done:   %d", result.AlreadyRestored)
+	}
 	out.Info("Failed:         %d", result.Failed)
 
 	if len(result.FailureDetails) > 0 {
@@ -1427,6 +3285,20 @@ func runUndoCommand(args []string, verbose bool) int {
 			out.Info("  - %s: %s (%s)", failure.SourcePath, failure.Message, failure.Reason)
 		}
 	}
+	if missingReport != "" {
+		for _, failure := range result.FailureDetails {
+			if failure.Reason == audit.ReasonSourceNotFound {
+				out.Info("\nFiles that could not be found were added to: %s", missingReport)
+				break
+			}
+		}
+	}
+	if reportPath != "" {
+		if err := audit.WriteUndoReport(reportPath, result); err != nil {
+			out.Error("Error: %v", err)
+			return 1
+		}
+	}
 
 	if result.Failed > 0 {
 		return 1
@@ -1434,26 +3306,30 @@ func runUndoCommand(args []string, verbose bool) int {
 	return 0
 }
 
-// runUndoPreview shows what would be undone without executing.
-func runUndoPreview(reader *audit.AuditReader, runID string, pathMappings []audit.PathMapping) int {
+// runUndoPreview shows what would be undone without executing. A non-nil
+// hashCache is used when verifying files. Errors are reported through out.
+func runUndoPreview(reader *audit.AuditReader, runID string, pathMappings []audit.PathMapping, pathRoot string, hashCache *audit.HashCache, out *output.Output) int {
 	// Create a temporary writer (won't actually write)
 	auditConfig := audit.DefaultAuditConfig()
 	auditConfig.LogDirectory = getAuditLogDir()
 	writer, err := audit.NewAuditWriter(auditConfig)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing audit writer: %v\n", err)
+		out.Error("Error initializing audit writer: %v", err)
 		return 1
 	}
 	defer writer.Close()
 
 	engine := audit.NewUndoEngine(reader, writer, "1.0.0", getMachineID())
+	if hashCache != nil {
+		engine.SetHashCache(hashCache)
+	}
 
 	var targetRunID audit.RunID
 	if runID == "" {
 		// Get most recent run
 		latestRun, err := reader.GetLatestRun()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			out.Error("Error: %v", err)
 			return 1
 		}
 		targetRunID = latestRun.RunID
@@ -1461,9 +3337,12 @@ func runUndoPreview(reader *audit.AuditReader, runID string, pathMappings []audi
 		targetRunID = audit.RunID(runID)
 	}
 
-	preview, err := engine.PreviewUndo(targetRunID, pathMappings)
+	preview, err := engine.PreviewUndoCrossMachine(targetRunID, audit.CrossMachineUndoConfig{
+		PathMappings: pathMappings,
+		PathRoot:     pathRoot,
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating preview: %v\n", err)
+		out.Error("Error generating preview: %v", err)
 		return 1
 	}
 
@@ -1499,10 +3378,12 @@ func runUndoPreview(reader *audit.AuditReader, runID string, pathMappings []audi
 
 // runWatchCommand starts the file watcher for automatic organization.
 // Requirements: 1.1, 1.6, 1.7, 2.5 - Watch mode with graceful shutdown and summary
-func runWatchCommand(configPath string, verbose bool, debounceOverride int) int {
+// Batch mode is enabled when batchWindow > 0 or batchMax > 0.
+func runWatchCommand(configPath string, verbose bool, jsonErrors bool, debounceOverride int, batchWindow time.Duration, batchMax int) int {
 	// Create output instance with verbose config
 	outConfig := output.DefaultConfig()
 	outConfig.Verbose = verbose
+	outConfig.JSONErrors = jsonErrors
 	out := output.New(outConfig)
 
 	// Load configuration
@@ -1575,8 +3456,65 @@ func runWatchCommand(configPath string, verbose bool, debounceOverride int) int
 		return result.EventType == "MOVE", result.EventType == "ROUTE_TO_REVIEW", nil
 	}
 
-	// Create the watcher
-	w := watcher.New(watcherCfg, fileHandler)
+	// Create the watcher, batching files into one organizer run per batch if requested
+	batchMode := batchWindow > 0 || batchMax > 0
+	var w *watcher.Watcher
+	if batchMode {
+		if batchWindow <= 0 {
+			batchWindow = watcher.DefaultBatchWindow
+		}
+		if batchMax <= 0 {
+			batchMax = watcher.DefaultBatchMax
+		}
+		watcherCfg.BatchWindow = batchWindow
+		watcherCfg.BatchMax = batchMax
+
+		batchNumber := 0
+		batchHandler := func(paths []string) watcher.BatchResult {
+			batchNumber++
+			summary, err := orchestrator.RunFilesWithOptions(configPath, paths, &orchestrator.Options{
+				AuditConfig: &auditConfig,
+				AppVersion:  "1.0.0",
+				MachineID:   getMachineID(),
+			})
+			if err != nil {
+				out.Error("Batch %d: %d file%s failed: %v", batchNumber, len(paths), pluralize(len(paths), "", "s"), err)
+				if summary == nil {
+					return watcher.BatchResult{Skipped: len(paths)}
+				}
+			}
+
+			result := watcher.BatchResult{
+				Organized: summary.SuccessCount - summary.ReviewCount,
+				Reviewed:  summary.ReviewCount,
+				Skipped:   len(paths) - summary.SuccessCount,
+			}
+
+			if verbose {
+				for _, r := range summary.Results {
+					switch r.EventType {
+					case "MOVE", "DUPLICATE_DETECTED":
+						out.Verbose("Organized: %s -> %s", r.SourcePath, r.DestinationPath)
+					case "ROUTE_TO_REVIEW":
+						out.Verbose("For review: %s -> %s", r.SourcePath, r.DestinationPath)
+					case "SKIP":
+						out.Verbose("Skipped: %s (reason: %s)", r.SourcePath, r.ReasonCode)
+					case "ERROR":
+						out.Verbose("Error: %s: %v", r.SourcePath, r.Error)
+					}
+				}
+			}
+			// Paths that vanished or are directories are not part of the summary
+			skipped := summary.SkippedCount + len(paths) - summary.TotalFiles
+			out.Info("Batch %d: %d file%s (organized %d, for review %d, skipped %d, errors %d)",
+				batchNumber, len(paths), pluralize(len(paths), "", "s"),
+				result.Organized, result.Reviewed, skipped, summary.ErrorCount)
+			return result
+		}
+		w = watcher.NewWithBatchHandler(watcherCfg, batchHandler)
+	} else {
+		w = watcher.New(watcherCfg, fileHandler)
+	}
 
 	// Set up signal handling for graceful shutdown
 	// Requirements: 1.6 - Continue running until interrupted (Ctrl+C)
@@ -1590,6 +3528,9 @@ func runWatchCommand(configPath string, verbose bool, debounceOverride int) int
 		out.Info("  - %s", dir)
 	}
 	out.Info("Debounce: %d seconds", watcherCfg.DebounceSeconds)
+	if batchMode {
+		out.Info("Batching: up to %d files or %s per run", watcherCfg.BatchMax, watcherCfg.BatchWindow)
+	}
 	out.Info("Press Ctrl+C to stop")
 	out.Info("")
 
@@ -1618,6 +3559,9 @@ func runWatchCommand(configPath string, verbose bool, debounceOverride int) int
 	out.Info("Files organized: %d", summary.FilesOrganized)
 	out.Info("Files for review: %d", summary.FilesReviewed)
 	out.Info("Files skipped: %d", summary.FilesSkipped)
+	if batchMode {
+		out.Info("Batches: %d", summary.Batches)
+	}
 
 	return 0
 }
@@ -1675,20 +3619,72 @@ Subcommands:
   show <run-id>         Show detailed events for a specific run
   export <run-id>       Export run audit data to a file
   stats                 Display aggregate statistics across all runs
+  verify [run-id]       Check audit logs for corrupt lines and incomplete runs
+  record                Record moves made outside Sorta so they can be undone
+  note <run-id> "text"  Add a note to a run, shown by 'show' and ignored by undo
 
 Options for 'show':
   --type <event-type>   Filter events by type (e.g., MOVE, SKIP, ERROR)
+  --errors-only         Show only failures: ERROR, PARSE_FAILURE, VALIDATION_FAILURE,
+                        COLLISION, CONTENT_CHANGED, SOURCE_MISSING, CONFLICT_DETECTED
+  --page N              Show page N of the (filtered) events
+  --page-size M         Events per page (default: 100)
+  --bytes               Show file sizes in bytes instead of KiB/MiB
+  --grep <pattern>      Show only events whose paths, reason or error message match
+                        the regular expression
+  --group-by-type       List events grouped by type, with a count per type; pages
+                        follow the grouped order
+  --context N           Also show N events before and after each event matched by
+                        --type, --errors-only or --grep; windows are separated by "--"
+
+Options for 'list':
+  --status <status>     List only completed, failed, interrupted or undo runs
+  --since <date>        List only runs started on or after this date
+  --until <date>        List only runs started before this date, or on it when no
+                        time is given (format: 2024-01-01 or 2024-01-01T15:04:05,
+                        in the time zone timestamps are shown in)
+
+Options for 'list' and 'show':
+  --local               Show timestamps in the local time zone (with its offset)
+  --utc                 Show timestamps in UTC (default unless audit.displayLocalTime is set)
 
 Options for 'stats':
   --since <date>        Filter stats to runs after this date (format: 2024-01-01 or 2024-01-01T15:04:05)
+  --top N               Show the N most active prefixes (default: 10, 0 = all)
+
+Options for 'export':
+  --anonymize           Replace path names and machine IDs with stable tokens
+                        (extensions, dates and years are kept)
+  --redact-hashes       With --anonymize, zero content hashes as well
+
+Options for 'verify':
+  --all                 Verify every run (default when no run-id is given)
+  --repair              Truncate each corrupt log at its first bad line and mark
+                        runs left without an end event as INTERRUPTED
+
+Options for 'record':
+  --from-plan <file>    JSON plan of the moves: {"moves": [{"source": ..., "destination": ...}]}.
+                        Nothing is moved; each file's identity is read at its destination
 
 Examples:
   sorta audit list
+  sorta audit list --status failed
+  sorta audit list --since 2024-01-01 --until 2024-01-31
   sorta audit show abc123-def456-...
   sorta audit show abc123-def456-... --type MOVE
+  sorta audit show abc123-def456-... --errors-only
+  sorta audit show abc123-def456-... --errors-only --context 2
+  sorta audit show abc123-def456-... --page 2 --page-size 50
+  sorta audit show abc123-def456-... --group-by-type
   sorta audit export abc123-def456-... output.json
+  sorta audit export abc123-def456-... shared.json --anonymize
   sorta audit stats
-  sorta audit stats --since 2024-01-01`)
+  sorta audit stats --since 2024-01-01
+  sorta audit stats --top 5
+  sorta audit verify --all
+  sorta audit verify --repair
+  sorta audit record --from-plan plan.json
+  sorta audit note abc123-def456-... "Tidied up before the tax return"`)
 }
 
 // printUndoUsage prints usage information for the undo command.
@@ -1700,13 +3696,37 @@ Arguments:
 
 Options:
   --preview             Show what would be undone without making changes
+  --continue            Resume the most recent undo if it was interrupted, skipping
+                        files it already restored
   --path-mapping <map>  Path mapping for cross-machine undo (format: original:mapped)
+  --path-root <dir>     Resolve the paths of a run recorded with --relative-paths
+                        against <dir> instead of the root it recorded
+  --on-collision <mode> What to do when the original location is occupied:
+                        fail (default), trash, or keep-both
+  --inode-check <mode>  When a file was replaced by another with identical content:
+                        warn (default, record and restore), strict (skip it), or off
+  --force               Restore files even if a later run touched them, or into an
+                        inbound directory the run did not scan
+                        (an occupied original location is still never overwritten)
+  --no-hash-cache       Hash every file when verifying it instead of reusing the hash
+                        of files whose size and modification time are unchanged
+  --missing-report <f>  Append each file that could not be found to the JSON report <f>,
+                        with its expected path and recorded hash, for manual placement
+  --report <file>       Write the result of this undo (counts and failure details) to
+                        <file> as JSON
 
 Examples:
   sorta undo                                    Undo most recent run
   sorta undo abc123-def456-...                  Undo specific run
   sorta undo --preview                          Preview undo of most recent run
-  sorta undo --path-mapping /old/path:/new/path Cross-machine undo with path mapping`)
+  sorta undo --continue                         Finish an interrupted undo
+  sorta undo --path-mapping /old/path:/new/path Cross-machine undo with path mapping
+  sorta undo --path-root /mnt/archive           Undo a relative-path run after moving its tree
+  sorta undo --on-collision trash               Move blocking files to .sorta/trash/<undo-run-id>/
+  sorta undo --inode-check strict               Never restore files replaced with identical content
+  sorta undo --force abc123-def456-...          Undo an older run despite later-run conflicts
+  sorta undo --missing-report missing.json      Record files that could not be found
+  sorta undo --report undo.json                 Save the result as JSON for other tools`)
 }
 
 func printUsage() {
@@ -1716,64 +3736,159 @@ Usage: sorta [flags] <command> [arguments]
 
 Commands:
   config                Display current configuration
+  config show-rule <p>  Show one prefix rule and how many files it has organized
+  config edit           Open the config in $EDITOR and validate it on save
+  config lint           Warn about ambiguous prefixes, shared or missing folders, empty inbound dirs
   add-inbound <dir>     Add an inbound directory to configuration
   discover <dir>        Auto-discover prefix rules from existing directories
   run                   Execute file organization
   watch                 Monitor directories and organize files automatically
   status                Show pending files across all inbound directories
+  dedupe-report         Report files with identical content across inbound directories
+  rename-rule <prefix>  Change a prefix rule's outbound directory
+  promote <run-id>      Move a staged run's files out of their staging folders
   audit <subcommand>    View audit trail history
   undo [run-id]         Undo file operations from a run
 
 Flags:
   -c, --config <path>   Config file path (default: sorta-config.json)
   -v, --verbose         Enable verbose output for detailed operation information
+  --progress-to <file>  Write run/discover/undo progress as JSON lines to a file or fifo
+  --progress-width N    Fit run/discover/undo progress and explain lines to N columns (0 = no limit)
+  --json-errors         Write errors to stderr as JSON lines: {"level":"error","msg":"..."}
   -h, --help            Show this help message
 
 Config Options:
   --validate            Validate configuration and report errors
+  --with-counts         Show how many pending files in the inbound directories match each rule
 
 Discover Options:
   --depth N             Limit scan depth (0 = immediate directory only, default: unlimited)
   --interactive         Prompt to accept or reject each discovered rule
+  --max-dirs N          Ask for confirmation if the tree has more than N directories (default: 5000)
+  --force               Skip the directory count check (required for large trees in non-TTY)
+  --merge-target <dir>  Point every new rule to <dir>/<prefix> instead of where it was found
+  --prefix-transform M  Change the case of discovered prefixes: title (default), upper, lower or none
 
 Run Options:
   --depth N             Override scan depth (0 = immediate directory only)
   --dry-run             Preview what files would be moved without making changes
+  --only-prefix P       Only organize files matching prefix P (repeatable); others are left in place
+  --normalize-spaces    Collapse repeated spaces/tabs in destination names (same as "normalizeSpaces")
+  --strip-diacritics    Transliterate accented characters in destination names (same as "stripDiacritics")
+  --check-locks         Skip files locked by another process (FILE_LOCKED) instead of moving them
+  --settle <duration>   Stat each file again <duration> (e.g. 2s) after the run starts and skip
+                        files whose size or modification time changed (STILL_WRITING)
+  --no-create-dirs      Skip files whose destination folder does not exist (DEST_DIR_MISSING)
+  --dedupe-within-run   Skip files identical to one already moved to the same name in this run
+  --no-hash-cache       Hash every file instead of reusing hashes of unchanged files
+  --fail-fast           Stop at the first file that fails, end the run as FAILED and exit 1
+  --max-errors N        Abort once N files have failed, end the run as FAILED and exit 1
+  --skip-outbound-check Don't check that every outbound directory is writable before the run
+  --transactional       Stage files below the audit directory and move them to their
+                        destinations only once every file is processed
+  --resume              Finish a --transactional run that stopped before moving its staged files
+  --trace <file>        Write every matching and routing decision to <file> as timestamped
+                        key=value lines (also with --dry-run)
+  --verify-after-move   Check each file copied to another volume against the source before
+                        removing the source; keep the source on a mismatch (POST_MOVE_VERIFY_FAILED)
+  --dir-perm <mode>     Create destination folders with octal <mode>, e.g. 0775 (same as "dirPermissions")
+  --dir <path>          Organize only <path>, ignoring the configured inbound directories
+  --since-last-run      Skip files modified before the last completed run ended (BEFORE_LAST_RUN)
+  --stage <name>        Move files into a <name> folder below each destination (see promote)
+  --relative-paths <d>  Record paths in the audit log relative to <d> (see undo --path-root)
+  --status-line         End with "SORTA_RESULT moved=N review=N skipped=N errors=N runId=ID"
+  --explain             Print one line per file: matched rule, parsed date, destination, decision
+  --notify              Show a desktop notification with the counts when the run ends
+  --retry-file <file>   Write the source paths of files that failed to <file> (JSON)
+  --summary-json-to <f> Write the run summary (run ID, times, counts, hasErrors) to <f> as JSON
+  --retry-from <file>   Organize only the files listed in <file> and rewrite it with
+                        those that still fail (unless --retry-file names another file)
+  --include-from <file> Organize only the files listed in <file>, one absolute or
+                        inbound-relative path per line
 
 Watch Options:
   --debounce N          Override debounce period in seconds (default: 2)
+  --batch-window D      Organize new files in batches, at most D apart (e.g. 10s; default: 10s)
+  --batch-max N         Maximum files per batch (default: 100); one audit run per batch
+
+Dedupe Report Options:
+  --json                Print the report as JSON
+
+Rename Rule Options:
+  --dir <path>          New outbound directory for the rule (required)
+  --relocate            Also move the prefix's existing year folders (recorded for undo)
 
 Audit Subcommands:
   audit list            List all runs with summary statistics
   audit show <run-id>   Show detailed events for a specific run
   audit export <run-id> Export run audit data to a file
   audit stats           Display aggregate statistics across all runs
+  audit verify [run-id] Check audit logs for corrupt lines and incomplete runs (--repair to fix)
 
 Undo Options:
   --preview             Show what would be undone without making changes
+  --continue            Resume the most recent undo if it was interrupted, skipping
+                        files it already restored
   --path-mapping <map>  Path mapping for cross-machine undo (format: original:mapped)
+  --path-root <dir>     Root for the paths of a run recorded with --relative-paths
+  --on-collision <mode> What to do when the original location is occupied:
+                        fail (default), trash, or keep-both
+  --inode-check <mode>  Replaced-file check: warn (default), strict, or off
+  --force               Undo despite conflicts with later runs (never overwrites)
+  --no-hash-cache       Hash every file instead of reusing hashes of unchanged files
+  --missing-report <f>  Append files that could not be found to a JSON report
+  --report <file>       Write this undo's counts and failure details as JSON
 
 Examples:
   sorta config                          Show current configuration
   sorta config --validate               Validate configuration
+  sorta config --with-counts            Show the rules with their pending file counts
+  sorta config show-rule invoice        Show the Invoice rule
+  EDITOR=nano sorta config edit         Edit the configuration safely
+  sorta config lint                     Check the rules for common problems
   sorta add-inbound /path/to/inbound    Add an inbound directory
   sorta discover /path/to/organized     Discover prefix rules from existing files
   sorta discover --depth 2 /path        Discover with depth limit of 2 levels
   sorta discover --interactive /path    Discover with interactive prompts for each rule
   sorta discover --depth 2 --interactive /path  Combine depth limit with interactive mode
+  sorta discover --force /path          Discover without the directory count check
+  sorta discover --merge-target /archive /path  Discover rules that all point under /archive
+  sorta discover --prefix-transform title /path  Propose "Invoice" for files named "INVOICE ..."
   sorta run                             Organize files according to configuration
   sorta run --depth 2                   Run with scan depth of 2 levels
   sorta run --dry-run                   Preview what files would be moved
+  sorta run --only-prefix Invoice       Organize only Invoice files
+  sorta run --dir ~/scans --dry-run     Preview organizing an ad-hoc folder with the configured rules
+  sorta run --since-last-run            Organize only files added since the last run
+  sorta run --stage batch-42            Stage files under ".../2024 Invoice/batch-42/" for inspection
+  sorta promote <run-id>                Move the staged files up into ".../2024 Invoice/"
+  sorta run --status-line | tail -n 1   Print only the machine-readable result line
+  sorta run --summary-json-to summary.json  Save the run summary as JSON for CI dashboards
+  sorta run --explain                   Show why each file went where it did
+  sorta run --notify                    Show a desktop notification when the run ends
+  sorta run --retry-file failed.json    Record files that failed, then later:
+  sorta run --retry-from failed.json    Retry just those files
+  sorta run --include-from list.txt --dry-run  Preview organizing only the listed files
+  sorta run --normalize-spaces          Organize "Invoice  2024-01-15  Acme.pdf" as "Invoice 2024-01-15 Acme.pdf"
+  sorta run --strip-diacritics          Organize "Café 2024-01-15 Menu.pdf" as "Cafe 2024-01-15 Menu.pdf"
   sorta watch                           Start watching directories for new files
   sorta watch --debounce 5              Watch with 5 second debounce period
+  sorta watch --batch-window 10s --batch-max 100  Organize new files in batches
   sorta status                          Show pending files in all inbound directories
   sorta -v status                       Show pending files with verbose file listing
+  sorta dedupe-report                   List duplicate files and reclaimable space
+  sorta dedupe-report --json            Duplicate report as JSON
+  sorta rename-rule Invoice --dir /archive/invoices  Point the Invoice rule at a new directory
+  sorta rename-rule Invoice --dir /archive/invoices --relocate  Also move existing Invoice folders
   sorta -v run                          Run with verbose output
   sorta -v watch                        Watch with verbose output
+  sorta --progress-to /tmp/sorta.fifo run  Stream progress to a GUI as JSON lines
   sorta audit list                      List all audit runs
   sorta audit show <run-id>             Show details for a specific run
   sorta undo                            Undo most recent run
   sorta undo --preview                  Preview what would be undone
+  sorta undo --on-collision trash       Undo, moving blocking files to trash
   sorta -c custom.json run              Use custom config file
 
 Config file format (JSON):
@@ -1784,6 +3899,11 @@ Config file format (JSON):
     ],
     "symlinkPolicy": "skip",
     "scanDepth": 0,
+    "preserveSourceSubpath": false,
+    "normalizeSpaces": false,
+    "stripDiacritics": false,
+    "undatedFolder": "",
+    "includes": ["rules/*.json"],
     "watch": {
       "debounceSeconds": 2,
       "stableThresholdMs": 1000,
@@ -1794,5 +3914,20 @@ Config file format (JSON):
 Files matching "<prefix> <YYYY-MM-DD> <description>" are moved to:
   <outboundDirectory>/<year> <prefix>/<normalized filename>
 
+An outboundDirectory with {year}, {month}, {day} or {prefix} tokens, such as
+"/archive/{year}/{month}", is filled in and used without the "<year> <prefix>" folder.
+
+With "preserveSourceSubpath": true and scanDepth > 0, a file's subdirectory
+within its inbound directory is kept under "<year> <prefix>/".
+
+With "normalizeSpaces": true, repeated spaces or tabs between prefix, date and
+description are accepted and collapsed to single spaces in the destination name.
+
+With "stripDiacritics": true, accented characters in the destination name are
+transliterated to ASCII (e.g. "Café" becomes "Cafe").
+
+With "undatedFolder": "undated", files that match a prefix but have no valid date
+are moved to "<outboundDirectory>/undated <prefix>/" instead of for-review.
+
 Files not matching any rule go to a "for-review" subdirectory within their inbound directory.`)
 }
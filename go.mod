@@ -3,8 +3,10 @@ module sorta
 go 1.24.0
 
 require (
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
-	github.com/leanovate/gopter v0.2.11 // indirect
-	golang.org/x/sys v0.40.0 // indirect
-	golang.org/x/term v0.39.0 // indirect
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/sys v0.40.0
+	golang.org/x/term v0.39.0
+	golang.org/x/text v0.33.0
 )
+
+require github.com/leanovate/gopter v0.2.11
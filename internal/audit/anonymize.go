@@ -0,0 +1,164 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// anonymizedPatterns matches the parts of a path component that are kept as
+// they are: ISO dates and four-digit years.
+var anonymizedPatterns = regexp.MustCompile(`\d{4}-\d{2}-\d{2}|\b\d{4}\b`)
+
+// redactedHash replaces content hashes when hashes are redacted.
+var redactedHash = strings.Repeat("0", sha256.Size*2)
+
+// Anonymizer replaces the names in audit paths with stable tokens so an export
+// can be shared without revealing real paths. The same text always gets the
+// same token from one Anonymizer, so directory structure, shared prefixes and
+// repeated files stay recognizable; extensions, dates and years are kept.
+// Tokens are keyed with a per-export salt, so they cannot be matched across
+// exports or reversed by hashing guessed names.
+type Anonymizer struct {
+	salt         []byte
+	RedactHashes bool // Zero content hashes instead of keeping them
+}
+
+// NewAnonymizer returns an Anonymizer keyed with salt. A random salt is used
+// when salt is empty.
+func NewAnonymizer(salt []byte) (*Anonymizer, error) {
+	if len(salt) == 0 {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate anonymization salt: %w", err)
+		}
+	}
+	return &Anonymizer{salt: salt}, nil
+}
+
+// token returns the stable token for text.
+func (a *Anonymizer) token(text string) string {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(text))
+	return "x" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
+
+// Path anonymizes every component of path, keeping separators, a leading
+// root, extensions, dates and years.
+func (a *Anonymizer) Path(path string) string {
+	if path == "" {
+		return ""
+	}
+	slashed := filepath.ToSlash(path)
+	volume := filepath.VolumeName(path)
+	parts := strings.Split(strings.TrimPrefix(slashed, filepath.ToSlash(volume)), "/")
+	for i, part := range parts {
+		parts[i] = a.component(part)
+	}
+	return filepath.FromSlash(volume + strings.Join(parts, "/"))
+}
+
+// component anonymizes a single path component.
+func (a *Anonymizer) component(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name
+	}
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range anonymizedPatterns.FindAllStringIndex(base, -1) {
+		sb.WriteString(a.text(base[last:loc[0]]))
+		sb.WriteString(base[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(a.text(base[last:]))
+	sb.WriteString(ext)
+	return sb.String()
+}
+
+// text anonymizes a run of text between kept patterns, preserving the
+// surrounding spaces so "Invoice 2024-01-15 Acme" keeps its shape.
+func (a *Anonymizer) text(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + a.token(trimmed) + s[start+len(trimmed):]
+}
+
+// Event returns a copy of event with its paths, path metadata, notes and machine ID
+// anonymized, and its content hash zeroed when RedactHashes is set. Paths
+// quoted in error messages are replaced too.
+func (a *Anonymizer) Event(event AuditEvent) AuditEvent {
+	anonymized := event
+	anonymized.SourcePath = a.Path(event.SourcePath)
+	anonymized.DestinationPath = a.Path(event.DestinationPath)
+
+	if event.FileIdentity != nil && a.RedactHashes {
+		identity := *event.FileIdentity
+		identity.ContentHash = redactedHash
+		anonymized.FileIdentity = &identity
+	}
+
+	if event.ErrorDetails != nil {
+		details := *event.ErrorDetails
+		details.ErrorMessage = a.replacePaths(details.ErrorMessage, event.SourcePath, event.DestinationPath)
+		anonymized.ErrorDetails = &details
+	}
+
+	if event.Metadata != nil {
+		anonymized.Metadata = make(map[string]string, len(event.Metadata))
+		for key, value := range event.Metadata {
+			switch {
+			case key == "machineId" && value != "":
+				value = a.token(value)
+			case key == "note" && value != "":
+				// Notes are free-form and may name anything
+				value = a.token(value)
+			case key == "matchedPrefix" && value != "":
+				// Same token as the prefix in anonymized paths
+				value = a.token(value)
+			case filepath.IsAbs(value):
+				value = a.Path(value)
+			}
+			anonymized.Metadata[key] = value
+		}
+	}
+
+	return anonymized
+}
+
+// RunInfo returns a copy of info with its machine ID anonymized.
+func (a *Anonymizer) RunInfo(info RunInfo) RunInfo {
+	if info.MachineID != "" {
+		info.MachineID = a.token(info.MachineID)
+	}
+	return info
+}
+
+// replacePaths replaces occurrences of the given paths (and their directories)
+// in message with their anonymized form.
+func (a *Anonymizer) replacePaths(message string, paths ...string) string {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		message = strings.ReplaceAll(message, path, a.Path(path))
+		if dir := filepath.Dir(path); dir != "." && dir != string(filepath.Separator) {
+			message = strings.ReplaceAll(message, dir, a.Path(dir))
+		}
+	}
+	return message
+}
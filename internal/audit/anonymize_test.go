@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnonymizerHidesNamesAndKeepsStructure verifies that real path segments
+// do not survive anonymization while separators, extensions, dates and years
+// do, and that the same name always maps to the same token.
+func TestAnonymizerHidesNamesAndKeepsStructure(t *testing.T) {
+	anonymizer, err := NewAnonymizer([]byte("test-salt"))
+	if err != nil {
+		t.Fatalf("NewAnonymizer failed: %v", err)
+	}
+
+	source := filepath.FromSlash("/home/alice/inbox/Invoice 2024-01-15 Acme.pdf")
+	dest := filepath.FromSlash("/home/alice/Invoices/2024 Invoice/Invoice 2024-01-15 Acme.pdf")
+	event := AuditEvent{
+		RunID:           "run-1",
+		EventType:       EventMove,
+		Status:          StatusSuccess,
+		SourcePath:      source,
+		DestinationPath: dest,
+		FileIdentity:    &FileIdentity{ContentHash: "abc123", Size: 10},
+		Metadata:        map[string]string{"intendedDestination": dest, "reason": "DUPLICATE_RENAMED", "matchedPrefix": "Invoice"},
+	}
+
+	got := anonymizer.Event(event)
+	for _, secret := range []string{"alice", "inbox", "Invoice", "Acme", "home"} {
+		for _, path := range []string{got.SourcePath, got.DestinationPath, got.Metadata["intendedDestination"]} {
+			if strings.Contains(path, secret) {
+				t.Errorf("Anonymized path %q still contains %q", path, secret)
+			}
+		}
+	}
+
+	destParts := strings.Split(filepath.ToSlash(got.DestinationPath), "/")
+	if len(destParts) != 6 || destParts[0] != "" {
+		t.Fatalf("Expected an absolute path with 5 components, got %q", got.DestinationPath)
+	}
+	if !strings.HasPrefix(destParts[4], "2024 ") {
+		t.Errorf("Expected year to be kept in folder name, got %q", destParts[4])
+	}
+	if !strings.Contains(destParts[5], " 2024-01-15 ") || !strings.HasSuffix(destParts[5], ".pdf") {
+		t.Errorf("Expected date and extension to be kept in file name, got %q", destParts[5])
+	}
+
+	// Shared components map to the same tokens
+	if filepath.Base(got.SourcePath) != filepath.Base(got.DestinationPath) {
+		t.Errorf("Expected the same file name to get the same token: %q vs %q", got.SourcePath, got.DestinationPath)
+	}
+	if !strings.HasPrefix(destParts[5], strings.TrimPrefix(destParts[4], "2024 ")+" ") {
+		t.Errorf("Expected prefix token in folder and file name to match: %q, %q", destParts[4], destParts[5])
+	}
+	if got.Metadata["matchedPrefix"] != strings.TrimPrefix(destParts[4], "2024 ") {
+		t.Errorf("Expected matched prefix to get the same token as in paths, got %q", got.Metadata["matchedPrefix"])
+	}
+	if got.Metadata["intendedDestination"] != got.DestinationPath || got.Metadata["reason"] != "DUPLICATE_RENAMED" {
+		t.Errorf("Unexpected metadata: %v", got.Metadata)
+	}
+
+	if got.FileIdentity.ContentHash != "abc123" {
+		t.Errorf("Expected content hash to be kept, got %q", got.FileIdentity.ContentHash)
+	}
+	anonymizer.RedactHashes = true
+	if redacted := anonymizer.Event(event); redacted.FileIdentity.ContentHash != redactedHash {
+		t.Errorf("Expected content hash to be zeroed, got %q", redacted.FileIdentity.ContentHash)
+	}
+	if event.SourcePath != source || event.FileIdentity.ContentHash != "abc123" {
+		t.Error("Expected the original event to be left unchanged")
+	}
+}
@@ -0,0 +1,122 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// compressedLogSuffix is appended to a log file's name when it is gzipped.
+const compressedLogSuffix = ".gz"
+
+// IsCompressedLog returns true if path names a gzipped log file.
+func IsCompressedLog(path string) bool {
+	return strings.HasSuffix(path, compressedLogSuffix)
+}
+
+// gzipFile closes both the gzip stream and the underlying file.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// openLogFile opens a log file for reading, transparently decompressing
+// gzipped logs.
+func openLogFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompressedLog(path) {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open compressed log: %w", err)
+	}
+	return &gzipFile{Reader: gz, file: file}, nil
+}
+
+// CompressLogFile gzips the closed log file at path into path+".gz" and removes
+// the original. It returns the path of the compressed file.
+func CompressLogFile(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open log for compression: %w", err)
+	}
+	defer src.Close()
+
+	compressedPath := path + compressedLogSuffix
+	tmpPath := compressedPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create compressed log: %w", err)
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to compress log %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmpPath, compressedPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to rename compressed log: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove uncompressed log: %w", err)
+	}
+
+	return compressedPath, nil
+}
+
+// compressClosedLogsLocked gzips the given closed run log (if any) and every
+// rotated segment that is not yet compressed. The active shared log is left
+// alone so it can still be appended to.
+func (w *AuditWriter) compressClosedLogsLocked(runLogPath string) error {
+	if runLogPath != "" {
+		if _, err := CompressLogFile(runLogPath); err != nil {
+			return err
+		}
+	}
+
+	segments, err := DiscoverSegments(w.config.LogDirectory)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if IsCompressedLog(segment) {
+			continue
+		}
+		if _, err := CompressLogFile(filepath.Join(w.config.LogDirectory, segment)); err != nil {
+			return err
+		}
+		if err := renameIndexedSegment(w.config.LogDirectory, segment, segment+compressedLogSuffix); err != nil {
+			// The index can be rebuilt from the filesystem if needed
+			fmt.Fprintf(os.Stderr, "warning: failed to update rotation index: %v\n", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCompressClosedRunsGzipsRunLog verifies that with CompressClosedRuns a
+// run's log is gzipped when the run ends and remains readable.
+func TestCompressClosedRunsGzipsRunLog(t *testing.T) {
+	logDir := t.TempDir()
+	config := AuditConfig{LogDirectory: logDir, PartitionByDate: true, CompressClosedRuns: true}
+
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	start := time.Now()
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	if err := writer.RecordMove("/src/a.txt", "/dst/a.txt", nil); err != nil {
+		t.Fatalf("Failed to record move: %v", err)
+	}
+
+	runLogPath := PartitionedRunLogPath(logDir, runID, start)
+	if _, err := os.Stat(runLogPath); err != nil {
+		t.Fatalf("Expected uncompressed log while the run is active: %v", err)
+	}
+
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{TotalFiles: 1, Moved: 1}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+
+	if _, err := os.Stat(runLogPath); !os.IsNotExist(err) {
+		t.Errorf("Expected uncompressed log to be removed, got %v", err)
+	}
+	file, err := os.Open(runLogPath + ".gz")
+	if err != nil {
+		t.Fatalf("Expected gzipped run log: %v", err)
+	}
+	defer file.Close()
+	if _, err := gzip.NewReader(file); err != nil {
+		t.Errorf("Expected valid gzip data: %v", err)
+	}
+
+	reader := NewAuditReader(logDir)
+	events, err := reader.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if len(events) != 3 || events[1].EventType != EventMove || events[1].DestinationPath != "/dst/a.txt" {
+		t.Errorf("Expected RUN_START, MOVE, RUN_END from the compressed log, got %+v", events)
+	}
+
+	result, err := reader.Verify(runID)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.OK() {
+		t.Errorf("Expected compressed log to verify cleanly, got %+v", result.Issues)
+	}
+}
+
+// TestCompressClosedRunsGzipsRotatedSegments verifies that rotated segments of
+// the shared log are gzipped when a run ends while the active log is not.
+func TestCompressClosedRunsGzipsRotatedSegments(t *testing.T) {
+	logDir := t.TempDir()
+	config := AuditConfig{LogDirectory: logDir, RotationSize: 512, CompressClosedRuns: true}
+
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := writer.RecordMove("/src/a.txt", "/dst/a.txt", nil); err != nil {
+			t.Fatalf("Failed to record move: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond) // keep rotated segment names unique
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{TotalFiles: 10, Moved: 10}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+
+	segments, err := DiscoverSegments(logDir)
+	if err != nil {
+		t.Fatalf("DiscoverSegments failed: %v", err)
+	}
+	if len(segments) == 0 {
+		t.Fatal("Expected the log to have rotated")
+	}
+	for _, segment := range segments {
+		if !IsCompressedLog(segment) {
+			t.Errorf("Expected segment %s to be compressed", segment)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "sorta-audit.jsonl")); err != nil {
+		t.Errorf("Expected active log to stay uncompressed: %v", err)
+	}
+
+	events, err := NewAuditReader(logDir).FilterEvents(runID, EventFilter{EventTypes: []EventType{EventMove}})
+	if err != nil {
+		t.Fatalf("FilterEvents failed: %v", err)
+	}
+	if len(events) != 10 {
+		t.Errorf("Expected 10 MOVE events across compressed segments, got %d", len(events))
+	}
+}
@@ -0,0 +1,147 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// hashCacheName is the file name of the hash cache in the audit log directory.
+const hashCacheName = "sorta-hash-cache.json"
+
+// HashCache remembers the content hash of files by path, size and modification
+// time, so files that have not changed since they were last hashed are not
+// read again. An entry is only used while the file's size and modification
+// time are the ones it was recorded with.
+type HashCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]hashCacheEntry
+	dirty   bool
+}
+
+// hashCacheEntry is the cached hash of a single file.
+type hashCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // Unix nanoseconds
+	Hash    string `json:"hash"`
+}
+
+// LoadHashCache loads the hash cache stored in logDir. A missing cache file
+// yields an empty cache. A cache file that cannot be parsed is an error; the
+// returned cache is still usable and starts empty.
+func LoadHashCache(logDir string) (*HashCache, error) {
+	cache := &HashCache{
+		path:    filepath.Join(logDir, hashCacheName),
+		entries: make(map[string]hashCacheEntry),
+	}
+
+	data, err := os.ReadFile(cache.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("failed to read hash cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		cache.entries = make(map[string]hashCacheEntry)
+		return cache, fmt.Errorf("invalid hash cache %s: %w", cache.path, err)
+	}
+	return cache, nil
+}
+
+// Lookup returns the cached hash of the file at path, described by info. It
+// reports false when there is no entry or the file's size or modification
+// time has changed since it was hashed.
+func (c *HashCache) Lookup(path string, info os.FileInfo) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || entry.Size != info.Size() || entry.ModTime != info.ModTime().UnixNano() {
+		return "", false
+	}
+	return entry.Hash, true
+}
+
+// Store records hash as the content hash of the file at path, described by
+// info, replacing any earlier entry.
+func (c *HashCache) Store(path string, info os.FileInfo, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[path] = hashCacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    hash,
+	}
+	c.dirty = true
+}
+
+// Moved carries the cached hash of the file at src over to dst, where the
+// file has been moved or copied, so that later runs and undo, which look the
+// file up at its destination, find it. The entry is carried over only when
+// dst has the size it was recorded with, with dst's modification time. Unless
+// copied is set, the entry of src is dropped.
+func (c *HashCache) Moved(src, dst string, copied bool) {
+	info, err := os.Stat(dst)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[src]
+	if !ok {
+		return
+	}
+	if !copied {
+		delete(c.entries, src)
+		c.dirty = true
+	}
+	if err != nil || info.IsDir() || info.Size() != entry.Size {
+		return
+	}
+	c.entries[dst] = hashCacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    entry.Hash,
+	}
+	c.dirty = true
+}
+
+// Save writes the cache back to the audit log directory if it has changed
+// since it was loaded. Entries of files that no longer exist are dropped.
+func (c *HashCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(c.entries, path)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hash cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
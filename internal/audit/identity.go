@@ -21,18 +21,32 @@ const (
 	IdentitySizeMismatch
 	// IdentityNotFound indicates the file was not found.
 	IdentityNotFound
+	// IdentityInodeMismatch indicates the content matches but the file has a
+	// different device/inode, i.e. it was replaced by another file.
+	IdentityInodeMismatch
 )
 
 // IdentityResolver provides methods for capturing and verifying file identity.
-type IdentityResolver struct{}
+type IdentityResolver struct {
+	cache  *HashCache                        // Hashes of unchanged files (nil = always hash)
+	hasher func(path string) (string, error) // Computes content hashes (nil = computeSHA256)
+}
 
 // NewIdentityResolver creates a new IdentityResolver instance.
 func NewIdentityResolver() *IdentityResolver {
 	return &IdentityResolver{}
 }
 
+// NewIdentityResolverWithCache creates an IdentityResolver that takes the
+// content hash of files whose size and modification time have not changed
+// from cache instead of reading them again, and records new hashes in it.
+func NewIdentityResolverWithCache(cache *HashCache) *IdentityResolver {
+	return &IdentityResolver{cache: cache}
+}
+
 // CaptureIdentity captures the identity of a file at the given path.
-// It computes the SHA-256 hash, file size, and modification time.
+// It computes the SHA-256 hash, file size, and modification time, plus the
+// device and inode numbers on platforms that have them.
 // Requirements: 4.1, 4.2, 4.3
 func (r *IdentityResolver) CaptureIdentity(path string) (*FileIdentity, error) {
 	// Get file info for size and mod time
@@ -46,20 +60,56 @@ func (r *IdentityResolver) CaptureIdentity(path string) (*FileIdentity, error) {
 	}
 
 	// Compute SHA-256 hash
-	hash, err := computeSHA256(path)
+	hash, err := r.contentHash(path, info)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute hash: %w", err)
 	}
 
-	return &FileIdentity{
+	identity := &FileIdentity{
 		ContentHash: hash,
 		Size:        info.Size(),
 		ModTime:     info.ModTime(),
-	}, nil
+	}
+	if dev, ino, ok := fileInode(info); ok {
+		identity.Device = dev
+		identity.Inode = ino
+	}
+	return identity, nil
+}
+
+// ForMove returns the identity to record for moving the file it describes
+// from src into destDir. A rename keeps the file's device and inode, but a
+// move to another volume falls back to copying and the copy gets new ones, so
+// they are left out unless destDir, or its nearest existing parent, is on the
+// same device as src. Undo then compares the content only.
+func (f *FileIdentity) ForMove(src, destDir string) *FileIdentity {
+	if f == nil || !f.HasInode() {
+		return f
+	}
+	dir := destDir
+	for {
+		if info, err := os.Stat(dir); err == nil {
+			if dev, _, ok := fileInode(info); ok && dev == f.Device {
+				return f
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	withoutInode := *f
+	withoutInode.Device = 0
+	withoutInode.Inode = 0
+	return &withoutInode
 }
 
 // VerifyIdentity compares a file at the given path against an expected identity.
 // It returns the match result indicating whether the file matches or why it doesn't.
+// When both the expected identity and the file have inode numbers, a file with
+// matching content but a different device/inode yields IdentityInodeMismatch.
 // Requirements: 4.6
 func (r *IdentityResolver) VerifyIdentity(path string, expected FileIdentity) (IdentityMatch, error) {
 	// Check if file exists
@@ -77,7 +127,7 @@ func (r *IdentityResolver) VerifyIdentity(path string, expected FileIdentity) (I
 	}
 
 	// Compute and compare hash
-	hash, err := computeSHA256(path)
+	hash, err := r.contentHash(path, info)
 	if err != nil {
 		return IdentityNotFound, fmt.Errorf("failed to compute hash: %w", err)
 	}
@@ -86,6 +136,12 @@ func (r *IdentityResolver) VerifyIdentity(path string, expected FileIdentity) (I
 		return IdentityHashMismatch, nil
 	}
 
+	if expected.HasInode() {
+		if dev, ino, ok := fileInode(info); ok && (dev != expected.Device || ino != expected.Inode) {
+			return IdentityInodeMismatch, nil
+		}
+	}
+
 	return IdentityMatches, nil
 }
 
@@ -106,7 +162,8 @@ func (r *IdentityResolver) FindByHash(hash string, searchDirs []string) ([]strin
 				return nil
 			}
 
-			fileHash, err := computeSHA256(path)
+			// Only files a run touched are cached, not every file searched
+			fileHash, err := r.lookupHash(path, info)
 			if err != nil {
 				// Skip files we can't read
 				return nil
@@ -126,6 +183,34 @@ func (r *IdentityResolver) FindByHash(hash string, searchDirs []string) ([]strin
 	return matches, nil
 }
 
+// contentHash returns the SHA-256 hash of the file at path, described by info,
+// from the cache when the file is unchanged since it was last hashed. A newly
+// computed hash is recorded in the cache.
+func (r *IdentityResolver) contentHash(path string, info os.FileInfo) (string, error) {
+	hash, err := r.lookupHash(path, info)
+	if err != nil {
+		return "", err
+	}
+	if r.cache != nil {
+		r.cache.Store(path, info, hash)
+	}
+	return hash, nil
+}
+
+// lookupHash is contentHash without recording new hashes in the cache.
+func (r *IdentityResolver) lookupHash(path string, info os.FileInfo) (string, error) {
+	if r.cache != nil {
+		if hash, ok := r.cache.Lookup(path, info); ok {
+			return hash, nil
+		}
+	}
+	hasher := r.hasher
+	if hasher == nil {
+		hasher = computeSHA256
+	}
+	return hasher(path)
+}
+
 // computeSHA256 computes the SHA-256 hash of a file and returns it as a hex string.
 func computeSHA256(path string) (string, error) {
 	f, err := os.Open(path)
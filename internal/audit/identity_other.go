@@ -0,0 +1,12 @@
+//go:build !unix
+
+package audit
+
+import (
+	"os"
+)
+
+// fileInode reports that inode numbers are not available on this platform.
+func fileInode(info os.FileInfo) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
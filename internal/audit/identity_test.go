@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -421,3 +422,133 @@ func TestCaptureIdentity_NonExistent(t *testing.T) {
 		t.Error("Expected error when capturing identity of non-existent file")
 	}
 }
+
+// TestVerifyIdentity_HashCache verifies that a second verification of an
+// unchanged file takes its hash from the cache, that a changed modification
+// time invalidates the entry, and that the cache survives a save and load.
+func TestVerifyIdentity_HashCache(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "audit")
+	path := filepath.Join(tempDir, "Invoice 2024-01-15 Acme.pdf")
+	if err := os.WriteFile(path, []byte("invoice content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	expected, err := NewIdentityResolver().CaptureIdentity(path)
+	if err != nil {
+		t.Fatalf("CaptureIdentity failed: %v", err)
+	}
+
+	cache, err := LoadHashCache(logDir)
+	if err != nil {
+		t.Fatalf("LoadHashCache failed: %v", err)
+	}
+	calls := 0
+	countingHasher := func(path string) (string, error) {
+		calls++
+		return computeSHA256(path)
+	}
+	resolver := NewIdentityResolverWithCache(cache)
+	resolver.hasher = countingHasher
+
+	verify := func(wantCalls int) {
+		t.Helper()
+		match, err := resolver.VerifyIdentity(path, *expected)
+		if err != nil {
+			t.Fatalf("VerifyIdentity failed: %v", err)
+		}
+		if match != IdentityMatches {
+			t.Errorf("Expected IdentityMatches, got %v", match)
+		}
+		if calls != wantCalls {
+			t.Errorf("Expected %d hash computations, got %d", wantCalls, calls)
+		}
+	}
+
+	verify(1)
+	verify(1)
+
+	// A new modification time invalidates the cached hash
+	later := expected.ModTime.Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to change modification time: %v", err)
+	}
+	verify(2)
+	verify(2)
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := LoadHashCache(logDir)
+	if err != nil {
+		t.Fatalf("LoadHashCache failed: %v", err)
+	}
+	resolver = NewIdentityResolverWithCache(reloaded)
+	resolver.hasher = countingHasher
+	verify(2)
+}
+
+// TestFindByHashDoesNotGrowCache verifies that searching directories by hash
+// reuses cached hashes but does not add the files it walks to the cache.
+func TestFindByHashDoesNotGrowCache(t *testing.T) {
+	tempDir := t.TempDir()
+	searchDir := filepath.Join(tempDir, "search")
+	os.MkdirAll(searchDir, 0755)
+	path := filepath.Join(searchDir, "a.txt")
+	os.WriteFile(path, []byte("content"), 0644)
+	identity, err := NewIdentityResolver().CaptureIdentity(path)
+	if err != nil {
+		t.Fatalf("CaptureIdentity failed: %v", err)
+	}
+
+	cache, _ := LoadHashCache(filepath.Join(tempDir, "audit"))
+	matches, err := NewIdentityResolverWithCache(cache).FindByHash(identity.ContentHash, []string{searchDir})
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Expected one match, got %v (%v)", matches, err)
+	}
+	info, _ := os.Stat(path)
+	if _, ok := cache.Lookup(path, info); ok {
+		t.Errorf("Expected %s not to be cached by the search", path)
+	}
+}
+
+// TestFileIdentityForMove verifies that a move into a directory on the same
+// device keeps the device and inode, since it is a rename, and that a move to
+// another device leaves them out, since the file is copied there.
+func TestFileIdentityForMove(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	identity, err := NewIdentityResolver().CaptureIdentity(src)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+	if !identity.HasInode() {
+		t.Skip("inode numbers are not available on this platform")
+	}
+
+	// The destination does not exist yet; its parent is on the same device
+	sameDevice := identity.ForMove(src, filepath.Join(tempDir, "dest", "2024 Invoice"))
+	if sameDevice.Device != identity.Device || sameDevice.Inode != identity.Inode {
+		t.Errorf("Expected the inode to be kept for a rename, got %+v", sameDevice)
+	}
+
+	otherDevice := *identity
+	otherDevice.Device++
+	moved := otherDevice.ForMove(src, filepath.Join(tempDir, "dest"))
+	if moved.HasInode() || moved.Device != 0 {
+		t.Errorf("Expected no inode for a move to another device, got %+v", moved)
+	}
+	if moved.ContentHash != identity.ContentHash || moved.Size != identity.Size {
+		t.Errorf("Expected the content identity to be kept, got %+v", moved)
+	}
+	if !otherDevice.HasInode() {
+		t.Error("Expected ForMove to leave the original identity unchanged")
+	}
+
+	var none *FileIdentity
+	if none.ForMove(src, tempDir) != nil {
+		t.Error("Expected a nil identity to stay nil")
+	}
+}
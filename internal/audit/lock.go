@@ -0,0 +1,29 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sorta/internal/filesystem"
+)
+
+// logLockName is the file name of the lock in the audit log directory.
+const logLockName = "sorta-audit.lock"
+
+// LockLogDirectory takes the lock on the audit log directory logDir, waiting
+// while another process holds it. It guards state in the directory that is
+// read and then written back, such as the run sequence counter and the
+// staging areas of transactional runs. Calling unlock releases it; the lock
+// is not reentrant, so a process must not take it twice.
+func LockLogDirectory(logDir string) (unlock func() error, err error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to lock audit log directory: %w", err)
+	}
+	unlock, err = filesystem.Lock(filepath.Join(logDir, logLockName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock audit log directory: %w", err)
+	}
+	return unlock, nil
+}
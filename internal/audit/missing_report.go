@@ -0,0 +1,58 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MissingFileEntry records a file undo could not find, so it can be located
+// and put back manually.
+type MissingFileEntry struct {
+	RecordedAt   time.Time `json:"recordedAt"`
+	UndoRunID    RunID     `json:"undoRunId"`
+	TargetRunID  RunID     `json:"targetRunId"`
+	EventType    EventType `json:"eventType"`             // Event that was being undone
+	ExpectedPath string    `json:"expectedPath"`          // Where undo expected to find the file
+	OriginalPath string    `json:"originalPath"`          // Where the file should be restored to
+	ContentHash  string    `json:"contentHash,omitempty"` // Hash recorded when the file was moved
+	Message      string    `json:"message"`
+}
+
+// MissingReport is the JSON document written by AppendMissingReport.
+type MissingReport struct {
+	Entries []MissingFileEntry `json:"entries"`
+}
+
+// AppendMissingReport appends entries to the JSON report at path, creating the
+// report if it does not exist yet.
+func AppendMissingReport(path string, entries []MissingFileEntry) error {
+	var report MissingReport
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &report); err != nil {
+			return fmt.Errorf("invalid missing-file report %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read missing-file report: %w", err)
+	}
+
+	report.Entries = append(report.Entries, entries...)
+	data, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal missing-file report: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write missing-file report: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write missing-file report: %w", err)
+	}
+	return nil
+}
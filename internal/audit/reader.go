@@ -8,8 +8,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -111,6 +113,32 @@ func (r *AuditReader) GetLatestRun() (*RunInfo, error) {
 	return &runs[0], nil
 }
 
+// GetLatestCompletedRun returns the ORGANIZE run with status COMPLETED that
+// ended most recently, or nil if there is none (including when the log
+// directory does not exist yet).
+func (r *AuditReader) GetLatestCompletedRun() (*RunInfo, error) {
+	if _, err := os.Stat(r.logDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	runs, err := r.ListRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *RunInfo
+	for i := range runs {
+		run := &runs[i]
+		if run.RunType != RunTypeOrganize || run.Status != RunStatusCompleted || run.EndTime == nil {
+			continue
+		}
+		if latest == nil || run.EndTime.After(*latest.EndTime) {
+			latest = run
+		}
+	}
+	return latest, nil
+}
+
 // FilterEvents returns events matching the filter criteria for a specific run.
 // Requirements: 15.5
 func (r *AuditReader) FilterEvents(runID RunID, filter EventFilter) ([]AuditEvent, error) {
@@ -133,6 +161,200 @@ func (r *AuditReader) FilterAllEvents(filter EventFilter) ([]AuditEvent, error)
 	return r.applyFilter(events, filter), nil
 }
 
+// DefaultPageSize is the number of events per page when only a page number is given.
+const DefaultPageSize = 100
+
+// EventPage is one window of an event list.
+type EventPage struct {
+	Events      []AuditEvent // Events on this page
+	Page        int          // 1-based page number
+	TotalPages  int          // Number of pages (at least 1)
+	TotalEvents int          // Events across all pages
+	First       int          // 1-based index of the first event on this page (0 if empty)
+	Last        int          // 1-based index of the last event on this page (0 if empty)
+}
+
+// PaginateEvents returns the given 1-based page of events. It slices the
+// already-loaded list; a page past the end is an error.
+func PaginateEvents(events []AuditEvent, page, pageSize int) (*EventPage, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("page must be at least 1, got %d", page)
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("page size must be at least 1, got %d", pageSize)
+	}
+
+	total := len(events)
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		return nil, fmt.Errorf("page %d out of range (1-%d)", page, totalPages)
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	result := &EventPage{
+		Events:      events[start:end],
+		Page:        page,
+		TotalPages:  totalPages,
+		TotalEvents: total,
+	}
+	if end > start {
+		result.First = start + 1
+		result.Last = end
+	}
+	return result, nil
+}
+
+// Footer describes the window, e.g. "Showing 1–100 of 54321 (page 1/544)".
+func (p *EventPage) Footer() string {
+	return fmt.Sprintf("Showing %d–%d of %d (page %d/%d)", p.First, p.Last, p.TotalEvents, p.Page, p.TotalPages)
+}
+
+// EventGroup is the events of one type, as listed by "audit show --group-by-type".
+type EventGroup struct {
+	Type   EventType
+	Events []AuditEvent
+}
+
+// GroupEventsByType groups events by type. Groups are in the order their type
+// first appears, and events keep their order within a group.
+func GroupEventsByType(events []AuditEvent) []EventGroup {
+	var groups []EventGroup
+	index := make(map[EventType]int)
+	for _, event := range events {
+		i, ok := index[event.EventType]
+		if !ok {
+			i = len(groups)
+			index[event.EventType] = i
+			groups = append(groups, EventGroup{Type: event.EventType})
+		}
+		groups[i].Events = append(groups[i].Events, event)
+	}
+	return groups
+}
+
+// Header names the group's type and counts its events, e.g. "MOVE (12)".
+func (g EventGroup) Header() string {
+	return fmt.Sprintf("%s (%d)", g.Type, len(g.Events))
+}
+
+// GroupedEvents returns the events of groups one group after another, the
+// order in which "audit show --group-by-type" pages through a run.
+func GroupedEvents(groups []EventGroup) []AuditEvent {
+	var events []AuditEvent
+	for _, group := range groups {
+		events = append(events, group.Events...)
+	}
+	return events
+}
+
+// MatchesPattern reports whether pattern matches the event's source or
+// destination path, reason code or error message, as "audit show --grep"
+// selects events.
+func (e AuditEvent) MatchesPattern(pattern *regexp.Regexp) bool {
+	if pattern.MatchString(e.SourcePath) || pattern.MatchString(e.DestinationPath) || pattern.MatchString(string(e.ReasonCode)) {
+		return true
+	}
+	return e.ErrorDetails != nil && pattern.MatchString(e.ErrorDetails.ErrorMessage)
+}
+
+// ContextWindows returns, for each event for which matched is true, that event
+// with up to n events before and after it, like "grep -C n". Windows that
+// overlap or touch are merged, so each event appears at most once and the
+// windows keep the order of events.
+func ContextWindows(events []AuditEvent, matched func(AuditEvent) bool, n int) [][]AuditEvent {
+	var windows [][]AuditEvent
+	start, end := -1, -1 // Current window, events[start:end]
+	for i, event := range events {
+		if !matched(event) {
+			continue
+		}
+		from := i - n
+		if from < 0 {
+			from = 0
+		}
+		to := i + n + 1
+		if to > len(events) {
+			to = len(events)
+		}
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			windows = append(windows, events[start:end])
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		windows = append(windows, events[start:end])
+	}
+	return windows
+}
+
+// RunStatusFilter selects runs by the status "audit list" shows for them:
+// the RunStatus of an organize run, or UNDO for any undo run.
+type RunStatusFilter string
+
+const (
+	RunFilterCompleted   RunStatusFilter = "completed"
+	RunFilterFailed      RunStatusFilter = "failed"
+	RunFilterInterrupted RunStatusFilter = "interrupted"
+	RunFilterUndo        RunStatusFilter = "undo"
+)
+
+// ParseRunStatusFilter returns the RunStatusFilter named s (in any case).
+func ParseRunStatusFilter(s string) (RunStatusFilter, error) {
+	switch f := RunStatusFilter(strings.ToLower(s)); f {
+	case RunFilterCompleted, RunFilterFailed, RunFilterInterrupted, RunFilterUndo:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid run status %q (use completed, failed, interrupted or undo)", s)
+}
+
+// Matches reports whether run passes the filter.
+func (f RunStatusFilter) Matches(run RunInfo) bool {
+	if run.RunType == RunTypeUndo {
+		return f == RunFilterUndo
+	}
+	return strings.EqualFold(string(run.Status), string(f))
+}
+
+// FilterRuns returns the runs that pass filter, in their original order.
+func FilterRuns(runs []RunInfo, filter RunStatusFilter) []RunInfo {
+	filtered := make([]RunInfo, 0, len(runs))
+	for _, run := range runs {
+		if filter.Matches(run) {
+			filtered = append(filtered, run)
+		}
+	}
+	return filtered
+}
+
+// FilterRunsBetween returns the runs that started at or after since and
+// before until, in their original order. A zero since or until leaves that
+// end of the range open.
+func FilterRunsBetween(runs []RunInfo, since, until time.Time) []RunInfo {
+	filtered := make([]RunInfo, 0, len(runs))
+	for _, run := range runs {
+		if !since.IsZero() && run.StartTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !run.StartTime.Before(until) {
+			continue
+		}
+		filtered = append(filtered, run)
+	}
+	return filtered
+}
+
 // applyFilter filters events based on the given criteria.
 func (r *AuditReader) applyFilter(events []AuditEvent, filter EventFilter) []AuditEvent {
 	var filtered []AuditEvent
@@ -204,7 +426,7 @@ func (r *AuditReader) readAllEvents() ([]AuditEvent, error) {
 
 // readEventsFromFile reads all events from a single log file.
 func (r *AuditReader) readEventsFromFile(filePath string) ([]AuditEvent, error) {
-	file, err := os.Open(filePath)
+	file, err := openLogFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
@@ -242,30 +464,64 @@ func (r *AuditReader) readEventsFromFile(filePath string) ([]AuditEvent, error)
 
 // extractRunInfos extracts RunInfo from a list of events.
 func (r *AuditReader) extractRunInfos(events []AuditEvent) []RunInfo {
-	// Group events by run ID, skipping system events with empty RunID
+	// Group events by run ID, skipping system events with empty RunID, and
+	// remember the order in which runs first appear in the log
 	runEvents := make(map[RunID][]AuditEvent)
+	var order []RunID
 	for _, event := range events {
 		// Skip system events (LOG_INITIALIZED, etc.) that have no RunID
 		if event.RunID == "" {
 			continue
 		}
+		if _, seen := runEvents[event.RunID]; !seen {
+			order = append(order, event.RunID)
+		}
 		runEvents[event.RunID] = append(runEvents[event.RunID], event)
 	}
 
 	var runs []RunInfo
-	for runID, events := range runEvents {
-		runInfo := r.buildRunInfo(runID, events)
+	for _, runID := range order {
+		runInfo := r.buildRunInfo(runID, runEvents[runID])
 		runs = append(runs, runInfo)
 	}
 
-	// Sort by start time (oldest first)
-	sort.Slice(runs, func(i, j int) bool {
+	// Sort by start time (oldest first). Runs started within the same second
+	// keep their log order, since timestamps may be stored to the second.
+	sort.SliceStable(runs, func(i, j int) bool {
 		return runs[i].StartTime.Before(runs[j].StartTime)
 	})
 
 	return runs
 }
 
+// ResolvePaths returns events with the paths recorded relative to a run's
+// PathRoot joined with root. Empty and absolute paths are left as they are.
+func ResolvePaths(events []AuditEvent, root string) []AuditEvent {
+	resolved := make([]AuditEvent, len(events))
+	for i, event := range events {
+		event.SourcePath = resolvePath(event.SourcePath, root)
+		event.DestinationPath = resolvePath(event.DestinationPath, root)
+		if dest, ok := event.Metadata["intendedDestination"]; ok {
+			metadata := make(map[string]string, len(event.Metadata))
+			for key, value := range event.Metadata {
+				metadata[key] = value
+			}
+			metadata["intendedDestination"] = resolvePath(dest, root)
+			event.Metadata = metadata
+		}
+		resolved[i] = event
+	}
+	return resolved
+}
+
+// resolvePath joins a relative path recorded by the audit writer with root.
+func resolvePath(path, root string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
 // buildRunInfo constructs a RunInfo from a list of events for a single run.
 func (r *AuditReader) buildRunInfo(runID RunID, events []AuditEvent) RunInfo {
 	info := RunInfo{
@@ -285,6 +541,14 @@ func (r *AuditReader) buildRunInfo(runID RunID, events []AuditEvent) RunInfo {
 				if runType, ok := event.Metadata["runType"]; ok {
 					info.RunType = RunType(runType)
 				}
+				info.Stage = event.Metadata["stage"]
+				info.PathRoot = event.Metadata["pathRoot"]
+				if sequence, err := strconv.ParseUint(event.Metadata["sequence"], 10, 64); err == nil {
+					info.Sequence = sequence
+				}
+				if dirs := event.Metadata["inboundDirectories"]; dirs != "" {
+					info.InboundDirectories = filepath.SplitList(dirs)
+				}
 				if undoTarget, ok := event.Metadata["undoTargetId"]; ok {
 					targetID := RunID(undoTarget)
 					info.UndoTargetID = &targetID
@@ -303,7 +567,7 @@ func (r *AuditReader) buildRunInfo(runID RunID, events []AuditEvent) RunInfo {
 				info.Summary = r.parseSummaryFromMetadata(event.Metadata)
 			}
 
-		case EventMove:
+		case EventMove, EventCopy:
 			info.Summary.TotalFiles++
 			info.Summary.Moved++
 
@@ -439,7 +703,7 @@ func (r *AuditReader) CheckFileIntegrity(filePath string) (*LogIntegrityResult,
 	}
 
 	// Open file for reading
-	file, err := os.Open(filePath)
+	file, err := openLogFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
@@ -462,8 +726,8 @@ func (r *AuditReader) CheckFileIntegrity(filePath string) (*LogIntegrityResult,
 
 // validateJSONLines reads through the file and validates each line is valid JSON.
 // Returns the number of valid lines, the line number of any corruption, and an error if corrupt.
-func (r *AuditReader) validateJSONLines(file *os.File) (validLines int, corruptLine int, err error) {
-	scanner := bufio.NewScanner(file)
+func (r *AuditReader) validateJSONLines(reader io.Reader) (validLines int, corruptLine int, err error) {
+	scanner := bufio.NewScanner(reader)
 
 	// Increase buffer size for potentially long lines
 	const maxScanTokenSize = 1024 * 1024 // 1MB
@@ -499,8 +763,11 @@ func (r *AuditReader) validateJSONLines(file *os.File) (validLines int, corruptL
 	}
 
 	// Check for truncated last line by checking if file ends with newline
-	if err := r.checkLastLineComplete(file); err != nil {
-		return validLines, lineNum, err
+	// (compressed logs are closed and cannot have a partial append)
+	if file, ok := reader.(*os.File); ok {
+		if err := r.checkLastLineComplete(file); err != nil {
+			return validLines, lineNum, err
+		}
 	}
 
 	return validLines, 0, nil
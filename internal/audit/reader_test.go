@@ -1,9 +1,13 @@
 package audit
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -538,6 +542,41 @@ func TestFilterEventsByStatus(t *testing.T) {
 	}
 }
 
+// TestFilterEventsErrorTypes verifies that filtering by ErrorEventTypes keeps
+// only failure events and omits successful and skipped ones.
+func TestFilterEventsErrorTypes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: tempDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	identity := &FileIdentity{ContentHash: "abc", Size: 100}
+	writer.RecordMove("/source/moved.pdf", "/dest/moved.pdf", identity)
+	writer.RecordSkip("/source/skipped.pdf", ReasonNoMatch)
+	writer.RecordRouteToReview("/source/review.pdf", "/source/for-review/review.pdf", ReasonUnclassified)
+	writer.RecordParseFailure("/source/bad.pdf", "<prefix> <date>", "no date")
+	writer.RecordError("/source/error.pdf", "IO_ERROR", "test error", "move")
+	writer.EndRun(runID, RunStatusCompleted, RunSummary{})
+	writer.Close()
+
+	events, err := NewAuditReader(tempDir).FilterEvents(runID, EventFilter{EventTypes: ErrorEventTypes})
+	if err != nil {
+		t.Fatalf("Failed to filter events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 failure events, got %d: %+v", len(events), events)
+	}
+	if events[0].EventType != EventParseFailure || events[1].EventType != EventError {
+		t.Errorf("Expected PARSE_FAILURE and ERROR events, got %s and %s", events[0].EventType, events[1].EventType)
+	}
+}
+
 // TestFilterEventsByTimeRange tests filtering events by time range.
 func TestFilterEventsByTimeRange(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "audit-filter-time-*")
@@ -1128,3 +1167,332 @@ func TestNoLogInitializedForExistingLog(t *testing.T) {
 		t.Errorf("Expected exactly 1 LOG_INITIALIZED event (not written for existing log), got %d", len(events))
 	}
 }
+
+// TestPaginateEvents verifies that the requested window of events is returned
+// along with a footer describing it.
+func TestPaginateEvents(t *testing.T) {
+	events := make([]AuditEvent, 250)
+	for i := range events {
+		events[i] = AuditEvent{SourcePath: "/src/file" + strconv.Itoa(i+1)}
+	}
+
+	page, err := PaginateEvents(events, 3, 100)
+	if err != nil {
+		t.Fatalf("PaginateEvents failed: %v", err)
+	}
+
+	if len(page.Events) != 50 {
+		t.Fatalf("Expected 50 events on last page, got %d", len(page.Events))
+	}
+	if page.Events[0].SourcePath != "/src/file201" || page.Events[49].SourcePath != "/src/file250" {
+		t.Errorf("Unexpected window: first %s, last %s", page.Events[0].SourcePath, page.Events[49].SourcePath)
+	}
+	if got, want := page.Footer(), "Showing 201–250 of 250 (page 3/3)"; got != want {
+		t.Errorf("Expected footer %q, got %q", want, got)
+	}
+
+	first, err := PaginateEvents(events, 1, 100)
+	if err != nil {
+		t.Fatalf("PaginateEvents failed: %v", err)
+	}
+	if got, want := first.Footer(), "Showing 1–100 of 250 (page 1/3)"; got != want {
+		t.Errorf("Expected footer %q, got %q", want, got)
+	}
+
+	if _, err := PaginateEvents(events, 4, 100); err == nil {
+		t.Error("Expected error for page past the end")
+	}
+}
+
+// TestListRunsFindsDatePartitionedRuns verifies that runs written with
+// PartitionByDate land in YYYY/MM/DD/<run-id>/ and are found by ListRuns and
+// GetRun alongside runs in an existing flat log.
+func TestListRunsFindsDatePartitionedRuns(t *testing.T) {
+	logDir := t.TempDir()
+
+	// A run in the flat layout, written before partitioning was enabled
+	flatWriter, err := NewAuditWriter(AuditConfig{LogDirectory: logDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	flatRunID, err := flatWriter.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	flatWriter.RecordSkip("/src/flat.txt", ReasonUnclassified)
+	flatWriter.EndRun(flatRunID, RunStatusCompleted, RunSummary{TotalFiles: 1, Skipped: 1})
+	flatWriter.Close()
+
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: logDir, PartitionByDate: true})
+	if err != nil {
+		t.Fatalf("Failed to create partitioned writer: %v", err)
+	}
+	var partitionedRuns []RunID
+	for i := 0; i < 2; i++ {
+		runID, err := writer.StartRun("1.0.0", "test-machine")
+		if err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+		if err := writer.RecordMove("/src/a.txt", "/dst/a.txt", nil); err != nil {
+			t.Fatalf("Failed to record move: %v", err)
+		}
+		if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{TotalFiles: 1, Moved: 1}); err != nil {
+			t.Fatalf("Failed to end run: %v", err)
+		}
+		partitionedRuns = append(partitionedRuns, runID)
+	}
+	writer.Close()
+
+	reader := NewAuditReader(logDir)
+	runs, err := reader.ListRuns()
+	if err != nil {
+		t.Fatalf("ListRuns failed: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 runs, got %d", len(runs))
+	}
+	found := make(map[RunID]bool)
+	for _, run := range runs {
+		found[run.RunID] = true
+	}
+	for _, runID := range append([]RunID{flatRunID}, partitionedRuns...) {
+		if !found[runID] {
+			t.Errorf("Expected ListRuns to include run %s", runID)
+		}
+	}
+
+	for _, runID := range partitionedRuns {
+		events, err := reader.GetRun(runID)
+		if err != nil {
+			t.Fatalf("GetRun(%s) failed: %v", runID, err)
+		}
+		if len(events) != 3 {
+			t.Errorf("Expected 3 events for run %s, got %d", runID, len(events))
+		}
+
+		path := PartitionedRunLogPath(logDir, runID, events[0].Timestamp)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected run log at %s: %v", path, err)
+		}
+		rel, _ := filepath.Rel(logDir, filepath.Dir(path))
+		parts := strings.Split(rel, string(filepath.Separator))
+		if len(parts) != 4 || parts[3] != string(runID) {
+			t.Errorf("Expected YYYY/MM/DD/<run-id> layout, got %s", rel)
+		}
+	}
+
+	// The partitioned runs' events must not be in the shared active log
+	activeEvents, err := reader.readEventsFromFile(filepath.Join(logDir, "sorta-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read active log: %v", err)
+	}
+	for _, event := range activeEvents {
+		if event.RunID == partitionedRuns[0] || event.RunID == partitionedRuns[1] {
+			t.Errorf("Partitioned run event found in active log: %+v", event)
+		}
+	}
+}
+
+// TestGroupEventsByType verifies that events are listed under their type in
+// the order the types first appear, with a count in each group's header.
+func TestGroupEventsByType(t *testing.T) {
+	types := []EventType{EventRunStart, EventMove, EventSkip, EventMove, EventError, EventSkip, EventMove, EventRunEnd}
+	events := make([]AuditEvent, len(types))
+	for i, eventType := range types {
+		events[i] = AuditEvent{EventType: eventType, SourcePath: "/src/file" + strconv.Itoa(i+1)}
+	}
+
+	groups := GroupEventsByType(events)
+
+	wantHeaders := []string{"RUN_START (1)", "MOVE (3)", "SKIP (2)", "ERROR (1)", "RUN_END (1)"}
+	if len(groups) != len(wantHeaders) {
+		t.Fatalf("Expected %d groups, got %d", len(wantHeaders), len(groups))
+	}
+	for i, group := range groups {
+		if group.Header() != wantHeaders[i] {
+			t.Errorf("Group %d: expected header %q, got %q", i, wantHeaders[i], group.Header())
+		}
+		for _, event := range group.Events {
+			if event.EventType != group.Type {
+				t.Errorf("Event %s of type %s listed under %s", event.SourcePath, event.EventType, group.Type)
+			}
+		}
+	}
+
+	moves := groups[1].Events
+	if moves[0].SourcePath != "/src/file2" || moves[1].SourcePath != "/src/file4" || moves[2].SourcePath != "/src/file7" {
+		t.Errorf("Expected MOVE events in their original order, got %v", moves)
+	}
+	if GroupEventsByType(nil) != nil {
+		t.Error("Expected no groups for no events")
+	}
+}
+
+// TestFilterRunsByStatus verifies that only runs with the requested status
+// are kept, and that undo runs are selected by type.
+func TestFilterRunsByStatus(t *testing.T) {
+	logDir := t.TempDir()
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: logDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	runIDs := make(map[RunStatus]RunID)
+	for _, status := range []RunStatus{RunStatusCompleted, RunStatusFailed, RunStatusInterrupted, RunStatusFailed} {
+		runID, err := writer.StartRun("1.0.0", "test-machine")
+		if err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+		if err := writer.EndRun(runID, status, RunSummary{}); err != nil {
+			t.Fatalf("Failed to end run: %v", err)
+		}
+		runIDs[status] = runID
+	}
+	undoID, err := writer.StartUndoRun("1.0.0", "test-machine", runIDs[RunStatusCompleted])
+	if err != nil {
+		t.Fatalf("Failed to start undo run: %v", err)
+	}
+	writer.EndRun(undoID, RunStatusFailed, RunSummary{})
+
+	runs, err := NewAuditReader(logDir).ListRuns()
+	if err != nil {
+		t.Fatalf("ListRuns failed: %v", err)
+	}
+
+	filter, err := ParseRunStatusFilter("FAILED")
+	if err != nil {
+		t.Fatalf("ParseRunStatusFilter failed: %v", err)
+	}
+	failed := FilterRuns(runs, filter)
+	if len(failed) != 2 {
+		t.Fatalf("Expected 2 failed runs, got %d", len(failed))
+	}
+	for _, run := range failed {
+		if run.Status != RunStatusFailed || run.RunType == RunTypeUndo {
+			t.Errorf("Expected only failed organize runs, got %s %s", run.RunType, run.Status)
+		}
+	}
+
+	undos := FilterRuns(runs, RunFilterUndo)
+	if len(undos) != 1 || undos[0].RunID != undoID {
+		t.Errorf("Expected only the undo run, got %+v", undos)
+	}
+	if _, err := ParseRunStatusFilter("in_progress"); err == nil {
+		t.Error("Expected an error for an unsupported status")
+	}
+}
+
+// TestFilterRunsBetween verifies that runs are kept from since up to, but
+// not including, until, and that a zero bound leaves the range open.
+func TestFilterRunsBetween(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	runs := []RunInfo{
+		{RunID: "a", StartTime: day(1)},
+		{RunID: "b", StartTime: day(2)},
+		{RunID: "c", StartTime: day(3)},
+	}
+
+	ids := func(runs []RunInfo) string {
+		var ids string
+		for _, run := range runs {
+			ids += string(run.RunID)
+		}
+		return ids
+	}
+	tests := []struct {
+		since, until time.Time
+		want         string
+	}{
+		{time.Time{}, time.Time{}, "abc"},
+		{day(2), time.Time{}, "bc"},
+		{time.Time{}, day(2), "a"},
+		{day(1), day(3), "ab"},
+		{day(4), time.Time{}, ""},
+	}
+	for _, tt := range tests {
+		if got := ids(FilterRunsBetween(runs, tt.since, tt.until)); got != tt.want {
+			t.Errorf("FilterRunsBetween(%v, %v) = %q, want %q", tt.since, tt.until, got, tt.want)
+		}
+	}
+}
+
+// TestContextWindows verifies that each matched event is listed with its
+// neighbours and that overlapping windows are merged.
+func TestContextWindows(t *testing.T) {
+	types := []EventType{EventRunStart, EventMove, EventMove, EventError, EventMove, EventSkip, EventError, EventMove, EventMove, EventMove, EventMove, EventError, EventRunEnd}
+	events := make([]AuditEvent, len(types))
+	for i, eventType := range types {
+		events[i] = AuditEvent{EventType: eventType, SourcePath: "/src/file" + strconv.Itoa(i+1)}
+	}
+	isError := func(event AuditEvent) bool { return event.EventType == EventError }
+
+	windows := ContextWindows(events, isError, 1)
+
+	// Errors at 4 and 7 overlap (3-5 and 6-8 touch), the error at 12 stands alone
+	want := [][]string{
+		{"/src/file3", "/src/file4", "/src/file5", "/src/file6", "/src/file7", "/src/file8"},
+		{"/src/file11", "/src/file12", "/src/file13"},
+	}
+	if len(windows) != len(want) {
+		t.Fatalf("Expected %d windows, got %d: %v", len(want), len(windows), windows)
+	}
+	for i, window := range windows {
+		var got []string
+		for _, event := range window {
+			got = append(got, event.SourcePath)
+		}
+		if strings.Join(got, ",") != strings.Join(want[i], ",") {
+			t.Errorf("Window %d: expected %v, got %v", i, want[i], got)
+		}
+	}
+
+	if windows := ContextWindows(events, func(AuditEvent) bool { return false }, 2); len(windows) != 0 {
+		t.Errorf("Expected no windows without a match, got %d", len(windows))
+	}
+}
+
+// TestMatchesPattern verifies that a pattern is matched against an event's
+// paths, reason code and error message, and that grouped events are listed
+// group by group.
+func TestMatchesPattern(t *testing.T) {
+	events := []AuditEvent{
+		{EventType: EventMove, SourcePath: "/inbox/Invoice 2024-01-15 Acme.pdf", DestinationPath: "/docs/2024 Invoice/Invoice 2024-01-15 Acme.pdf"},
+		{EventType: EventSkip, SourcePath: "/inbox/notes.txt", ReasonCode: ReasonFileLocked},
+		{EventType: EventError, SourcePath: "/inbox/Receipt 2024-02-01 Shop.pdf", ErrorDetails: &ErrorDetails{ErrorMessage: "permission denied"}},
+		{EventType: EventMove, SourcePath: "/inbox/Receipt 2024-03-01 Cafe.pdf", DestinationPath: "/docs/2024 Receipt/Receipt 2024-03-01 Cafe.pdf"},
+	}
+
+	tests := []struct {
+		pattern string
+		want    []int
+	}{
+		{"Acme", []int{0}},
+		{"2024 Receipt/", []int{3}},
+		{"LOCKED", []int{1}},
+		{"permission", []int{2}},
+		{`(?i)receipt.*\.pdf$`, []int{2, 3}},
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		pattern := regexp.MustCompile(tt.pattern)
+		var got []int
+		for i, event := range events {
+			if event.MatchesPattern(pattern) {
+				got = append(got, i)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Pattern %q: expected events %v, got %v", tt.pattern, tt.want, got)
+		}
+	}
+
+	grouped := GroupedEvents(GroupEventsByType(events))
+	var order []EventType
+	for _, event := range grouped {
+		order = append(order, event.EventType)
+	}
+	if fmt.Sprint(order) != fmt.Sprint([]EventType{EventMove, EventMove, EventSkip, EventError}) {
+		t.Errorf("Expected events grouped by type, got %v", order)
+	}
+}
@@ -140,7 +140,7 @@ func (rm *RetentionManager) CheckRetention() ([]SegmentRunInfo, error) {
 						// Check if not already in toPrune list
 						found := false
 						for _, p := range toPrune {
-							if p.Filename == seg.Filename {
+							if p.FilePath == seg.FilePath {
 								found = true
 								break
 							}
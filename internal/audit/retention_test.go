@@ -1,6 +1,7 @@
 package audit
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -445,3 +446,53 @@ func TestUnlimitedRetention(t *testing.T) {
 		t.Errorf("Expected no segments to prune with unlimited retention, got %d", len(toPrune))
 	}
 }
+
+// TestRunCountRetentionWithPartitionedLogs verifies that run-count retention
+// prunes every partitioned run log beyond the limit, although all of them are
+// named run.jsonl.
+func TestRunCountRetentionWithPartitionedLogs(t *testing.T) {
+	tempDir := t.TempDir()
+	config := AuditConfig{
+		LogDirectory:    tempDir,
+		RetentionRuns:   1,
+		PartitionByDate: true,
+	}
+
+	start := time.Now().UTC().AddDate(0, 0, -30)
+	for i := 0; i < 3; i++ {
+		runID, err := GenerateRunID()
+		if err != nil {
+			t.Fatalf("Failed to generate run ID: %v", err)
+		}
+		runStart := start.Add(time.Duration(i) * time.Hour)
+		path := PartitionedRunLogPath(tempDir, runID, runStart)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create run directory: %v", err)
+		}
+		var lines []byte
+		for _, event := range []AuditEvent{
+			{Timestamp: runStart, RunID: runID, EventType: EventRunStart, Status: StatusSuccess},
+			{Timestamp: runStart, RunID: runID, EventType: EventRunEnd, Status: StatusSuccess},
+		} {
+			line, err := json.Marshal(event)
+			if err != nil {
+				t.Fatalf("Failed to marshal event: %v", err)
+			}
+			lines = append(append(lines, line...), '\n')
+		}
+		if err := os.WriteFile(path, lines, 0644); err != nil {
+			t.Fatalf("Failed to write run log: %v", err)
+		}
+	}
+
+	toPrune, err := NewRetentionManager(config).CheckRetention()
+	if err != nil {
+		t.Fatalf("Failed to check retention: %v", err)
+	}
+	if len(toPrune) != 2 {
+		t.Fatalf("Expected the 2 oldest run logs to be pruned, got %d", len(toPrune))
+	}
+	if toPrune[0].FilePath == toPrune[1].FilePath {
+		t.Errorf("Expected two different run logs, got %s twice", toPrune[0].FilePath)
+	}
+}
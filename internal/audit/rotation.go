@@ -182,6 +182,35 @@ func (rm *RotationManager) updateIndex(logDir, rotatedFilename string, size int6
 	return nil
 }
 
+// renameIndexedSegment updates a segment's filename in the rotation index, e.g.
+// after it has been compressed. A missing index is left alone.
+func renameIndexedSegment(logDir, oldFilename, newFilename string) error {
+	indexPath := filepath.Join(logDir, "sorta-audit-index.json")
+	index, err := LoadIndex(logDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load index: %w", err)
+	}
+
+	for i := range index.Segments {
+		if index.Segments[i].Filename == oldFilename {
+			index.Segments[i].Filename = newFilename
+		}
+	}
+	index.LastUpdated = time.Now()
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
 // loadIndex loads the rotation index from disk.
 func (rm *RotationManager) loadIndex(indexPath string) (*RotationIndex, error) {
 	data, err := os.ReadFile(indexPath)
@@ -229,8 +258,8 @@ func DiscoverSegments(logDir string) ([]string, error) {
 			continue
 		}
 		name := entry.Name()
-		// Match rotated segments: sorta-audit-YYYYMMDD-HHMMSS.jsonl
-		if strings.HasPrefix(name, "sorta-audit-") && strings.HasSuffix(name, ".jsonl") && name != "sorta-audit.jsonl" {
+		// Match rotated segments: sorta-audit-YYYYMMDD-HHMMSS.jsonl, optionally gzipped
+		if strings.HasPrefix(name, "sorta-audit-") && strings.HasSuffix(strings.TrimSuffix(name, compressedLogSuffix), ".jsonl") {
 			segments = append(segments, name)
 		}
 	}
@@ -241,8 +270,31 @@ func DiscoverSegments(logDir string) ([]string, error) {
 	return segments, nil
 }
 
+// partitionedRunLogName is the file name of a run's log in the date-partitioned layout.
+const partitionedRunLogName = "run.jsonl"
+
+// DiscoverPartitionedLogs finds the per-run log files written with PartitionByDate
+// (<logDir>/YYYY/MM/DD/<run-id>/run.jsonl, optionally gzipped), sorted by date.
+func DiscoverPartitionedLogs(logDir string) ([]string, error) {
+	pattern := filepath.Join(logDir, "[0-9][0-9][0-9][0-9]", "[0-9][0-9]", "[0-9][0-9]", "*", partitionedRunLogName+"*")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover partitioned logs: %w", err)
+	}
+
+	var files []string
+	for _, file := range matches {
+		name := filepath.Base(file)
+		if name == partitionedRunLogName || name == partitionedRunLogName+compressedLogSuffix {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // GetAllLogFiles returns all log files in chronological order (oldest first).
-// This includes both rotated segments and the active log.
+// This includes rotated segments, date-partitioned run logs and the active log.
 // Requirements: 9.4, 9.5
 func GetAllLogFiles(logDir string) ([]string, error) {
 	segments, err := DiscoverSegments(logDir)
@@ -256,6 +308,12 @@ func GetAllLogFiles(logDir string) ([]string, error) {
 		files = append(files, filepath.Join(logDir, seg))
 	}
 
+	partitioned, err := DiscoverPartitionedLogs(logDir)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, partitioned...)
+
 	// Add active log if it exists
 	activeLog := filepath.Join(logDir, "sorta-audit.jsonl")
 	if _, err := os.Stat(activeLog); err == nil {
@@ -0,0 +1,64 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runSequenceName is the file name of the run sequence counter in the audit
+// log directory.
+const runSequenceName = "sorta-run-sequence"
+
+// nextRunSequence reserves the next run sequence number in logDir and returns
+// it. Sequence numbers only ever increase, whatever the clock says, so they
+// order runs even when timestamps do not. If the counter is missing, counting
+// continues after the highest sequence recorded in the logs. The counter is
+// read and written back under the log directory lock, so concurrent runs get
+// distinct numbers.
+func nextRunSequence(logDir string) (uint64, error) {
+	path := filepath.Join(logDir, runSequenceName)
+
+	unlock, err := LockLogDirectory(logDir)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var last uint64
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		last, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid run sequence %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		runs, err := NewAuditReader(logDir).ListRuns()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read run sequence: %w", err)
+		}
+		for _, run := range runs {
+			if run.Sequence > last {
+				last = run.Sequence
+			}
+		}
+	default:
+		return 0, fmt.Errorf("failed to read run sequence: %w", err)
+	}
+
+	next := last + 1
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.FormatUint(next, 10)+"\n"), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write run sequence: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to write run sequence: %w", err)
+	}
+	return next, nil
+}
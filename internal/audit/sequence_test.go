@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestNextRunSequenceConcurrent verifies that runs starting at the same time
+// are given distinct sequence numbers.
+func TestNextRunSequenceConcurrent(t *testing.T) {
+	logDir := t.TempDir()
+
+	const runs = 20
+	sequences := make([]uint64, runs)
+	errs := make([]error, runs)
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequences[i], errs[i] = nextRunSequence(logDir)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[uint64]bool)
+	for i, sequence := range sequences {
+		if errs[i] != nil {
+			t.Fatalf("nextRunSequence failed: %v", errs[i])
+		}
+		if seen[sequence] {
+			t.Errorf("Sequence %d was handed out twice", sequence)
+		}
+		seen[sequence] = true
+	}
+	for sequence := uint64(1); sequence <= runs; sequence++ {
+		if !seen[sequence] {
+			t.Errorf("Expected sequence %d to be handed out", sequence)
+		}
+	}
+}
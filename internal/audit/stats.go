@@ -3,10 +3,16 @@ package audit
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 )
 
+// yearPrefixFolder matches the "<year> <prefix>" folders files are organized into.
+var yearPrefixFolder = regexp.MustCompile(`^\d{4} (.+)$`)
+
 // AuditStats contains aggregate metrics across all audit runs.
 // Requirements: 4.1, 4.2, 4.3, 4.4, 4.5, 4.6
 type AuditStats struct {
@@ -15,10 +21,17 @@ type AuditStats struct {
 	TotalRuns      int            // Number of organize runs
 	TotalUndos     int            // Number of undo operations
 	ByPrefix       map[string]int // Files per prefix (top N)
+	TopPrefixes    []PrefixCount  // ByPrefix sorted by count, most active first
 	FirstRun       time.Time      // Earliest run timestamp
 	LastRun        time.Time      // Most recent run timestamp
 }
 
+// PrefixCount is the number of files moved for one prefix.
+type PrefixCount struct {
+	Prefix string
+	Count  int
+}
+
 // StatsOptions configures stats aggregation.
 // Requirements: 4.7
 type StatsOptions struct {
@@ -80,7 +93,7 @@ func AggregateStats(logDir string, opts StatsOptions) (*AuditStats, error) {
 
 		// Extract prefix counts from MOVE events
 		for _, event := range events {
-			if event.EventType == EventMove && event.Status == StatusSuccess {
+			if (event.EventType == EventMove || event.EventType == EventCopy) && event.Status == StatusSuccess {
 				prefix := extractPrefix(event.DestinationPath)
 				if prefix != "" {
 					allPrefixCounts[prefix]++
@@ -91,12 +104,31 @@ func AggregateStats(logDir string, opts StatsOptions) (*AuditStats, error) {
 
 	// Apply top N filtering to prefix counts
 	stats.ByPrefix = filterTopN(allPrefixCounts, opts.TopN)
+	stats.TopPrefixes = sortedPrefixCounts(stats.ByPrefix)
 
 	return stats, nil
 }
 
-// extractPrefix extracts the prefix (first directory component after the base)
-// from a destination path. For example:
+// sortedPrefixCounts returns counts sorted by count descending, then by prefix.
+func sortedPrefixCounts(counts map[string]int) []PrefixCount {
+	sorted := make([]PrefixCount, 0, len(counts))
+	for prefix, count := range counts {
+		sorted = append(sorted, PrefixCount{Prefix: prefix, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Prefix < sorted[j].Prefix
+	})
+	return sorted
+}
+
+// extractPrefix extracts the prefix from a destination path. Files organized
+// by Sorta live in a "<year> <prefix>" folder (possibly with a staging folder
+// below it), whose prefix is returned. For other paths the first directory
+// component after the base is used. For example:
+// "/archive/Invoices/2024 Invoice/Invoice 2024-01-15.pdf" -> "Invoice"
 // "/organized/invoices/2024/file.pdf" -> "invoices"
 // "/organized/receipts/file.pdf" -> "receipts"
 func extractPrefix(destPath string) string {
@@ -104,6 +136,13 @@ func extractPrefix(destPath string) string {
 		return ""
 	}
 
+	dirs := strings.FieldsFunc(filepath.ToSlash(filepath.Dir(destPath)), func(r rune) bool { return r == '/' || r == '\\' })
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if match := yearPrefixFolder.FindStringSubmatch(dirs[i]); match != nil {
+			return match[1]
+		}
+	}
+
 	// Split path into components
 	// We want to find the first meaningful directory after any base path
 	// Typically the structure is: /base/prefix/... or prefix/...
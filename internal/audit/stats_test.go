@@ -420,6 +420,64 @@ func TestAggregateStats_MultipleRuns(t *testing.T) {
 	}
 }
 
+// TestAggregateStats_YearPrefixFolders tests that prefixes are taken from
+// "<year> <prefix>" destination folders and listed most active first.
+func TestAggregateStats_YearPrefixFolders(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	identity := &FileIdentity{ContentHash: "abc123", Size: 1000}
+	moves := []string{
+		"/archive/Receipts/2024 Receipt/Receipt 2024-02-01 Cafe.pdf",
+		"/archive/Invoices/2023 Invoice/Invoice 2023-12-30 Acme.pdf",
+		"/archive/Invoices/2024 Invoice/Invoice 2024-01-15 Acme.pdf",
+		"/archive/Invoices/2024 Invoice/staged/Invoice 2024-03-01 Acme.pdf",
+		"/archive/Statements/2024 Bank Statement/Bank Statement 2024-01-31.pdf",
+		"/archive/Receipts/2024 Receipt/Receipt 2024-02-02 Cafe.pdf",
+	}
+	for _, dest := range moves {
+		if err := writer.RecordMove("/inbox/file.pdf", dest, identity); err != nil {
+			t.Fatalf("Failed to record move: %v", err)
+		}
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{TotalFiles: 6, Moved: 6}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+
+	stats, err := AggregateStats(tmpDir, StatsOptions{})
+	if err != nil {
+		t.Fatalf("AggregateStats failed: %v", err)
+	}
+
+	expected := []PrefixCount{{"Invoice", 3}, {"Receipt", 2}, {"Bank Statement", 1}}
+	if len(stats.TopPrefixes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, stats.TopPrefixes)
+	}
+	for i, want := range expected {
+		if stats.TopPrefixes[i] != want {
+			t.Errorf("TopPrefixes[%d] = %v, want %v", i, stats.TopPrefixes[i], want)
+		}
+	}
+
+	stats, err = AggregateStats(tmpDir, StatsOptions{TopN: 1})
+	if err != nil {
+		t.Fatalf("AggregateStats failed: %v", err)
+	}
+	if len(stats.TopPrefixes) != 1 || stats.TopPrefixes[0] != expected[0] {
+		t.Errorf("Expected only %v with TopN 1, got %v", expected[0], stats.TopPrefixes)
+	}
+}
+
 // TestExtractPrefix tests the prefix extraction helper function.
 func TestExtractPrefix(t *testing.T) {
 	tests := []struct {
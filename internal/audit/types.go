@@ -16,6 +16,7 @@ const (
 	// Run lifecycle events
 	EventRunStart EventType = "RUN_START"
 	EventRunEnd   EventType = "RUN_END"
+	EventNote     EventType = "NOTE" // Free-form note added to a run after the fact
 
 	// File operation events
 	EventMove              EventType = "MOVE"
@@ -25,6 +26,8 @@ const (
 	EventParseFailure      EventType = "PARSE_FAILURE"
 	EventValidationFailure EventType = "VALIDATION_FAILURE"
 	EventError             EventType = "ERROR"
+	EventCopy              EventType = "COPY"    // File copied from a read-only source, which is left in place
+	EventExtract           EventType = "EXTRACT" // File extracted from an archive next to it
 
 	// Undo events
 	EventUndoMove          EventType = "UNDO_MOVE"
@@ -35,6 +38,7 @@ const (
 	EventSourceMissing     EventType = "SOURCE_MISSING"
 	EventContentChanged    EventType = "CONTENT_CHANGED"
 	EventConflictDetected  EventType = "CONFLICT_DETECTED"
+	EventTrash             EventType = "TRASH"
 
 	// System events
 	EventRotation       EventType = "ROTATION"
@@ -42,6 +46,18 @@ const (
 	EventLogInitialized EventType = "LOG_INITIALIZED"
 )
 
+// ErrorEventTypes are the event types that record something going wrong in a
+// run or an undo, as shown by "audit show --errors-only".
+var ErrorEventTypes = []EventType{
+	EventError,
+	EventParseFailure,
+	EventValidationFailure,
+	EventCollision,
+	EventContentChanged,
+	EventSourceMissing,
+	EventConflictDetected,
+}
+
 // OperationStatus represents the outcome of an operation.
 type OperationStatus string
 
@@ -56,18 +72,39 @@ type ReasonCode string
 
 const (
 	// Skip reasons
-	ReasonNoMatch          ReasonCode = "NO_MATCH"
-	ReasonInvalidDate      ReasonCode = "INVALID_DATE"
-	ReasonAlreadyProcessed ReasonCode = "ALREADY_PROCESSED"
+	ReasonNoMatch           ReasonCode = "NO_MATCH"
+	ReasonInvalidDate       ReasonCode = "INVALID_DATE"
+	ReasonAlreadyProcessed  ReasonCode = "ALREADY_PROCESSED"
+	ReasonPrefixNotSelected ReasonCode = "PREFIX_NOT_SELECTED"
+	ReasonFileLocked        ReasonCode = "FILE_LOCKED"
+	ReasonStillWriting      ReasonCode = "STILL_WRITING"
+	ReasonBeforeLastRun     ReasonCode = "BEFORE_LAST_RUN"
+	ReasonAlreadyCopied     ReasonCode = "ALREADY_COPIED"
+	ReasonAlreadyOrganized  ReasonCode = "ALREADY_ORGANIZED"
+	ReasonReadOnlySource    ReasonCode = "READ_ONLY_SOURCE"
+	ReasonDestDirMissing    ReasonCode = "DEST_DIR_MISSING"
+	ReasonIntraRunDuplicate ReasonCode = "INTRA_RUN_DUPLICATE"
+	ReasonInsufficientSpace ReasonCode = "INSUFFICIENT_SPACE"
+	ReasonZeroByte          ReasonCode = "ZERO_BYTE" // Also used for review routing
+	ReasonTooSmall          ReasonCode = "TOO_SMALL"
 
 	// Review routing reasons
-	ReasonUnclassified    ReasonCode = "UNCLASSIFIED"
-	ReasonParseError      ReasonCode = "PARSE_ERROR"
-	ReasonValidationError ReasonCode = "VALIDATION_ERROR"
+	ReasonUnclassified        ReasonCode = "UNCLASSIFIED"
+	ReasonParseError          ReasonCode = "PARSE_ERROR"
+	ReasonValidationError     ReasonCode = "VALIDATION_ERROR"
+	ReasonPathTooLong         ReasonCode = "PATH_TOO_LONG"
+	ReasonNoExtension         ReasonCode = "NO_EXTENSION"
+	ReasonContentMismatch     ReasonCode = "EXTENSION_CONTENT_MISMATCH"
+	ReasonExtensionNotAllowed ReasonCode = "EXTENSION_NOT_ALLOWED_FOR_RULE"
 
 	// Duplicate reasons
 	ReasonDuplicateRenamed ReasonCode = "DUPLICATE_RENAMED"
 
+	// Move reasons
+	ReasonMatchedNoDate ReasonCode = "MATCHED_NO_DATE"
+	ReasonTypeFallback  ReasonCode = "TYPE_FALLBACK"
+	ReasonCatchAll      ReasonCode = "CATCH_ALL"
+
 	// Undo skip reasons
 	ReasonNoOpEvent            ReasonCode = "NO_OP_EVENT"
 	ReasonIdentityMismatch     ReasonCode = "IDENTITY_MISMATCH"
@@ -96,9 +133,16 @@ const (
 
 // FileIdentity captures the attributes used to uniquely identify a file across machines.
 type FileIdentity struct {
-	ContentHash string    `json:"contentHash"` // SHA-256 hex string
-	Size        int64     `json:"size"`        // File size in bytes
-	ModTime     time.Time `json:"modTime"`     // File modification timestamp
+	ContentHash string    `json:"contentHash"`      // SHA-256 hex string
+	Size        int64     `json:"size"`             // File size in bytes
+	ModTime     time.Time `json:"modTime"`          // File modification timestamp
+	Device      uint64    `json:"device,omitempty"` // Device number (0 where unsupported)
+	Inode       uint64    `json:"inode,omitempty"`  // Inode number (0 where unsupported)
+}
+
+// HasInode returns true if the device and inode numbers were recorded.
+func (f FileIdentity) HasInode() bool {
+	return f.Inode != 0
 }
 
 // ErrorDetails contains detailed information about an error.
@@ -143,6 +187,20 @@ type RunInfo struct {
 	MachineID    string     `json:"machineId"`
 	Summary      RunSummary `json:"summary"`
 	UndoTargetID *RunID     `json:"undoTargetId,omitempty"` // For UNDO runs
+	Stage        string     `json:"stage,omitempty"`        // Staging folder used by the run, if any
+
+	// InboundDirectories are the inbound directories an organize run scanned,
+	// for runs that recorded them.
+	InboundDirectories []string `json:"inboundDirectories,omitempty"`
+
+	// PathRoot is the directory the paths of the run's events are relative
+	// to, for runs that recorded relative paths.
+	PathRoot string `json:"pathRoot,omitempty"`
+
+	// Sequence is the position of the run in the order runs were started in
+	// this audit log directory, independent of the clock. Runs written by
+	// older versions have none (0).
+	Sequence uint64 `json:"sequence,omitempty"`
 }
 
 // PathMapping defines a path translation for cross-machine undo.
@@ -159,6 +217,29 @@ type AuditConfig struct {
 	RetentionDays    int    `json:"retentionDays"`     // 0 = unlimited
 	RetentionRuns    int    `json:"retentionRuns"`     // 0 = unlimited
 	MinRetentionDays int    `json:"minRetentionDays"`  // Default: 7
+
+	// PartitionByDate stores each run's events in its own file under
+	// <LogDirectory>/YYYY/MM/DD/<run-id>/ instead of the shared active log.
+	PartitionByDate bool `json:"partitionByDate,omitempty"`
+
+	// CompressClosedRuns gzips log files once they can no longer be appended to:
+	// a run's own log (with PartitionByDate) and rotated segments, when a run ends.
+	CompressClosedRuns bool `json:"compressClosedRuns,omitempty"`
+
+	// DisplayLocalTime shows timestamps in "audit list" and "audit show" in the
+	// local time zone instead of UTC. Events are always stored in UTC.
+	DisplayLocalTime bool `json:"displayLocalTime,omitempty"`
+
+	// RecordMatchedRule adds the prefix and outbound directory of the rule a
+	// file matched to its MOVE event, as "matchedPrefix" and
+	// "matchedRuleOutbound" metadata.
+	RecordMatchedRule bool `json:"recordMatchedRule,omitempty"`
+
+	// PathRoot stores the paths of an organize run's events relative to this
+	// directory, which is recorded in the run's RUN_START event. Paths outside
+	// it stay absolute. Moving the whole tree to another machine then only
+	// needs a new root for undo instead of path mappings.
+	PathRoot string `json:"pathRoot,omitempty"`
 }
 
 // DefaultAuditConfig returns an AuditConfig with sensible defaults.
@@ -6,26 +6,83 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// CollisionResolution controls what undo does when a file's original location
+// is already occupied.
+type CollisionResolution string
+
+const (
+	// CollisionFail leaves both files in place and reports the collision (default).
+	CollisionFail CollisionResolution = "fail"
+	// CollisionTrash moves the occupying file to the trash directory, then restores.
+	CollisionTrash CollisionResolution = "trash"
+	// CollisionKeepBoth restores the file next to the occupant under a new name.
+	CollisionKeepBoth CollisionResolution = "keep-both"
+)
+
+// DefaultTrashDirectory is where occupying files are moved when using CollisionTrash.
+// Each undo run gets its own subdirectory named after the undo run ID.
+const DefaultTrashDirectory = ".sorta/trash"
+
+// ParseCollisionResolution parses a collision resolution name.
+func ParseCollisionResolution(s string) (CollisionResolution, error) {
+	switch CollisionResolution(s) {
+	case CollisionFail, CollisionTrash, CollisionKeepBoth:
+		return CollisionResolution(s), nil
+	default:
+		return "", fmt.Errorf("invalid collision resolution %q (must be fail, trash, or keep-both)", s)
+	}
+}
+
+// InodeCheck controls how undo treats a file whose content matches but whose
+// device/inode differs from the one recorded, i.e. a same-content replacement.
+type InodeCheck string
+
+const (
+	// InodeCheckWarn records an IDENTITY_MISMATCH event but still restores the file.
+	InodeCheckWarn InodeCheck = "warn"
+	// InodeCheckStrict refuses to restore the file.
+	InodeCheckStrict InodeCheck = "strict"
+	// InodeCheckOff ignores inode differences.
+	InodeCheckOff InodeCheck = "off"
+)
+
+// ParseInodeCheck parses an inode check mode.
+func ParseInodeCheck(s string) (InodeCheck, error) {
+	switch InodeCheck(s) {
+	case InodeCheckWarn, InodeCheckStrict, InodeCheckOff:
+		return InodeCheck(s), nil
+	default:
+		return "", fmt.Errorf("invalid inode check %q (must be warn, strict, or off)", s)
+	}
+}
+
 // UndoResult contains the result of an undo operation.
 type UndoResult struct {
-	UndoRunID      RunID       // The run ID of the undo operation itself
-	TargetRunID    RunID       // The run ID that was undone
-	TotalEvents    int         // Total events processed
-	Restored       int         // Files successfully restored
-	Skipped        int         // Files skipped (no-op events)
-	Failed         int         // Files that failed to restore
-	FailureDetails []UndoError // Details of failures
+	UndoRunID   RunID `json:"undoRunId"`   // The run ID of the undo operation itself
+	TargetRunID RunID `json:"targetRunId"` // The run ID that was undone
+	TotalEvents int   `json:"totalEvents"` // Total events processed
+	Restored    int   `json:"restored"`    // Files successfully restored
+	Skipped     int   `json:"skipped"`     // Files skipped (no-op events)
+	Failed      int   `json:"failed"`      // Files that failed to restore
+
+	// AlreadyRestored counts files left alone by ContinueUndo because the
+	// interrupted undo had already restored them.
+	AlreadyRestored int `json:"alreadyRestored"`
+
+	FailureDetails []UndoError `json:"failureDetails"` // Details of failures
 }
 
 // UndoError contains details about a failed undo operation.
 type UndoError struct {
-	SourcePath string     // Original source path
-	DestPath   string     // Destination path (where file currently is)
-	Reason     ReasonCode // Reason for failure
-	Message    string     // Detailed error message
+	SourcePath string     `json:"sourcePath"` // Original source path
+	DestPath   string     `json:"destPath"`   // Destination path (where file currently is)
+	Reason     ReasonCode `json:"reason"`     // Reason for failure
+	Message    string     `json:"message"`    // Detailed error message
 }
 
 // UndoPreview shows what would be undone without executing.
@@ -51,6 +108,43 @@ type CrossMachineUndoConfig struct {
 	PathMappings       []PathMapping // Path translations between machines
 	SearchDirectories  []string      // Directories to search when file not at expected path
 	OriginatingMachine string        // Machine ID where the original run was executed
+
+	OnCollision    CollisionResolution // How to handle an occupied original location (default: fail)
+	TrashDirectory string              // Base trash directory for CollisionTrash (default: DefaultTrashDirectory)
+
+	// InodeCheck controls same-content replacement detection (default: warn).
+	// It only applies when undoing on the originating machine without path mappings.
+	InodeCheck InodeCheck
+
+	// Force restores files even when a later run touched them. The conflict is
+	// still recorded (with "forced" metadata) and collision handling still applies.
+	Force bool
+
+	// MissingReport is a JSON report file that every file undo could not find
+	// (SOURCE_MISSING) is appended to, with its expected path and recorded hash.
+	MissingReport string
+
+	// InboundDirectories are the inbound directories configured now. Unless
+	// Force is set, an undo that would restore files into one of them that the
+	// run did not scan stops with an InboundRestoreError before restoring any.
+	InboundDirectories []string
+
+	// PathRoot replaces the root recorded by runs that stored relative paths,
+	// for a tree that was moved as a whole (default: the recorded root).
+	PathRoot string
+}
+
+// InboundRestoreError is returned when undoing a run would restore files into
+// an inbound directory the run did not scan, where the next run (or a watcher)
+// may organize them again right away. Nothing is restored; undo with Force to
+// restore the files anyway.
+type InboundRestoreError struct {
+	RunID RunID
+	Paths []string // Restore targets inside another inbound directory
+}
+
+func (e *InboundRestoreError) Error() string {
+	return fmt.Sprintf("undoing run %s would restore %d file(s) into another inbound directory, where they may be organized again", e.RunID, len(e.Paths))
 }
 
 // UndoCallback is called during undo operations to report progress.
@@ -67,6 +161,13 @@ type UndoProgressEvent struct {
 	Reason       string // Reason for skip or error
 	VerifyStatus string // Verification status: "match", "mismatch", "not_found"
 	Success      bool   // Whether the operation succeeded
+
+	// Bytes restored so far, including this file when it was just restored,
+	// and bytes of every file the undo restores. Sizes come from the recorded
+	// file identities; files without one count as 0 bytes, and both are 0
+	// when no sizes are known.
+	BytesDone  int64
+	BytesTotal int64
 }
 
 // UndoEngine orchestrates undo operations.
@@ -80,6 +181,11 @@ type UndoEngine struct {
 	appVersion       string
 	machineID        string
 	callback         UndoCallback
+
+	// Byte progress of the undo in progress, reported with every callback
+	bytesDone    int64
+	bytesTotal   int64
+	currentBytes int64 // Size of the file being undone, added to bytesDone once it is restored
 }
 
 // NewUndoEngine creates a new UndoEngine with the given reader and writer.
@@ -99,9 +205,21 @@ func (e *UndoEngine) SetCallback(callback UndoCallback) {
 	e.callback = callback
 }
 
+// SetHashCache makes the undo engine take the content hash of files that have
+// not changed since they were last hashed from cache when verifying them.
+func (e *UndoEngine) SetHashCache(cache *HashCache) {
+	e.identityResolver = NewIdentityResolverWithCache(cache)
+}
+
 // notifyCallback calls the callback if set.
 func (e *UndoEngine) notifyCallback(event UndoProgressEvent) {
+	if event.Type == "restore" && event.Success {
+		e.bytesDone += e.currentBytes
+		e.currentBytes = 0
+	}
 	if e.callback != nil {
+		event.BytesDone = e.bytesDone
+		event.BytesTotal = e.bytesTotal
 		e.callback(event)
 	}
 }
@@ -153,6 +271,119 @@ func (e *UndoEngine) UndoRun(runID RunID, pathMappings []PathMapping) (*UndoResu
 // It supports path mappings, hash-based file discovery, and records originating machine ID.
 // Requirements: 5.2, 5.7, 5.8, 6.1, 6.2, 6.5, 6.6, 7.2, 7.3, 7.5, 7.6, 14.1, 14.2
 func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoConfig) (*UndoResult, error) {
+	return e.undoRun(runID, config, nil)
+}
+
+// ContinueUndo resumes the most recent undo when it did not complete, for
+// example because the process was killed part-way. Events whose files an
+// earlier undo of the same run already restored (tracked by its UNDO_MOVE
+// events) are counted in AlreadyRestored instead of being undone again; the
+// remaining events are undone in a new UNDO run. It returns an error if the
+// most recent undo completed.
+func (e *UndoEngine) ContinueUndo(config CrossMachineUndoConfig) (*UndoResult, error) {
+	runs, err := e.reader.ListRuns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	var interrupted *RunInfo
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].RunType != RunTypeUndo {
+			continue
+		}
+		if runs[i].EndTime != nil && runs[i].Status != RunStatusInterrupted {
+			return nil, fmt.Errorf("the most recent undo (%s) completed; there is nothing to continue", runs[i].RunID)
+		}
+		interrupted = &runs[i]
+		break
+	}
+	if interrupted == nil || interrupted.UndoTargetID == nil {
+		return nil, fmt.Errorf("no interrupted undo found")
+	}
+	targetRunID := *interrupted.UndoTargetID
+
+	// Collect the files restored by every undo of the target run so far
+	restored := &restoredFiles{from: make(map[string]bool), to: make(map[string]bool)}
+	for _, run := range runs {
+		if run.RunType != RunTypeUndo || run.UndoTargetID == nil || *run.UndoTargetID != targetRunID {
+			continue
+		}
+		events, err := e.reader.GetRun(run.RunID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events for undo run %s: %w", run.RunID, err)
+		}
+		for _, event := range events {
+			if event.EventType == EventUndoMove {
+				restored.from[event.SourcePath] = true
+				restored.to[event.DestinationPath] = true
+			}
+		}
+	}
+
+	return e.undoRun(targetRunID, config, restored)
+}
+
+// runEvents returns the events of run, without those of operations that
+// failed (see withoutFailedOperations). If the run recorded paths relative to
+// a root, they are joined with pathRoot, or with the recorded root if
+// pathRoot is empty.
+func (e *UndoEngine) runEvents(run RunInfo, pathRoot string) ([]AuditEvent, error) {
+	events, err := e.reader.GetRun(run.RunID)
+	if err != nil {
+		return nil, err
+	}
+	events = withoutFailedOperations(events)
+	if run.PathRoot == "" {
+		return events, nil
+	}
+	if pathRoot == "" {
+		pathRoot = run.PathRoot
+	}
+	return ResolvePaths(events, pathRoot), nil
+}
+
+// withoutFailedOperations returns events without the file operations that
+// did not happen. An operation is recorded before it is carried out, so one
+// that then failed, such as a move whose copy did not match its source, is
+// followed by an ERROR for the same source path. Undoing it would look for a
+// file that never moved.
+func withoutFailedOperations(events []AuditEvent) []AuditEvent {
+	failed := make(map[string]bool)
+	kept := make([]AuditEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		switch event.EventType {
+		case EventError:
+			failed[event.SourcePath] = true
+		case EventMove, EventCopy, EventExtract, EventRouteToReview, EventDuplicateDetected:
+			if failed[event.SourcePath] {
+				continue
+			}
+		}
+		kept = append(kept, event)
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// restoredFiles records the files an earlier undo restored, by the path each
+// file was restored from and the path it was restored to.
+type restoredFiles struct {
+	from map[string]bool
+	to   map[string]bool
+}
+
+// contains reports whether the file of an event with the given (mapped)
+// source and destination paths was already restored.
+func (r *restoredFiles) contains(sourcePath, destPath string) bool {
+	return r != nil && ((destPath != "" && r.from[destPath]) || (sourcePath != "" && r.to[sourcePath]))
+}
+
+// undoRun undoes runID in a new UNDO run. Events whose files are in restored
+// (which may be nil) are counted as already restored and not undone again.
+func (e *UndoEngine) undoRun(runID RunID, config CrossMachineUndoConfig, restored *restoredFiles) (*UndoResult, error) {
 	// Validate that the run exists
 	// Requirements: 6.2
 	runInfo, err := e.reader.GetRunByID(runID)
@@ -166,14 +397,22 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 	}
 
 	// Get all events for the run
-	events, err := e.reader.GetRun(runID)
+	events, err := e.runEvents(*runInfo, config.PathRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events for run %s: %w", runID, err)
 	}
 
+	// Files restored into an inbound directory the run did not scan may be
+	// organized again as soon as they are back
+	if !config.Force {
+		if paths := e.inboundRestores(runInfo, events, config, restored); len(paths) > 0 {
+			return nil, &InboundRestoreError{RunID: runID, Paths: paths}
+		}
+	}
+
 	// Build conflict map for older run undo
 	// Requirements: 6.5, 6.6
-	conflictMap, err := e.buildConflictMap(runID, runInfo.StartTime)
+	conflictMap, err := e.buildConflictMap(runID, config.PathRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build conflict map: %w", err)
 	}
@@ -194,6 +433,12 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 	// Requirements: 5.2
 	sortedEvents := e.sortEventsReverse(events)
 	result.TotalEvents = len(sortedEvents)
+	e.bytesDone, e.bytesTotal = 0, 0
+	for _, event := range sortedEvents {
+		e.bytesTotal += restoredBytes(event)
+	}
+
+	var missing []MissingFileEntry
 
 	// Process each event
 	for i, event := range sortedEvents {
@@ -201,10 +446,29 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 		sourcePath := e.applyPathMappings(event.SourcePath, config.PathMappings)
 		destPath := e.applyPathMappings(event.DestinationPath, config.PathMappings)
 
+		// Files restored by an interrupted undo are already back in place
+		if e.isFileModificationEvent(event.EventType) && restored.contains(sourcePath, destPath) {
+			result.AlreadyRestored++
+			e.notifyCallback(UndoProgressEvent{
+				Type:       "skip",
+				Current:    i + 1,
+				Total:      result.TotalEvents,
+				SourcePath: sourcePath,
+				DestPath:   destPath,
+				Reason:     "already restored",
+				Success:    true,
+			})
+			continue
+		}
+
 		// Check for conflicts with subsequent runs before undoing
 		// Requirements: 6.5, 6.6
-		if conflict := e.checkConflict(event, conflictMap, config.PathMappings); conflict != nil {
-			e.recordConflictDetected(event.SourcePath, event.DestinationPath, conflict.ConflictingRunID)
+		conflict := e.checkConflict(event, conflictMap, config.PathMappings)
+		if conflict != nil && config.Force {
+			// Record the override and fall through to the normal restore path
+			e.recordConflictDetected(event.SourcePath, event.DestinationPath, conflict.ConflictingRunID, true)
+		} else if conflict != nil {
+			e.recordConflictDetected(event.SourcePath, event.DestinationPath, conflict.ConflictingRunID, false)
 			result.Failed++
 			errMsg := fmt.Sprintf("file was modified by subsequent run %s", conflict.ConflictingRunID)
 			result.FailureDetails = append(result.FailureDetails, UndoError{
@@ -226,10 +490,15 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 			continue
 		}
 
+		e.currentBytes = restoredBytes(event)
 		wasNoOp, undoErr := e.undoEventCrossMachineWithCallback(event, config, i+1, result.TotalEvents)
+		e.currentBytes = 0
 		if undoErr != nil {
 			result.Failed++
 			result.FailureDetails = append(result.FailureDetails, *undoErr)
+			if undoErr.Reason == ReasonSourceNotFound && config.MissingReport != "" {
+				missing = append(missing, newMissingFileEntry(undoRunID, runID, event, undoErr))
+			}
 		} else if wasNoOp {
 			result.Skipped++
 		} else {
@@ -241,7 +510,7 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 	summary := RunSummary{
 		TotalFiles: result.TotalEvents,
 		Moved:      result.Restored,
-		Skipped:    result.Skipped,
+		Skipped:    result.Skipped + result.AlreadyRestored,
 		Errors:     result.Failed,
 	}
 
@@ -254,9 +523,33 @@ func (e *UndoEngine) UndoRunCrossMachine(runID RunID, config CrossMachineUndoCon
 		return result, fmt.Errorf("failed to end undo run: %w", err)
 	}
 
+	if len(missing) > 0 {
+		if err := AppendMissingReport(config.MissingReport, missing); err != nil {
+			return result, err
+		}
+	}
+
 	return result, nil
 }
 
+// newMissingFileEntry builds a missing-file report entry for an event whose
+// file could not be found.
+func newMissingFileEntry(undoRunID, targetRunID RunID, event AuditEvent, undoErr *UndoError) MissingFileEntry {
+	entry := MissingFileEntry{
+		RecordedAt:   time.Now().UTC(),
+		UndoRunID:    undoRunID,
+		TargetRunID:  targetRunID,
+		EventType:    event.EventType,
+		ExpectedPath: undoErr.DestPath,
+		OriginalPath: undoErr.SourcePath,
+		Message:      undoErr.Message,
+	}
+	if event.FileIdentity != nil {
+		entry.ContentHash = event.FileIdentity.ContentHash
+	}
+	return entry
+}
+
 // PreviewUndo shows what would be undone without executing.
 func (e *UndoEngine) PreviewUndo(runID RunID, pathMappings []PathMapping) (*UndoPreview, error) {
 	config := CrossMachineUndoConfig{
@@ -268,13 +561,13 @@ func (e *UndoEngine) PreviewUndo(runID RunID, pathMappings []PathMapping) (*Undo
 // PreviewUndoCrossMachine shows what would be undone without executing, with cross-machine support.
 func (e *UndoEngine) PreviewUndoCrossMachine(runID RunID, config CrossMachineUndoConfig) (*UndoPreview, error) {
 	// Validate that the run exists
-	_, err := e.reader.GetRunByID(runID)
+	runInfo, err := e.reader.GetRunByID(runID)
 	if err != nil {
 		return nil, fmt.Errorf("run not found: %s", runID)
 	}
 
 	// Get all events for the run
-	events, err := e.reader.GetRun(runID)
+	events, err := e.runEvents(*runInfo, config.PathRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get events for run %s: %w", runID, err)
 	}
@@ -294,7 +587,7 @@ func (e *UndoEngine) PreviewUndoCrossMachine(runID RunID, config CrossMachineUnd
 		}
 
 		switch event.EventType {
-		case EventMove:
+		case EventMove, EventCopy, EventExtract:
 			previewEvent.WillRestore = true
 			preview.TotalMoves++
 		case EventRouteToReview:
@@ -363,18 +656,36 @@ func (e *UndoEngine) sortEventsReverse(events []AuditEvent) []AuditEvent {
 		}
 	}
 
-	// Sort in reverse chronological order (newest first)
-	sort.Slice(fileEvents, func(i, j int) bool {
+	// Sort in reverse chronological order (newest first). Timestamps only
+	// have second precision, so events are reversed first and sorted stably
+	// to undo events written in the same second in reverse log order.
+	for i, j := 0, len(fileEvents)-1; i < j; i, j = i+1, j-1 {
+		fileEvents[i], fileEvents[j] = fileEvents[j], fileEvents[i]
+	}
+	sort.SliceStable(fileEvents, func(i, j int) bool {
 		return fileEvents[i].Timestamp.After(fileEvents[j].Timestamp)
 	})
 
 	return fileEvents
 }
 
+// restoredBytes returns the recorded size of the file event moved or copied,
+// or 0 if the event moved no file or has no recorded identity.
+func restoredBytes(event AuditEvent) int64 {
+	if event.FileIdentity == nil {
+		return 0
+	}
+	switch event.EventType {
+	case EventMove, EventCopy, EventExtract, EventRouteToReview, EventDuplicateDetected:
+		return event.FileIdentity.Size
+	}
+	return 0
+}
+
 // isFileEvent returns true if the event type is a file operation event.
 func (e *UndoEngine) isFileEvent(eventType EventType) bool {
 	switch eventType {
-	case EventMove, EventRouteToReview, EventSkip, EventDuplicateDetected,
+	case EventMove, EventCopy, EventExtract, EventRouteToReview, EventSkip, EventDuplicateDetected,
 		EventParseFailure, EventValidationFailure, EventError:
 		return true
 	default:
@@ -423,6 +734,9 @@ func (e *UndoEngine) undoEventCrossMachineWithCallback(event AuditEvent, config
 	case EventMove:
 		undoErr := e.undoMoveCrossMachineWithCallback(event, config, current, total)
 		return false, undoErr
+	case EventCopy, EventExtract:
+		undoErr := e.undoCopyWithCallback(event, config, current, total)
+		return false, undoErr
 	case EventRouteToReview:
 		undoErr := e.undoRouteToReviewCrossMachineWithCallback(event, config, current, total)
 		return false, undoErr
@@ -612,6 +926,10 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 				Reason:     ReasonIdentityMismatch,
 				Message:    "file size has changed since original operation",
 			}
+		case IdentityInodeMismatch:
+			if undoErr := e.handleInodeMismatch(sourcePath, actualFilePath, config, current, total); undoErr != nil {
+				return undoErr
+			}
 		case IdentityMatches:
 			// Notify callback about successful verification
 			// Requirement 4.3: Display verification status for file identity checks
@@ -629,23 +947,12 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 
 	// Check if destination (original source) already has a file
 	// Requirements: 13.1, 13.2
+	restorePath := sourcePath
 	if _, err := os.Stat(sourcePath); err == nil {
-		e.recordCollision(sourcePath, actualFilePath)
-		// Notify callback about collision error
-		e.notifyCallback(UndoProgressEvent{
-			Type:       "error",
-			Current:    current,
-			Total:      total,
-			SourcePath: sourcePath,
-			DestPath:   actualFilePath,
-			Reason:     "original location already has a file",
-			Success:    false,
-		})
-		return &UndoError{
-			SourcePath: sourcePath,
-			DestPath:   actualFilePath,
-			Reason:     ReasonDestinationOccupied,
-			Message:    "original location already has a file",
+		var collisionErr *UndoError
+		restorePath, collisionErr = e.resolveCollision(sourcePath, actualFilePath, config, current, total)
+		if collisionErr != nil {
+			return collisionErr
 		}
 	}
 
@@ -672,7 +979,7 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 	}
 
 	// Perform the undo move
-	if err := os.Rename(actualFilePath, sourcePath); err != nil {
+	if err := os.Rename(actualFilePath, restorePath); err != nil {
 		e.recordUndoError(sourcePath, actualFilePath, err)
 		// Notify callback about error
 		e.notifyCallback(UndoProgressEvent{
@@ -693,7 +1000,7 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 	}
 
 	// Record successful undo
-	e.recordUndoMove(sourcePath, actualFilePath, event.FileIdentity)
+	e.recordUndoMove(restorePath, actualFilePath, event.FileIdentity)
 
 	// Notify callback about successful restore
 	// Requirement 4.1: Display each file being restored with source and destination
@@ -701,7 +1008,7 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 		Type:       "restore",
 		Current:    current,
 		Total:      total,
-		SourcePath: sourcePath,
+		SourcePath: restorePath,
 		DestPath:   actualFilePath,
 		Success:    true,
 	})
@@ -709,6 +1016,65 @@ func (e *UndoEngine) undoMoveCrossMachineWithCallback(event AuditEvent, config C
 	return nil
 }
 
+// undoCopyWithCallback undoes a COPY or EXTRACT event by deleting the copy or
+// the extracted file. The source was never moved, so nothing is restored; the
+// copy is only deleted if it still matches the recorded identity.
+func (e *UndoEngine) undoCopyWithCallback(event AuditEvent, config CrossMachineUndoConfig, current, total int) *UndoError {
+	sourcePath := e.applyPathMappings(event.SourcePath, config.PathMappings)
+	destPath := e.applyPathMappings(event.DestinationPath, config.PathMappings)
+
+	fail := func(reason ReasonCode, message string) *UndoError {
+		e.notifyCallback(UndoProgressEvent{
+			Type:       "error",
+			Current:    current,
+			Total:      total,
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Reason:     message,
+			Success:    false,
+		})
+		return &UndoError{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Reason:     reason,
+			Message:    message,
+		}
+	}
+
+	if _, err := os.Stat(destPath); err != nil {
+		e.recordSourceMissing(sourcePath, destPath)
+		return fail(ReasonSourceNotFound, "copied file not found")
+	}
+
+	if event.FileIdentity != nil {
+		match, err := e.identityResolver.VerifyIdentity(destPath, *event.FileIdentity)
+		if err != nil {
+			e.recordIdentityMismatch(sourcePath, destPath, fmt.Sprintf("identity verification error: %v", err))
+			return fail(ReasonIdentityMismatch, fmt.Sprintf("identity verification error: %v", err))
+		}
+		if match == IdentityHashMismatch || match == IdentitySizeMismatch {
+			e.recordContentChanged(sourcePath, destPath, "copied file has changed since original operation")
+			return fail(ReasonIdentityMismatch, "copied file has changed since original operation")
+		}
+	}
+
+	if err := os.Remove(destPath); err != nil {
+		e.recordUndoError(sourcePath, destPath, err)
+		return fail(ReasonSourceNotFound, fmt.Sprintf("failed to delete copy: %v", err))
+	}
+
+	e.recordUndoCopy(sourcePath, destPath, event.FileIdentity)
+	e.notifyCallback(UndoProgressEvent{
+		Type:       "restore",
+		Current:    current,
+		Total:      total,
+		SourcePath: sourcePath,
+		DestPath:   destPath,
+		Success:    true,
+	})
+	return nil
+}
+
 // findFileForUndo attempts to locate a file for undo operations.
 // It first checks the expected path, then searches by content hash if configured.
 // Requirements: 7.4, 7.5
@@ -771,6 +1137,54 @@ func (e *UndoEngine) findFileForUndo(expectedPath string, identity *FileIdentity
 	}
 }
 
+// handleInodeMismatch applies the configured InodeCheck to a file whose content
+// matches but whose device/inode changed. It returns an UndoError if the file
+// must not be restored.
+func (e *UndoEngine) handleInodeMismatch(sourcePath, filePath string, config CrossMachineUndoConfig, current, total int) *UndoError {
+	mode := config.InodeCheck
+	if mode == "" {
+		mode = InodeCheckWarn
+	}
+
+	// Inode numbers are only comparable on the machine that recorded them
+	sameMachine := config.OriginatingMachine == "" || config.OriginatingMachine == e.machineID
+	if mode == InodeCheckOff || !sameMachine || len(config.PathMappings) > 0 {
+		e.notifyCallback(UndoProgressEvent{
+			Type:         "verify",
+			Current:      current,
+			Total:        total,
+			SourcePath:   sourcePath,
+			DestPath:     filePath,
+			VerifyStatus: "match",
+			Success:      true,
+		})
+		return nil
+	}
+
+	message := "file was replaced by a different file with identical content"
+	e.recordIdentityMismatch(sourcePath, filePath, message)
+	e.notifyCallback(UndoProgressEvent{
+		Type:         "verify",
+		Current:      current,
+		Total:        total,
+		SourcePath:   sourcePath,
+		DestPath:     filePath,
+		VerifyStatus: "mismatch",
+		Reason:       message,
+		Success:      mode != InodeCheckStrict,
+	})
+
+	if mode == InodeCheckStrict {
+		return &UndoError{
+			SourcePath: sourcePath,
+			DestPath:   filePath,
+			Reason:     ReasonIdentityMismatch,
+			Message:    message,
+		}
+	}
+	return nil
+}
+
 // recordPathDiscrepancy records when a file was found at a different path than expected.
 // Requirements: 7.4
 func (e *UndoEngine) recordPathDiscrepancy(sourcePath, expectedPath, actualPath string) {
@@ -790,6 +1204,115 @@ func (e *UndoEngine) recordPathDiscrepancy(sourcePath, expectedPath, actualPath
 	e.writer.WriteEvent(event)
 }
 
+// resolveCollision handles an occupied original location according to config.OnCollision.
+// It returns the path the file should be restored to, or an UndoError if the
+// collision is not resolved.
+func (e *UndoEngine) resolveCollision(sourcePath, currentPath string, config CrossMachineUndoConfig, current, total int) (string, *UndoError) {
+	var message string
+
+	switch config.OnCollision {
+	case CollisionKeepBoth:
+		return nextAvailablePath(sourcePath, "_restored"), nil
+	case CollisionTrash:
+		trashDir := config.TrashDirectory
+		if trashDir == "" {
+			trashDir = DefaultTrashDirectory
+		}
+		trashDir = filepath.Join(trashDir, string(*e.writer.CurrentRunID()))
+
+		err := os.MkdirAll(trashDir, 0755)
+		if err == nil {
+			trashPath := nextAvailablePath(filepath.Join(trashDir, filepath.Base(sourcePath)), "_trashed")
+			if err = moveToTrash(sourcePath, trashPath); err == nil {
+				e.recordTrash(sourcePath, trashPath)
+				e.notifyCallback(UndoProgressEvent{
+					Type:       "trash",
+					Current:    current,
+					Total:      total,
+					SourcePath: sourcePath,
+					DestPath:   trashPath,
+					Success:    true,
+				})
+				return sourcePath, nil
+			}
+		}
+		message = fmt.Sprintf("original location already has a file and it could not be moved to trash: %v", err)
+	default:
+		message = "original location already has a file"
+	}
+
+	e.recordCollision(sourcePath, currentPath)
+	// Notify callback about collision error
+	e.notifyCallback(UndoProgressEvent{
+		Type:       "error",
+		Current:    current,
+		Total:      total,
+		SourcePath: sourcePath,
+		DestPath:   currentPath,
+		Reason:     message,
+		Success:    false,
+	})
+	return "", &UndoError{
+		SourcePath: sourcePath,
+		DestPath:   currentPath,
+		Reason:     ReasonDestinationOccupied,
+		Message:    message,
+	}
+}
+
+// nextAvailablePath returns path if nothing exists there, otherwise the first of
+// "<base><suffix><ext>", "<base><suffix>_2<ext>", ... that is free.
+func nextAvailablePath(path, suffix string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+
+	candidate := base + suffix + ext
+	for n := 2; ; n++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = base + suffix + "_" + strconv.Itoa(n) + ext
+	}
+}
+
+// moveToTrash moves the file at src to trashPath. The trash directory may be
+// on another volume than src, so a rename that fails for any reason other
+// than permissions falls back to copying the file and removing src.
+func moveToTrash(src, trashPath string) error {
+	err := os.Rename(src, trashPath)
+	if err == nil || os.IsPermission(err) {
+		return err
+	}
+	return copyAndRemove(src, trashPath)
+}
+
+// copyAndRemove copies the file at src to dst with its permissions and
+// modification time, then removes src. The copy is removed again if src
+// cannot be, so the file is never left in both places.
+func copyAndRemove(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
 // undoRouteToReview undoes a ROUTE_TO_REVIEW event.
 // Requirements: 5.4
 func (e *UndoEngine) undoRouteToReview(event AuditEvent, pathMappings []PathMapping) *UndoError {
@@ -859,23 +1382,12 @@ func (e *UndoEngine) undoRouteToReviewCrossMachineWithCallback(event AuditEvent,
 	}
 
 	// Check if destination (original source) already has a file
+	restorePath := sourcePath
 	if _, err := os.Stat(sourcePath); err == nil {
-		e.recordCollision(sourcePath, destPath)
-		// Notify callback about collision error
-		e.notifyCallback(UndoProgressEvent{
-			Type:       "error",
-			Current:    current,
-			Total:      total,
-			SourcePath: sourcePath,
-			DestPath:   destPath,
-			Reason:     "original location already has a file",
-			Success:    false,
-		})
-		return &UndoError{
-			SourcePath: sourcePath,
-			DestPath:   destPath,
-			Reason:     ReasonDestinationOccupied,
-			Message:    "original location already has a file",
+		var collisionErr *UndoError
+		restorePath, collisionErr = e.resolveCollision(sourcePath, destPath, config, current, total)
+		if collisionErr != nil {
+			return collisionErr
 		}
 	}
 
@@ -902,7 +1414,7 @@ func (e *UndoEngine) undoRouteToReviewCrossMachineWithCallback(event AuditEvent,
 	}
 
 	// Perform the undo move
-	if err := os.Rename(destPath, sourcePath); err != nil {
+	if err := os.Rename(destPath, restorePath); err != nil {
 		e.recordUndoError(sourcePath, destPath, err)
 		// Notify callback about error
 		e.notifyCallback(UndoProgressEvent{
@@ -923,7 +1435,7 @@ func (e *UndoEngine) undoRouteToReviewCrossMachineWithCallback(event AuditEvent,
 	}
 
 	// Record successful undo
-	e.recordUndoMove(sourcePath, destPath, nil)
+	e.recordUndoMove(restorePath, destPath, nil)
 
 	// Notify callback about successful restore
 	// Requirement 4.1: Display each file being restored with source and destination
@@ -931,7 +1443,7 @@ func (e *UndoEngine) undoRouteToReviewCrossMachineWithCallback(event AuditEvent,
 		Type:       "restore",
 		Current:    current,
 		Total:      total,
-		SourcePath: sourcePath,
+		SourcePath: restorePath,
 		DestPath:   destPath,
 		Success:    true,
 	})
@@ -1059,23 +1571,12 @@ func (e *UndoEngine) undoDuplicateCrossMachineWithCallback(event AuditEvent, con
 	}
 
 	// Move file back to original source
+	restorePath := sourcePath
 	if _, err := os.Stat(sourcePath); err == nil {
-		e.recordCollision(sourcePath, actualDest)
-		// Notify callback about collision error
-		e.notifyCallback(UndoProgressEvent{
-			Type:       "error",
-			Current:    current,
-			Total:      total,
-			SourcePath: sourcePath,
-			DestPath:   actualDest,
-			Reason:     "original location already has a file",
-			Success:    false,
-		})
-		return false, &UndoError{
-			SourcePath: sourcePath,
-			DestPath:   actualDest,
-			Reason:     ReasonDestinationOccupied,
-			Message:    "original location already has a file",
+		var collisionErr *UndoError
+		restorePath, collisionErr = e.resolveCollision(sourcePath, actualDest, config, current, total)
+		if collisionErr != nil {
+			return false, collisionErr
 		}
 	}
 
@@ -1101,7 +1602,7 @@ func (e *UndoEngine) undoDuplicateCrossMachineWithCallback(event AuditEvent, con
 		}
 	}
 
-	if err := os.Rename(actualDest, sourcePath); err != nil {
+	if err := os.Rename(actualDest, restorePath); err != nil {
 		e.recordUndoError(sourcePath, actualDest, err)
 		// Notify callback about error
 		e.notifyCallback(UndoProgressEvent{
@@ -1121,7 +1622,7 @@ func (e *UndoEngine) undoDuplicateCrossMachineWithCallback(event AuditEvent, con
 		}
 	}
 
-	e.recordUndoMove(sourcePath, actualDest, nil)
+	e.recordUndoMove(restorePath, actualDest, nil)
 
 	// Notify callback about successful restore
 	// Requirement 4.1: Display each file being restored with source and destination
@@ -1129,7 +1630,7 @@ func (e *UndoEngine) undoDuplicateCrossMachineWithCallback(event AuditEvent, con
 		Type:       "restore",
 		Current:    current,
 		Total:      total,
-		SourcePath: sourcePath,
+		SourcePath: restorePath,
 		DestPath:   actualDest,
 		Success:    true,
 	})
@@ -1169,6 +1670,22 @@ func (e *UndoEngine) recordUndoMove(sourcePath, destPath string, identity *FileI
 	e.writer.WriteEvent(event)
 }
 
+// recordUndoCopy records an UNDO_MOVE event for a deleted copy, marked with
+// "copyDeleted" metadata since the source was never moved.
+func (e *UndoEngine) recordUndoCopy(sourcePath, destPath string, identity *FileIdentity) {
+	event := AuditEvent{
+		Timestamp:       time.Now().UTC(),
+		RunID:           *e.writer.CurrentRunID(),
+		EventType:       EventUndoMove,
+		Status:          StatusSuccess,
+		SourcePath:      destPath,
+		DestinationPath: sourcePath,
+		FileIdentity:    identity,
+		Metadata:        map[string]string{"copyDeleted": "true"},
+	}
+	e.writer.WriteEvent(event)
+}
+
 // recordUndoSkip records an UNDO_SKIP event.
 // Requirements: 14.4
 func (e *UndoEngine) recordUndoSkip(sourcePath string, reason ReasonCode) {
@@ -1249,6 +1766,23 @@ func (e *UndoEngine) recordCollision(sourcePath, destPath string) {
 	e.writer.WriteEvent(event)
 }
 
+// recordTrash records a TRASH event when an occupying file is moved aside.
+func (e *UndoEngine) recordTrash(originalPath, trashPath string) {
+	event := AuditEvent{
+		Timestamp:       time.Now().UTC(),
+		RunID:           *e.writer.CurrentRunID(),
+		EventType:       EventTrash,
+		Status:          StatusSuccess,
+		SourcePath:      originalPath,
+		DestinationPath: trashPath,
+		ReasonCode:      ReasonDestinationOccupied,
+	}
+	if identity, err := e.identityResolver.CaptureIdentity(trashPath); err == nil {
+		event.FileIdentity = identity
+	}
+	e.writer.WriteEvent(event)
+}
+
 // recordUndoError records an ERROR event during undo.
 func (e *UndoEngine) recordUndoError(sourcePath, destPath string, err error) {
 	event := AuditEvent{
@@ -1274,10 +1808,27 @@ type ConflictInfo struct {
 	EventType        EventType // The type of event that caused the conflict
 }
 
+// startedAfter reports whether runs[i] was started after runs[target]. Runs
+// that both have a sequence number are ordered by it, so clock skew between
+// machines or runs started within the same instant do not matter. Other runs
+// are ordered by start time, with the position in runs (log order) breaking
+// ties.
+func startedAfter(runs []RunInfo, i, target int) bool {
+	run, targetRun := runs[i], runs[target]
+	if run.Sequence > 0 && targetRun.Sequence > 0 {
+		return run.Sequence > targetRun.Sequence
+	}
+	if run.StartTime.Equal(targetRun.StartTime) {
+		return i > target
+	}
+	return run.StartTime.After(targetRun.StartTime)
+}
+
 // buildConflictMap builds a map of files that were modified by runs after the target run.
-// This is used to detect conflicts when undoing an older run.
+// This is used to detect conflicts when undoing an older run. Relative paths
+// are resolved against pathRoot, or each run's recorded root if it is empty.
 // Requirements: 6.5, 6.6
-func (e *UndoEngine) buildConflictMap(targetRunID RunID, targetRunStartTime time.Time) (map[string]*ConflictInfo, error) {
+func (e *UndoEngine) buildConflictMap(targetRunID RunID, pathRoot string) (map[string]*ConflictInfo, error) {
 	conflictMap := make(map[string]*ConflictInfo)
 
 	// Get all runs
@@ -1287,7 +1838,6 @@ func (e *UndoEngine) buildConflictMap(targetRunID RunID, targetRunStartTime time
 	}
 
 	// Find the index of the target run in the list
-	// Runs are sorted by start time (oldest first), so runs after the target index are subsequent
 	targetIndex := -1
 	for i, run := range runs {
 		if run.RunID == targetRunID {
@@ -1302,23 +1852,8 @@ func (e *UndoEngine) buildConflictMap(targetRunID RunID, targetRunStartTime time
 	}
 
 	// Find runs that are subsequent to the target run
-	// A run is subsequent if:
-	// 1. It started after the target run (by timestamp), OR
-	// 2. It has the same start time but appears later in the list (by log order)
 	for i, run := range runs {
-		// Skip the target run itself
-		if run.RunID == targetRunID {
-			continue
-		}
-
-		// Skip runs that started before the target run
-		if run.StartTime.Before(targetRunStartTime) {
-			continue
-		}
-
-		// If timestamps are equal, use list order as tiebreaker
-		// Runs appearing later in the list are considered subsequent
-		if run.StartTime.Equal(targetRunStartTime) && i <= targetIndex {
+		if !startedAfter(runs, i, targetIndex) {
 			continue
 		}
 
@@ -1328,7 +1863,7 @@ func (e *UndoEngine) buildConflictMap(targetRunID RunID, targetRunStartTime time
 		}
 
 		// Get events for this subsequent run
-		events, err := e.reader.GetRun(run.RunID)
+		events, err := e.runEvents(run, pathRoot)
 		if err != nil {
 			// Log but continue - we don't want to fail the entire undo
 			continue
@@ -1366,7 +1901,7 @@ func (e *UndoEngine) buildConflictMap(targetRunID RunID, targetRunStartTime time
 // These are events that could create conflicts when undoing an older run.
 func (e *UndoEngine) isFileModificationEvent(eventType EventType) bool {
 	switch eventType {
-	case EventMove, EventRouteToReview, EventDuplicateDetected:
+	case EventMove, EventCopy, EventExtract, EventRouteToReview, EventDuplicateDetected:
 		return true
 	default:
 		return false
@@ -1418,9 +1953,58 @@ func (e *UndoEngine) checkConflict(event AuditEvent, conflictMap map[string]*Con
 	return nil
 }
 
+// inboundRestores returns the (mapped) paths that undoing the events of run
+// would restore files to and that lie inside one of config.InboundDirectories
+// the run did not scan, even if it scanned a directory below that one. Runs
+// that did not record their inbound directories (older runs, promote and
+// record runs) are not checked. Files in restored are left out.
+func (e *UndoEngine) inboundRestores(run *RunInfo, events []AuditEvent, config CrossMachineUndoConfig, restored *restoredFiles) []string {
+	if run.InboundDirectories == nil {
+		return nil
+	}
+	var paths []string
+	for _, event := range events {
+		switch event.EventType {
+		case EventMove, EventRouteToReview, EventDuplicateDetected:
+		default:
+			continue
+		}
+		sourcePath := e.applyPathMappings(event.SourcePath, config.PathMappings)
+		destPath := e.applyPathMappings(event.DestinationPath, config.PathMappings)
+		if sourcePath == "" || restored.contains(sourcePath, destPath) {
+			continue
+		}
+		for _, dir := range config.InboundDirectories {
+			if insideDir(sourcePath, dir) && !containsDir(run.InboundDirectories, dir) {
+				paths = append(paths, sourcePath)
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// insideDir reports whether path lies below dir.
+func insideDir(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// containsDir reports whether dirs lists dir.
+func containsDir(dirs []string, dir string) bool {
+	for _, d := range dirs {
+		if filepath.Clean(d) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
 // recordConflictDetected records a CONFLICT_DETECTED event when a file was modified by a subsequent run.
+// When forced is true the conflict was overridden with --force: the event is recorded as
+// successful with "forced" metadata and the restore is attempted.
 // Requirements: 6.5, 6.6
-func (e *UndoEngine) recordConflictDetected(sourcePath, destPath string, conflictingRunID RunID) {
+func (e *UndoEngine) recordConflictDetected(sourcePath, destPath string, conflictingRunID RunID, forced bool) {
 	event := AuditEvent{
 		Timestamp:       time.Now().UTC(),
 		RunID:           *e.writer.CurrentRunID(),
@@ -1433,5 +2017,9 @@ func (e *UndoEngine) recordConflictDetected(sourcePath, destPath string, conflic
 			"conflictingRunId": string(conflictingRunID),
 		},
 	}
+	if forced {
+		event.Status = StatusSuccess
+		event.Metadata["forced"] = "true"
+	}
 	e.writer.WriteEvent(event)
 }
@@ -0,0 +1,31 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WriteUndoReport writes result as JSON to path, replacing any previous
+// contents. A result without failures has an empty failureDetails list.
+func WriteUndoReport(path string, result *UndoResult) error {
+	report := *result
+	if report.FailureDetails == nil {
+		report.FailureDetails = []UndoError{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal undo report: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write undo report: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write undo report: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package audit
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -622,6 +626,99 @@ func TestUndoEngine_PathMappings(t *testing.T) {
 	}
 }
 
+// TestUndoEngine_RelativePaths verifies that a run with a PathRoot records
+// paths relative to it and that undo restores its files below a new root after
+// the tree was moved.
+func TestUndoEngine_RelativePaths(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	rootA := filepath.Join(tempDir, "machine-a")
+	rootB := filepath.Join(tempDir, "machine-b")
+	outside := filepath.Join(tempDir, "elsewhere", "notes.txt")
+
+	destPath := filepath.Join(rootA, "dest", "test.txt")
+	if err := os.MkdirAll(filepath.Join(rootA, "inbox"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(destPath, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	config := AuditConfig{LogDirectory: logDir, PathRoot: rootA}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "machine-a")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+	if err := writer.RecordMove(filepath.Join(rootA, "inbox", "test.txt"), destPath, identity); err != nil {
+		t.Fatalf("Failed to record move: %v", err)
+	}
+	if err := writer.RecordSkip(outside, ReasonNoMatch); err != nil {
+		t.Fatalf("Failed to record skip: %v", err)
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1, Skipped: 1}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+	writer.Close()
+
+	reader := NewAuditReader(logDir)
+	run, err := reader.GetRunByID(runID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if run.PathRoot != rootA {
+		t.Errorf("Expected path root %s, got %q", rootA, run.PathRoot)
+	}
+	events, err := reader.GetRun(runID)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	for _, event := range events {
+		switch event.EventType {
+		case EventMove:
+			if event.SourcePath != "inbox/test.txt" || event.DestinationPath != "dest/test.txt" {
+				t.Errorf("Expected relative paths, got %q -> %q", event.SourcePath, event.DestinationPath)
+			}
+		case EventSkip:
+			if event.SourcePath != outside {
+				t.Errorf("Expected a path outside the root to stay absolute, got %q", event.SourcePath)
+			}
+		}
+	}
+
+	// Move the whole tree and undo against its new root
+	if err := os.Rename(rootA, rootB); err != nil {
+		t.Fatalf("Failed to move tree: %v", err)
+	}
+	writer2, err := NewAuditWriter(AuditConfig{LogDirectory: logDir})
+	if err != nil {
+		t.Fatalf("Failed to create second writer: %v", err)
+	}
+	defer writer2.Close()
+
+	engine := NewUndoEngine(reader, writer2, "1.0.0", "machine-b")
+	result, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{PathRoot: rootB})
+	if err != nil {
+		t.Fatalf("Failed to undo run: %v", err)
+	}
+	if result.Restored != 1 {
+		t.Errorf("Expected 1 restored file, got %d (failures: %v)", result.Restored, result.FailureDetails)
+	}
+	if _, err := os.Stat(filepath.Join(rootB, "inbox", "test.txt")); err != nil {
+		t.Errorf("File not restored below the new root: %v", err)
+	}
+}
+
 // Unit tests for undo handlers for each event type
 // Requirements: 5.3, 5.4, 5.5, 5.6
 
@@ -1996,6 +2093,102 @@ func TestUndoEngine_InterruptedUndoIsResumable(t *testing.T) {
 	}
 }
 
+// TestUndoEngine_ContinueInterruptedUndo verifies that ContinueUndo finishes an
+// undo that stopped part-way, leaving the files it already restored alone
+// instead of reporting them as failures.
+func TestUndoEngine_ContinueInterruptedUndo(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{logDir, sourceDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	identityResolver := NewIdentityResolver()
+	var sources, dests []string
+	for i := 0; i < 5; i++ {
+		fileName := "file" + string(rune('A'+i)) + ".txt"
+		source := filepath.Join(sourceDir, fileName)
+		dest := filepath.Join(destDir, fileName)
+		if err := os.WriteFile(dest, []byte("content-"+fileName), 0644); err != nil {
+			t.Fatalf("Failed to create file %d: %v", i, err)
+		}
+		identity, _ := identityResolver.CaptureIdentity(dest)
+		if err := writer.RecordMove(source, dest, identity); err != nil {
+			t.Fatalf("Failed to record move %d: %v", i, err)
+		}
+		sources = append(sources, source)
+		dests = append(dests, dest)
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 5}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+
+	// An undo that restored the last three files and then stopped without a RUN_END
+	undoRunID, err := writer.StartUndoRun("1.0.0", "test-machine", runID)
+	if err != nil {
+		t.Fatalf("Failed to start undo run: %v", err)
+	}
+	for i := 4; i >= 2; i-- {
+		if err := os.Rename(dests[i], sources[i]); err != nil {
+			t.Fatalf("Failed to restore file %d: %v", i, err)
+		}
+		if err := writer.WriteEvent(AuditEvent{
+			Timestamp:       time.Now().UTC(),
+			RunID:           undoRunID,
+			EventType:       EventUndoMove,
+			Status:          StatusSuccess,
+			SourcePath:      dests[i],
+			DestinationPath: sources[i],
+		}); err != nil {
+			t.Fatalf("Failed to record undo move %d: %v", i, err)
+		}
+	}
+	writer.Close()
+
+	writer2, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create second writer: %v", err)
+	}
+	defer writer2.Close()
+
+	engine := NewUndoEngine(NewAuditReader(logDir), writer2, "1.0.0", "test-machine")
+	result, err := engine.ContinueUndo(CrossMachineUndoConfig{})
+	if err != nil {
+		t.Fatalf("ContinueUndo failed: %v", err)
+	}
+	if result.TargetRunID != runID {
+		t.Errorf("Expected target run %s, got %s", runID, result.TargetRunID)
+	}
+	if result.Restored != 2 || result.AlreadyRestored != 3 || result.Failed != 0 {
+		t.Errorf("Expected 2 restored, 3 already restored and no failures, got restored=%d already=%d failed=%d: %+v",
+			result.Restored, result.AlreadyRestored, result.Failed, result.FailureDetails)
+	}
+	for i, source := range sources {
+		if _, err := os.Stat(source); err != nil {
+			t.Errorf("Expected file %d at its original location: %v", i, err)
+		}
+	}
+
+	// The continuation completed, so there is nothing left to continue
+	if _, err := engine.ContinueUndo(CrossMachineUndoConfig{}); err == nil {
+		t.Error("Expected an error when the most recent undo completed")
+	}
+}
+
 // TestUndoEngine_ContentChangedEvent tests that CONTENT_CHANGED event is recorded
 // when file content has changed since the original operation.
 // Requirements: 13.4
@@ -3162,6 +3355,91 @@ func TestUndoEngine_ConflictDetection(t *testing.T) {
 	}
 }
 
+// TestUndoEngine_ConflictDetectionWithClockSkew verifies that a later run is
+// recognized as later by its sequence number even when its wall-clock start
+// time is before the run being undone.
+func TestUndoEngine_ConflictDetectionWithClockSkew(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	sourcePath := filepath.Join(tempDir, "source", "test.txt")
+	dest1Path := filepath.Join(tempDir, "dest1", "test.txt")
+	dest2Path := filepath.Join(tempDir, "dest2", "test.txt")
+	for _, dir := range []string{logDir, filepath.Dir(sourcePath), filepath.Dir(dest1Path), filepath.Dir(dest2Path)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	if err := os.WriteFile(dest2Path, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	identity, err := NewIdentityResolver().CaptureIdentity(dest2Path)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	var runIDs []RunID
+	for _, move := range [][2]string{{sourcePath, dest1Path}, {dest1Path, dest2Path}} {
+		runID, err := writer.StartRun("1.0.0", "test-machine")
+		if err != nil {
+			t.Fatalf("Failed to start run: %v", err)
+		}
+		if err := writer.RecordMove(move[0], move[1], identity); err != nil {
+			t.Fatalf("Failed to record move: %v", err)
+		}
+		if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1}); err != nil {
+			t.Fatalf("Failed to end run: %v", err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+	writer.Close()
+
+	// Set the second run's clock an hour behind the first
+	logPath := filepath.Join(logDir, "sorta-audit.jsonl")
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	var skewed []byte
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		event, err := UnmarshalJSONLine(line)
+		if err != nil {
+			t.Fatalf("Failed to parse event: %v", err)
+		}
+		if event.RunID == runIDs[1] {
+			event.Timestamp = event.Timestamp.Add(-time.Hour)
+		}
+		line, err = event.MarshalJSONLine()
+		if err != nil {
+			t.Fatalf("Failed to marshal event: %v", err)
+		}
+		skewed = append(append(skewed, line...), '\n')
+	}
+	if err := os.WriteFile(logPath, skewed, 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	reader := NewAuditReader(logDir)
+	writer2, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create second writer: %v", err)
+	}
+	defer writer2.Close()
+
+	engine := NewUndoEngine(reader, writer2, "1.0.0", "test-machine")
+	result, err := engine.UndoRun(runIDs[0], nil)
+	if err != nil {
+		t.Fatalf("Failed to undo run: %v", err)
+	}
+	if result.Restored != 0 || len(result.FailureDetails) != 1 || result.FailureDetails[0].Reason != ReasonConflictWithLaterRun {
+		t.Errorf("Expected the move to fail with %s, got %+v", ReasonConflictWithLaterRun, result)
+	}
+}
+
 // TestUndoEngine_ConflictDetectionWithMultipleFiles tests conflict detection with multiple files
 // where some have conflicts and some don't
 // Requirements: 6.5, 6.6
@@ -3451,3 +3729,802 @@ func TestUndoEngine_ConflictDetectionIgnoresUndoRuns(t *testing.T) {
 		t.Errorf("Expected 1 restored file, got %d", result.Restored)
 	}
 }
+
+// TestUndoEngine_CollisionResolutionTrash tests that undo with CollisionTrash moves
+// the occupying file to the trash directory and restores the original file.
+func TestUndoEngine_CollisionResolutionTrash(t *testing.T) {
+	tempDir := t.TempDir()
+
+	logDir := filepath.Join(tempDir, "logs")
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+	trashDir := filepath.Join(tempDir, "trash")
+
+	for _, dir := range []string{logDir, sourceDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	sourcePath := filepath.Join(sourceDir, "test.txt")
+	destPath := filepath.Join(destDir, "test.txt")
+
+	if err := os.WriteFile(destPath, []byte("moved content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+
+	if err := writer.RecordMove(sourcePath, destPath, identity); err != nil {
+		t.Fatalf("Failed to record move: %v", err)
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+	writer.Close()
+
+	// A new file now occupies the original location
+	if err := os.WriteFile(sourcePath, []byte("occupant"), 0644); err != nil {
+		t.Fatalf("Failed to create occupant: %v", err)
+	}
+
+	reader := NewAuditReader(logDir)
+	writer2, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create second writer: %v", err)
+	}
+	defer writer2.Close()
+
+	engine := NewUndoEngine(reader, writer2, "1.0.0", "test-machine")
+	result, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{
+		OnCollision:    CollisionTrash,
+		TrashDirectory: trashDir,
+	})
+	if err != nil {
+		t.Fatalf("Failed to undo run: %v", err)
+	}
+
+	if result.Restored != 1 || result.Failed != 0 {
+		t.Fatalf("Expected 1 restored and 0 failed, got %d restored and %d failed: %+v",
+			result.Restored, result.Failed, result.FailureDetails)
+	}
+
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to read restored file: %v", err)
+	}
+	if string(content) != "moved content" {
+		t.Errorf("Expected restored content 'moved content', got %q", content)
+	}
+
+	trashedPath := filepath.Join(trashDir, string(result.UndoRunID), "test.txt")
+	content, err = os.ReadFile(trashedPath)
+	if err != nil {
+		t.Fatalf("Expected occupant in trash at %s: %v", trashedPath, err)
+	}
+	if string(content) != "occupant" {
+		t.Errorf("Expected trashed content 'occupant', got %q", content)
+	}
+
+	undoEvents, err := reader.GetRun(result.UndoRunID)
+	if err != nil {
+		t.Fatalf("Failed to get undo run events: %v", err)
+	}
+
+	trashCount := 0
+	for _, event := range undoEvents {
+		if event.EventType == EventTrash {
+			trashCount++
+			if event.SourcePath != sourcePath || event.DestinationPath != trashedPath {
+				t.Errorf("Unexpected TRASH event paths: %s -> %s", event.SourcePath, event.DestinationPath)
+			}
+		}
+	}
+	if trashCount != 1 {
+		t.Errorf("Expected 1 TRASH event, got %d", trashCount)
+	}
+}
+
+// TestCopyAndRemove verifies the fallback used when the trash is on another
+// volume: the file is copied with its mode and modification time, and the
+// original is removed.
+func TestCopyAndRemove(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "occupant.txt")
+	dst := filepath.Join(tempDir, "trash", "occupant.txt")
+	if err := os.WriteFile(src, []byte("occupant"), 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	modTime := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	if err := copyAndRemove(src, dst); err != nil {
+		t.Fatalf("copyAndRemove failed: %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be removed, got %v", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("Expected copy at %s: %v", dst, err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "occupant" {
+		t.Errorf("Expected copied content, got %q", data)
+	}
+	if info.Mode().Perm() != 0600 || !info.ModTime().Equal(modTime) {
+		t.Errorf("Expected mode 0600 and mtime %v, got %v and %v", modTime, info.Mode().Perm(), info.ModTime())
+	}
+}
+
+// TestUndoEngine_InodeCheckDetectsSameContentReplacement verifies that a destination
+// file replaced by a different file with identical content is detected by inode.
+func TestUndoEngine_InodeCheckDetectsSameContentReplacement(t *testing.T) {
+	for _, mode := range []InodeCheck{InodeCheckStrict, InodeCheckWarn} {
+		t.Run(string(mode), func(t *testing.T) {
+			tempDir := t.TempDir()
+			logDir := filepath.Join(tempDir, "logs")
+			sourceDir := filepath.Join(tempDir, "source")
+			destDir := filepath.Join(tempDir, "dest")
+			for _, dir := range []string{logDir, sourceDir, destDir} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+			}
+
+			sourcePath := filepath.Join(sourceDir, "test.txt")
+			destPath := filepath.Join(destDir, "test.txt")
+			if err := os.WriteFile(destPath, []byte("same content"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+
+			identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+			if err != nil {
+				t.Fatalf("Failed to capture identity: %v", err)
+			}
+			if !identity.HasInode() {
+				t.Skip("inode numbers not available on this platform")
+			}
+
+			config := AuditConfig{LogDirectory: logDir}
+			writer, err := NewAuditWriter(config)
+			if err != nil {
+				t.Fatalf("Failed to create writer: %v", err)
+			}
+			runID, err := writer.StartRun("1.0.0", "test-machine")
+			if err != nil {
+				t.Fatalf("Failed to start run: %v", err)
+			}
+			if err := writer.RecordMove(sourcePath, destPath, identity); err != nil {
+				t.Fatalf("Failed to record move: %v", err)
+			}
+			if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1}); err != nil {
+				t.Fatalf("Failed to end run: %v", err)
+			}
+			writer.Close()
+
+			// Another process replaces the file with an identical copy (new inode)
+			replacement := filepath.Join(destDir, "replacement.tmp")
+			if err := os.WriteFile(replacement, []byte("same content"), 0644); err != nil {
+				t.Fatalf("Failed to create replacement: %v", err)
+			}
+			if err := os.Rename(replacement, destPath); err != nil {
+				t.Fatalf("Failed to replace file: %v", err)
+			}
+
+			match, err := NewIdentityResolver().VerifyIdentity(destPath, *identity)
+			if err != nil || match != IdentityInodeMismatch {
+				t.Fatalf("Expected IdentityInodeMismatch, got %v (%v)", match, err)
+			}
+
+			reader := NewAuditReader(logDir)
+			writer2, err := NewAuditWriter(config)
+			if err != nil {
+				t.Fatalf("Failed to create second writer: %v", err)
+			}
+			defer writer2.Close()
+
+			engine := NewUndoEngine(reader, writer2, "1.0.0", "test-machine")
+			result, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{InodeCheck: mode})
+			if err != nil {
+				t.Fatalf("Failed to undo run: %v", err)
+			}
+
+			_, statErr := os.Stat(sourcePath)
+			if mode == InodeCheckStrict {
+				if result.Restored != 0 || result.Failed != 1 {
+					t.Errorf("Expected 0 restored and 1 failed, got %d and %d", result.Restored, result.Failed)
+				}
+				if len(result.FailureDetails) != 1 || result.FailureDetails[0].Reason != ReasonIdentityMismatch {
+					t.Errorf("Expected IDENTITY_MISMATCH failure, got %+v", result.FailureDetails)
+				}
+				if statErr == nil {
+					t.Error("Expected replaced file not to be restored")
+				}
+			} else {
+				if result.Restored != 1 {
+					t.Errorf("Expected 1 restored, got %d: %+v", result.Restored, result.FailureDetails)
+				}
+				if statErr != nil {
+					t.Errorf("Expected file restored to %s: %v", sourcePath, statErr)
+				}
+			}
+
+			mismatches, err := reader.FilterEvents(result.UndoRunID, EventFilter{EventTypes: []EventType{EventIdentityMismatch}})
+			if err != nil {
+				t.Fatalf("Failed to read events: %v", err)
+			}
+			if len(mismatches) != 1 {
+				t.Errorf("Expected 1 IDENTITY_MISMATCH event, got %d", len(mismatches))
+			}
+		})
+	}
+}
+
+// TestUndoEngine_ForceOverridesLaterRunConflict reproduces a conflict where a later
+// run moved a new file from the same source path, then verifies that --force
+// restores the older run's file once the source slot is free.
+func TestUndoEngine_ForceOverridesLaterRunConflict(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		force bool
+	}{{"without-force", false}, {"force", true}} {
+		force := tc.force
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			logDir := filepath.Join(tempDir, "logs")
+			sourceDir := filepath.Join(tempDir, "source")
+			destDir1 := filepath.Join(tempDir, "dest1")
+			destDir2 := filepath.Join(tempDir, "dest2")
+			for _, dir := range []string{logDir, sourceDir, destDir1, destDir2} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+			}
+
+			config := AuditConfig{LogDirectory: logDir}
+			identityResolver := NewIdentityResolver()
+			sourcePath := filepath.Join(sourceDir, "test.txt")
+			dest1Path := filepath.Join(destDir1, "test.txt")
+			dest2Path := filepath.Join(destDir2, "test.txt")
+
+			// Run 1 moved the original file from source to dest1
+			writer, err := NewAuditWriter(config)
+			if err != nil {
+				t.Fatalf("Failed to create writer: %v", err)
+			}
+			run1ID, err := writer.StartRun("1.0.0", "test-machine")
+			if err != nil {
+				t.Fatalf("Failed to start run 1: %v", err)
+			}
+			if err := os.WriteFile(dest1Path, []byte("first file"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			identity1, err := identityResolver.CaptureIdentity(dest1Path)
+			if err != nil {
+				t.Fatalf("Failed to capture identity: %v", err)
+			}
+			writer.RecordMove(sourcePath, dest1Path, identity1)
+			writer.EndRun(run1ID, RunStatusCompleted, RunSummary{Moved: 1})
+			writer.Close()
+
+			// Run timestamps have second precision; cross a boundary so run 2 is
+			// unambiguously later than run 1
+			time.Sleep(1100 * time.Millisecond)
+
+			// Run 2 moved a second file that arrived at the same source path to dest2
+			writer, err = NewAuditWriter(config)
+			if err != nil {
+				t.Fatalf("Failed to reopen writer: %v", err)
+			}
+			run2ID, err := writer.StartRun("1.0.0", "test-machine")
+			if err != nil {
+				t.Fatalf("Failed to start run 2: %v", err)
+			}
+			if err := os.WriteFile(dest2Path, []byte("second file"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			identity2, err := identityResolver.CaptureIdentity(dest2Path)
+			if err != nil {
+				t.Fatalf("Failed to capture identity: %v", err)
+			}
+			writer.RecordMove(sourcePath, dest2Path, identity2)
+			writer.EndRun(run2ID, RunStatusCompleted, RunSummary{Moved: 1})
+			writer.Close()
+
+			reader := NewAuditReader(logDir)
+			undoWriter, err := NewAuditWriter(config)
+			if err != nil {
+				t.Fatalf("Failed to create undo writer: %v", err)
+			}
+			defer undoWriter.Close()
+
+			engine := NewUndoEngine(reader, undoWriter, "1.0.0", "test-machine")
+			result, err := engine.UndoRunCrossMachine(run1ID, CrossMachineUndoConfig{Force: force})
+			if err != nil {
+				t.Fatalf("Undo failed: %v", err)
+			}
+
+			undoEvents, err := reader.GetRun(result.UndoRunID)
+			if err != nil {
+				t.Fatalf("Failed to get undo events: %v", err)
+			}
+			var conflicts []AuditEvent
+			for _, event := range undoEvents {
+				if event.EventType == EventConflictDetected {
+					conflicts = append(conflicts, event)
+				}
+			}
+			if len(conflicts) != 1 || conflicts[0].Metadata["conflictingRunId"] != string(run2ID) {
+				t.Fatalf("Expected 1 CONFLICT_DETECTED event for run 2, got %+v", conflicts)
+			}
+
+			if !force {
+				if result.Failed != 1 || result.Restored != 0 {
+					t.Errorf("Expected conflict to block undo, got restored=%d failed=%d", result.Restored, result.Failed)
+				}
+				if _, err := os.Stat(sourcePath); !os.IsNotExist(err) {
+					t.Error("File should not be restored without --force")
+				}
+				return
+			}
+
+			if result.Restored != 1 || result.Failed != 0 {
+				t.Errorf("Expected forced restore, got restored=%d failed=%d: %+v", result.Restored, result.Failed, result.FailureDetails)
+			}
+			if conflicts[0].Metadata["forced"] != "true" {
+				t.Errorf("Expected forced override in metadata, got %v", conflicts[0].Metadata)
+			}
+			data, err := os.ReadFile(sourcePath)
+			if err != nil || string(data) != "first file" {
+				t.Errorf("Expected run 1's file restored to source, got %q (%v)", data, err)
+			}
+			if _, err := os.Stat(dest2Path); err != nil {
+				t.Errorf("Run 2's file should be untouched: %v", err)
+			}
+		})
+	}
+}
+
+// TestUndoEngine_MissingReport checks that a file undo cannot find is appended
+// to the missing-file report with its expected path and recorded hash.
+func TestUndoEngine_MissingReport(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{logDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	sourcePath := filepath.Join(tempDir, "source", "test.txt")
+	destPath := filepath.Join(destDir, "test.txt")
+	if err := os.WriteFile(destPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	writer.RecordMove(sourcePath, destPath, identity)
+	writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1})
+
+	// The file disappears before undo
+	if err := os.Remove(destPath); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	reportPath := filepath.Join(tempDir, "missing.json")
+	engine := NewUndoEngine(NewAuditReader(logDir), writer, "1.0.0", "test-machine")
+	result, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{MissingReport: reportPath})
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Failed != 1 {
+		t.Fatalf("Expected 1 failure, got %+v", result)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report MissingReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if len(report.Entries) != 1 {
+		t.Fatalf("Expected 1 report entry, got %+v", report.Entries)
+	}
+	entry := report.Entries[0]
+	if entry.ExpectedPath != destPath || entry.OriginalPath != sourcePath {
+		t.Errorf("Expected %s -> %s, got %s -> %s", destPath, sourcePath, entry.ExpectedPath, entry.OriginalPath)
+	}
+	if entry.ContentHash != identity.ContentHash || entry.TargetRunID != runID || entry.UndoRunID != result.UndoRunID {
+		t.Errorf("Unexpected report entry: %+v", entry)
+	}
+
+	// A second undo appends to the existing report
+	if _, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{MissingReport: reportPath}); err != nil {
+		t.Fatalf("Second undo failed: %v", err)
+	}
+	data, _ = os.ReadFile(reportPath)
+	report = MissingReport{}
+	if err := json.Unmarshal(data, &report); err != nil || len(report.Entries) != 2 {
+		t.Errorf("Expected 2 report entries after second undo, got %d (%v)", len(report.Entries), err)
+	}
+}
+
+// TestWriteUndoReport checks that the undo report holds the counts and the
+// per-failure reasons of the UndoResult it was written from.
+func TestWriteUndoReport(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{logDir, sourceDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: logDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	resolver := NewIdentityResolver()
+	for _, name := range []string{"restored.txt", "missing.txt", "occupied.txt"} {
+		dest := filepath.Join(destDir, name)
+		if err := os.WriteFile(dest, []byte("content-"+name), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		identity, _ := resolver.CaptureIdentity(dest)
+		writer.RecordMove(filepath.Join(sourceDir, name), dest, identity)
+	}
+	writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 3})
+
+	// One file disappears and another's original location is taken
+	os.Remove(filepath.Join(destDir, "missing.txt"))
+	os.WriteFile(filepath.Join(sourceDir, "occupied.txt"), []byte("new"), 0644)
+
+	engine := NewUndoEngine(NewAuditReader(logDir), writer, "1.0.0", "test-machine")
+	result, err := engine.UndoRunCrossMachine(runID, CrossMachineUndoConfig{})
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != 1 || result.Failed != 2 {
+		t.Fatalf("Expected 1 restored and 2 failed, got %+v", result)
+	}
+
+	reportPath := filepath.Join(tempDir, "undo.json")
+	if err := WriteUndoReport(reportPath, result); err != nil {
+		t.Fatalf("WriteUndoReport failed: %v", err)
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	for key, want := range map[string]int{"restored": result.Restored, "skipped": result.Skipped, "failed": result.Failed} {
+		if got, ok := fields[key].(float64); !ok || int(got) != want {
+			t.Errorf("Expected %q to be %d, got %v", key, want, fields[key])
+		}
+	}
+
+	var report UndoResult
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report does not decode as an UndoResult: %v", err)
+	}
+	if report.UndoRunID != result.UndoRunID || report.TargetRunID != runID {
+		t.Errorf("Expected run IDs %s and %s, got %s and %s", result.UndoRunID, runID, report.UndoRunID, report.TargetRunID)
+	}
+	if len(report.FailureDetails) != len(result.FailureDetails) {
+		t.Fatalf("Expected %d failure details, got %d", len(result.FailureDetails), len(report.FailureDetails))
+	}
+	for i, failure := range result.FailureDetails {
+		if report.FailureDetails[i] != failure {
+			t.Errorf("Failure %d: expected %+v, got %+v", i, failure, report.FailureDetails[i])
+		}
+	}
+}
+
+// TestUndoEngine_InboundRestoreRequiresForce verifies that undo refuses to
+// restore files into a configured inbound directory the run did not scan,
+// unless forced, and that the run's own inbound directories are allowed.
+func TestUndoEngine_InboundRestoreRequiresForce(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	scannedDir := filepath.Join(tempDir, "scanned")
+	otherDir := filepath.Join(tempDir, "other")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{logDir, scannedDir, otherDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRunWithMetadata("1.0.0", "test-machine", map[string]string{"inboundDirectories": scannedDir})
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	sources := []string{filepath.Join(scannedDir, "a.txt"), filepath.Join(otherDir, "b.txt")}
+	for _, source := range sources {
+		dest := filepath.Join(destDir, filepath.Base(source))
+		if err := os.WriteFile(dest, []byte(source), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		identity, err := NewIdentityResolver().CaptureIdentity(dest)
+		if err != nil {
+			t.Fatalf("Failed to capture identity: %v", err)
+		}
+		writer.RecordMove(source, dest, identity)
+	}
+	writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 2})
+	writer.Close()
+
+	reader := NewAuditReader(logDir)
+	undoWriter, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create undo writer: %v", err)
+	}
+	defer undoWriter.Close()
+	engine := NewUndoEngine(reader, undoWriter, "1.0.0", "test-machine")
+
+	undoConfig := CrossMachineUndoConfig{InboundDirectories: []string{scannedDir, otherDir}}
+	_, err = engine.UndoRunCrossMachine(runID, undoConfig)
+	var inboundErr *InboundRestoreError
+	if !errors.As(err, &inboundErr) {
+		t.Fatalf("Expected InboundRestoreError, got %v", err)
+	}
+	if len(inboundErr.Paths) != 1 || inboundErr.Paths[0] != sources[1] {
+		t.Errorf("Expected only %s to be reported, got %v", sources[1], inboundErr.Paths)
+	}
+	for _, source := range sources {
+		if _, err := os.Stat(source); !os.IsNotExist(err) {
+			t.Errorf("Expected nothing to be restored without force, found %s", source)
+		}
+	}
+
+	undoConfig.Force = true
+	result, err := engine.UndoRunCrossMachine(runID, undoConfig)
+	if err != nil {
+		t.Fatalf("Forced undo failed: %v", err)
+	}
+	if result.Restored != 2 {
+		t.Errorf("Expected 2 files restored with force, got %d: %+v", result.Restored, result.FailureDetails)
+	}
+
+	// A run that did not record its inbound directories is not checked
+	writer, err = NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err = writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	unrecorded := filepath.Join(otherDir, "c.txt")
+	dest := filepath.Join(destDir, "c.txt")
+	os.WriteFile(dest, []byte("c"), 0644)
+	identity, err := NewIdentityResolver().CaptureIdentity(dest)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+	writer.RecordMove(unrecorded, dest, identity)
+	writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: 1})
+	writer.Close()
+
+	undoConfig.Force = false
+	result, err = engine.UndoRunCrossMachine(runID, undoConfig)
+	if err != nil {
+		t.Fatalf("Undo of a run without inbound directories failed: %v", err)
+	}
+	if result.Restored != 1 {
+		t.Errorf("Expected 1 file restored, got %d: %+v", result.Restored, result.FailureDetails)
+	}
+}
+
+// TestUndoProgressReportsBytes verifies that undo progress events carry the
+// bytes restored so far, increasing with every restored file up to the total
+// size of the run's files.
+func TestUndoProgressReportsBytes(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{logDir, sourceDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+
+	sizes := []int{100, 2000, 30}
+	for i, size := range sizes {
+		name := fmt.Sprintf("file%d.txt", i)
+		destPath := filepath.Join(destDir, name)
+		if err := os.WriteFile(destPath, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+		if err != nil {
+			t.Fatalf("Failed to capture identity: %v", err)
+		}
+		if err := writer.RecordMove(filepath.Join(sourceDir, name), destPath, identity); err != nil {
+			t.Fatalf("Failed to record move: %v", err)
+		}
+	}
+	if err := writer.RecordSkip(filepath.Join(sourceDir, "skipped.txt"), ReasonNoMatch); err != nil {
+		t.Fatalf("Failed to record skip: %v", err)
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{Moved: len(sizes)}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+	writer.Close()
+
+	writer2, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create second writer: %v", err)
+	}
+	defer writer2.Close()
+
+	var restores []UndoProgressEvent
+	engine := NewUndoEngine(NewAuditReader(logDir), writer2, "1.0.0", "test-machine")
+	engine.SetCallback(func(event UndoProgressEvent) {
+		if event.BytesTotal != 2130 {
+			t.Errorf("Expected a byte total of 2130 on every event, got %d (%s)", event.BytesTotal, event.Type)
+		}
+		if event.Type == "restore" {
+			restores = append(restores, event)
+		}
+	})
+	if _, err := engine.UndoLatest(nil); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if len(restores) != len(sizes) {
+		t.Fatalf("Expected %d restore events, got %d", len(sizes), len(restores))
+	}
+	var previous int64
+	for _, event := range restores {
+		if event.BytesDone <= previous {
+			t.Errorf("Expected increasing bytes restored, got %d after %d", event.BytesDone, previous)
+		}
+		previous = event.BytesDone
+	}
+	if previous != 2130 {
+		t.Errorf("Expected 2130 bytes restored in the end, got %d", previous)
+	}
+}
+
+// TestRecordNote verifies that a note added to a finished run is read back
+// with the run's events, after them, without changing the run's summary or
+// what undo restores.
+func TestRecordNote(t *testing.T) {
+	tempDir := t.TempDir()
+	logDir := filepath.Join(tempDir, "logs")
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+	for _, dir := range []string{sourceDir, destDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	config := AuditConfig{LogDirectory: logDir}
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	destPath := filepath.Join(destDir, "file.txt")
+	if err := os.WriteFile(destPath, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	identity, err := NewIdentityResolver().CaptureIdentity(destPath)
+	if err != nil {
+		t.Fatalf("Failed to capture identity: %v", err)
+	}
+	if err := writer.RecordMove(filepath.Join(sourceDir, "file.txt"), destPath, identity); err != nil {
+		t.Fatalf("Failed to record move: %v", err)
+	}
+	if err := writer.EndRun(runID, RunStatusCompleted, RunSummary{TotalFiles: 1, Moved: 1}); err != nil {
+		t.Fatalf("Failed to end run: %v", err)
+	}
+	writer.Close()
+
+	// Add the note later, as "sorta audit note" does
+	writer, err = NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+	if err := writer.RecordNote(runID, "Tidied up before the tax return"); err != nil {
+		t.Fatalf("RecordNote failed: %v", err)
+	}
+
+	reader := NewAuditReader(logDir)
+	events, err := reader.GetRun(runID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	last := events[len(events)-1]
+	if len(events) != 4 || last.EventType != EventNote || last.Metadata["note"] != "Tidied up before the tax return" {
+		t.Fatalf("Expected the run's events followed by the note, got %+v", events)
+	}
+	if events[len(events)-2].EventType != EventRunEnd {
+		t.Errorf("Expected the events before the note to be unchanged, got %+v", events)
+	}
+
+	info, err := reader.GetRunByID(runID)
+	if err != nil {
+		t.Fatalf("GetRunByID failed: %v", err)
+	}
+	if info.Status != RunStatusCompleted || info.Summary.TotalFiles != 1 || info.Summary.Moved != 1 {
+		t.Errorf("Expected the note not to change the run's status or summary, got %+v", info)
+	}
+
+	result, err := NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoRun(runID, nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != 1 || result.Skipped != 0 || result.Failed != 0 {
+		t.Errorf("Expected one file restored and the note ignored, got %+v", result)
+	}
+}
@@ -0,0 +1,324 @@
+// Package audit provides audit trail functionality for Sorta file operations.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// VerifyIssue describes a problem found while verifying audit logs.
+type VerifyIssue struct {
+	FilePath string // Log file containing the problem
+	Line     int    // 1-based line number (0 for run-level issues)
+	Offset   int64  // Byte offset of the start of the line
+	RunID    RunID  // Run the issue belongs to (empty if the line is unreadable)
+	Message  string // Description of the problem
+}
+
+// VerifyResult contains the outcome of verifying audit logs.
+type VerifyResult struct {
+	FilesChecked  int
+	EventsChecked int
+	RunsChecked   int
+	Issues        []VerifyIssue
+}
+
+// OK returns true if no issues were found.
+func (v *VerifyResult) OK() bool {
+	return len(v.Issues) == 0
+}
+
+// RepairResult describes a log file truncated by RepairLog.
+type RepairResult struct {
+	FilePath        string  // Log file that was repaired
+	Offset          int64   // Byte offset the file was truncated at
+	LinesRemoved    int     // Number of lines discarded from the corrupt line onwards
+	InterruptedRuns []RunID // Runs given a RUN_END with status INTERRUPTED
+}
+
+// logLine is a single line read from a log file along with its position.
+type logLine struct {
+	number int
+	offset int64
+	event  *AuditEvent
+	err    error
+}
+
+// Verify checks every audit log file line by line: each line must be complete,
+// valid JSON with a timestamp, eventType, status and (outside system events) a
+// runId, the events of a run must not go back in time, and every run must
+// have a RUN_START and a RUN_END event. If runID is non-empty, run-level
+// checks are limited to that run; unreadable lines are always reported since
+// they cannot be attributed to a run.
+func (r *AuditReader) Verify(runID RunID) (*VerifyResult, error) {
+	logFiles, err := GetAllLogFiles(r.logDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log files: %w", err)
+	}
+
+	result := &VerifyResult{FilesChecked: len(logFiles)}
+	type runState struct {
+		filePath string
+		started  bool
+		ended    bool
+		last     time.Time // Timestamp of the run's latest event so far
+	}
+	runs := make(map[RunID]*runState)
+	var order []RunID
+
+	for _, logFile := range logFiles {
+		lines, err := readLogLines(logFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", logFile, err)
+		}
+
+		for _, line := range lines {
+			if line.err != nil {
+				result.Issues = append(result.Issues, VerifyIssue{
+					FilePath: logFile,
+					Line:     line.number,
+					Offset:   line.offset,
+					Message:  line.err.Error(),
+				})
+				continue
+			}
+			event := line.event
+			if event.RunID == "" || (runID != "" && event.RunID != runID) {
+				continue
+			}
+
+			result.EventsChecked++
+			state, ok := runs[event.RunID]
+			if !ok {
+				state = &runState{filePath: logFile}
+				runs[event.RunID] = state
+				order = append(order, event.RunID)
+			}
+			if event.Timestamp.Before(state.last) {
+				result.Issues = append(result.Issues, VerifyIssue{
+					FilePath: logFile,
+					Line:     line.number,
+					Offset:   line.offset,
+					RunID:    event.RunID,
+					Message: fmt.Sprintf("event at line %d is older than the run's previous event (%s before %s)",
+						line.number, event.Timestamp.Format(time.RFC3339), state.last.Format(time.RFC3339)),
+				})
+			} else {
+				state.last = event.Timestamp
+			}
+			switch event.EventType {
+			case EventRunStart:
+				state.started = true
+			case EventRunEnd:
+				state.ended = true
+			}
+		}
+	}
+
+	if runID != "" && len(runs) == 0 && result.OK() {
+		return nil, fmt.Errorf("run not found: %s", runID)
+	}
+
+	result.RunsChecked = len(order)
+	for _, id := range order {
+		state := runs[id]
+		if !state.started {
+			result.Issues = append(result.Issues, VerifyIssue{
+				FilePath: state.filePath,
+				RunID:    id,
+				Message:  "run has no RUN_START event",
+			})
+		}
+		if !state.ended {
+			result.Issues = append(result.Issues, VerifyIssue{
+				FilePath: state.filePath,
+				RunID:    id,
+				Message:  "run has no RUN_END event",
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// RepairLog truncates filePath at its first corrupt line and appends a RUN_END
+// event with status INTERRUPTED for every run in the file left without one,
+// including runs that had none before. It returns nil if the file has no
+// corrupt lines and no run without a RUN_END. A run that is still in progress
+// has no RUN_END yet either, so logs should not be repaired during a run.
+func RepairLog(filePath string) (*RepairResult, error) {
+	if IsCompressedLog(filePath) {
+		return nil, fmt.Errorf("cannot repair compressed log %s", filePath)
+	}
+
+	lines, err := readLogLines(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	corrupt := len(lines)
+	for i, line := range lines {
+		if line.err != nil {
+			corrupt = i
+			break
+		}
+	}
+
+	result := &RepairResult{
+		FilePath:     filePath,
+		LinesRemoved: len(lines) - corrupt,
+	}
+	if corrupt < len(lines) {
+		result.Offset = lines[corrupt].offset
+	} else {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", filePath, err)
+		}
+		result.Offset = info.Size()
+	}
+
+	// Collect the surviving events of runs that no longer have a RUN_END
+	runEvents := make(map[RunID][]AuditEvent)
+	var order []RunID
+	for _, line := range lines[:corrupt] {
+		id := line.event.RunID
+		if id == "" {
+			continue
+		}
+		if _, ok := runEvents[id]; !ok {
+			order = append(order, id)
+		}
+		runEvents[id] = append(runEvents[id], *line.event)
+	}
+
+	var buf bytes.Buffer
+	reader := &AuditReader{}
+	for _, id := range order {
+		info := reader.buildRunInfo(id, runEvents[id])
+		if info.EndTime != nil {
+			continue
+		}
+		summary := info.Summary
+		event := AuditEvent{
+			Timestamp: time.Now().UTC(),
+			RunID:     id,
+			EventType: EventRunEnd,
+			Status:    runStatusToOperationStatus(RunStatusInterrupted),
+			Metadata: map[string]string{
+				"status":       string(RunStatusInterrupted),
+				"totalFiles":   fmt.Sprintf("%d", summary.TotalFiles),
+				"moved":        fmt.Sprintf("%d", summary.Moved),
+				"skipped":      fmt.Sprintf("%d", summary.Skipped),
+				"routedReview": fmt.Sprintf("%d", summary.RoutedReview),
+				"duplicates":   fmt.Sprintf("%d", summary.Duplicates),
+				"errors":       fmt.Sprintf("%d", summary.Errors),
+				"repaired":     "true",
+			},
+		}
+		data, err := event.MarshalJSONLine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal RUN_END event: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+		result.InterruptedRuns = append(result.InterruptedRuns, id)
+	}
+	if result.LinesRemoved == 0 && len(result.InterruptedRuns) == 0 {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	if err := file.Truncate(result.Offset); err != nil {
+		return nil, fmt.Errorf("failed to truncate %s: %w", filePath, err)
+	}
+	if _, err := file.WriteAt(buf.Bytes(), result.Offset); err != nil {
+		return nil, fmt.Errorf("failed to write to %s: %w", filePath, err)
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync %s: %w", filePath, err)
+	}
+
+	return result, nil
+}
+
+// readLogLines reads filePath line by line, recording each line's position and
+// parsed event, or the reason it could not be parsed. Empty lines are skipped.
+func readLogLines(filePath string) ([]logLine, error) {
+	file, err := openLogFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []logLine
+	reader := bufio.NewReader(file)
+	var offset int64
+	for number := 1; ; number++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if len(data) == 0 {
+			break
+		}
+
+		line := logLine{number: number, offset: offset}
+		offset += int64(len(data))
+
+		trimmed := bytes.TrimRight(data, "\r\n")
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		if data[len(data)-1] != '\n' {
+			line.err = fmt.Errorf("truncated line %d: missing trailing newline", number)
+		} else {
+			line.event, line.err = parseLogLine(trimmed, number)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// parseLogLine parses a log line into an event and checks its required fields.
+func parseLogLine(data []byte, number int) (*AuditEvent, error) {
+	event, err := UnmarshalJSONLine(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event at line %d: %v", number, err)
+	}
+
+	switch {
+	case event.Timestamp.IsZero():
+		return nil, fmt.Errorf("invalid event at line %d: missing timestamp", number)
+	case event.EventType == "":
+		return nil, fmt.Errorf("invalid event at line %d: missing eventType", number)
+	case event.Status == "":
+		return nil, fmt.Errorf("invalid event at line %d: missing status", number)
+	case event.RunID == "" && !isSystemEvent(event.EventType):
+		return nil, fmt.Errorf("invalid event at line %d: missing runId", number)
+	}
+
+	return event, nil
+}
+
+// isSystemEvent reports whether eventType is a log-level event written outside
+// any run.
+func isSystemEvent(eventType EventType) bool {
+	switch eventType {
+	case EventRotation, EventRetentionPrune, EventLogInitialized:
+		return true
+	}
+	return false
+}
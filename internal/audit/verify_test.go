@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestVerifyAndRepairCorruptLine writes a complete run followed by a run whose
+// log is corrupted partway through, then checks that Verify reports the bad
+// line with its offset and that RepairLog truncates there and marks the second
+// run as interrupted.
+func TestVerifyAndRepairCorruptLine(t *testing.T) {
+	logDir := t.TempDir()
+	config := AuditConfig{LogDirectory: logDir}
+
+	writer, err := NewAuditWriter(config)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	run1ID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run 1: %v", err)
+	}
+	writer.RecordMove("/src/a.pdf", "/dst/a.pdf", nil)
+	writer.EndRun(run1ID, RunStatusCompleted, RunSummary{TotalFiles: 1, Moved: 1})
+	run2ID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run 2: %v", err)
+	}
+	writer.RecordMove("/src/b.pdf", "/dst/b.pdf", nil)
+	writer.Close()
+
+	logPath := filepath.Join(logDir, "sorta-audit.jsonl")
+	original, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+
+	reader := NewAuditReader(logDir)
+	result, err := reader.Verify("")
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].RunID != run2ID {
+		t.Fatalf("Expected only run 2's missing RUN_END before corruption, got %+v", result.Issues)
+	}
+
+	// Corrupt the log: a garbage line after run 2's move, then another run 2 event
+	corruptOffset := int64(len(original))
+	corrupted := string(original) + "{\"timestamp\":\"2024-01-15T10:00:00Z\",\"runId\n" +
+		strings.SplitAfter(string(original), "\n")[5]
+	if err := os.WriteFile(logPath, []byte(corrupted), 0644); err != nil {
+		t.Fatalf("Failed to corrupt log: %v", err)
+	}
+
+	result, err = reader.Verify("")
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	var lineIssues []VerifyIssue
+	for _, issue := range result.Issues {
+		if issue.Line > 0 {
+			lineIssues = append(lineIssues, issue)
+		}
+	}
+	if len(lineIssues) != 1 {
+		t.Fatalf("Expected 1 corrupt line, got %+v", result.Issues)
+	}
+	if lineIssues[0].Line != 7 || lineIssues[0].Offset != corruptOffset || lineIssues[0].FilePath != logPath {
+		t.Errorf("Expected corrupt line 7 at offset %d, got line %d at offset %d",
+			corruptOffset, lineIssues[0].Line, lineIssues[0].Offset)
+	}
+
+	// Scoping to run 1 still reports the unattributable corrupt line
+	result, err = reader.Verify(run1ID)
+	if err != nil {
+		t.Fatalf("Verify(run1) failed: %v", err)
+	}
+	if result.RunsChecked != 1 || len(result.Issues) != 1 || result.Issues[0].Line != 7 {
+		t.Errorf("Expected run 1 to be intact apart from the corrupt line, got %+v", result)
+	}
+
+	repaired, err := RepairLog(logPath)
+	if err != nil {
+		t.Fatalf("RepairLog failed: %v", err)
+	}
+	if repaired == nil || repaired.Offset != corruptOffset || repaired.LinesRemoved != 2 {
+		t.Fatalf("Expected truncation at %d removing 2 lines, got %+v", corruptOffset, repaired)
+	}
+	if len(repaired.InterruptedRuns) != 1 || repaired.InterruptedRuns[0] != run2ID {
+		t.Errorf("Expected run 2 marked interrupted, got %v", repaired.InterruptedRuns)
+	}
+
+	result, err = reader.Verify("")
+	if err != nil {
+		t.Fatalf("Verify after repair failed: %v", err)
+	}
+	if !result.OK() {
+		t.Errorf("Expected no issues after repair, got %+v", result.Issues)
+	}
+
+	run2, err := reader.GetRunByID(run2ID)
+	if err != nil {
+		t.Fatalf("Failed to get run 2: %v", err)
+	}
+	if run2.Status != RunStatusInterrupted || run2.Summary.Moved != 1 {
+		t.Errorf("Expected run 2 INTERRUPTED with 1 move, got %s with %+v", run2.Status, run2.Summary)
+	}
+
+	// A clean file is left alone
+	if again, err := RepairLog(logPath); err != nil || again != nil {
+		t.Errorf("Expected no repair of a clean log, got %+v (%v)", again, err)
+	}
+}
+
+// TestVerifyAndRepairRunWithoutEnd checks that Verify reports events of a run
+// that go back in time, and that RepairLog marks a run without a RUN_END as
+// interrupted in a log that has no corrupt lines.
+func TestVerifyAndRepairRunWithoutEnd(t *testing.T) {
+	logDir := t.TempDir()
+	writer, err := NewAuditWriter(AuditConfig{LogDirectory: logDir})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	runID, err := writer.StartRun("1.0.0", "test-machine")
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	writer.RecordMove("/src/a.pdf", "/dst/a.pdf", nil)
+	writer.WriteEvent(AuditEvent{
+		Timestamp: time.Now().UTC().Add(-time.Hour),
+		RunID:     runID,
+		EventType: EventSkip,
+		Status:    StatusSkipped,
+	})
+	writer.Close()
+
+	logPath := filepath.Join(logDir, "sorta-audit.jsonl")
+	result, err := NewAuditReader(logDir).Verify(runID)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	var outOfOrder, missingEnd bool
+	for _, issue := range result.Issues {
+		switch {
+		case issue.Line > 0 && strings.Contains(issue.Message, "older than"):
+			outOfOrder = true
+		case issue.Line == 0 && strings.Contains(issue.Message, "RUN_END"):
+			missingEnd = true
+		}
+	}
+	if !outOfOrder || !missingEnd {
+		t.Fatalf("Expected an out-of-order event and a missing RUN_END, got %+v", result.Issues)
+	}
+
+	repaired, err := RepairLog(logPath)
+	if err != nil {
+		t.Fatalf("RepairLog failed: %v", err)
+	}
+	if repaired == nil || repaired.LinesRemoved != 0 || len(repaired.InterruptedRuns) != 1 || repaired.InterruptedRuns[0] != runID {
+		t.Fatalf("Expected run marked interrupted without removing lines, got %+v", repaired)
+	}
+	run, err := NewAuditReader(logDir).GetRunByID(runID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if run.Status != RunStatusInterrupted {
+		t.Errorf("Expected run INTERRUPTED, got %s", run.Status)
+	}
+}
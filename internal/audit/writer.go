@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,6 +23,16 @@ type AuditWriter struct {
 	currentRun      *RunID
 	config          AuditConfig
 	rotationManager *RotationManager
+	runLog          *runLog // Per-run log file when PartitionByDate is enabled
+	pathRoot        string  // Absolute PathRoot of the current run, if it records relative paths
+}
+
+// runLog is the log file holding a single run's events in the date-partitioned layout.
+type runLog struct {
+	runID  RunID
+	path   string
+	file   *os.File
+	writer *bufio.Writer
 }
 
 // NewAuditWriter creates a new AuditWriter with the given configuration.
@@ -93,6 +105,12 @@ func GenerateRunID() (RunID, error) {
 // It generates a unique Run ID and records the run start timestamp.
 // Requirements: 1.1, 1.4, 11.4
 func (w *AuditWriter) StartRun(appVersion string, machineID string) (RunID, error) {
+	return w.StartRunWithMetadata(appVersion, machineID, nil)
+}
+
+// StartRunWithMetadata initializes a new run like StartRun, adding metadata to
+// the RUN_START event (e.g. "stage" for a staged run).
+func (w *AuditWriter) StartRunWithMetadata(appVersion string, machineID string, metadata map[string]string) (RunID, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -113,13 +131,32 @@ func (w *AuditWriter) StartRun(appVersion string, machineID string) (RunID, erro
 			"machineId":  machineID,
 		},
 	}
+	for key, value := range metadata {
+		event.Metadata[key] = value
+	}
+	if err := w.addSequenceLocked(&event); err != nil {
+		return "", err
+	}
+	pathRoot := ""
+	if w.config.PathRoot != "" {
+		if pathRoot, err = filepath.Abs(w.config.PathRoot); err != nil {
+			return "", fmt.Errorf("invalid path root: %w", err)
+		}
+		event.Metadata["pathRoot"] = pathRoot
+	}
+
+	if err := w.openRunLogLocked(runID, event.Timestamp); err != nil {
+		return "", err
+	}
 
 	// Write the event (fail-fast on error)
 	if err := w.writeEventLocked(event); err != nil {
+		w.closeRunLogLocked()
 		return "", fmt.Errorf("failed to write RUN_START event: %w", err)
 	}
 
 	w.currentRun = &runID
+	w.pathRoot = pathRoot
 	return runID, nil
 }
 
@@ -149,16 +186,36 @@ func (w *AuditWriter) StartUndoRun(appVersion string, machineID string, targetRu
 			"undoTargetId": string(targetRunID),
 		},
 	}
+	if err := w.addSequenceLocked(&event); err != nil {
+		return "", err
+	}
+
+	if err := w.openRunLogLocked(runID, event.Timestamp); err != nil {
+		return "", err
+	}
 
 	// Write the event (fail-fast on error)
 	if err := w.writeEventLocked(event); err != nil {
+		w.closeRunLogLocked()
 		return "", fmt.Errorf("failed to write RUN_START event: %w", err)
 	}
 
 	w.currentRun = &runID
+	w.pathRoot = ""
 	return runID, nil
 }
 
+// addSequenceLocked records the next run sequence number in the metadata of
+// a RUN_START event.
+func (w *AuditWriter) addSequenceLocked(event *AuditEvent) error {
+	sequence, err := nextRunSequence(w.config.LogDirectory)
+	if err != nil {
+		return err
+	}
+	event.Metadata["sequence"] = strconv.FormatUint(sequence, 10)
+	return nil
+}
+
 // WriteEvent writes a single audit event to the log.
 // It fails fast if the write cannot be completed.
 // Requirements: 8.1, 8.4, 11.1, 11.4
@@ -166,9 +223,42 @@ func (w *AuditWriter) WriteEvent(event AuditEvent) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.pathRoot != "" && w.currentRun != nil && event.RunID == *w.currentRun {
+		event = relativeEvent(event, w.pathRoot)
+	}
 	return w.writeEventLocked(event)
 }
 
+// relativeEvent returns event with its paths relative to root, using forward
+// slashes so they also resolve on other systems. Paths outside root are left
+// as they are.
+func relativeEvent(event AuditEvent, root string) AuditEvent {
+	event.SourcePath = relativePath(event.SourcePath, root)
+	event.DestinationPath = relativePath(event.DestinationPath, root)
+	if dest, ok := event.Metadata["intendedDestination"]; ok {
+		metadata := make(map[string]string, len(event.Metadata))
+		for key, value := range event.Metadata {
+			metadata[key] = value
+		}
+		metadata["intendedDestination"] = relativePath(dest, root)
+		event.Metadata = metadata
+	}
+	return event
+}
+
+// relativePath returns path relative to root, or path itself if it is not
+// an absolute path below root.
+func relativePath(path, root string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
 // writeEventLocked writes an event while holding the lock.
 // It marshals the event to JSON, appends a newline, and flushes to disk.
 // It also checks for rotation needs after writing.
@@ -179,34 +269,89 @@ func (w *AuditWriter) writeEventLocked(event AuditEvent) error {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	// Events of a partitioned run go to the run's own file, which is never rotated
+	if w.runLog != nil && event.RunID == w.runLog.runID {
+		return appendLine(w.runLog.writer, w.runLog.file, data)
+	}
+
+	if err := appendLine(w.writer, w.file, data); err != nil {
+		return err
+	}
+
+	// Check if rotation is needed after writing (not for ROTATION events to avoid infinite loop)
+	if event.EventType != EventRotation {
+		if err := w.checkAndRotate(); err != nil {
+			return fmt.Errorf("failed to check/perform rotation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// appendLine writes data followed by a newline, then flushes and syncs the file.
+func appendLine(bw *bufio.Writer, file *os.File, data []byte) error {
 	// Write JSON line with newline
-	if _, err := w.writer.Write(data); err != nil {
+	if _, err := bw.Write(data); err != nil {
 		return fmt.Errorf("failed to write event: %w", err)
 	}
-	if _, err := w.writer.WriteString("\n"); err != nil {
+	if _, err := bw.WriteString("\n"); err != nil {
 		return fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	// Flush to ensure durability (Requirements: 8.4)
-	if err := w.writer.Flush(); err != nil {
+	if err := bw.Flush(); err != nil {
 		return fmt.Errorf("failed to flush event: %w", err)
 	}
 
 	// Sync to disk for durability
-	if err := w.file.Sync(); err != nil {
+	if err := file.Sync(); err != nil {
 		return fmt.Errorf("failed to sync event to disk: %w", err)
 	}
+	return nil
+}
 
-	// Check if rotation is needed after writing (not for ROTATION events to avoid infinite loop)
-	if event.EventType != EventRotation {
-		if err := w.checkAndRotate(); err != nil {
-			return fmt.Errorf("failed to check/perform rotation: %w", err)
-		}
+// PartitionedRunLogPath returns the log file for a run started at start in the
+// date-partitioned layout: <logDir>/YYYY/MM/DD/<run-id>/run.jsonl (UTC date).
+func PartitionedRunLogPath(logDir string, runID RunID, start time.Time) string {
+	start = start.UTC()
+	return filepath.Join(logDir, start.Format("2006"), start.Format("01"), start.Format("02"), string(runID), partitionedRunLogName)
+}
+
+// openRunLogLocked opens the run's own log file when PartitionByDate is enabled.
+func (w *AuditWriter) openRunLogLocked(runID RunID, start time.Time) error {
+	if !w.config.PartitionByDate {
+		return nil
 	}
+	w.closeRunLogLocked()
 
+	path := PartitionedRunLogPath(w.config.LogDirectory, runID, start)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create run log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open run log: %w", err)
+	}
+
+	w.runLog = &runLog{runID: runID, path: path, file: file, writer: bufio.NewWriter(file)}
 	return nil
 }
 
+// closeRunLogLocked flushes and closes the current run's log file, if any.
+func (w *AuditWriter) closeRunLogLocked() error {
+	if w.runLog == nil {
+		return nil
+	}
+	rl := w.runLog
+	w.runLog = nil
+
+	if err := rl.writer.Flush(); err != nil {
+		rl.file.Close()
+		return fmt.Errorf("failed to flush run log: %w", err)
+	}
+	return rl.file.Close()
+}
+
 // checkAndRotate checks if rotation is needed and performs it if so.
 // Requirements: 9.1, 9.2, 9.5, 9.6
 func (w *AuditWriter) checkAndRotate() error {
@@ -300,6 +445,20 @@ func (w *AuditWriter) EndRun(runID RunID, status RunStatus, summary RunSummary)
 	}
 
 	w.currentRun = nil
+	w.pathRoot = ""
+	runLogPath := ""
+	if w.runLog != nil && w.runLog.runID == runID {
+		runLogPath = w.runLog.path
+		if err := w.closeRunLogLocked(); err != nil {
+			return fmt.Errorf("failed to close run log: %w", err)
+		}
+	}
+
+	if w.config.CompressClosedRuns {
+		if err := w.compressClosedLogsLocked(runLogPath); err != nil {
+			return fmt.Errorf("failed to compress closed logs: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -322,6 +481,10 @@ func (w *AuditWriter) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if err := w.closeRunLogLocked(); err != nil {
+		return err
+	}
+
 	if err := w.writer.Flush(); err != nil {
 		return fmt.Errorf("failed to flush on close: %w", err)
 	}
@@ -348,6 +511,18 @@ func (w *AuditWriter) LogPath() string {
 // RecordMove records a MOVE event when a file is moved to a classified destination.
 // Requirements: 2.1
 func (w *AuditWriter) RecordMove(source, dest string, identity *FileIdentity) error {
+	return w.RecordMoveWithReason(source, dest, identity, "")
+}
+
+// RecordMoveWithReason records a MOVE event annotated with a reason code, such as
+// ReasonMatchedNoDate for files routed to the undated folder.
+func (w *AuditWriter) RecordMoveWithReason(source, dest string, identity *FileIdentity, reason ReasonCode) error {
+	return w.RecordMoveWithMetadata(source, dest, identity, reason, nil)
+}
+
+// RecordMoveWithMetadata records a MOVE event with a reason code and extra
+// metadata, such as the rule the file matched. metadata may be nil.
+func (w *AuditWriter) RecordMoveWithMetadata(source, dest string, identity *FileIdentity, reason ReasonCode, metadata map[string]string) error {
 	if w.currentRun == nil {
 		return fmt.Errorf("no active run: call StartRun first")
 	}
@@ -359,6 +534,56 @@ func (w *AuditWriter) RecordMove(source, dest string, identity *FileIdentity) er
 		Status:          StatusSuccess,
 		SourcePath:      source,
 		DestinationPath: dest,
+		ReasonCode:      reason,
+		FileIdentity:    identity,
+		Metadata:        metadata,
+	}
+
+	return w.WriteEvent(event)
+}
+
+// RecordCopy records a COPY event when a file from a read-only source is copied
+// to actualDest instead of being moved. When the copy was given a duplicate
+// name, intendedDest is recorded as "intendedDestination" metadata.
+func (w *AuditWriter) RecordCopy(source, intendedDest, actualDest string, identity *FileIdentity) error {
+	if w.currentRun == nil {
+		return fmt.Errorf("no active run: call StartRun first")
+	}
+
+	event := AuditEvent{
+		Timestamp:       time.Now().UTC(),
+		RunID:           *w.currentRun,
+		EventType:       EventCopy,
+		Status:          StatusSuccess,
+		SourcePath:      source,
+		DestinationPath: actualDest,
+		FileIdentity:    identity,
+	}
+	if intendedDest != actualDest {
+		event.ReasonCode = ReasonDuplicateRenamed
+		event.Metadata = map[string]string{
+			"intendedDestination": intendedDest,
+		}
+	}
+
+	return w.WriteEvent(event)
+}
+
+// RecordExtract records an EXTRACT event when the file inside the archive at
+// archive was extracted to extracted. Undo deletes the extracted file, like a
+// copy.
+func (w *AuditWriter) RecordExtract(archive, extracted string, identity *FileIdentity) error {
+	if w.currentRun == nil {
+		return fmt.Errorf("no active run: call StartRun first")
+	}
+
+	event := AuditEvent{
+		Timestamp:       time.Now().UTC(),
+		RunID:           *w.currentRun,
+		EventType:       EventExtract,
+		Status:          StatusSuccess,
+		SourcePath:      archive,
+		DestinationPath: extracted,
 		FileIdentity:    identity,
 	}
 
@@ -473,6 +698,23 @@ func (w *AuditWriter) RecordError(source, errType, errMsg, operation string) err
 	return w.WriteEvent(event)
 }
 
+// RecordNote appends a NOTE event holding text to the run runID, which need
+// not be the writer's current run. Events already recorded for the run are
+// left as they are, and undo ignores notes.
+func (w *AuditWriter) RecordNote(runID RunID, text string) error {
+	event := AuditEvent{
+		Timestamp: time.Now().UTC(),
+		RunID:     runID,
+		EventType: EventNote,
+		Status:    StatusSuccess,
+		Metadata: map[string]string{
+			"note": text,
+		},
+	}
+
+	return w.WriteEvent(event)
+}
+
 // writeLogInitialized writes a LOG_INITIALIZED event when a new log file is created.
 // This is called internally when NewAuditWriter creates a new log file.
 // Requirements: 12.1
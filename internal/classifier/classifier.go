@@ -2,6 +2,9 @@
 package classifier
 
 import (
+	"path/filepath"
+	"strings"
+
 	"sorta/internal/config"
 	"sorta/internal/dateparser"
 	"sorta/internal/matcher"
@@ -15,70 +18,229 @@ const (
 	NoPrefixMatch    UnclassifiedReason = "NO_PREFIX_MATCH"
 	MissingDelimiter UnclassifiedReason = "MISSING_DELIMITER"
 	InvalidDate      UnclassifiedReason = "INVALID_DATE"
+
+	// ExtensionNotAllowed means the file matched a prefix rule whose
+	// extensions do not include the file's extension
+	ExtensionNotAllowed UnclassifiedReason = "EXTENSION_NOT_ALLOWED_FOR_RULE"
 )
 
 // Classification represents the result of classifying a file.
 // It is either CLASSIFIED (with destination info) or UNCLASSIFIED (with reason).
 type Classification struct {
 	Type               string // "CLASSIFIED" or "UNCLASSIFIED"
+	Prefix             string // Canonical prefix of the matched rule (CLASSIFIED only)
+	Date               string // YYYY-MM-DD date the year was taken from (empty when undated)
 	Year               int
 	NormalisedFilename string
 	OutboundDirectory  string
 	Reason             UnclassifiedReason
+	UndatedFolder      string // Set instead of Year when a prefix matched but no date was found
+	Bucket             string // Initial-letter folder below the destination ("A"-"Z" or "#"), set for rules with BucketByInitial
+}
+
+// Options configures classification behavior.
+type Options struct {
+	// NormalizeSpaces tolerates runs of whitespace between prefix, date and description
+	// and collapses them to single spaces in the normalised filename.
+	NormalizeSpaces bool
+
+	// StripDiacritics transliterates accented characters in the normalised
+	// filename to ASCII (e.g. "é" to "e").
+	StripDiacritics bool
+
+	// UndatedFolder, when non-empty, classifies prefix-matched files without a valid
+	// date into "<UndatedFolder> <prefix>" instead of leaving them unclassified.
+	UndatedFolder string
+
+	// DateAnywhere uses the first valid ISO date anywhere in the filename and
+	// matches prefix rules against the text before it, so trailing dates such as
+	// "Report Acme 2024-01-15.pdf" are classified. Files without such a date fall
+	// back to the usual prefix-then-date matching.
+	DateAnywhere bool
+
+	// TwoDigitYears also accepts a YY-MM-DD date after the prefix. Years below
+	// TwoDigitYearPivot are read as 20xx, the others as 19xx.
+	TwoDigitYears     bool
+	TwoDigitYearPivot int
 }
 
 // Classify determines the classification of a file based on its filename and prefix rules.
 // For valid files, it returns CLASSIFIED with year, normalised filename, and outbound directory.
 // For invalid files, it returns UNCLASSIFIED with the reason.
 func Classify(filename string, rules []config.PrefixRule) *Classification {
-	// Step 1: Match filename against prefix rules
-	matchResult := matcher.Match(filename, rules)
+	return ClassifyWithOptions(filename, rules, Options{})
+}
 
-	if !matchResult.Matched {
+// ClassifyWithOptions determines the classification of a file with configurable options.
+func ClassifyWithOptions(filename string, rules []config.PrefixRule, opts Options) *Classification {
+	if opts.DateAnywhere {
+		if classification := classifyDateAnywhere(filename, rules, opts); classification != nil {
+			return classification
+		}
+	}
+
+	// Step 1: Split the filename into the matching rule's prefix and the date
+	parsed, dated := ParseFilename(filename, ParseConfig{
+		Rules:             rules,
+		AllowExtraSpaces:  opts.NormalizeSpaces,
+		TwoDigitYears:     opts.TwoDigitYears,
+		TwoDigitYearPivot: opts.TwoDigitYearPivot,
+	})
+
+	if parsed.Rule == nil {
 		return &Classification{
 			Type:   "UNCLASSIFIED",
 			Reason: NoPrefixMatch,
 		}
 	}
+	if !parsed.Rule.AllowsExtension(filename) {
+		return &Classification{
+			Type:   "UNCLASSIFIED",
+			Reason: ExtensionNotAllowed,
+		}
+	}
 
-	// Step 2: Extract ISO date from remainder
-	// The remainder should start with the date (YYYY-MM-DD)
-	remainder := matchResult.Remainder
+	// Step 2: Files without a date after the prefix
+	if !dated {
+		return classifyUndated(filename, parsed.Rule, opts)
+	}
 
-	// Check if remainder is long enough to contain a date
-	if len(remainder) < 10 {
+	// Step 3: Normalize the filename
+	return &Classification{
+		Type:               "CLASSIFIED",
+		Prefix:             parsed.Rule.Prefix,
+		Date:               parsed.Date,
+		Year:               parsed.ParsedDate.Year,
+		NormalisedFilename: normaliseMatchedFilename(filename, parsed.Rule, opts),
+		OutboundDirectory:  parsed.Rule.OutboundDirectory,
+		Bucket:             initialBucket(parsed.Rule, parsed.Description),
+	}
+}
+
+// classifyDateAnywhere classifies filename by its first valid ISO date token,
+// matching prefix rules against the text before the date. It returns nil if
+// the filename has no date token or the text before it matches no rule.
+func classifyDateAnywhere(filename string, rules []config.PrefixRule, opts Options) *Classification {
+	index, isoDate := findFirstDate(filename)
+	if index < 0 {
+		return nil
+	}
+
+	candidate := strings.TrimRight(filename[:index], " \t")
+	if candidate == "" {
+		return nil
+	}
+
+	// The matcher expects a delimiter after the prefix, so a candidate that is
+	// exactly a prefix still matches
+	matchResult := matcher.MatchWithOptions(candidate+" ", rules, matcher.MatchOptions{
+		AllowExtraSpaces: opts.NormalizeSpaces,
+	})
+	if !matchResult.Matched {
+		return nil
+	}
+	if !matchResult.Rule.AllowsExtension(filename) {
 		return &Classification{
 			Type:   "UNCLASSIFIED",
-			Reason: InvalidDate,
+			Reason: ExtensionNotAllowed,
 		}
 	}
 
-	// Extract the date portion (first 10 characters)
-	datePortion := remainder[:10]
+	return &Classification{
+		Type:               "CLASSIFIED",
+		Prefix:             matchResult.Rule.Prefix,
+		Date:               filename[index : index+10],
+		Year:               isoDate.Year,
+		NormalisedFilename: normaliseMatchedFilename(filename, matchResult.Rule, opts),
+		OutboundDirectory:  matchResult.Rule.OutboundDirectory,
+		Bucket:             initialBucket(matchResult.Rule, strings.TrimSuffix(filename[index+10:], filepath.Ext(filename))),
+	}
+}
 
-	// Parse the ISO date
-	isoDate, err := dateparser.ParseIsoDate(datePortion)
-	if err != nil {
+// HasDate reports whether filename contains a valid YYYY-MM-DD date anywhere,
+// as found when dates may appear anywhere in a filename.
+func HasDate(filename string) bool {
+	index, _ := findFirstDate(filename)
+	return index >= 0
+}
+
+// findFirstDate returns the index and value of the first valid YYYY-MM-DD token
+// in s, or -1 if there is none. A token must not be directly preceded by a
+// letter or digit, nor followed by a digit.
+func findFirstDate(s string) (int, *dateparser.IsoDate) {
+	for i := 0; i+10 <= len(s); i++ {
+		if i > 0 && isAlphanumeric(s[i-1]) {
+			continue
+		}
+		if i+10 < len(s) && s[i+10] >= '0' && s[i+10] <= '9' {
+			continue
+		}
+		if !hasValidDatePrefix(s[i:]) {
+			continue
+		}
+		if isoDate, err := dateparser.ParseIsoDate(s[i : i+10]); err == nil {
+			return i, isoDate
+		}
+	}
+	return -1, nil
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit.
+func isAlphanumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// classifyUndated handles a prefix-matched file without a valid date. It is
+// CLASSIFIED into the undated folder when one is configured, otherwise
+// UNCLASSIFIED with reason InvalidDate.
+func classifyUndated(filename string, rule *config.PrefixRule, opts Options) *Classification {
+	if opts.UndatedFolder == "" {
 		return &Classification{
 			Type:   "UNCLASSIFIED",
 			Reason: InvalidDate,
 		}
 	}
 
-	// Step 3: Normalize the filename
-	// The matched prefix in the filename is the original casing
-	// We need to extract it from the original filename
-	matchedPrefix := filename[:len(matchResult.Rule.Prefix)]
-	canonicalPrefix := matchResult.Rule.Prefix
-
-	normalisedFilename := normalizer.Normalize(filename, matchedPrefix, canonicalPrefix)
-
 	return &Classification{
 		Type:               "CLASSIFIED",
-		Year:               isoDate.Year,
-		NormalisedFilename: normalisedFilename,
-		OutboundDirectory:  matchResult.Rule.OutboundDirectory,
+		Prefix:             rule.Prefix,
+		NormalisedFilename: normaliseMatchedFilename(filename, rule, opts),
+		OutboundDirectory:  rule.OutboundDirectory,
+		UndatedFolder:      opts.UndatedFolder,
+		Bucket:             initialBucket(rule, strings.TrimSuffix(filename[len(rule.Prefix):], filepath.Ext(filename))),
+	}
+}
+
+// initialBucket returns the folder a file with the given description is
+// bucketed into when rule has BucketByInitial: the description's first letter
+// in upper case, or "#" if it does not start with an ASCII letter once leading
+// blanks and "-" or "_" separators are dropped. It returns "" for other rules.
+func initialBucket(rule *config.PrefixRule, description string) string {
+	if !rule.BucketByInitial {
+		return ""
+	}
+	description = strings.TrimLeft(description, " \t-_")
+	if description == "" || !isLetter(description[0]) {
+		return "#"
+	}
+	return strings.ToUpper(description[:1])
+}
+
+// normaliseMatchedFilename rewrites the filename with the canonical prefix casing
+// of rule. The matched prefix in the filename is the original casing, so it is
+// extracted from the original filename.
+func normaliseMatchedFilename(filename string, rule *config.PrefixRule, opts Options) string {
+	matchedPrefix := filename[:len(rule.Prefix)]
+	canonicalPrefix := rule.Prefix
+
+	normalisedFilename := normalizer.Normalize(filename, matchedPrefix, canonicalPrefix)
+	if opts.NormalizeSpaces {
+		normalisedFilename = normalizer.CollapseSpaces(normalisedFilename)
+	}
+	if opts.StripDiacritics {
+		normalisedFilename = normalizer.StripDiacritics(normalisedFilename)
 	}
+	return normalisedFilename
 }
 
 // extractDateFromRemainder extracts the date portion from the remainder string.
@@ -105,6 +267,12 @@ func (c *Classification) IsClassified() bool {
 	return c.Type == "CLASSIFIED"
 }
 
+// IsUndated returns true if the file matched a prefix but had no valid date
+// and was classified into the undated folder.
+func (c *Classification) IsUndated() bool {
+	return c.IsClassified() && c.UndatedFolder != ""
+}
+
 // IsUnclassified returns true if the classification is UNCLASSIFIED.
 func (c *Classification) IsUnclassified() bool {
 	return c.Type == "UNCLASSIFIED"
@@ -120,6 +288,13 @@ func ClassifyWithMatchResult(filename string, matchResult *matcher.MatchResult)
 		}
 	}
 
+	if !matchResult.Rule.AllowsExtension(filename) {
+		return &Classification{
+			Type:   "UNCLASSIFIED",
+			Reason: ExtensionNotAllowed,
+		}
+	}
+
 	remainder := matchResult.Remainder
 
 	// Check if remainder is long enough to contain a date
@@ -147,9 +322,12 @@ func ClassifyWithMatchResult(filename string, matchResult *matcher.MatchResult)
 
 	return &Classification{
 		Type:               "CLASSIFIED",
+		Prefix:             canonicalPrefix,
+		Date:               datePortion,
 		Year:               isoDate.Year,
 		NormalisedFilename: normalisedFilename,
 		OutboundDirectory:  matchResult.Rule.OutboundDirectory,
+		Bucket:             initialBucket(matchResult.Rule, strings.TrimSuffix(remainder[10:], filepath.Ext(remainder))),
 	}
 }
 
@@ -250,3 +250,171 @@ func TestDeterministicClassification(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// TestClassifyNormalizeSpaces verifies repeated whitespace is tolerated and collapsed
+// only when NormalizeSpaces is enabled.
+func TestClassifyNormalizeSpaces(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Invoice", OutboundDirectory: "/invoices"},
+	}
+	filename := "invoice   2024-01-15   Acme  Corp.pdf"
+
+	result := ClassifyWithOptions(filename, rules, Options{NormalizeSpaces: true})
+	if !result.IsClassified() {
+		t.Fatalf("Expected CLASSIFIED, got %s (%s)", result.Type, result.Reason)
+	}
+	if result.NormalisedFilename != "Invoice 2024-01-15 Acme Corp.pdf" {
+		t.Errorf("Expected single-spaced name, got %q", result.NormalisedFilename)
+	}
+	if result.Year != 2024 {
+		t.Errorf("Expected year 2024, got %d", result.Year)
+	}
+
+	tabbed := ClassifyWithOptions("Invoice\t2024-01-15\tAcme.pdf", rules, Options{NormalizeSpaces: true})
+	if !tabbed.IsClassified() || tabbed.NormalisedFilename != "Invoice 2024-01-15 Acme.pdf" {
+		t.Errorf("Expected tabs to be collapsed, got %s %q", tabbed.Type, tabbed.NormalisedFilename)
+	}
+
+	strict := Classify(filename, rules)
+	if strict.IsClassified() || strict.Reason != InvalidDate {
+		t.Errorf("Expected UNCLASSIFIED/INVALID_DATE without NormalizeSpaces, got %s/%s", strict.Type, strict.Reason)
+	}
+}
+
+// TestClassifyUndatedFolder verifies prefix-matched files without a date are classified
+// into the undated folder only when one is configured.
+func TestClassifyUndatedFolder(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Invoice", OutboundDirectory: "/invoices"},
+	}
+
+	result := ClassifyWithOptions("invoice Acme.pdf", rules, Options{UndatedFolder: "undated"})
+	if !result.IsUndated() {
+		t.Fatalf("Expected undated classification, got %s (%s)", result.Type, result.Reason)
+	}
+	if result.NormalisedFilename != "Invoice Acme.pdf" || result.OutboundDirectory != "/invoices" {
+		t.Errorf("Unexpected classification: %+v", result)
+	}
+
+	dated := ClassifyWithOptions("Invoice 2024-01-15 Acme.pdf", rules, Options{UndatedFolder: "undated"})
+	if dated.IsUndated() || dated.Year != 2024 {
+		t.Errorf("Expected dated file to be classified by year, got %+v", dated)
+	}
+
+	unmatched := ClassifyWithOptions("Receipt Acme.pdf", rules, Options{UndatedFolder: "undated"})
+	if unmatched.IsClassified() || unmatched.Reason != NoPrefixMatch {
+		t.Errorf("Expected NO_PREFIX_MATCH for unmatched file, got %+v", unmatched)
+	}
+
+	if Classify("Invoice Acme.pdf", rules).Reason != InvalidDate {
+		t.Error("Expected INVALID_DATE without UndatedFolder")
+	}
+}
+
+// TestClassifyDateAnywhere verifies that with DateAnywhere the first valid date
+// in the filename is used and the text before it is matched against prefixes.
+func TestClassifyDateAnywhere(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Report", OutboundDirectory: "/reports"},
+		{Prefix: "Invoice", OutboundDirectory: "/invoices"},
+	}
+	opts := Options{DateAnywhere: true}
+
+	tests := []struct {
+		filename   string
+		year       int
+		outbound   string
+		normalised string
+	}{
+		{"Report Acme 2024-01-15.pdf", 2024, "/reports", "Report Acme 2024-01-15.pdf"},
+		{"report 2023-06-30.pdf", 2023, "/reports", "Report 2023-06-30.pdf"},
+		{"Invoice 2024-01-15 Acme.pdf", 2024, "/invoices", "Invoice 2024-01-15 Acme.pdf"},
+		{"Report v2024-13-01 Acme 2022-02-28.pdf", 2022, "/reports", "Report v2024-13-01 Acme 2022-02-28.pdf"},
+	}
+	for _, tc := range tests {
+		result := ClassifyWithOptions(tc.filename, rules, opts)
+		if !result.IsClassified() {
+			t.Errorf("%q: expected CLASSIFIED, got %s (%s)", tc.filename, result.Type, result.Reason)
+			continue
+		}
+		if result.Year != tc.year || result.OutboundDirectory != tc.outbound || result.NormalisedFilename != tc.normalised {
+			t.Errorf("%q: expected %d %s %q, got %d %s %q", tc.filename, tc.year, tc.outbound, tc.normalised,
+				result.Year, result.OutboundDirectory, result.NormalisedFilename)
+		}
+	}
+
+	if result := ClassifyWithOptions("Receipt Acme 2024-01-15.pdf", rules, opts); result.Reason != NoPrefixMatch {
+		t.Errorf("Expected NO_PREFIX_MATCH for unknown prefix, got %s (%s)", result.Type, result.Reason)
+	}
+	if result := ClassifyWithOptions("Report Acme.pdf", rules, opts); result.Reason != InvalidDate {
+		t.Errorf("Expected INVALID_DATE without a date, got %s (%s)", result.Type, result.Reason)
+	}
+	if result := Classify("Report Acme 2024-01-15.pdf", rules); result.Reason != InvalidDate {
+		t.Errorf("Expected INVALID_DATE with the default after-prefix position, got %s (%s)", result.Type, result.Reason)
+	}
+}
+
+// TestClassifyTwoDigitYears verifies that with TwoDigitYears a YY-MM-DD date
+// is expanded around the pivot, and that it is off by default.
+func TestClassifyTwoDigitYears(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Invoice", OutboundDirectory: "/invoices"},
+	}
+	opts := Options{TwoDigitYears: true, TwoDigitYearPivot: 69}
+
+	tests := []struct {
+		filename string
+		year     int
+		date     string
+	}{
+		{"Invoice 24-01-15 Acme.pdf", 2024, "2024-01-15"},
+		{"Invoice 68-12-31 Acme.pdf", 2068, "2068-12-31"},
+		{"Invoice 69-01-01 Acme.pdf", 1969, "1969-01-01"},
+		{"Invoice 2024-01-15 Acme.pdf", 2024, "2024-01-15"},
+	}
+	for _, tc := range tests {
+		result := ClassifyWithOptions(tc.filename, rules, opts)
+		if !result.IsClassified() || result.Year != tc.year || result.Date != tc.date {
+			t.Errorf("%q: expected %d (%s), got %+v", tc.filename, tc.year, tc.date, result)
+		}
+		if result.NormalisedFilename != tc.filename {
+			t.Errorf("%q: expected the filename to be kept, got %q", tc.filename, result.NormalisedFilename)
+		}
+	}
+
+	for _, filename := range []string{"Invoice 01-15-2024 Acme.pdf", "Invoice 24-13-15 Acme.pdf"} {
+		if result := ClassifyWithOptions(filename, rules, opts); result.Reason != InvalidDate {
+			t.Errorf("%q: expected INVALID_DATE, got %s (%s)", filename, result.Type, result.Reason)
+		}
+	}
+	if result := Classify("Invoice 24-01-15 Acme.pdf", rules); result.Reason != InvalidDate {
+		t.Errorf("Expected INVALID_DATE without TwoDigitYears, got %s (%s)", result.Type, result.Reason)
+	}
+}
+
+// TestClassifyBucketSkipsSeparators verifies that with BucketByInitial the
+// bucket is the first letter after any blanks and "-" or "_" separators that
+// follow the date, also for dates found anywhere in the filename.
+func TestClassifyBucketSkipsSeparators(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Report", OutboundDirectory: "/reports", BucketByInitial: true},
+	}
+	opts := Options{DateAnywhere: true}
+
+	tests := []struct {
+		filename string
+		bucket   string
+	}{
+		{"Report 2024-01-15 - acme.pdf", "A"},
+		{"Report Q1 2024-01-15_beta.pdf", "B"},
+		{"Report Q1 2024-01-15-_ Zeta.pdf", "Z"},
+		{"Report Q1 2024-01-15 - 3M.pdf", "#"},
+		{"Report Q1 2024-01-15.pdf", "#"},
+	}
+	for _, tc := range tests {
+		result := ClassifyWithOptions(tc.filename, rules, opts)
+		if !result.IsClassified() || result.Bucket != tc.bucket {
+			t.Errorf("%q: expected bucket %q, got %+v", tc.filename, tc.bucket, result)
+		}
+	}
+}
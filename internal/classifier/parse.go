@@ -0,0 +1,140 @@
+// Package classifier handles file classification based on prefix and date for Sorta.
+package classifier
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/config"
+	"sorta/internal/dateparser"
+	"sorta/internal/matcher"
+)
+
+// ParseConfig controls how ParseFilename reads a filename.
+type ParseConfig struct {
+	// Rules are the prefixes a filename may start with; the longest rule that
+	// matches case-insensitively wins. Without rules, the prefix is any single
+	// word of ASCII letters and digits that starts with a letter.
+	Rules []config.PrefixRule
+
+	// AllowExtraSpaces accepts any run of spaces or tabs between the prefix and
+	// the date instead of exactly one space.
+	AllowExtraSpaces bool
+
+	// TwoDigitYears also accepts a YY-MM-DD date after the prefix. Years below
+	// TwoDigitYearPivot are read as 20xx, the others as 19xx.
+	TwoDigitYears     bool
+	TwoDigitYearPivot int
+}
+
+// ParsedFilename is a filename of the form
+// "<prefix> <date> <description><extension>" split into its parts.
+type ParsedFilename struct {
+	Prefix      string              // Prefix as written in the filename
+	Rule        *config.PrefixRule  // Rule the prefix matched (nil without ParseConfig.Rules)
+	Date        string              // Date in YYYY-MM-DD form, also for two-digit years
+	ParsedDate  *dateparser.IsoDate // Date as parsed
+	Description string              // Text between the date and the extension (empty if not separated from the date by a blank)
+	Extension   string              // Extension including its dot (empty if none)
+}
+
+// ParseFilename splits name into prefix, date, description and extension. It
+// reports false when name does not start with a prefix followed by a valid
+// date. When name starts with a prefix but no valid date follows it, the
+// returned ParsedFilename has only Prefix and, with cfg.Rules, Rule set.
+//
+// The date must directly follow the separator after the prefix. A description
+// is not required; callers that need one check Description.
+func ParseFilename(name string, cfg ParseConfig) (ParsedFilename, bool) {
+	var parsed ParsedFilename
+	var remainder string
+	if len(cfg.Rules) > 0 {
+		match := matcher.MatchWithOptions(name, cfg.Rules, matcher.MatchOptions{
+			AllowExtraSpaces: cfg.AllowExtraSpaces,
+		})
+		if !match.Matched {
+			return ParsedFilename{}, false
+		}
+		parsed.Prefix = name[:len(match.Rule.Prefix)]
+		parsed.Rule = match.Rule
+		remainder = match.Remainder
+	} else {
+		end := prefixWordEnd(name)
+		if end == 0 {
+			return ParsedFilename{}, false
+		}
+		rest, ok := skipSeparator(name[end:], cfg.AllowExtraSpaces)
+		if !ok {
+			return ParsedFilename{}, false
+		}
+		parsed.Prefix = name[:end]
+		remainder = rest
+	}
+
+	date, isoDate, n := leadingDate(remainder, cfg)
+	if isoDate == nil {
+		return parsed, false
+	}
+	parsed.Date = date
+	parsed.ParsedDate = isoDate
+
+	// Dates contain no dots, so the extension is always after the date
+	rest := remainder[n:]
+	parsed.Extension = filepath.Ext(rest)
+	rest = strings.TrimSuffix(rest, parsed.Extension)
+	if trimmed := strings.TrimLeft(rest, " \t"); trimmed != rest {
+		parsed.Description = trimmed
+	}
+	return parsed, true
+}
+
+// leadingDate returns the date at the start of remainder as YYYY-MM-DD,
+// together with its parsed value and the number of bytes it takes up in
+// remainder, or nil if remainder does not start with a valid date.
+// Two-digit-year dates are only accepted with cfg.TwoDigitYears and must not
+// be followed by another digit, so "01-15-2024" is no match.
+func leadingDate(remainder string, cfg ParseConfig) (string, *dateparser.IsoDate, int) {
+	if len(remainder) >= 10 {
+		if isoDate, err := dateparser.ParseIsoDate(remainder[:10]); err == nil {
+			return remainder[:10], isoDate, 10
+		}
+	}
+	if cfg.TwoDigitYears && len(remainder) >= 8 && (len(remainder) == 8 || remainder[8] < '0' || remainder[8] > '9') {
+		if isoDate, err := dateparser.ParseTwoDigitYearDate(remainder[:8], cfg.TwoDigitYearPivot); err == nil {
+			return isoDate.String(), isoDate, 8
+		}
+	}
+	return "", nil, 0
+}
+
+// prefixWordEnd returns the length of the word of ASCII letters and digits at
+// the start of name, or 0 if name does not start with a letter.
+func prefixWordEnd(name string) int {
+	if name == "" || !isLetter(name[0]) {
+		return 0
+	}
+	end := 1
+	for end < len(name) && isAlphanumeric(name[end]) {
+		end++
+	}
+	return end
+}
+
+// skipSeparator returns s without the separator at its start: exactly one
+// space, or with allowExtraSpaces any run of spaces and tabs. It reports false
+// if s does not start with a separator.
+func skipSeparator(s string, allowExtraSpaces bool) (string, bool) {
+	if allowExtraSpaces {
+		trimmed := strings.TrimLeft(s, " \t")
+		return trimmed, trimmed != s
+	}
+	if !strings.HasPrefix(s, " ") {
+		return s, false
+	}
+	return s[1:], true
+}
+
+// isLetter reports whether c is an ASCII letter.
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
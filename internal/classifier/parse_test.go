@@ -0,0 +1,156 @@
+package classifier
+
+import (
+	"testing"
+
+	"sorta/internal/config"
+)
+
+func TestParseFilename(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Bank", OutboundDirectory: "/bank"},
+		{Prefix: "Bank Statement", OutboundDirectory: "/statements"},
+	}
+
+	tests := []struct {
+		name       string
+		filename   string
+		cfg        ParseConfig
+		wantOK     bool
+		wantPrefix string
+		wantRule   string
+		wantDate   string
+		wantDesc   string
+		wantExt    string
+	}{
+		{
+			name:       "prefix word, date, description and extension",
+			filename:   "Invoice 2024-01-15 Acme Corp.pdf",
+			wantOK:     true,
+			wantPrefix: "Invoice",
+			wantDate:   "2024-01-15",
+			wantDesc:   "Acme Corp",
+			wantExt:    ".pdf",
+		},
+		{
+			name:       "no description or extension",
+			filename:   "Invoice 2024-01-15",
+			wantOK:     true,
+			wantPrefix: "Invoice",
+			wantDate:   "2024-01-15",
+		},
+		{
+			name:       "description not separated from the date",
+			filename:   "Invoice 2024-01-15Acme.pdf",
+			wantOK:     true,
+			wantPrefix: "Invoice",
+			wantDate:   "2024-01-15",
+			wantExt:    ".pdf",
+		},
+		{
+			name:       "longest rule wins and keeps the filename's casing",
+			filename:   "bank statement 2024-03-31 Q1.pdf",
+			cfg:        ParseConfig{Rules: rules},
+			wantOK:     true,
+			wantPrefix: "bank statement",
+			wantRule:   "Bank Statement",
+			wantDate:   "2024-03-31",
+			wantDesc:   "Q1",
+			wantExt:    ".pdf",
+		},
+		{
+			name:       "extra spaces when allowed",
+			filename:   "Invoice   2024-01-15 Acme.pdf",
+			cfg:        ParseConfig{AllowExtraSpaces: true},
+			wantOK:     true,
+			wantPrefix: "Invoice",
+			wantDate:   "2024-01-15",
+			wantDesc:   "Acme",
+			wantExt:    ".pdf",
+		},
+		{
+			name:       "two-digit year when enabled",
+			filename:   "Invoice 24-01-15 Acme.pdf",
+			cfg:        ParseConfig{TwoDigitYears: true, TwoDigitYearPivot: 70},
+			wantOK:     true,
+			wantPrefix: "Invoice",
+			wantDate:   "2024-01-15",
+			wantDesc:   "Acme",
+			wantExt:    ".pdf",
+		},
+		{
+			name:       "extra spaces by default",
+			filename:   "Invoice  2024-01-15 Acme.pdf",
+			wantPrefix: "Invoice",
+		},
+		{
+			name:       "two-digit year by default",
+			filename:   "Invoice 24-01-15 Acme.pdf",
+			wantPrefix: "Invoice",
+		},
+		{
+			name:       "invalid date",
+			filename:   "Invoice 2024-02-30 Acme.pdf",
+			wantPrefix: "Invoice",
+		},
+		{
+			name:     "prefix starting with a digit",
+			filename: "2Invoice 2024-01-15 Acme.pdf",
+		},
+		{
+			name:     "no space before the date",
+			filename: "Invoice2024-01-15 Acme.pdf",
+		},
+		{
+			name:     "no matching rule",
+			filename: "Invoice 2024-01-15 Acme.pdf",
+			cfg:      ParseConfig{Rules: rules},
+		},
+		{
+			name:       "matching rule without a date",
+			filename:   "Bank letter.pdf",
+			cfg:        ParseConfig{Rules: rules},
+			wantPrefix: "Bank",
+			wantRule:   "Bank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, ok := ParseFilename(tt.filename, tt.cfg)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseFilename(%q) ok = %v, want %v", tt.filename, ok, tt.wantOK)
+			}
+			rule := ""
+			if parsed.Rule != nil {
+				rule = parsed.Rule.Prefix
+			}
+			if parsed.Prefix != tt.wantPrefix || rule != tt.wantRule || parsed.Date != tt.wantDate ||
+				parsed.Description != tt.wantDesc || parsed.Extension != tt.wantExt {
+				t.Errorf("ParseFilename(%q) = prefix %q, rule %q, date %q, description %q, extension %q; want %q, %q, %q, %q, %q",
+					tt.filename, parsed.Prefix, rule, parsed.Date, parsed.Description, parsed.Extension,
+					tt.wantPrefix, tt.wantRule, tt.wantDate, tt.wantDesc, tt.wantExt)
+			}
+		})
+	}
+}
+
+// TestParseFilenameAgreesWithRules verifies that a prefix parsed without rules
+// matches the same filename once it is a rule, with the same date.
+func TestParseFilenameAgreesWithRules(t *testing.T) {
+	for _, filename := range []string{
+		"Invoice 2024-01-15 Acme Corp.pdf",
+		"Doc123 2023-06-15 Scan",
+		"receipt 2024-02-29 Store.jpg",
+	} {
+		found, ok := ParseFilename(filename, ParseConfig{})
+		if !ok {
+			t.Fatalf("Expected %q to parse", filename)
+		}
+		rules := []config.PrefixRule{{Prefix: found.Prefix, OutboundDirectory: "/out"}}
+		matched, ok := ParseFilename(filename, ParseConfig{Rules: rules})
+		if !ok || matched.Rule == nil || matched.Date != found.Date {
+			t.Errorf("Expected %q to match rule %q with date %s, got %+v", filename, found.Prefix, found.Date, matched)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sorta/internal/audit"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +19,7 @@ const (
 	FileNotFound    ConfigErrorType = "FILE_NOT_FOUND"
 	InvalidJSON     ConfigErrorType = "INVALID_JSON"
 	ValidationError ConfigErrorType = "VALIDATION_ERROR"
+	IncludeError    ConfigErrorType = "INCLUDE_ERROR"
 )
 
 // ConfigError represents an error that occurred during configuration loading.
@@ -34,6 +37,8 @@ func (e *ConfigError) Error() string {
 		return fmt.Sprintf("invalid JSON in configuration file: %s", e.Message)
 	case ValidationError:
 		return fmt.Sprintf("configuration validation error: %s", e.Message)
+	case IncludeError:
+		return fmt.Sprintf("configuration include error: %s", e.Message)
 	default:
 		return fmt.Sprintf("configuration error: %s", e.Message)
 	}
@@ -41,10 +46,123 @@ func (e *ConfigError) Error() string {
 
 // PrefixRule maps a filename prefix to an outbound directory.
 type PrefixRule struct {
-	Prefix            string `json:"prefix"`
+	Prefix            string   `json:"prefix"`
+	OutboundDirectory string   `json:"outboundDirectory"`
+	Extensions        []string `json:"extensions,omitempty"` // Allowed file extensions, e.g. ".pdf" (empty = any)
+
+	// BucketByInitial adds a folder named after the first letter of the
+	// description (A-Z, or "#" for anything else) below the rule's
+	// destination, for prefixes with too many files for one folder.
+	BucketByInitial bool `json:"bucketByInitial,omitempty"`
+}
+
+// AllowsExtension reports whether a file named filename may be organized by
+// the rule. Extensions are compared case-insensitively, with or without their
+// leading dot. A rule without extensions allows every file.
+func (r PrefixRule) AllowsExtension(filename string) bool {
+	if len(r.Extensions) == 0 {
+		return true
+	}
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+	for _, allowed := range r.Extensions {
+		if strings.EqualFold(strings.TrimPrefix(allowed, "."), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tokens that can appear in a prefix rule's outbound directory. A rule whose
+// outbound directory contains any of them is rendered for each file and used
+// as the full destination directory, without the "<year> <prefix>" subfolder.
+const (
+	TokenYear   = "{year}"
+	TokenMonth  = "{month}"
+	TokenDay    = "{day}"
+	TokenPrefix = "{prefix}"
+)
+
+var outboundTokens = []string{TokenYear, TokenMonth, TokenDay, TokenPrefix}
+
+// HasOutboundTokens reports whether an outbound directory contains any of the
+// {year}, {month}, {day} or {prefix} tokens.
+func HasOutboundTokens(dir string) bool {
+	return outboundTokenIndex(dir) >= 0
+}
+
+// outboundTokenIndex returns the index of the first token in dir, or -1.
+func outboundTokenIndex(dir string) int {
+	first := -1
+	for _, token := range outboundTokens {
+		if i := strings.Index(dir, token); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	return first
+}
+
+// RenderOutboundDirectory replaces the tokens in dir with the prefix and the
+// parts of date (YYYY-MM-DD). For example, "/archive/{year}/{month}" renders
+// as "/archive/2024/01" for the date 2024-01-15.
+func RenderOutboundDirectory(dir, prefix, date string) string {
+	year, month, day := "", "", ""
+	if parts := strings.Split(date, "-"); len(parts) == 3 {
+		year, month, day = parts[0], parts[1], parts[2]
+	}
+	return strings.NewReplacer(
+		TokenYear, year,
+		TokenMonth, month,
+		TokenDay, day,
+		TokenPrefix, prefix,
+	).Replace(dir)
+}
+
+// OutboundRoot returns the directory of dir that comes before its first token,
+// which every rendered path lies under: "/archive/{year}/{month}" has the root
+// "/archive". A directory without tokens is returned unchanged.
+func OutboundRoot(dir string) string {
+	i := outboundTokenIndex(dir)
+	if i < 0 {
+		return dir
+	}
+	root := dir[:i]
+	if !strings.HasSuffix(root, string(filepath.Separator)) && !strings.HasSuffix(root, "/") {
+		root = filepath.Dir(root)
+	}
+	return filepath.Clean(root)
+}
+
+// TypeRule routes files that match no prefix rule by their detected content
+// type. Mime is a media type such as "application/pdf", or "image/*" to match
+// every subtype.
+type TypeRule struct {
+	Mime              string `json:"mime"`
 	OutboundDirectory string `json:"outboundDirectory"`
 }
 
+// CatchAll collects files that match no prefix or type rule into one folder
+// instead of routing them to for-review. Mode selects which files: only those
+// with a YYYY-MM-DD date in their name ("dated-only", the default), or every
+// file ("everything").
+type CatchAll struct {
+	OutboundDirectory string `json:"outboundDirectory"`
+	Mode              string `json:"mode,omitempty"`
+}
+
+// Catch-all mode constants
+const (
+	CatchAllDatedOnly  = "dated-only"
+	CatchAllEverything = "everything"
+)
+
+// GetMode returns the configured catch-all mode or default "dated-only".
+func (c *CatchAll) GetMode() string {
+	if c.Mode == "" {
+		return CatchAllDatedOnly
+	}
+	return c.Mode
+}
+
 // Symlink policy constants
 const (
 	SymlinkPolicyFollow = "follow"
@@ -52,6 +170,31 @@ const (
 	SymlinkPolicyError  = "error"
 )
 
+// Date position constants
+const (
+	DatePositionAfterPrefix = "after-prefix"
+	DatePositionAnywhere    = "anywhere"
+)
+
+// Zero-byte action constants
+const (
+	ZeroByteProcess = "process"
+	ZeroByteSkip    = "skip"
+	ZeroByteReview  = "review"
+)
+
+// DefaultCopySpaceMarginBytes is the free space kept on a destination volume
+// when CheckCopySpace is set and no margin is configured.
+const DefaultCopySpaceMarginBytes = 64 << 20
+
+// DefaultDirPermissions is the mode destination directories are created with
+// when DirPermissions is not set.
+const DefaultDirPermissions os.FileMode = 0755
+
+// DefaultTwoDigitYearPivot is the two-digit year from which TwoDigitYears
+// dates are read as 19xx: 00-68 become 2000-2068 and 69-99 become 1969-1999.
+const DefaultTwoDigitYearPivot = 69
+
 // Watch configuration defaults
 const (
 	DefaultDebounceSeconds   = 2
@@ -87,6 +230,115 @@ type Configuration struct {
 	SymlinkPolicy      string             `json:"symlinkPolicy,omitempty"`
 	ScanDepth          *int               `json:"scanDepth,omitempty"` // nil = default (0)
 	Watch              *WatchConfig       `json:"watch,omitempty"`
+
+	// PreserveSourceSubpath keeps a file's subdirectory relative to its inbound
+	// root under the destination when scanning recursively.
+	PreserveSourceSubpath bool `json:"preserveSourceSubpath,omitempty"`
+
+	// NormalizeSpaces tolerates repeated spaces or tabs in filenames and collapses
+	// them to single spaces in the destination name.
+	NormalizeSpaces bool `json:"normalizeSpaces,omitempty"`
+
+	// StripDiacritics transliterates accented characters to ASCII in the
+	// destination name (e.g. "Café" becomes "Cafe").
+	StripDiacritics bool `json:"stripDiacritics,omitempty"`
+
+	// UndatedFolder routes files that match a prefix but have no valid date to
+	// <outbound>/<UndatedFolder> <prefix>/ (e.g. "undated"). Empty = for-review.
+	UndatedFolder string `json:"undatedFolder,omitempty"`
+
+	// DuplicateRenameTemplate controls how a file colliding with an existing
+	// destination is renamed, e.g. "{name} ({date}){ext}". Tokens: {name},
+	// {ext}, {n}, {date}. Empty = "_duplicate" suffix.
+	DuplicateRenameTemplate string `json:"duplicateRenameTemplate,omitempty"`
+
+	// DatePosition selects where the ISO date is expected: "after-prefix" (the
+	// default) or "anywhere", which uses the first date in the filename and
+	// matches prefix rules against the text before it.
+	DatePosition string `json:"datePosition,omitempty"`
+
+	// TwoDigitYears also accepts YY-MM-DD dates after the prefix (such as
+	// "Invoice 24-01-15 Acme.pdf"), reading years below TwoDigitYearPivot as
+	// 20xx and the others as 19xx. Off by default because dates like
+	// "01-15-2024" would otherwise be easy to misread.
+	TwoDigitYears     bool `json:"twoDigitYears,omitempty"`
+	TwoDigitYearPivot *int `json:"twoDigitYearPivot,omitempty"` // nil = default (69)
+
+	// TypeRules route files that match no prefix rule by their sniffed content
+	// type before they fall back to for-review. The first matching rule wins.
+	TypeRules []TypeRule `json:"typeRules,omitempty"`
+
+	// CatchAll, when set, moves files that match no prefix or type rule into
+	// its outbound directory before they fall back to for-review.
+	CatchAll *CatchAll `json:"catchAll,omitempty"`
+
+	// ReadOnlySource copies files from every inbound directory instead of
+	// moving them, leaving the originals in place.
+	ReadOnlySource bool `json:"readOnlySource,omitempty"`
+
+	// ReadOnlyInbounds lists inbound directories (e.g. read-only network
+	// shares) whose files are copied instead of moved.
+	ReadOnlyInbounds []string `json:"readOnlyInbounds,omitempty"`
+
+	// TruncateLongNames shortens the description of a file whose destination
+	// path would exceed the platform's length limits, instead of routing the
+	// file to review with PATH_TOO_LONG.
+	TruncateLongNames bool `json:"truncateLongNames,omitempty"`
+
+	// CheckCopySpace checks the free space on the destination volume right
+	// before each file is copied (for read-only sources and cross-device
+	// moves), skipping the file with INSUFFICIENT_SPACE when it would not fit
+	// with CopySpaceMarginBytes to spare.
+	CheckCopySpace       bool  `json:"checkCopySpace,omitempty"`
+	CopySpaceMarginBytes int64 `json:"copySpaceMarginBytes,omitempty"` // default: 64 MiB
+
+	// PreserveXattrs copies a file's extended attributes (on macOS also its
+	// resource fork and Finder tags) along with its contents whenever the file
+	// is copied rather than renamed. A file whose attributes cannot be copied
+	// is not moved.
+	PreserveXattrs bool `json:"preserveXattrs,omitempty"`
+
+	// RequireExtension routes files whose name has no extension (such as
+	// "Invoice 2024-01-15 Acme") to for-review with reason NO_EXTENSION instead
+	// of organizing them.
+	RequireExtension bool `json:"requireExtension,omitempty"`
+
+	// VerifyExtensionMatchesContent sniffs the content of files with common
+	// extensions (such as ".pdf") and routes a file whose content does not
+	// match its extension to for-review with reason EXTENSION_CONTENT_MISMATCH
+	// instead of organizing it.
+	VerifyExtensionMatchesContent bool `json:"verifyExtensionMatchesContent,omitempty"`
+
+	// ZeroByteAction decides what happens to empty files, which are often
+	// interrupted downloads: "process" (the default) organizes them like any
+	// other file, "skip" leaves them in place and "review" routes them to
+	// for-review, both with reason ZERO_BYTE.
+	ZeroByteAction string `json:"zeroByteAction,omitempty"`
+
+	// MinFileSize, when positive, leaves files smaller than this many bytes,
+	// such as icons and shortcuts, in place with reason TOO_SMALL (0 = no
+	// minimum). Empty files go by ZeroByteAction when it is "skip" or
+	// "review".
+	MinFileSize int64 `json:"minFileSize,omitempty"`
+
+	// ExtractArchives unpacks a .zip archive that holds a single file whose
+	// name matches a prefix rule: the file is extracted next to the archive
+	// and organized, and the archive is moved to a "processed-archives" folder
+	// in its directory. Other archives are organized like any other file.
+	ExtractArchives bool `json:"extractArchives,omitempty"`
+
+	// DirPermissions is the mode, in octal such as "0775", given to the
+	// destination directories a run creates. When empty, directories are
+	// created with mode 0755 less the process umask.
+	DirPermissions string `json:"dirPermissions,omitempty"`
+
+	// Includes lists files (glob patterns, relative to this file) whose prefix
+	// rules and inbound directories are merged in on load. Entries in this file
+	// take precedence over included ones.
+	Includes []string `json:"includes,omitempty"`
+
+	// included records what was merged in from Includes so Save can leave it out.
+	included *includedEntries
 }
 
 // GetSymlinkPolicy returns the configured symlink policy or default "skip".
@@ -97,6 +349,66 @@ func (c *Configuration) GetSymlinkPolicy() string {
 	return c.SymlinkPolicy
 }
 
+// GetDatePosition returns the configured date position or default "after-prefix".
+func (c *Configuration) GetDatePosition() string {
+	if c.DatePosition == "" {
+		return DatePositionAfterPrefix
+	}
+	return c.DatePosition
+}
+
+// GetTwoDigitYearPivot returns the configured two-digit year pivot or the default.
+func (c *Configuration) GetTwoDigitYearPivot() int {
+	if c.TwoDigitYearPivot == nil {
+		return DefaultTwoDigitYearPivot
+	}
+	return *c.TwoDigitYearPivot
+}
+
+// GetZeroByteAction returns the configured zero-byte action or default "process".
+func (c *Configuration) GetZeroByteAction() string {
+	if c.ZeroByteAction == "" {
+		return ZeroByteProcess
+	}
+	return c.ZeroByteAction
+}
+
+// GetDirPermissions returns the configured destination directory mode or
+// the default 0755. An invalid mode, which Validate rejects, also gives the
+// default.
+func (c *Configuration) GetDirPermissions() os.FileMode {
+	mode, err := ParseDirPermissions(c.DirPermissions)
+	if err != nil {
+		return DefaultDirPermissions
+	}
+	return mode
+}
+
+// ParseDirPermissions parses an octal directory mode such as "0775" or "775".
+// The mode must fit in the permission bits and give the owner read, write and
+// search access, so Sorta can keep organizing into the directories it creates.
+func ParseDirPermissions(s string) (os.FileMode, error) {
+	if s == "" {
+		return DefaultDirPermissions, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("%q is not an octal permission mode such as \"0775\"", s)
+	}
+	if mode&0o700 != 0o700 {
+		return 0, fmt.Errorf("%q must give the owner read, write and search access (0700)", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// GetCopySpaceMargin returns the configured copy space margin or the default.
+func (c *Configuration) GetCopySpaceMargin() int64 {
+	if c.CopySpaceMarginBytes <= 0 {
+		return DefaultCopySpaceMarginBytes
+	}
+	return c.CopySpaceMarginBytes
+}
+
 // GetScanDepth returns the configured scan depth or default 0.
 func (c *Configuration) GetScanDepth() int {
 	if c.ScanDepth == nil {
@@ -134,11 +446,131 @@ func (c *Configuration) Validate() error {
 				Message: fmt.Sprintf("prefixRules[%d].outboundDirectory cannot be empty", i),
 			}
 		}
+		for j, ext := range rule.Extensions {
+			if strings.TrimPrefix(ext, ".") == "" {
+				return &ConfigError{
+					Type:    ValidationError,
+					Message: fmt.Sprintf("prefixRules[%d].extensions[%d] cannot be empty", i, j),
+				}
+			}
+		}
+	}
+
+	for i, rule := range c.TypeRules {
+		if !strings.Contains(rule.Mime, "/") {
+			return &ConfigError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("typeRules[%d].mime must be a media type such as \"application/pdf\" or \"image/*\"", i),
+			}
+		}
+		if rule.OutboundDirectory == "" {
+			return &ConfigError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("typeRules[%d].outboundDirectory cannot be empty", i),
+			}
+		}
+	}
+
+	if c.CatchAll != nil {
+		if c.CatchAll.OutboundDirectory == "" {
+			return &ConfigError{
+				Type:    ValidationError,
+				Message: "catchAll.outboundDirectory cannot be empty",
+			}
+		}
+		if mode := c.CatchAll.GetMode(); mode != CatchAllDatedOnly && mode != CatchAllEverything {
+			return &ConfigError{
+				Type:    ValidationError,
+				Message: fmt.Sprintf("catchAll.mode must be %q or %q, got %q", CatchAllDatedOnly, CatchAllEverything, mode),
+			}
+		}
+	}
+
+	if c.MinFileSize < 0 {
+		return &ConfigError{
+			Type:    ValidationError,
+			Message: "minFileSize cannot be negative",
+		}
+	}
+
+	if _, err := ParseDirPermissions(c.DirPermissions); err != nil {
+		return &ConfigError{
+			Type:    ValidationError,
+			Message: fmt.Sprintf("dirPermissions: %v", err),
+		}
+	}
+
+	if strings.ContainsAny(c.DuplicateRenameTemplate, `/\`) {
+		return &ConfigError{
+			Type:    ValidationError,
+			Message: "duplicateRenameTemplate cannot contain path separators",
+		}
+	}
+
+	return nil
+}
+
+// IsReadOnlySource reports whether files at path must be copied rather than
+// moved: ReadOnlySource is set, or path is inside one of ReadOnlyInbounds.
+func (c *Configuration) IsReadOnlySource(path string) bool {
+	if c.ReadOnlySource {
+		return true
+	}
+	for _, dir := range c.ReadOnlyInbounds {
+		if isSameOrInside(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDirectoryNesting returns an error if any outbound directory is an
+// inbound directory or lies inside one: files moved there would be scanned
+// again by a recursive run. The error names the first offending pair. It is
+// not part of Validate, since nesting is harmless with a scan depth of 0.
+func (c *Configuration) ValidateDirectoryNesting() error {
+	check := func(field, outDir string) error {
+		for _, inDir := range c.InboundDirectories {
+			if isSameOrInside(outDir, inDir) {
+				return &ConfigError{
+					Type:    ValidationError,
+					Message: fmt.Sprintf("%s %q is inside inbound directory %q; organized files would be processed again", field, outDir, inDir),
+				}
+			}
+		}
+		return nil
 	}
 
+	for i, rule := range c.PrefixRules {
+		if err := check(fmt.Sprintf("prefixRules[%d].outboundDirectory", i), OutboundRoot(rule.OutboundDirectory)); err != nil {
+			return err
+		}
+	}
+	for i, rule := range c.TypeRules {
+		if err := check(fmt.Sprintf("typeRules[%d].outboundDirectory", i), rule.OutboundDirectory); err != nil {
+			return err
+		}
+	}
+	if c.CatchAll != nil {
+		if err := check("catchAll.outboundDirectory", c.CatchAll.OutboundDirectory); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// isSameOrInside reports whether path is dir or one of its descendants.
+func isSameOrInside(path, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
 // ApplyAuditDefaults ensures the Audit configuration has sensible defaults.
 // If Audit is nil, it creates a new AuditConfig with defaults.
 // If Audit exists but has zero values, it applies defaults for those fields.
@@ -226,14 +658,63 @@ func (c *Configuration) HasPrefix(prefix string) bool {
 	return false
 }
 
-// AddPrefixRule adds a rule if the prefix doesn't already exist (case-insensitive).
-// Returns true if the rule was added, false if it was a duplicate.
-func (c *Configuration) AddPrefixRule(rule PrefixRule) bool {
+// NormalizePrefix trims surrounding whitespace from a prefix taken from a
+// filename or user input. The matcher compares the prefix literally and then
+// expects a space, so a stored prefix with trailing blanks would never match the
+// files it came from. Punctuation is kept because it is part of the literal match.
+// Returns an error if nothing is left after trimming.
+func NormalizePrefix(prefix string) (string, error) {
+	normalized := strings.TrimSpace(prefix)
+	if normalized == "" {
+		return "", fmt.Errorf("invalid prefix %q: prefix is empty after trimming whitespace", prefix)
+	}
+	return normalized, nil
+}
+
+// AddPrefixRule normalizes the rule's prefix and adds the rule if the prefix
+// doesn't already exist (case-insensitive).
+// Returns true if the rule was added, false if it was a duplicate, and an error
+// if the prefix is empty after normalization.
+func (c *Configuration) AddPrefixRule(rule PrefixRule) (bool, error) {
+	prefix, err := NormalizePrefix(rule.Prefix)
+	if err != nil {
+		return false, err
+	}
+	rule.Prefix = prefix
+
 	if c.HasPrefix(rule.Prefix) {
-		return false
+		return false, nil
 	}
 	c.PrefixRules = append(c.PrefixRules, rule)
-	return true
+	return true, nil
+}
+
+// FindTypeRule returns the first type rule matching contentType, or nil if
+// there is none. Parameters such as "; charset=utf-8" are ignored and
+// comparison is case-insensitive.
+func (c *Configuration) FindTypeRule(contentType string) *TypeRule {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	major, _, _ := strings.Cut(mediaType, "/")
+
+	for i := range c.TypeRules {
+		mime := strings.ToLower(c.TypeRules[i].Mime)
+		if mime == mediaType || mime == major+"/*" {
+			return &c.TypeRules[i]
+		}
+	}
+	return nil
+}
+
+// FindPrefixRule returns the rule for prefix (case-insensitive), or nil if there is none.
+// The returned pointer refers into PrefixRules, so changes to it update the configuration.
+func (c *Configuration) FindPrefixRule(prefix string) *PrefixRule {
+	for i := range c.PrefixRules {
+		if strings.EqualFold(c.PrefixRules[i].Prefix, prefix) {
+			return &c.PrefixRules[i]
+		}
+	}
+	return nil
 }
 
 // HasInboundDirectory checks if a directory already exists in inboundDirectories.
@@ -281,6 +762,10 @@ func Load(filePath string) (*Configuration, error) {
 		}
 	}
 
+	if err := config.resolveIncludes(filePath); err != nil {
+		return nil, err
+	}
+
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
@@ -319,6 +804,10 @@ func LoadOrCreate(filePath string) (*Configuration, error) {
 		}
 	}
 
+	if err := config.resolveIncludes(filePath); err != nil {
+		return nil, err
+	}
+
 	// Apply audit defaults for missing or partial audit configuration
 	config.ApplyAuditDefaults()
 
@@ -326,8 +815,10 @@ func LoadOrCreate(filePath string) (*Configuration, error) {
 }
 
 // Save serializes and writes a configuration to the given path.
+// Rules and inbound directories merged in from included files are not written;
+// included files themselves are never modified.
 func Save(config *Configuration, filePath string) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := json.MarshalIndent(config.withoutIncluded(), "", "  ")
 	if err != nil {
 		return &ConfigError{
 			Type:    InvalidJSON,
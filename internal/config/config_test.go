@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sorta/internal/audit"
+	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -167,7 +168,10 @@ func TestPrefixRuleDuplicatePrevention(t *testing.T) {
 			originalLen := len(config.PrefixRules)
 
 			// Try to add the rule
-			added := config.AddPrefixRule(newRule)
+			added, err := config.AddPrefixRule(newRule)
+			if err != nil {
+				return false
+			}
 
 			if alreadyExists {
 				// If it already existed, it should not be added and existing rules unchanged
@@ -703,3 +707,153 @@ func TestWatchConfigRoundTrip(t *testing.T) {
 		t.Errorf("IgnorePatterns: expected %v, got %v", original.Watch.IgnorePatterns, loaded.Watch.IgnorePatterns)
 	}
 }
+
+// TestAddPrefixRuleNormalizesPrefix checks that surrounding whitespace is
+// trimmed from added prefixes and that blank prefixes are rejected.
+func TestAddPrefixRuleNormalizesPrefix(t *testing.T) {
+	cfg := &Configuration{}
+
+	added, err := cfg.AddPrefixRule(PrefixRule{Prefix: " Invoice ", OutboundDirectory: "/invoices"})
+	if err != nil {
+		t.Fatalf("AddPrefixRule failed: %v", err)
+	}
+	if !added || len(cfg.PrefixRules) != 1 || cfg.PrefixRules[0].Prefix != "Invoice" {
+		t.Fatalf("Expected stored prefix %q, got %+v", "Invoice", cfg.PrefixRules)
+	}
+
+	// The trimmed prefix is what duplicates are checked against
+	added, err = cfg.AddPrefixRule(PrefixRule{Prefix: "\tinvoice", OutboundDirectory: "/other"})
+	if err != nil || added {
+		t.Errorf("Expected whitespace variant to be a duplicate, got added=%v err=%v", added, err)
+	}
+
+	for _, prefix := range []string{"", "   ", "\t\n"} {
+		added, err := cfg.AddPrefixRule(PrefixRule{Prefix: prefix, OutboundDirectory: "/blank"})
+		if err == nil || added {
+			t.Errorf("Expected error for blank prefix %q, got added=%v", prefix, added)
+		}
+	}
+	if len(cfg.PrefixRules) != 1 {
+		t.Errorf("Expected 1 rule, got %+v", cfg.PrefixRules)
+	}
+}
+
+// TestFindTypeRule checks exact and wildcard media type matching.
+func TestFindTypeRule(t *testing.T) {
+	cfg := &Configuration{TypeRules: []TypeRule{
+		{Mime: "application/pdf", OutboundDirectory: "/documents"},
+		{Mime: "image/*", OutboundDirectory: "/pictures"},
+	}}
+
+	tests := []struct {
+		contentType string
+		expected    string
+	}{
+		{"application/pdf", "/documents"},
+		{"image/png", "/pictures"},
+		{"text/plain; charset=utf-8", ""},
+		{"application/octet-stream", ""},
+	}
+	for _, tt := range tests {
+		rule := cfg.FindTypeRule(tt.contentType)
+		got := ""
+		if rule != nil {
+			got = rule.OutboundDirectory
+		}
+		if got != tt.expected {
+			t.Errorf("FindTypeRule(%q) = %q, want %q", tt.contentType, got, tt.expected)
+		}
+	}
+}
+
+// TestValidateDirectoryNesting verifies that an outbound directory
+// nested in an inbound directory is rejected with both directories named,
+// while sibling directories are accepted. Validate itself allows nesting.
+func TestValidateDirectoryNesting(t *testing.T) {
+	cfg := &Configuration{
+		InboundDirectories: []string{"/inbox"},
+		PrefixRules: []PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/documents/invoices"},
+			{Prefix: "Receipt", OutboundDirectory: "/inbox/organized"},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected Validate to allow nesting, got: %v", err)
+	}
+
+	err := cfg.ValidateDirectoryNesting()
+	if err == nil {
+		t.Fatal("Expected an error for an outbound directory inside the inbound directory")
+	}
+	if !strings.Contains(err.Error(), `"/inbox/organized"`) || !strings.Contains(err.Error(), `"/inbox"`) {
+		t.Errorf("Expected error to name both directories, got: %v", err)
+	}
+
+	cfg.PrefixRules[1].OutboundDirectory = "/inbox-organized"
+	if err := cfg.ValidateDirectoryNesting(); err != nil {
+		t.Errorf("Expected sibling directories to be valid, got: %v", err)
+	}
+
+	cfg.TypeRules = []TypeRule{{Mime: "image/*", OutboundDirectory: "/inbox"}}
+	if err := cfg.ValidateDirectoryNesting(); err == nil {
+		t.Error("Expected an error for a type rule outbound directory equal to the inbound directory")
+	}
+}
+
+// TestValidateCatchAll verifies that the catch-all rule needs a known mode and
+// counts as an outbound directory for the nesting check.
+func TestValidateCatchAll(t *testing.T) {
+	cfg := &Configuration{
+		InboundDirectories: []string{"/inbox"},
+		PrefixRules: []PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/documents/invoices"},
+		},
+		CatchAll: &CatchAll{OutboundDirectory: "/documents/misc"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected a valid catch-all rule, got: %v", err)
+	}
+	if cfg.CatchAll.GetMode() != CatchAllDatedOnly {
+		t.Errorf("Expected default mode %q, got %q", CatchAllDatedOnly, cfg.CatchAll.GetMode())
+	}
+
+	cfg.CatchAll.Mode = "some"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "catchAll.mode") {
+		t.Errorf("Expected an error for an unknown mode, got: %v", err)
+	}
+
+	cfg.CatchAll = &CatchAll{OutboundDirectory: "/inbox/misc", Mode: CatchAllEverything}
+	if err := cfg.ValidateDirectoryNesting(); err == nil {
+		t.Error("Expected an error for a catch-all directory inside the inbound directory")
+	}
+}
+
+func TestValidateDirPermissions(t *testing.T) {
+	cfg := &Configuration{
+		InboundDirectories: []string{"/inbox"},
+		PrefixRules: []PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/documents/invoices"},
+		},
+	}
+	if cfg.GetDirPermissions() != 0755 {
+		t.Errorf("Expected default mode 0755, got %o", cfg.GetDirPermissions())
+	}
+
+	for _, mode := range []string{"0775", "775", "0700"} {
+		cfg.DirPermissions = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", mode, err)
+		}
+	}
+	if cfg.GetDirPermissions() != 0700 {
+		t.Errorf("Expected mode 0700, got %o", cfg.GetDirPermissions())
+	}
+
+	for _, mode := range []string{"rwx", "0778", "01777", "0575", "-1"} {
+		cfg.DirPermissions = mode
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "dirPermissions") {
+			t.Errorf("Expected an error for %q, got: %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package config handles configuration loading and validation for Sorta.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoEditor is returned by Edit when no editor command is given.
+var ErrNoEditor = errors.New("no editor set: set $EDITOR to your editor command (e.g. EDITOR=nano or EDITOR=\"code --wait\")")
+
+// Edit opens filePath in editor and loads the file again once the editor
+// exits. editor is a command line such as "vim" or "code --wait"; the path is
+// appended as its last argument. If the edited configuration does not load or
+// validate, reopen is asked whether to open the editor again; when it declines
+// (or is nil) the file is restored to its previous contents and the
+// validation error is returned, so an edit never leaves an invalid file. The
+// file is also restored when the editor fails or exits with a non-zero status.
+func Edit(filePath, editor string, reopen func(err error) bool) (*Configuration, error) {
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return nil, ErrNoEditor
+	}
+
+	original, err := os.ReadFile(filePath)
+	existed := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, &ConfigError{Type: FileNotFound, Path: filePath, Message: err.Error()}
+	}
+
+	// restore puts back the contents filePath had before the edit and
+	// returns cause, noting when that fails
+	restore := func(cause error) error {
+		var err error
+		if existed {
+			err = os.WriteFile(filePath, original, 0644)
+		} else {
+			err = os.Remove(filePath)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w (and restoring the previous configuration failed: %v)", cause, err)
+		}
+		return cause
+	}
+
+	for {
+		cmd := exec.Command(args[0], append(args[1:], filePath)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, restore(fmt.Errorf("failed to run editor %q: %w", editor, err))
+		}
+
+		cfg, loadErr := Load(filePath)
+		if loadErr == nil {
+			return cfg, nil
+		}
+		if reopen != nil && reopen(loadErr) {
+			continue
+		}
+		return nil, restore(loadErr)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeEditor writes a shell script that replaces the edited file with
+// contents and returns the editor command that runs it.
+func fakeEditor(t *testing.T, dir, contents string) string {
+	t.Helper()
+	source := filepath.Join(dir, "edited.json")
+	if err := os.WriteFile(source, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write edited contents: %v", err)
+	}
+	script := filepath.Join(dir, "editor.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncp \""+source+"\" \"$1\"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake editor: %v", err)
+	}
+	return script
+}
+
+// TestEditRejectsInvalidAndAcceptsValid verifies that an edit that fails
+// validation is rolled back, and that a valid edit is kept and loaded.
+func TestEditRejectsInvalidAndAcceptsValid(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "sorta-config.json")
+	original := `{"inboundDirectories": ["/inbox"], "prefixRules": [{"prefix": "Invoice", "outboundDirectory": "/Invoices"}]}`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// No editor configured
+	if _, err := Edit(configPath, "", nil); !errors.Is(err, ErrNoEditor) {
+		t.Errorf("Expected ErrNoEditor, got %v", err)
+	}
+
+	// An edit that removes every prefix rule is invalid and is rolled back
+	editor := fakeEditor(t, tempDir, `{"inboundDirectories": ["/inbox"], "prefixRules": []}`)
+	reopened := 0
+	_, err := Edit(configPath, editor, func(error) bool {
+		reopened++
+		return false
+	})
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Type != ValidationError {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if reopened != 1 {
+		t.Errorf("Expected to be asked to reopen once, got %d", reopened)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != original {
+		t.Errorf("Expected the original config to be restored, got %s", data)
+	}
+
+	// A valid edit is kept
+	edited := `{"inboundDirectories": ["/inbox"], "prefixRules": [{"prefix": "Receipt", "outboundDirectory": "/Receipts"}]}`
+	editor = fakeEditor(t, tempDir, edited)
+	cfg, err := Edit(configPath, editor, nil)
+	if err != nil {
+		t.Fatalf("Expected valid edit to be accepted, got %v", err)
+	}
+	if cfg.FindPrefixRule("Receipt") == nil {
+		t.Errorf("Expected the edited config to be returned, got %+v", cfg.PrefixRules)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != edited {
+		t.Errorf("Expected the edit to be kept, got %s", data)
+	}
+}
+
+// TestEditRestoresOnEditorFailure verifies that the file is restored when the
+// editor writes to it and then exits with a non-zero status.
+func TestEditRestoresOnEditorFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "sorta-config.json")
+	original := `{"inboundDirectories": ["/inbox"], "prefixRules": [{"prefix": "Invoice", "outboundDirectory": "/Invoices"}]}`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	script := filepath.Join(tempDir, "failing-editor.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho '{' > \"$1\"\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake editor: %v", err)
+	}
+
+	if _, err := Edit(configPath, script, nil); err == nil {
+		t.Fatal("Expected an error when the editor fails")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != original {
+		t.Errorf("Expected the original config to be restored, got %s", data)
+	}
+}
@@ -0,0 +1,192 @@
+// Package config handles configuration loading and validation for Sorta.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includedEntries tracks the prefix rules and inbound directories that were
+// merged into a configuration from included files.
+type includedEntries struct {
+	rules []PrefixRule
+	dirs  []string
+}
+
+// resolveIncludes expands c.Includes relative to filePath and merges the prefix
+// rules and inbound directories of every included file into c. Included files
+// may include further files; entries already present win over included ones.
+func (c *Configuration) resolveIncludes(filePath string) error {
+	if len(c.Includes) == 0 {
+		return nil
+	}
+
+	root, err := filepath.Abs(filePath)
+	if err != nil {
+		return &ConfigError{Type: IncludeError, Path: filePath, Message: err.Error()}
+	}
+
+	c.included = &includedEntries{}
+	visited := map[string]bool{root: true}
+	return c.mergeIncludes(root, c.Includes, []string{root}, visited)
+}
+
+// mergeIncludes merges the files matched by patterns (relative to from) into c.
+// stack holds the chain of files currently being included, for cycle detection.
+func (c *Configuration) mergeIncludes(from string, patterns []string, stack []string, visited map[string]bool) error {
+	baseDir := filepath.Dir(from)
+
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return &ConfigError{
+				Type:    IncludeError,
+				Path:    from,
+				Message: fmt.Sprintf("invalid include pattern %q in %s: %v", pattern, from, err),
+			}
+		}
+		// A literal path that does not exist is an error; an empty glob is not
+		if len(matches) == 0 && !hasGlobMeta(pattern) {
+			return &ConfigError{
+				Type:    IncludeError,
+				Path:    pattern,
+				Message: fmt.Sprintf("included file not found: %s (included from %s)", pattern, from),
+			}
+		}
+
+		for _, match := range matches {
+			if err := c.mergeIncludedFile(match, from, stack, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeIncludedFile reads one included file, merges its entries and follows its own includes.
+func (c *Configuration) mergeIncludedFile(path, from string, stack []string, visited map[string]bool) error {
+	for _, ancestor := range stack {
+		if ancestor == path {
+			return &ConfigError{
+				Type:    IncludeError,
+				Path:    path,
+				Message: fmt.Sprintf("include cycle: %s", strings.Join(append(stack, path), " -> ")),
+			}
+		}
+	}
+	// Files reached through more than one include are merged once
+	if visited[path] {
+		return nil
+	}
+	visited[path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = errors.New("file not found")
+		}
+		return &ConfigError{
+			Type:    IncludeError,
+			Path:    path,
+			Message: fmt.Sprintf("failed to read %s (included from %s): %v", path, from, err),
+		}
+	}
+
+	var included Configuration
+	if err := json.Unmarshal(data, &included); err != nil {
+		return &ConfigError{
+			Type:    InvalidJSON,
+			Path:    path,
+			Message: fmt.Sprintf("%s: %v", path, err),
+		}
+	}
+
+	for _, rule := range included.PrefixRules {
+		added, err := c.AddPrefixRule(rule)
+		if err != nil {
+			return &ConfigError{
+				Type:    ValidationError,
+				Path:    path,
+				Message: fmt.Sprintf("%s: %v", path, err),
+			}
+		}
+		if added {
+			// Record the rule as stored, with its prefix normalized
+			c.included.rules = append(c.included.rules, c.PrefixRules[len(c.PrefixRules)-1])
+		}
+	}
+	for _, dir := range included.InboundDirectories {
+		if c.AddInboundDirectory(dir) {
+			c.included.dirs = append(c.included.dirs, dir)
+		}
+	}
+
+	return c.mergeIncludes(path, included.Includes, append(stack, path), visited)
+}
+
+// withoutIncluded returns a copy of c without the entries merged in from
+// included files. Included rules that were changed after loading are kept, so
+// the change is saved to the main file where it takes precedence.
+func (c *Configuration) withoutIncluded() *Configuration {
+	if c.included == nil {
+		return c
+	}
+
+	mainOnly := *c
+	mainOnly.PrefixRules = []PrefixRule{}
+	for _, rule := range c.PrefixRules {
+		if !containsRule(c.included.rules, rule) {
+			mainOnly.PrefixRules = append(mainOnly.PrefixRules, rule)
+		}
+	}
+	mainOnly.InboundDirectories = []string{}
+	for _, dir := range c.InboundDirectories {
+		if !containsString(c.included.dirs, dir) {
+			mainOnly.InboundDirectories = append(mainOnly.InboundDirectories, dir)
+		}
+	}
+	return &mainOnly
+}
+
+// hasGlobMeta reports whether pattern contains glob metacharacters.
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+func containsRule(rules []PrefixRule, rule PrefixRule) bool {
+	for _, r := range rules {
+		if r.Prefix == rule.Prefix && r.OutboundDirectory == rule.OutboundDirectory && equalStrings(r.Extensions, rule.Extensions) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
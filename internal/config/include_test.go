@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeJSONFile writes v as JSON to path, creating parent directories.
+func writeJSONFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// TestLoadMergesIncludedRuleFiles verifies that a main file including two rule
+// files gets their rules and inbound directories, that the main file wins on
+// conflicts, and that Save writes only the main file's own entries.
+func TestLoadMergesIncludedRuleFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := filepath.Join(tempDir, "sorta-config.json")
+
+	writeJSONFile(t, mainPath, map[string]interface{}{
+		"inboundDirectories": []string{"/inbound/main"},
+		"prefixRules": []PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/main/invoices"},
+		},
+		"includes": []string{"rules/*.json"},
+	})
+	workRules := filepath.Join(tempDir, "rules", "a-work.json")
+	writeJSONFile(t, workRules, map[string]interface{}{
+		"inboundDirectories": []string{"/inbound/work", "/inbound/main"},
+		"prefixRules": []PrefixRule{
+			{Prefix: "invoice", OutboundDirectory: "/work/invoices"},
+			{Prefix: "Statement", OutboundDirectory: "/work/statements"},
+		},
+	})
+	homeRules := filepath.Join(tempDir, "rules", "b-home.json")
+	writeJSONFile(t, homeRules, map[string]interface{}{
+		"prefixRules": []PrefixRule{
+			{Prefix: "Receipt", OutboundDirectory: "/home/receipts"},
+			{Prefix: "Statement", OutboundDirectory: "/home/statements"},
+		},
+	})
+	homeBefore, _ := os.ReadFile(homeRules)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expectedRules := []PrefixRule{
+		{Prefix: "Invoice", OutboundDirectory: "/main/invoices"},
+		{Prefix: "Statement", OutboundDirectory: "/work/statements"},
+		{Prefix: "Receipt", OutboundDirectory: "/home/receipts"},
+	}
+	if !reflect.DeepEqual(cfg.PrefixRules, expectedRules) {
+		t.Errorf("Expected merged rules %+v, got %+v", expectedRules, cfg.PrefixRules)
+	}
+	expectedDirs := []string{"/inbound/main", "/inbound/work"}
+	if !reflect.DeepEqual(cfg.InboundDirectories, expectedDirs) {
+		t.Errorf("Expected merged inbound directories %v, got %v", expectedDirs, cfg.InboundDirectories)
+	}
+
+	// Changing an included rule saves it to the main file, where it overrides the include
+	cfg.FindPrefixRule("Receipt").OutboundDirectory = "/main/receipts"
+	cfg.AddPrefixRule(PrefixRule{Prefix: "Contract", OutboundDirectory: "/main/contracts"})
+	if err := Save(cfg, mainPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var saved Configuration
+	data, _ := os.ReadFile(mainPath)
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Saved config is not valid JSON: %v", err)
+	}
+	expectedSaved := []PrefixRule{
+		{Prefix: "Invoice", OutboundDirectory: "/main/invoices"},
+		{Prefix: "Receipt", OutboundDirectory: "/main/receipts"},
+		{Prefix: "Contract", OutboundDirectory: "/main/contracts"},
+	}
+	if !reflect.DeepEqual(saved.PrefixRules, expectedSaved) {
+		t.Errorf("Expected main file rules %+v, got %+v", expectedSaved, saved.PrefixRules)
+	}
+	if !reflect.DeepEqual(saved.InboundDirectories, []string{"/inbound/main"}) {
+		t.Errorf("Expected only the main inbound directory to be saved, got %v", saved.InboundDirectories)
+	}
+	if !reflect.DeepEqual(saved.Includes, []string{"rules/*.json"}) {
+		t.Errorf("Expected includes to be preserved, got %v", saved.Includes)
+	}
+	if homeAfter, _ := os.ReadFile(homeRules); string(homeAfter) != string(homeBefore) {
+		t.Error("Included file must not be modified by Save")
+	}
+
+	reloaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := reloaded.FindPrefixRule("Receipt").OutboundDirectory; got != "/main/receipts" {
+		t.Errorf("Expected main file override for Receipt, got %s", got)
+	}
+}
+
+// TestLoadIncludeErrors verifies that missing includes and include cycles fail clearly.
+func TestLoadIncludeErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	missingPath := filepath.Join(tempDir, "missing.json")
+	writeJSONFile(t, missingPath, map[string]interface{}{
+		"inboundDirectories": []string{"/inbound"},
+		"prefixRules":        []PrefixRule{{Prefix: "Invoice", OutboundDirectory: "/invoices"}},
+		"includes":           []string{"rules/absent.json"},
+	})
+
+	cyclePath := filepath.Join(tempDir, "cycle.json")
+	writeJSONFile(t, cyclePath, map[string]interface{}{
+		"inboundDirectories": []string{"/inbound"},
+		"prefixRules":        []PrefixRule{{Prefix: "Invoice", OutboundDirectory: "/invoices"}},
+		"includes":           []string{"rules/one.json"},
+	})
+	writeJSONFile(t, filepath.Join(tempDir, "rules", "one.json"), map[string]interface{}{
+		"includes": []string{"two.json"},
+	})
+	writeJSONFile(t, filepath.Join(tempDir, "rules", "two.json"), map[string]interface{}{
+		"includes": []string{"../cycle.json"},
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		message string
+	}{
+		{"missing", missingPath, "included file not found"},
+		{"cycle", cyclePath, "include cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.path)
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Type != IncludeError {
+				t.Fatalf("Expected IncludeError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
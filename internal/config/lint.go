@@ -0,0 +1,109 @@
+// Package config handles configuration loading and validation for Sorta.
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errFoundFile stops the walk in hasNoFiles at the first file.
+var errFoundFile = errors.New("found file")
+
+// Lint checks a configuration for quality problems that are not errors:
+// prefixes contained in other prefixes, outbound directories that do not
+// exist yet, outbound directories shared by several prefixes and inbound
+// directories without files. All findings have SeverityWarning.
+func Lint(cfg *Configuration) []ConfigValidationError {
+	var warnings []ConfigValidationError
+
+	// Prefixes contained in other prefixes make it easy to file a document
+	// under the wrong rule
+	for i, rule := range cfg.PrefixRules {
+		for j, other := range cfg.PrefixRules {
+			shorter, longer := strings.ToLower(rule.Prefix), strings.ToLower(other.Prefix)
+			if i == j || len(shorter) >= len(longer) || !strings.Contains(longer, shorter) {
+				continue
+			}
+			warnings = append(warnings, ConfigValidationError{
+				Field: formatField("prefixRules", i) + ".prefix",
+				Message: "prefix \"" + rule.Prefix + "\" is contained in prefix \"" + other.Prefix + "\" (rule at index " + itoa(j) +
+					"); files are matched to the longest prefix, so check which rule files like \"" + other.Prefix + " ...\" are meant for, or rename one of the prefixes",
+				Severity: SeverityWarning,
+			})
+		}
+	}
+
+	// Outbound directories that do not exist yet are created on the first move
+	for i, rule := range cfg.PrefixRules {
+		root := OutboundRoot(rule.OutboundDirectory)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			warnings = append(warnings, ConfigValidationError{
+				Field:    formatField("prefixRules", i) + ".outboundDirectory",
+				Message:  "outbound directory does not exist yet and will be created on the first run: " + root + " (check the path for typos)",
+				Severity: SeverityWarning,
+			})
+		}
+	}
+
+	// Several prefixes filing into one directory. Directories with a {prefix}
+	// token render differently for each prefix.
+	firstRule := make(map[string]int) // cleaned outbound directory -> first index
+	for i, rule := range cfg.PrefixRules {
+		if strings.Contains(rule.OutboundDirectory, TokenPrefix) {
+			continue
+		}
+		dir := filepath.Clean(rule.OutboundDirectory)
+		first, exists := firstRule[dir]
+		if !exists {
+			firstRule[dir] = i
+			continue
+		}
+		warnings = append(warnings, ConfigValidationError{
+			Field: formatField("prefixRules", i) + ".outboundDirectory",
+			Message: "prefix \"" + rule.Prefix + "\" uses the same outbound directory as prefix \"" + cfg.PrefixRules[first].Prefix + "\" (rule at index " + itoa(first) +
+				"): " + rule.OutboundDirectory + "; give each prefix its own directory, or merge the rules if they are the same kind of document",
+			Severity: SeverityWarning,
+		})
+	}
+
+	// Inbound directories without any file to organize
+	for i, dir := range cfg.InboundDirectories {
+		empty, err := hasNoFiles(dir)
+		if err != nil || !empty {
+			continue
+		}
+		warnings = append(warnings, ConfigValidationError{
+			Field:    formatField("inboundDirectories", i),
+			Message:  "inbound directory has no files: " + dir + " (check that this is the folder your files arrive in, or remove it if it is no longer used)",
+			Severity: SeverityWarning,
+		})
+	}
+
+	return warnings
+}
+
+// hasNoFiles reports whether dir and its subdirectories contain no files.
+func hasNoFiles(dir string) (bool, error) {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return errFoundFile
+		}
+		return nil
+	})
+	if errors.Is(err, errFoundFile) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
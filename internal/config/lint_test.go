@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLintWarnsAboutContainedPrefixes verifies that a prefix contained in
+// another prefix is reported, naming both prefixes.
+func TestLintWarnsAboutContainedPrefixes(t *testing.T) {
+	tempDir := t.TempDir()
+	inbound := filepath.Join(tempDir, "inbound")
+	os.MkdirAll(inbound, 0755)
+	os.WriteFile(filepath.Join(inbound, "Invoice 2024-01-15 Acme.pdf"), []byte("invoice"), 0644)
+	invDir := filepath.Join(tempDir, "Inv")
+	invoiceDir := filepath.Join(tempDir, "Invoices")
+	os.MkdirAll(invDir, 0755)
+	os.MkdirAll(invoiceDir, 0755)
+
+	cfg := &Configuration{
+		InboundDirectories: []string{inbound},
+		PrefixRules: []PrefixRule{
+			{Prefix: "Inv", OutboundDirectory: invDir},
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	}
+
+	warnings := Lint(cfg)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %+v", len(warnings), warnings)
+	}
+	warning := warnings[0]
+	if warning.Field != "prefixRules[0].prefix" || warning.Severity != SeverityWarning {
+		t.Errorf("Expected a warning on prefixRules[0].prefix, got %+v", warning)
+	}
+	if !strings.Contains(warning.Message, `"Inv"`) || !strings.Contains(warning.Message, `"Invoice"`) {
+		t.Errorf("Expected the warning to name both prefixes, got %q", warning.Message)
+	}
+
+	// Distinct prefixes produce no warning
+	cfg.PrefixRules[0].Prefix = "Receipt"
+	if warnings := Lint(cfg); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
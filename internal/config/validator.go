@@ -112,9 +112,10 @@ func ValidatePaths(cfg *Configuration) []ConfigValidationError {
 		}
 	}
 
-	// Check outbound directories exist or parent is writable
+	// Check outbound directories exist or parent is writable. For directories
+	// with tokens, the part before the first token is checked.
 	for i, rule := range cfg.PrefixRules {
-		outDir := rule.OutboundDirectory
+		outDir := OutboundRoot(rule.OutboundDirectory)
 		info, err := os.Stat(outDir)
 
 		if err == nil {
@@ -303,6 +304,33 @@ func ValidatePolicies(cfg *Configuration) []ConfigValidationError {
 		}
 	}
 
+	// Validate date position if set
+	if cfg.DatePosition != "" && cfg.DatePosition != DatePositionAfterPrefix && cfg.DatePosition != DatePositionAnywhere {
+		errors = append(errors, ConfigValidationError{
+			Field:    "datePosition",
+			Message:  "invalid date position: \"" + cfg.DatePosition + "\". Must be \"after-prefix\" or \"anywhere\"",
+			Severity: SeverityError,
+		})
+	}
+
+	// Validate two-digit year pivot if set
+	if cfg.TwoDigitYearPivot != nil && (*cfg.TwoDigitYearPivot < 0 || *cfg.TwoDigitYearPivot > 100) {
+		errors = append(errors, ConfigValidationError{
+			Field:    "twoDigitYearPivot",
+			Message:  "twoDigitYearPivot must be between 0 and 100",
+			Severity: SeverityError,
+		})
+	}
+
+	// Validate zero-byte action if set
+	if cfg.ZeroByteAction != "" && cfg.ZeroByteAction != ZeroByteProcess && cfg.ZeroByteAction != ZeroByteSkip && cfg.ZeroByteAction != ZeroByteReview {
+		errors = append(errors, ConfigValidationError{
+			Field:    "zeroByteAction",
+			Message:  "invalid zero-byte action: \"" + cfg.ZeroByteAction + "\". Must be \"process\", \"skip\", or \"review\"",
+			Severity: SeverityError,
+		})
+	}
+
 	// Validate scanDepth is non-negative if set
 	if cfg.ScanDepth != nil && *cfg.ScanDepth < 0 {
 		errors = append(errors, ConfigValidationError{
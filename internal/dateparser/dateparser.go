@@ -42,6 +42,9 @@ type IsoDate struct {
 // isoDatePattern matches the YYYY-MM-DD format strictly.
 var isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
 
+// twoDigitYearPattern matches the YY-MM-DD format strictly.
+var twoDigitYearPattern = regexp.MustCompile(`^(\d{2})-(\d{2})-(\d{2})$`)
+
 // ParseIsoDate parses a string in YYYY-MM-DD format and returns an IsoDate.
 // It validates the format strictly and checks that the date is valid
 // (correct month range, day range for the month, and leap year handling).
@@ -56,6 +59,37 @@ func ParseIsoDate(segment string) (*IsoDate, error) {
 	month, _ := strconv.Atoi(matches[2])
 	day, _ := strconv.Atoi(matches[3])
 
+	return newDate(year, month, day)
+}
+
+// ParseTwoDigitYearDate parses a string in YY-MM-DD format and returns an
+// IsoDate with the full year: years below pivot are in the 2000s, the others
+// in the 1900s. The date is validated like in ParseIsoDate.
+func ParseTwoDigitYearDate(segment string, pivot int) (*IsoDate, error) {
+	matches := twoDigitYearPattern.FindStringSubmatch(segment)
+	if matches == nil {
+		return nil, &DateParseError{Type: InvalidFormat}
+	}
+
+	year, _ := strconv.Atoi(matches[1])
+	month, _ := strconv.Atoi(matches[2])
+	day, _ := strconv.Atoi(matches[3])
+
+	if year < pivot {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	return newDate(year, month, day)
+}
+
+// String returns the date in YYYY-MM-DD format.
+func (d *IsoDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// newDate validates the month and day of a date and returns it as an IsoDate.
+func newDate(year, month, day int) (*IsoDate, error) {
 	// Validate month range (01-12)
 	if month < 1 || month > 12 {
 		return nil, &DateParseError{
@@ -91,3 +91,36 @@ func TestValidIsoDateExtraction(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// TestParseTwoDigitYearDate verifies that two-digit years are expanded around
+// the pivot and that the date is validated like an ISO date.
+func TestParseTwoDigitYearDate(t *testing.T) {
+	tests := []struct {
+		segment string
+		pivot   int
+		want    string
+	}{
+		{"24-01-15", 69, "2024-01-15"},
+		{"68-02-29", 69, "2068-02-29"},
+		{"69-02-28", 69, "1969-02-28"},
+		{"99-12-31", 69, "1999-12-31"},
+		{"00-01-01", 0, "1900-01-01"},
+		{"99-01-01", 100, "2099-01-01"},
+	}
+	for _, tc := range tests {
+		date, err := ParseTwoDigitYearDate(tc.segment, tc.pivot)
+		if err != nil {
+			t.Errorf("%s (pivot %d): unexpected error %v", tc.segment, tc.pivot, err)
+			continue
+		}
+		if date.String() != tc.want {
+			t.Errorf("%s (pivot %d): expected %s, got %s", tc.segment, tc.pivot, tc.want, date)
+		}
+	}
+
+	for _, segment := range []string{"2024-01-15", "24-1-15", "24-13-01", "69-02-29"} {
+		if _, err := ParseTwoDigitYearDate(segment, 69); err == nil {
+			t.Errorf("%s: expected an error", segment)
+		}
+	}
+}
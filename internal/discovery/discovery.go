@@ -2,9 +2,12 @@
 package discovery
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	"sorta/internal/config"
 )
@@ -15,12 +18,42 @@ type DiscoveredRule struct {
 	TargetDirectory string
 }
 
+// ConflictingRule represents a prefix found under more than one candidate directory.
+// Conflicting rules are never added automatically; the user must choose a target.
+type ConflictingRule struct {
+	Prefix            string
+	TargetDirectories []string // All candidate directories containing the prefix, in scan order
+}
+
 // DiscoveryResult contains the results of a discovery scan.
 type DiscoveryResult struct {
-	NewRules      []DiscoveredRule // Rules to be added
-	SkippedRules  []DiscoveredRule // Rules skipped (duplicate prefix)
-	ScannedDirs   int              // Number of directories scanned
-	FilesAnalyzed int              // Number of files analyzed
+	NewRules         []DiscoveredRule  // Rules to be added
+	SkippedRules     []DiscoveredRule  // Rules skipped (duplicate prefix)
+	ConflictingRules []ConflictingRule // Prefixes found in multiple candidate directories
+	ScannedDirs      int               // Number of directories scanned
+	FilesAnalyzed    int               // Number of files analyzed
+	ScanErrors       []error           // Directories skipped because they could not be read
+	Coverage         Coverage          // How many analyzed files are covered by a rule
+}
+
+// Coverage counts the analyzed files that are and are not covered by a rule
+// once discovery is done: a file is covered when its name matches the
+// prefix-and-date pattern and its prefix has a new or already configured rule.
+// Files whose prefix is a conflict are not covered, since no rule is added.
+type Coverage struct {
+	FilesMatched   int            // Files covered by a rule
+	FilesUnmatched int            // Files not covered by any rule
+	Rules          map[string]int // Files covered by each rule, by prefix
+}
+
+// Percent returns the share of analyzed files that matched the pattern, from
+// 0 to 100. It returns 0 when no files were analyzed.
+func (c Coverage) Percent() float64 {
+	total := c.FilesMatched + c.FilesUnmatched
+	if total == 0 {
+		return 0
+	}
+	return float64(c.FilesMatched) * 100 / float64(total)
 }
 
 // DiscoveryEventType represents the type of discovery event.
@@ -33,13 +66,17 @@ const (
 	EventTypeFile DiscoveryEventType = "file"
 	// EventTypePattern indicates a pattern was found.
 	EventTypePattern DiscoveryEventType = "pattern"
+	// EventTypeNoMatch indicates an analyzed file did not match the pattern.
+	EventTypeNoMatch DiscoveryEventType = "no_match"
 )
 
 // DiscoveryEvent represents a discovery progress event.
 type DiscoveryEvent struct {
-	Type    DiscoveryEventType // "dir", "file", "pattern"
+	Type    DiscoveryEventType // "dir", "file", "pattern", "no_match"
 	Path    string             // Path being processed
-	Pattern string             // Only for "pattern" type - the detected pattern
+	Pattern string             // For "pattern" type, and "file" type on a match - the detected pattern
+	Date    string             // For "file" type on a match - the extracted date
+	Reason  string             // Only for "no_match" type - why the file did not match
 	Current int                // Current progress count
 	Total   int                // Total items (if known, 0 otherwise)
 }
@@ -49,12 +86,61 @@ type DiscoveryCallback func(event DiscoveryEvent)
 
 // DiscoverOptions configures the discovery operation
 type DiscoverOptions struct {
-	MaxDepth    int  // -1 for unlimited, 0 for immediate only, N for N levels
-	Interactive bool // Whether to prompt for each rule
+	MaxDepth    int    // -1 for unlimited, 0 for immediate only, N for N levels
+	Interactive bool   // Whether to prompt for each rule
+	MergeTarget string // If set, new rules point to <MergeTarget>/<prefix> instead of where the prefix was found
+
+	// PrefixTransform changes the case of discovered prefixes before they
+	// are proposed as rules. Empty keeps them as found.
+	PrefixTransform PrefixTransform
+}
+
+// PrefixTransform selects how the case of a discovered prefix is normalized.
+// Rules match filenames case-insensitively, so a transformed prefix still
+// matches the files it was found in.
+type PrefixTransform string
+
+const (
+	PrefixTransformNone  PrefixTransform = "none"  // Keep prefixes as found
+	PrefixTransformTitle PrefixTransform = "title" // "INVOICE" -> "Invoice"
+	PrefixTransformUpper PrefixTransform = "upper" // "Invoice" -> "INVOICE"
+	PrefixTransformLower PrefixTransform = "lower" // "Invoice" -> "invoice"
+)
+
+// ParsePrefixTransform returns the PrefixTransform named s.
+func ParsePrefixTransform(s string) (PrefixTransform, error) {
+	switch t := PrefixTransform(strings.ToLower(s)); t {
+	case PrefixTransformNone, PrefixTransformTitle, PrefixTransformUpper, PrefixTransformLower:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid prefix transform %q (use title, upper, lower or none)", s)
+}
+
+// Apply returns prefix with its case changed by t. Title case upper-cases the
+// first letter of each space-separated word and lower-cases the rest.
+func (t PrefixTransform) Apply(prefix string) string {
+	switch t {
+	case PrefixTransformUpper:
+		return strings.ToUpper(prefix)
+	case PrefixTransformLower:
+		return strings.ToLower(prefix)
+	case PrefixTransformTitle:
+		runes := []rune(prefix)
+		for i, r := range runes {
+			if i == 0 || runes[i-1] == ' ' {
+				runes[i] = unicode.ToUpper(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+		}
+		return string(runes)
+	}
+	return prefix
 }
 
 // scanTargetCandidates finds immediate subdirectories of the scan directory.
-// Returns only immediate child directories, not nested ones.
+// Returns only immediate child directories, not nested ones, sorted by path so
+// discovery results do not depend on the order the OS lists them in.
 func scanTargetCandidates(scanDir string) ([]string, error) {
 	entries, err := os.ReadDir(scanDir)
 	if err != nil {
@@ -67,6 +153,7 @@ func scanTargetCandidates(scanDir string) ([]string, error) {
 			candidates = append(candidates, filepath.Join(scanDir, entry.Name()))
 		}
 	}
+	sort.Strings(candidates)
 
 	return candidates, nil
 }
@@ -93,6 +180,28 @@ func analyzeDirectoryWithCallback(dir string, callback DiscoveryCallback, fileCo
 // Prefixes are extracted only from files, never from directory names.
 // ISO-date directories (starting with YYYY-MM-DD) are skipped regardless of depth setting.
 func analyzeDirectoryWithDepth(dir string, maxDepth int, callback DiscoveryCallback, fileCounter *int) ([]string, error) {
+	scan, err := scanDirectory(dir, maxDepth, callback, fileCounter)
+	if err != nil {
+		return nil, err
+	}
+	return scan.Prefixes, nil
+}
+
+// directoryScan is the outcome of analyzing one candidate directory.
+type directoryScan struct {
+	Prefixes       []string       // Unique prefixes found, sorted
+	PrefixFiles    map[string]int // Files per prefix (lowercase) that matched the pattern
+	FilesUnmatched int            // Files whose name did not match the pattern
+	ScanErrors     []error        // Directories skipped because they could not be read
+}
+
+// scanDirectory implements analyzeDirectoryWithDepth, also counting the files
+// found for each prefix and those that did not match the pattern. Directories
+// that cannot be read for lack of permission are skipped and recorded in
+// ScanErrors. The prefixes are sorted so results are reproducible across
+// platforms.
+func scanDirectory(dir string, maxDepth int, callback DiscoveryCallback, fileCounter *int) (*directoryScan, error) {
+	scan := &directoryScan{PrefixFiles: make(map[string]int)}
 	prefixSet := make(map[string]bool)
 
 	// Clean the base directory path for consistent depth calculation
@@ -100,7 +209,10 @@ func analyzeDirectoryWithDepth(dir string, maxDepth int, callback DiscoveryCallb
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			// Skip directories we can't access
+			// Skip directories we can't access, recording permission problems
+			if os.IsPermission(err) {
+				scan.ScanErrors = append(scan.ScanErrors, err)
+			}
 			return nil
 		}
 
@@ -151,18 +263,35 @@ func analyzeDirectoryWithDepth(dir string, maxDepth int, callback DiscoveryCallb
 			return nil
 		}
 
+		// Extract prefix from filename
+		prefix, date, matched := ExtractPrefixAndDate(info.Name())
+
+		if matched {
+			scan.PrefixFiles[strings.ToLower(prefix)]++
+		} else {
+			scan.FilesUnmatched++
+		}
+
 		// Call callback for file being analyzed
 		if callback != nil && fileCounter != nil {
 			*fileCounter++
 			callback(DiscoveryEvent{
 				Type:    EventTypeFile,
 				Path:    path,
+				Pattern: prefix,
+				Date:    date,
 				Current: *fileCounter,
 			})
 		}
 
-		// Extract prefix from filename
-		prefix, matched := ExtractPrefixFromFilename(info.Name())
+		if !matched && callback != nil {
+			callback(DiscoveryEvent{
+				Type:   EventTypeNoMatch,
+				Path:   path,
+				Reason: NoMatchReason(info.Name()),
+			})
+		}
+
 		if matched {
 			// Check if this is a new prefix (case-insensitive)
 			lowerPrefix := strings.ToLower(prefix)
@@ -190,12 +319,12 @@ func analyzeDirectoryWithDepth(dir string, maxDepth int, callback DiscoveryCallb
 	}
 
 	// Convert set to slice
-	var prefixes []string
 	for prefix := range prefixSet {
-		prefixes = append(prefixes, prefix)
+		scan.Prefixes = append(scan.Prefixes, prefix)
 	}
+	sort.Strings(scan.Prefixes)
 
-	return prefixes, nil
+	return scan, nil
 }
 
 // Discover scans a directory and returns discovered prefix rules.
@@ -213,8 +342,9 @@ func Discover(scanDir string, existingConfig *config.Configuration) (*DiscoveryR
 // The callback is called for each directory scanned, file analyzed, and pattern found.
 func DiscoverWithCallback(scanDir string, existingConfig *config.Configuration, callback DiscoveryCallback) (*DiscoveryResult, error) {
 	result := &DiscoveryResult{
-		NewRules:     []DiscoveredRule{},
-		SkippedRules: []DiscoveredRule{},
+		NewRules:         []DiscoveredRule{},
+		SkippedRules:     []DiscoveredRule{},
+		ConflictingRules: []ConflictingRule{},
 	}
 
 	// Get immediate subdirectories as candidates
@@ -223,9 +353,8 @@ func DiscoverWithCallback(scanDir string, existingConfig *config.Configuration,
 		return nil, err
 	}
 
-	// Track which prefixes we've already seen during this discovery
-	// to avoid duplicates within the same scan
-	seenPrefixes := make(map[string]bool)
+	// Track every candidate directory each prefix was found in
+	targets := newPrefixTargets("")
 
 	// Track file count for progress reporting
 	fileCounter := 0
@@ -244,7 +373,7 @@ func DiscoverWithCallback(scanDir string, existingConfig *config.Configuration,
 		}
 
 		// Analyze the directory for prefixes with callback support
-		prefixes, err := analyzeDirectoryWithCallback(candidateDir, callback, &fileCounter)
+		scan, err := scanDirectory(candidateDir, -1, callback, &fileCounter)
 		if err != nil {
 			// Log warning but continue with other directories
 			continue
@@ -258,30 +387,12 @@ func DiscoverWithCallback(scanDir string, existingConfig *config.Configuration,
 			return nil
 		})
 
-		// Process each discovered prefix
-		for _, prefix := range prefixes {
-			lowerPrefix := strings.ToLower(prefix)
-
-			// Skip if we've already seen this prefix in this scan
-			if seenPrefixes[lowerPrefix] {
-				continue
-			}
-			seenPrefixes[lowerPrefix] = true
-
-			rule := DiscoveredRule{
-				Prefix:          prefix,
-				TargetDirectory: candidateDir,
-			}
-
-			// Check if prefix already exists in config (case-insensitive)
-			if existingConfig != nil && existingConfig.HasPrefix(prefix) {
-				result.SkippedRules = append(result.SkippedRules, rule)
-			} else {
-				result.NewRules = append(result.NewRules, rule)
-			}
-		}
+		targets.addScan(scan, candidateDir)
+		result.ScanErrors = append(result.ScanErrors, scan.ScanErrors...)
 	}
 
+	targets.resolve(result, existingConfig, "")
+
 	return result, nil
 }
 
@@ -294,8 +405,9 @@ func DiscoverWithCallback(scanDir string, existingConfig *config.Configuration,
 func DiscoverWithOptions(scanDir string, existingConfig *config.Configuration,
 	opts DiscoverOptions, callback DiscoveryCallback) (*DiscoveryResult, error) {
 	result := &DiscoveryResult{
-		NewRules:     []DiscoveredRule{},
-		SkippedRules: []DiscoveredRule{},
+		NewRules:         []DiscoveredRule{},
+		SkippedRules:     []DiscoveredRule{},
+		ConflictingRules: []ConflictingRule{},
 	}
 
 	// Get immediate subdirectories as candidates
@@ -304,9 +416,8 @@ func DiscoverWithOptions(scanDir string, existingConfig *config.Configuration,
 		return nil, err
 	}
 
-	// Track which prefixes we've already seen during this discovery
-	// to avoid duplicates within the same scan
-	seenPrefixes := make(map[string]bool)
+	// Track every candidate directory each prefix was found in
+	targets := newPrefixTargets(opts.PrefixTransform)
 
 	// Track file count for progress reporting
 	fileCounter := 0
@@ -325,7 +436,7 @@ func DiscoverWithOptions(scanDir string, existingConfig *config.Configuration,
 		}
 
 		// Analyze the directory for prefixes with depth limiting
-		prefixes, err := analyzeDirectoryWithDepth(candidateDir, opts.MaxDepth, callback, &fileCounter)
+		scan, err := scanDirectory(candidateDir, opts.MaxDepth, callback, &fileCounter)
 		if err != nil {
 			// Log warning but continue with other directories
 			continue
@@ -334,31 +445,135 @@ func DiscoverWithOptions(scanDir string, existingConfig *config.Configuration,
 		// Count files analyzed (respecting depth limit)
 		countFilesWithDepth(candidateDir, opts.MaxDepth, &result.FilesAnalyzed)
 
-		// Process each discovered prefix
-		for _, prefix := range prefixes {
-			lowerPrefix := strings.ToLower(prefix)
+		targets.addScan(scan, candidateDir)
+		result.ScanErrors = append(result.ScanErrors, scan.ScanErrors...)
+	}
 
-			// Skip if we've already seen this prefix in this scan
-			if seenPrefixes[lowerPrefix] {
-				continue
-			}
-			seenPrefixes[lowerPrefix] = true
+	targets.resolve(result, existingConfig, opts.MergeTarget)
 
-			rule := DiscoveredRule{
-				Prefix:          prefix,
-				TargetDirectory: candidateDir,
-			}
+	return result, nil
+}
 
-			// Check if prefix already exists in config (case-insensitive)
-			if existingConfig != nil && existingConfig.HasPrefix(prefix) {
-				result.SkippedRules = append(result.SkippedRules, rule)
-			} else {
-				result.NewRules = append(result.NewRules, rule)
-			}
+// prefixTargets records, per prefix (case-insensitive), every candidate directory
+// the prefix was found in. Prefixes are kept in first-seen order.
+type prefixTargets struct {
+	order     []string            // Lowercase prefixes in first-seen order
+	display   map[string]string   // Lowercase prefix -> prefix as first seen
+	dirs      map[string][]string // Lowercase prefix -> candidate directories
+	files     map[string]int      // Lowercase prefix -> files found with it
+	unmatched int                 // Files that cannot be covered by any rule
+	transform PrefixTransform     // Applied to each prefix before it is recorded
+}
+
+// newPrefixTargets creates an empty prefixTargets that records prefixes with
+// transform applied.
+func newPrefixTargets(transform PrefixTransform) *prefixTargets {
+	return &prefixTargets{
+		display:   make(map[string]string),
+		dirs:      make(map[string][]string),
+		files:     make(map[string]int),
+		transform: transform,
+	}
+}
+
+// addScan records the prefixes and file counts of scan, the analysis of
+// candidateDir.
+func (p *prefixTargets) addScan(scan *directoryScan, candidateDir string) {
+	for _, prefix := range scan.Prefixes {
+		p.add(prefix, candidateDir)
+	}
+	p.unmatched += scan.FilesUnmatched
+	for prefix, n := range scan.PrefixFiles {
+		if key, ok := p.key(prefix); ok {
+			p.files[strings.ToLower(key)] += n
+		} else {
+			p.unmatched += n
 		}
 	}
+}
 
-	return result, nil
+// key returns the prefix as it is recorded, normalized the same way
+// AddPrefixRule stores it and with its case transformed. It reports false for
+// prefixes that are empty after normalization.
+func (p *prefixTargets) key(prefix string) (string, bool) {
+	prefix, err := config.NormalizePrefix(prefix)
+	if err != nil {
+		return "", false
+	}
+	return p.transform.Apply(prefix), true
+}
+
+// add records that prefix was found in candidateDir.
+// The prefix is normalized the same way AddPrefixRule stores it, then its case
+// is transformed; prefixes that are empty after normalization are ignored.
+// Repeated prefixes within the same directory are recorded once.
+func (p *prefixTargets) add(prefix, candidateDir string) {
+	prefix, ok := p.key(prefix)
+	if !ok {
+		return
+	}
+	lowerPrefix := strings.ToLower(prefix)
+
+	dirs, seen := p.dirs[lowerPrefix]
+	if !seen {
+		p.order = append(p.order, lowerPrefix)
+		p.display[lowerPrefix] = prefix
+	}
+
+	for _, dir := range dirs {
+		if dir == candidateDir {
+			return
+		}
+	}
+	p.dirs[lowerPrefix] = append(dirs, candidateDir)
+}
+
+// resolve sorts the recorded prefixes into new, skipped, and conflicting rules.
+// Prefixes already configured are skipped; prefixes found in more than one
+// candidate directory are reported as conflicts instead of being added. When
+// mergeTarget is set, every rule points to <mergeTarget>/<prefix>, so a prefix
+// found in several directories is a single new rule rather than a conflict.
+// The files of new and skipped rules are counted as covered, those of
+// conflicts as not covered.
+func (p *prefixTargets) resolve(result *DiscoveryResult, existingConfig *config.Configuration, mergeTarget string) {
+	result.Coverage = Coverage{FilesUnmatched: p.unmatched, Rules: make(map[string]int)}
+	covered := func(prefix, lowerPrefix string) {
+		result.Coverage.FilesMatched += p.files[lowerPrefix]
+		result.Coverage.Rules[prefix] += p.files[lowerPrefix]
+	}
+
+	for _, lowerPrefix := range p.order {
+		prefix := p.display[lowerPrefix]
+		dirs := p.dirs[lowerPrefix]
+
+		rule := DiscoveredRule{
+			Prefix:          prefix,
+			TargetDirectory: dirs[0],
+		}
+		if mergeTarget != "" {
+			rule.TargetDirectory = filepath.Join(mergeTarget, prefix)
+			dirs = dirs[:1]
+		}
+
+		// Check if prefix already exists in config (case-insensitive)
+		if existingConfig != nil && existingConfig.HasPrefix(prefix) {
+			result.SkippedRules = append(result.SkippedRules, rule)
+			covered(prefix, lowerPrefix)
+			continue
+		}
+
+		if len(dirs) > 1 {
+			result.ConflictingRules = append(result.ConflictingRules, ConflictingRule{
+				Prefix:            prefix,
+				TargetDirectories: dirs,
+			})
+			result.Coverage.FilesUnmatched += p.files[lowerPrefix]
+			continue
+		}
+
+		result.NewRules = append(result.NewRules, rule)
+		covered(prefix, lowerPrefix)
+	}
 }
 
 // countFilesWithDepth counts files within a directory up to maxDepth levels.
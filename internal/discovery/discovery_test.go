@@ -1,9 +1,11 @@
 package discovery
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/leanovate/gopter/prop"
 
 	"sorta/internal/config"
+	"sorta/internal/matcher"
 )
 
 // Feature: config-auto-discover, Property 4: Candidate Directory Detection
@@ -1696,3 +1699,409 @@ func TestDepthLimitingEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// TestDiscoverConflictingTargetDirectories tests that a prefix found under two
+// candidate directories is reported as a conflict and not added as a new rule.
+func TestDiscoverConflictingTargetDirectories(t *testing.T) {
+	scanDir, err := os.MkdirTemp("", "sorta-conflict-*")
+	if err != nil {
+		t.Fatalf("Failed to create scan dir: %v", err)
+	}
+	defer os.RemoveAll(scanDir)
+
+	// Same prefix under two candidate directories, unique prefix under one
+	files := map[string]string{
+		"Invoices": "Invoice 2024-01-15 Acme.pdf",
+		"Archive":  "Invoice 2023-06-01 Beta.pdf",
+		"Receipts": "Receipt 2024-02-20 Store.pdf",
+	}
+	for dir, name := range files {
+		candidateDir := filepath.Join(scanDir, dir)
+		if err := os.MkdirAll(candidateDir, 0755); err != nil {
+			t.Fatalf("Failed to create candidate dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(candidateDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		discover func() (*DiscoveryResult, error)
+	}{
+		{"DiscoverWithCallback", func() (*DiscoveryResult, error) {
+			return DiscoverWithCallback(scanDir, nil, nil)
+		}},
+		{"DiscoverWithOptions", func() (*DiscoveryResult, error) {
+			return DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1}, nil)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.discover()
+			if err != nil {
+				t.Fatalf("Discovery failed: %v", err)
+			}
+
+			if len(result.ConflictingRules) != 1 {
+				t.Fatalf("Expected 1 conflicting rule, got %d", len(result.ConflictingRules))
+			}
+			conflict := result.ConflictingRules[0]
+			if !strings.EqualFold(conflict.Prefix, "Invoice") {
+				t.Errorf("Expected conflicting prefix 'Invoice', got %q", conflict.Prefix)
+			}
+			if len(conflict.TargetDirectories) != 2 {
+				t.Fatalf("Expected 2 target directories, got %v", conflict.TargetDirectories)
+			}
+			expected := map[string]bool{
+				filepath.Join(scanDir, "Invoices"): true,
+				filepath.Join(scanDir, "Archive"):  true,
+			}
+			for _, dir := range conflict.TargetDirectories {
+				if !expected[dir] {
+					t.Errorf("Unexpected target directory %q", dir)
+				}
+			}
+
+			// The conflicting prefix must not be auto-added
+			for _, rule := range result.NewRules {
+				if strings.EqualFold(rule.Prefix, "Invoice") {
+					t.Errorf("Conflicting prefix should not be a new rule: %+v", rule)
+				}
+			}
+
+			if len(result.NewRules) != 1 || !strings.EqualFold(result.NewRules[0].Prefix, "Receipt") {
+				t.Errorf("Expected only 'Receipt' as a new rule, got %+v", result.NewRules)
+			}
+		})
+	}
+}
+
+// TestDiscoverMergeTarget tests that with a merge target every new rule points
+// to <target>/<prefix>, including a prefix found in more than one directory.
+func TestDiscoverMergeTarget(t *testing.T) {
+	scanDir := t.TempDir()
+	files := map[string]string{
+		"Invoices": "Invoice 2024-01-15 Acme.pdf",
+		"Archive":  "Invoice 2023-06-01 Beta.pdf",
+		"Receipts": "Receipt 2024-02-20 Store.pdf",
+	}
+	for dir, name := range files {
+		candidateDir := filepath.Join(scanDir, dir)
+		if err := os.MkdirAll(candidateDir, 0755); err != nil {
+			t.Fatalf("Failed to create candidate dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(candidateDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	mergeTarget := filepath.Join(scanDir, "merged")
+	result, err := DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1, MergeTarget: mergeTarget}, nil)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	if len(result.ConflictingRules) != 0 {
+		t.Errorf("Expected no conflicts with a merge target, got %+v", result.ConflictingRules)
+	}
+	if len(result.NewRules) != 2 {
+		t.Fatalf("Expected 2 new rules, got %+v", result.NewRules)
+	}
+	for _, rule := range result.NewRules {
+		if expected := filepath.Join(mergeTarget, rule.Prefix); rule.TargetDirectory != expected {
+			t.Errorf("Expected rule %q to point to %q, got %q", rule.Prefix, expected, rule.TargetDirectory)
+		}
+	}
+
+	// Files are not moved
+	if _, err := os.Stat(filepath.Join(scanDir, "Archive", "Invoice 2023-06-01 Beta.pdf")); err != nil {
+		t.Errorf("Expected discovered file to stay in place: %v", err)
+	}
+}
+
+// TestDiscoverPrefixTransform tests that a prefix transform changes the case of
+// the proposed rule's prefix, and that the rule still matches the file it was
+// found from.
+func TestDiscoverPrefixTransform(t *testing.T) {
+	scanDir := t.TempDir()
+	candidateDir := filepath.Join(scanDir, "Invoices")
+	if err := os.MkdirAll(candidateDir, 0755); err != nil {
+		t.Fatalf("Failed to create candidate dir: %v", err)
+	}
+	filename := "INVOICE 2024-01-15 x.pdf"
+	if err := os.WriteFile(filepath.Join(candidateDir, filename), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	transform, err := ParsePrefixTransform("title")
+	if err != nil {
+		t.Fatalf("ParsePrefixTransform failed: %v", err)
+	}
+	result, err := DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1, PrefixTransform: transform}, nil)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+	if len(result.NewRules) != 1 || result.NewRules[0].Prefix != "Invoice" {
+		t.Fatalf("Expected a single rule with prefix %q, got %+v", "Invoice", result.NewRules)
+	}
+
+	rules := []config.PrefixRule{{Prefix: result.NewRules[0].Prefix, OutboundDirectory: candidateDir}}
+	if match := matcher.Match(filename, rules); !match.Matched {
+		t.Errorf("Expected %q to match the transformed rule", filename)
+	}
+
+	for input, expected := range map[string]string{"upper": "INVOICE", "lower": "invoice", "none": "INVOICE"} {
+		transform, _ := ParsePrefixTransform(input)
+		if got := transform.Apply("INVOICE"); got != expected {
+			t.Errorf("Expected %s transform to give %q, got %q", input, expected, got)
+		}
+	}
+	if got := PrefixTransformTitle.Apply("ACME corp"); got != "Acme Corp" {
+		t.Errorf("Expected title transform to give %q, got %q", "Acme Corp", got)
+	}
+	if _, err := ParsePrefixTransform("camel"); err == nil {
+		t.Error("Expected an error for an unknown transform")
+	}
+}
+
+// TestCheckScanSizeTriggersGuard verifies that the discovery safety guard fires
+// when the directory count exceeds the threshold.
+func TestCheckScanSizeTriggersGuard(t *testing.T) {
+	scanDir := t.TempDir()
+	for _, name := range []string{"Invoices", "Receipts"} {
+		if err := os.MkdirAll(filepath.Join(scanDir, name), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	// Stub the counter to simulate a huge tree under each candidate
+	originalCounter := dirCounter
+	dirCounter = func(dir string, maxDepth, limit int) int { return limit + 1 }
+	defer func() { dirCounter = originalCounter }()
+
+	estimate, err := CheckScanSize(scanDir, -1, 100)
+
+	var tooLarge *ScanTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected *ScanTooLargeError, got %v", err)
+	}
+	if !estimate.Exceeded {
+		t.Error("Expected estimate to be marked as exceeded")
+	}
+	if estimate.CandidateDirs != 2 {
+		t.Errorf("Expected 2 candidate dirs, got %d", estimate.CandidateDirs)
+	}
+
+	// A threshold of 0 disables the guard
+	if _, err := CheckScanSize(scanDir, -1, 0); err != nil {
+		t.Errorf("Expected no error with guard disabled, got %v", err)
+	}
+}
+
+// TestCheckScanSizeAllowsSmallTrees verifies that small trees pass the guard.
+func TestCheckScanSizeAllowsSmallTrees(t *testing.T) {
+	scanDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(scanDir, "Invoices", "2024"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	estimate, err := CheckScanSize(scanDir, -1, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if estimate.TotalDirs != 2 {
+		t.Errorf("Expected 2 directories counted, got %d", estimate.TotalDirs)
+	}
+}
+
+// TestDiscoverReportsNoMatchEvents verifies that the callback receives a
+// no-match event with a reason for each non-conforming file, and that matched
+// files report the extracted prefix and date.
+func TestDiscoverReportsNoMatchEvents(t *testing.T) {
+	scanDir := t.TempDir()
+	candidateDir := filepath.Join(scanDir, "Documents")
+	if err := os.MkdirAll(candidateDir, 0755); err != nil {
+		t.Fatalf("Failed to create candidate dir: %v", err)
+	}
+
+	reasons := map[string]string{
+		"Invoice 2024-01-15 Acme.pdf":     "",
+		"notes.txt":                       "no YYYY-MM-DD date in filename",
+		"Report Acme 2024-01-15.pdf":      `prefix "Report Acme" is not a single word of letters and digits starting with a letter`,
+		"Statement 2024-13-01 Bank.pdf":   "invalid date 2024-13-01",
+		"Receipt 2024-02-20.pdf":          "no description after date 2024-02-20",
+		"Memo_2024-03-01 Team Update.pdf": "date 2024-03-01 is not separated from the prefix by a space",
+	}
+	for name := range reasons {
+		if err := os.WriteFile(filepath.Join(candidateDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	noMatch := make(map[string]string)
+	var matchedFile DiscoveryEvent
+	callback := func(event DiscoveryEvent) {
+		switch event.Type {
+		case EventTypeNoMatch:
+			noMatch[filepath.Base(event.Path)] = event.Reason
+		case EventTypeFile:
+			if event.Pattern != "" {
+				matchedFile = event
+			}
+		}
+	}
+
+	if _, err := DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1}, callback); err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	for name, reason := range reasons {
+		got, ok := noMatch[name]
+		if reason == "" {
+			if ok {
+				t.Errorf("Unexpected no-match event for %q: %s", name, got)
+			}
+			continue
+		}
+		if got != reason {
+			t.Errorf("%q: expected reason %q, got %q", name, reason, got)
+		}
+	}
+
+	if matchedFile.Pattern != "Invoice" || matchedFile.Date != "2024-01-15" {
+		t.Errorf("Expected file event with prefix Invoice and date 2024-01-15, got %+v", matchedFile)
+	}
+}
+
+// TestDiscoverReportsCoverage verifies that discovery counts matching and
+// non-matching files and computes the coverage percentage from them.
+func TestDiscoverReportsCoverage(t *testing.T) {
+	scanDir := t.TempDir()
+	for dir, names := range map[string][]string{
+		"Invoices": {"Invoice 2024-01-15 Acme.pdf", "Invoice 2024-02-15 Beta.pdf", "scan.pdf"},
+		"Receipts": {"Receipt 2024-03-01 Shop.pdf", "notes.txt", "Receipt 2024-13-01 Bad.pdf"},
+	} {
+		if err := os.MkdirAll(filepath.Join(scanDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create candidate dir: %v", err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(scanDir, dir, name), []byte("test"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+		}
+	}
+
+	result, err := DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1}, nil)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	if result.Coverage.FilesMatched != 3 || result.Coverage.FilesUnmatched != 3 {
+		t.Errorf("Expected 3 matched and 3 unmatched files, got %+v", result.Coverage)
+	}
+	if got := result.Coverage.Percent(); got != 50 {
+		t.Errorf("Expected 50%% coverage, got %.1f", got)
+	}
+	if result.Coverage.Rules["Invoice"] != 2 || result.Coverage.Rules["Receipt"] != 1 {
+		t.Errorf("Expected 2 files for Invoice and 1 for Receipt, got %v", result.Coverage.Rules)
+	}
+	if got := (Coverage{FilesMatched: 1, FilesUnmatched: 3}).Percent(); got != 25 {
+		t.Errorf("Expected 25%% coverage, got %.1f", got)
+	}
+	if got := (Coverage{}).Percent(); got != 0 {
+		t.Errorf("Expected 0%% coverage with no files, got %.1f", got)
+	}
+}
+
+// TestDiscoverCoverageCountsRules verifies that coverage counts the files of
+// rules rather than pattern matches: files of an already configured prefix are
+// covered, while files of a prefix found in two directories are not, since no
+// rule is added for a conflict.
+func TestDiscoverCoverageCountsRules(t *testing.T) {
+	scanDir := t.TempDir()
+	for dir, names := range map[string][]string{
+		"Invoices":    {"Invoice 2024-01-15 Acme.pdf", "Statement 2024-01-31 Bank.pdf"},
+		"Old Records": {"Statement 2023-12-31 Bank.pdf", "Receipt 2024-03-01 Shop.pdf"},
+	} {
+		if err := os.MkdirAll(filepath.Join(scanDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create candidate dir: %v", err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(scanDir, dir, name), []byte("test"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+		}
+	}
+	existing := &config.Configuration{
+		PrefixRules: []config.PrefixRule{{Prefix: "Receipt", OutboundDirectory: "/archive/Receipts"}},
+	}
+
+	result, err := DiscoverWithOptions(scanDir, existing, DiscoverOptions{MaxDepth: -1}, nil)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	want := map[string]int{"Invoice": 1, "Receipt": 1}
+	if result.Coverage.FilesMatched != 2 || result.Coverage.FilesUnmatched != 2 || !reflect.DeepEqual(result.Coverage.Rules, want) {
+		t.Errorf("Expected Invoice and Receipt covered and both Statements not, got %+v", result.Coverage)
+	}
+}
+
+// TestDiscoverOrderIsDeterministic verifies that two discoveries over the same
+// tree produce the same new rules in the same, sorted order.
+func TestDiscoverOrderIsDeterministic(t *testing.T) {
+	scanDir := t.TempDir()
+	prefixes := []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo", "Foxtrot", "Golf", "Hotel"}
+	for i, prefix := range prefixes {
+		dir := filepath.Join(scanDir, prefix+" Docs")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create candidate dir: %v", err)
+		}
+		name := fmt.Sprintf("%s 2024-01-%02d Doc.pdf", prefix, i+1)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	// Several prefixes in one directory come out of a set, so their order
+	// used to vary between runs
+	mixed := filepath.Join(scanDir, "Mixed")
+	if err := os.MkdirAll(mixed, 0755); err != nil {
+		t.Fatalf("Failed to create candidate dir: %v", err)
+	}
+	for i, prefix := range []string{"Zulu", "Yankee", "Xray", "Whiskey", "Victor"} {
+		name := fmt.Sprintf("%s 2024-02-%02d Doc.pdf", prefix, i+1)
+		if err := os.WriteFile(filepath.Join(mixed, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	discover := func() []string {
+		result, err := DiscoverWithOptions(scanDir, nil, DiscoverOptions{MaxDepth: -1}, nil)
+		if err != nil {
+			t.Fatalf("Discovery failed: %v", err)
+		}
+		var got []string
+		for _, rule := range result.NewRules {
+			got = append(got, rule.Prefix+" -> "+rule.TargetDirectory)
+		}
+		return got
+	}
+
+	first := discover()
+	if len(first) != 13 {
+		t.Fatalf("Expected 13 new rules, got %v", first)
+	}
+	for i := 0; i < 5; i++ {
+		second := discover()
+		if strings.Join(second, "\n") != strings.Join(first, "\n") {
+			t.Fatalf("Expected identical rule order, got\n%v\nand\n%v", first, second)
+		}
+	}
+
+	want := append(append([]string{}, prefixes...), "Victor", "Whiskey", "Xray", "Yankee", "Zulu")
+	for i, rule := range first {
+		if !strings.HasPrefix(rule, want[i]+" -> ") {
+			t.Errorf("Expected rule %d to be for %s, got %s", i, want[i], rule)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package discovery handles auto-discovery of prefix rules from existing file structures.
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxDirs is the directory count above which discovery asks for confirmation.
+const DefaultMaxDirs = 5000
+
+// ScanEstimate describes how large a discovery scan would be.
+type ScanEstimate struct {
+	CandidateDirs int  // Immediate subdirectories of the scan directory
+	TotalDirs     int  // Directories in the tree that would be walked (capped at MaxDirs+1)
+	MaxDirs       int  // Threshold the estimate was checked against
+	Exceeded      bool // True if TotalDirs is over MaxDirs
+}
+
+// ScanTooLargeError is returned by CheckScanSize when the tree exceeds the threshold.
+type ScanTooLargeError struct {
+	Path     string
+	Estimate ScanEstimate
+}
+
+func (e *ScanTooLargeError) Error() string {
+	return fmt.Sprintf("%s contains more than %d directories (%d top-level)",
+		e.Path, e.Estimate.MaxDirs, e.Estimate.CandidateDirs)
+}
+
+// errCountLimit stops the directory walk once the threshold is passed.
+var errCountLimit = errors.New("directory count limit reached")
+
+// dirCounter counts directories under dir, stopping after limit+1. Tests replace it
+// to simulate very large trees.
+var dirCounter = countDirsUpTo
+
+// CheckScanSize estimates the size of a discovery scan before it starts, so an
+// accidental scan of "/" or a home directory can be stopped. Counting stops as soon
+// as maxDirs is exceeded. A maxDirs of 0 or less disables the check.
+// It returns a *ScanTooLargeError if the threshold is exceeded.
+func CheckScanSize(scanDir string, maxDepth, maxDirs int) (*ScanEstimate, error) {
+	candidates, err := scanTargetCandidates(scanDir)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &ScanEstimate{
+		CandidateDirs: len(candidates),
+		MaxDirs:       maxDirs,
+	}
+	if maxDirs <= 0 {
+		return estimate, nil
+	}
+
+	if len(candidates) > maxDirs {
+		estimate.TotalDirs = len(candidates)
+	} else {
+		for _, candidateDir := range candidates {
+			estimate.TotalDirs += dirCounter(candidateDir, maxDepth, maxDirs-estimate.TotalDirs)
+			if estimate.TotalDirs > maxDirs {
+				break
+			}
+		}
+	}
+
+	if estimate.TotalDirs > maxDirs {
+		estimate.Exceeded = true
+		return estimate, &ScanTooLargeError{Path: scanDir, Estimate: *estimate}
+	}
+	return estimate, nil
+}
+
+// countDirsUpTo counts dir and its subdirectories up to maxDepth levels, skipping
+// ISO-date directories like the discovery walk does. It stops once the count
+// exceeds limit.
+func countDirsUpTo(dir string, maxDepth, limit int) int {
+	baseDir := filepath.Clean(dir)
+	count := 0
+
+	filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		if path != baseDir {
+			if IsISODateDirectory(d.Name()) {
+				return filepath.SkipDir
+			}
+			relPath, relErr := filepath.Rel(baseDir, path)
+			if relErr != nil {
+				return filepath.SkipDir
+			}
+			depth := strings.Count(relPath, string(filepath.Separator)) + 1
+			if maxDepth >= 0 && depth > maxDepth {
+				return filepath.SkipDir
+			}
+		}
+
+		count++
+		if count > limit {
+			return errCountLimit
+		}
+		return nil
+	})
+
+	return count
+}
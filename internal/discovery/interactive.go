@@ -97,3 +97,20 @@ func (p *InteractivePrompter) PromptForRule(rule DiscoveredRule) (PromptResult,
 		return PromptReject, nil
 	}
 }
+
+// Confirm asks a yes/no question and returns true only for an explicit yes.
+// EOF or any other input is treated as no.
+func (p *InteractivePrompter) Confirm(question string) (bool, error) {
+	fmt.Fprintf(p.writer, "%s [y/N]: ", question)
+
+	scanner := bufio.NewScanner(p.reader)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return false, fmt.Errorf("error reading input: %w", err)
+		}
+		return false, nil
+	}
+
+	input := strings.TrimSpace(strings.ToLower(scanner.Text()))
+	return input == "y" || input == "yes", nil
+}
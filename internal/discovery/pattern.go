@@ -4,6 +4,9 @@ package discovery
 import (
 	"regexp"
 	"strings"
+
+	"sorta/internal/classifier"
+	"sorta/internal/dateparser"
 )
 
 // ISODateDirPattern matches directory names starting with YYYY-MM-DD
@@ -18,34 +21,74 @@ func IsISODateDirectory(dirName string) bool {
 	return ISODateDirPattern.MatchString(dirName)
 }
 
-// PrefixPattern represents the regex for detecting file patterns.
-// Pattern: ^([A-Za-z][A-Za-z0-9]*)\s+(\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))\s+(.+)$
-// This matches filenames like "Invoice 2024-01-15 Acme Corp.pdf"
-// The date portion validates:
-// - Month: 01-12
-// - Day: 01-31
-var PrefixPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)\s+(\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))\s+(.+)$`)
-
 // ExtractPrefixFromFilename returns the prefix if the filename matches the pattern.
 // The pattern is: <prefix> <YYYY-MM-DD> <other_info>
 // where prefix starts with a letter, is followed by alphanumeric characters,
-// and is separated from the date by exactly one space.
+// and is separated from the date by exactly one space. Filenames are parsed
+// with classifier.ParseFilename, so every prefix found here matches the same
+// filename once it is a prefix rule.
 //
 // Returns the extracted prefix and true if matched, or empty string and false if not matched.
 func ExtractPrefixFromFilename(filename string) (prefix string, matched bool) {
-	// Remove file extension for matching
+	prefix, _, matched = ExtractPrefixAndDate(filename)
+	return prefix, matched
+}
+
+// ExtractPrefixAndDate is ExtractPrefixFromFilename that also returns the
+// extracted YYYY-MM-DD date.
+func ExtractPrefixAndDate(filename string) (prefix, date string, matched bool) {
+	parsed, ok := classifier.ParseFilename(filename, classifier.ParseConfig{})
+	if !ok || parsed.Description == "" {
+		return "", "", false
+	}
+	return parsed.Prefix, parsed.Date, true
+}
+
+// looseDatePattern matches any YYYY-MM-DD shaped token, valid or not.
+var looseDatePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// prefixWordPattern matches a prefix accepted by ExtractPrefixAndDate.
+var prefixWordPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// NoMatchReason explains why ExtractPrefixAndDate does not match filename, or
+// returns an empty string if it does.
+func NoMatchReason(filename string) string {
+	if _, _, matched := ExtractPrefixAndDate(filename); matched {
+		return ""
+	}
+
 	nameWithoutExt := removeExtension(filename)
+	loc := looseDatePattern.FindStringIndex(nameWithoutExt)
+	if loc == nil {
+		return "no YYYY-MM-DD date in filename"
+	}
 
-	matches := PrefixPattern.FindStringSubmatch(nameWithoutExt)
-	if matches == nil {
-		return "", false
+	date := nameWithoutExt[loc[0]:loc[1]]
+	before := nameWithoutExt[:loc[0]]
+	after := nameWithoutExt[loc[1]:]
+
+	switch {
+	case !validDate(date):
+		return "invalid date " + date
+	case strings.TrimSpace(before) == "":
+		return "no prefix before date " + date
+	case strings.TrimRight(before, " \t") == before:
+		return "date " + date + " is not separated from the prefix by a space"
+	case !strings.HasSuffix(before, " ") || strings.TrimRight(before, " \t") != before[:len(before)-1]:
+		return "date " + date + " is not separated from the prefix by exactly one space"
+	case !prefixWordPattern.MatchString(strings.TrimSpace(before)):
+		return "prefix \"" + strings.TrimSpace(before) + "\" is not a single word of letters and digits starting with a letter"
+	case strings.TrimSpace(after) == "" || strings.TrimLeft(after, " \t") == after:
+		return "no description after date " + date
+	default:
+		return "does not match <prefix> <YYYY-MM-DD> <description>"
 	}
+}
 
-	// matches[0] is the full match
-	// matches[1] is the prefix
-	// matches[2] is the date
-	// matches[3] is the other info
-	return matches[1], true
+// validDate reports whether date is a valid YYYY-MM-DD date.
+func validDate(date string) bool {
+	_, err := dateparser.ParseIsoDate(date)
+	return err == nil
 }
 
 // removeExtension removes the file extension from a filename.
@@ -0,0 +1,160 @@
+// Package filesystem abstracts the file operations Sorta performs so callers can
+// substitute an in-memory implementation in tests or when embedding Sorta.
+package filesystem
+
+import (
+	"io"
+	"os"
+)
+
+// MaxNameLength is the longest file name, in bytes, that common filesystems
+// accept for a single path component.
+const MaxNameLength = 255
+
+// FS is the set of filesystem operations used by the scanner, organizer and orchestrator.
+// Errors should follow the os package conventions (*os.PathError wrapping
+// os.ErrNotExist, os.ErrExist or os.ErrPermission) so callers can use os.IsNotExist
+// and friends.
+type FS interface {
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Rename(oldpath, newpath string) error
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	Remove(name string) error
+}
+
+// OS is the FS backed by the real operating system filesystem.
+type OS struct{}
+
+// Default is the real filesystem used when no FS is supplied.
+var Default FS = OS{}
+
+// OrDefault returns fsys, or Default if fsys is nil.
+func OrDefault(fsys FS) FS {
+	if fsys == nil {
+		return Default
+	}
+	return fsys
+}
+
+func (OS) Stat(name string) (os.FileInfo, error)  { return os.Stat(name) }
+func (OS) Lstat(name string) (os.FileInfo, error) { return os.Lstat(name) }
+func (OS) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+func (OS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (OS) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (OS) ReadFile(name string) ([]byte, error)         { return os.ReadFile(name) }
+func (OS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (OS) Remove(name string) error { return os.Remove(name) }
+
+// FreeSpace returns the bytes available on the volume holding path.
+func (OS) FreeSpace(path string) (uint64, error) { return freeSpace(path) }
+
+// SpaceReporter is implemented by filesystems that can report how much space
+// is left on the volume holding a path. OS implements it; MemFS does not.
+type SpaceReporter interface {
+	FreeSpace(path string) (uint64, error)
+}
+
+// SameVolume reports whether the files at a and b are on the same volume.
+func (OS) SameVolume(a, b string) (bool, error) { return sameVolume(a, b) }
+
+// VolumeReporter is implemented by filesystems that can tell whether two
+// paths are on the same volume, so that a rename between them does not fall
+// back to copying. OS implements it; MemFS does not.
+type VolumeReporter interface {
+	SameVolume(a, b string) (bool, error)
+}
+
+// CopyXattrs copies the extended attributes of src to dst. It does nothing
+// on platforms without extended attributes (see XattrsSupported).
+func (OS) CopyXattrs(src, dst string) error { return copyXattrs(src, dst) }
+
+// XattrCopier is implemented by filesystems that can copy extended
+// attributes from one file to another. OS implements it; MemFS does not.
+type XattrCopier interface {
+	CopyXattrs(src, dst string) error
+}
+
+// Chmod changes the mode of the named file, regardless of the umask.
+func (OS) Chmod(name string, mode os.FileMode) error { return os.Chmod(name, mode) }
+
+// Chmoder is implemented by filesystems whose MkdirAll and WriteFile may not
+// apply the requested mode as is (the umask), so it can be set afterwards.
+// OS implements it; MemFS applies modes as given and does not.
+type Chmoder interface {
+	Chmod(name string, mode os.FileMode) error
+}
+
+// AppendFile appends data to the named file, creating it with perm if needed,
+// and syncs it to disk.
+func (OS) AppendFile(name string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Appender is implemented by filesystems that can append to a file without
+// rewriting it. OS implements it; MemFS does not.
+type Appender interface {
+	AppendFile(name string, data []byte, perm os.FileMode) error
+}
+
+// AppendFile appends data to the named file, creating it with perm if needed.
+// Filesystems that are not an Appender have the file read and written back
+// whole.
+func AppendFile(fsys FS, name string, data []byte, perm os.FileMode) error {
+	if appender, ok := fsys.(Appender); ok {
+		return appender.AppendFile(name, data, perm)
+	}
+	existing, err := fsys.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return fsys.WriteFile(name, append(existing, data...), perm)
+}
+
+// ReadHead returns up to the first n bytes of the named file. On the real
+// filesystem only those bytes are read; other implementations read the whole
+// file and truncate it.
+func ReadHead(fsys FS, name string, n int) ([]byte, error) {
+	if _, ok := fsys.(OS); !ok {
+		data, err := fsys.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > n {
+			data = data[:n]
+		}
+		return data, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
@@ -0,0 +1,7 @@
+//go:build darwin
+
+package filesystem
+
+// MaxPathLength is the longest path, in bytes, that is safe to create:
+// PATH_MAX (1024) less the terminating NUL.
+const MaxPathLength = 1023
@@ -0,0 +1,7 @@
+//go:build !windows && !darwin
+
+package filesystem
+
+// MaxPathLength is the longest path, in bytes, that is safe to create:
+// PATH_MAX (4096 on Linux) less the terminating NUL.
+const MaxPathLength = 4095
@@ -0,0 +1,7 @@
+//go:build windows
+
+package filesystem
+
+// MaxPathLength is the longest path, in bytes, that is safe to create:
+// MAX_PATH (260) less the terminating NUL.
+const MaxPathLength = 259
@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package filesystem
+
+import "os"
+
+// IsLocked always reports false on platforms without a lock probe.
+func IsLocked(path string) (bool, error) {
+	return false, nil
+}
+
+// Lock creates the file at path if needed. Platforms without file locks get
+// no mutual exclusion; unlock only closes the file.
+func Lock(path string) (unlock func() error, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return file.Close, nil
+}
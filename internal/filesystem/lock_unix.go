@@ -0,0 +1,45 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// IsLocked reports whether another process holds a lock on the file at path.
+// On Unix this probes for an exclusive advisory lock (flock) without blocking;
+// files that are merely open elsewhere are not reported as locked.
+func IsLocked(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	fd := int(file.Fd())
+	if err := syscall.Flock(fd, syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, syscall.Flock(fd, syscall.LOCK_UN)
+}
+
+// Lock takes an exclusive lock on the file at path, creating the file if
+// needed, and waits until no other process holds it. On Unix this is an
+// advisory lock (flock), released by unlock or when the process exits.
+func Lock(path string) (unlock func() error, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Closing the file releases the lock
+	return file.Close, nil
+}
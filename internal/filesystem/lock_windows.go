@@ -0,0 +1,49 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, returned when another
+// process has the file open in a way that prevents exclusive access.
+const errorSharingViolation syscall.Errno = 32
+
+// IsLocked reports whether another process has the file at path open. It tries
+// to open the file for reading with no sharing allowed, which fails with a
+// sharing violation while any other handle to the file is open.
+func IsLocked(path string) (bool, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ, 0, nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, syscall.CloseHandle(handle)
+}
+
+// Lock takes an exclusive lock on the file at path, creating the file if
+// needed, and waits until no other process holds it. The lock is released by
+// unlock or when the process exits.
+func Lock(path string) (unlock func() error, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, new(windows.Overlapped)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Closing the file releases the lock
+	return file.Close, nil
+}
@@ -0,0 +1,218 @@
+// Package filesystem abstracts the file operations Sorta performs so callers can
+// substitute an in-memory implementation in tests or when embedding Sorta.
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory FS. Paths are cleaned with filepath.Clean; the root
+// directory always exists. It is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	root := string(filepath.Separator)
+	return &MemFS{
+		nodes: map[string]*memNode{
+			root: {mode: os.ModeDir | 0755, modTime: time.Now()},
+		},
+	}
+}
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stat("stat", name)
+}
+
+// Lstat is the same as Stat; MemFS has no symlinks.
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stat("lstat", name)
+}
+
+func (m *MemFS) stat(op, name string) (os.FileInfo, error) {
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	return node.info(name), nil
+}
+
+// ReadDir returns the direct children of name sorted by filename.
+func (m *MemFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if !node.mode.IsDir() {
+		return nil, &os.PathError{Op: "readdirent", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var entries []os.DirEntry
+	for path, child := range m.nodes {
+		if path != name && filepath.Dir(path) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(path)))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(path), perm)
+}
+
+func (m *MemFS) mkdirAll(path string, perm os.FileMode) error {
+	if node, ok := m.nodes[path]; ok {
+		if node.mode.IsDir() {
+			return nil
+		}
+		return &os.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+	}
+	if parent := filepath.Dir(path); parent != path {
+		if err := m.mkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	m.nodes[path] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// Rename moves a file, replacing any existing file at newpath. Directories
+// cannot be renamed.
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	node, ok := m.nodes[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrInvalid}
+	}
+	if err := m.checkParent("rename", newpath); err != nil {
+		return err
+	}
+	if existing, ok := m.nodes[newpath]; ok && existing.mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrExist}
+	}
+
+	delete(m.nodes, oldpath)
+	m.nodes[newpath] = node
+	return nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+// WriteFile creates or truncates a file. The parent directory must exist.
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.checkParent("open", name); err != nil {
+		return err
+	}
+	if node, ok := m.nodes[name]; ok && node.mode.IsDir() {
+		return &os.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.nodes[name] = &memNode{
+		data:    append([]byte(nil), data...),
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// Remove deletes a file or an empty directory.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	node, ok := m.nodes[name]
+	if !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		prefix := name + string(filepath.Separator)
+		for path := range m.nodes {
+			if strings.HasPrefix(path, prefix) {
+				return &os.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+			}
+		}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+// checkParent returns an error unless the parent of name is an existing directory.
+func (m *MemFS) checkParent(op, name string) error {
+	parent, ok := m.nodes[filepath.Dir(name)]
+	if !ok {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	if !parent.mode.IsDir() {
+		return &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
+
+func (n *memNode) info(path string) os.FileInfo {
+	return &memFileInfo{name: filepath.Base(path), node: n}
+}
+
+// memFileInfo implements os.FileInfo for a MemFS node.
+type memFileInfo struct {
+	name string
+	node *memNode
+}
+
+func (fi *memFileInfo) Name() string       { return fi.name }
+func (fi *memFileInfo) Size() int64        { return int64(len(fi.node.data)) }
+func (fi *memFileInfo) Mode() os.FileMode  { return fi.node.mode }
+func (fi *memFileInfo) ModTime() time.Time { return fi.node.modTime }
+func (fi *memFileInfo) IsDir() bool        { return fi.node.mode.IsDir() }
+func (fi *memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,44 @@
+package filesystem
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMemFSBasicOperations verifies MemFS mirrors the os semantics the organizer relies on.
+func TestMemFSBasicOperations(t *testing.T) {
+	fsys := NewMemFS()
+
+	if err := fsys.WriteFile("/a/file.txt", []byte("x"), 0644); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error writing without parent, got %v", err)
+	}
+	if err := fsys.MkdirAll("/a/b", 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := fsys.WriteFile("/a/file.txt", []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	entries, err := fsys.ReadDir("/a")
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "b" || !entries[0].IsDir() || entries[1].Name() != "file.txt" {
+		t.Errorf("Unexpected entries: %v", entries)
+	}
+
+	if err := fsys.Rename("/a/file.txt", "/a/b/moved.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := fsys.Stat("/a/file.txt"); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be gone, got %v", err)
+	}
+	info, err := fsys.Stat("/a/b/moved.txt")
+	if err != nil || info.Size() != 1 || info.IsDir() {
+		t.Errorf("Unexpected stat of moved file: %v, %v", info, err)
+	}
+
+	if err := fsys.Remove("/a/b"); err == nil {
+		t.Error("Expected error removing non-empty directory")
+	}
+}
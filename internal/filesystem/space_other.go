@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package filesystem
+
+import "errors"
+
+// freeSpace is not supported on this platform.
+func freeSpace(path string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// sameVolume reports whether a and b have the same volume name, such as the
+// drive letter on Windows.
+func sameVolume(a, b string) (bool, error) {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(filepath.VolumeName(absA), filepath.VolumeName(absB)), nil
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// sameVolume reports whether the files at a and b are on the same device.
+func sameVolume(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	statA, okA := infoA.Sys().(*syscall.Stat_t)
+	statB, okB := infoB.Sys().(*syscall.Stat_t)
+	if !okA || !okB {
+		return false, errors.ErrUnsupported
+	}
+	return statA.Dev == statB.Dev, nil
+}
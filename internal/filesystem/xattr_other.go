@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package filesystem
+
+// XattrsSupported reports whether CopyXattrs copies extended attributes on
+// this platform.
+const XattrsSupported = false
+
+// copyXattrs does nothing: extended attributes are not supported here.
+func copyXattrs(src, dst string) error {
+	return nil
+}
@@ -0,0 +1,72 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// XattrsSupported reports whether CopyXattrs copies extended attributes on
+// this platform.
+const XattrsSupported = true
+
+// copyXattrs copies the extended attributes of src (on macOS also its
+// resource fork and Finder tags, which are stored as attributes) to dst.
+// Volumes that do not support extended attributes have none to copy, and a
+// destination volume without them (FAT, exFAT, some NFS mounts) gets none.
+// On Linux only the user namespace is copied: security, system and trusted
+// attributes belong to the system and cannot be set by ordinary users.
+func copyXattrs(src, dst string) error {
+	names, err := readXattr(func(buf []byte) (int, error) { return unix.Listxattr(src, buf) })
+	if errors.Is(err, unix.ENOTSUP) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+		if runtime.GOOS == "linux" && !strings.HasPrefix(attr, "user.") {
+			continue
+		}
+		value, err := readXattr(func(buf []byte) (int, error) { return unix.Getxattr(src, attr, buf) })
+		if err != nil {
+			return err
+		}
+		if err := unix.Setxattr(dst, attr, value, 0); err != nil {
+			if errors.Is(err, unix.ENOTSUP) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readXattr calls read once to learn the size of the value and again to
+// read it, retrying when the value grew in between.
+func readXattr(read func(buf []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := read(nil)
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		n, err := read(buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
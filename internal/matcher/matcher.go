@@ -15,10 +15,22 @@ type MatchResult struct {
 	Remainder string
 }
 
+// MatchOptions configures matching behavior.
+type MatchOptions struct {
+	// AllowExtraSpaces accepts any run of spaces or tabs after the prefix instead of
+	// exactly one space. The remainder then starts at the first non-blank character.
+	AllowExtraSpaces bool
+}
+
 // Match evaluates a filename against prefix rules using case-insensitive matching.
 // It returns the longest matching prefix rule, or a non-matched result if no rule matches.
 // A match requires the prefix to be followed by a single space delimiter.
 func Match(filename string, rules []config.PrefixRule) *MatchResult {
+	return MatchWithOptions(filename, rules, MatchOptions{})
+}
+
+// MatchWithOptions evaluates a filename against prefix rules with configurable options.
+func MatchWithOptions(filename string, rules []config.PrefixRule, opts MatchOptions) *MatchResult {
 	if len(rules) == 0 {
 		return &MatchResult{Matched: false}
 	}
@@ -43,12 +55,22 @@ func Match(filename string, rules []config.PrefixRule) *MatchResult {
 		}
 
 		// Verify single space delimiter after prefix
-		if len(filename) <= prefixLen || filename[prefixLen] != ' ' {
+		if len(filename) <= prefixLen {
+			continue
+		}
+		if opts.AllowExtraSpaces {
+			if !isBlank(filename[prefixLen]) {
+				continue
+			}
+		} else if filename[prefixLen] != ' ' {
 			continue
 		}
 
 		// Return match with remainder (everything after prefix and space)
 		remainder := filename[prefixLen+1:]
+		if opts.AllowExtraSpaces {
+			remainder = strings.TrimLeft(remainder, " \t")
+		}
 		return &MatchResult{
 			Matched:   true,
 			Rule:      rule,
@@ -58,3 +80,8 @@ func Match(filename string, rules []config.PrefixRule) *MatchResult {
 
 	return &MatchResult{Matched: false}
 }
+
+// isBlank reports whether c is a space or tab.
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
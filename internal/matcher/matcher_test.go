@@ -167,3 +167,36 @@ func TestLongestPrefixWins(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// TestNormalizedPrefixMatchesSourceFile checks that a prefix added with stray
+// whitespace matches the file it was taken from once stored.
+func TestNormalizedPrefixMatchesSourceFile(t *testing.T) {
+	cfg := &config.Configuration{}
+	if _, err := cfg.AddPrefixRule(config.PrefixRule{Prefix: " Invoice ", OutboundDirectory: "/invoices"}); err != nil {
+		t.Fatalf("AddPrefixRule failed: %v", err)
+	}
+
+	result := Match("Invoice 2024-01-15 Acme.pdf", cfg.PrefixRules)
+	if !result.Matched || result.Remainder != "2024-01-15 Acme.pdf" {
+		t.Errorf("Expected normalized rule to match, got %+v", result)
+	}
+}
+
+// TestMultiWordPrefix checks that a configured prefix containing a space is
+// matched as a whole, ahead of a shorter prefix made of its first word.
+func TestMultiWordPrefix(t *testing.T) {
+	rules := []config.PrefixRule{
+		{Prefix: "Bank", OutboundDirectory: "/bank"},
+		{Prefix: "Bank Statement", OutboundDirectory: "/statements"},
+	}
+
+	result := Match("bank statement 2024-01-15 Chase.pdf", rules)
+	if !result.Matched || result.Rule.Prefix != "Bank Statement" || result.Remainder != "2024-01-15 Chase.pdf" {
+		t.Errorf("Expected \"Bank Statement\" with remainder \"2024-01-15 Chase.pdf\", got %+v", result)
+	}
+
+	result = Match("Bank 2024-01-15 Fees.pdf", rules)
+	if !result.Matched || result.Rule.Prefix != "Bank" || result.Remainder != "2024-01-15 Fees.pdf" {
+		t.Errorf("Expected \"Bank\" with remainder \"2024-01-15 Fees.pdf\", got %+v", result)
+	}
+}
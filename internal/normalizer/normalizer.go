@@ -1,6 +1,13 @@
 // Package normalizer handles filename normalization for Sorta.
 package normalizer
 
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
 // Normalize rewrites a filename with the canonical prefix casing.
 // It replaces the matched prefix portion with the canonical casing
 // while preserving the space delimiter and all characters following the prefix exactly.
@@ -20,3 +27,26 @@ func Normalize(filename string, matchedPrefix string, canonicalPrefix string) st
 
 	return canonicalPrefix + remainder
 }
+
+// CollapseSpaces replaces every run of whitespace in a filename with a single space
+// and trims leading and trailing whitespace.
+// For example, "Invoice   2024-01-15\tAcme.pdf" becomes "Invoice 2024-01-15 Acme.pdf".
+func CollapseSpaces(filename string) string {
+	return strings.Join(strings.Fields(filename), " ")
+}
+
+// StripDiacritics transliterates accented letters in a filename to their
+// unaccented form by decomposing it and dropping combining marks.
+// For example, "Café 2024-01-15 Menu.pdf" becomes "Cafe 2024-01-15 Menu.pdf".
+// Characters without a decomposition, such as "ø" or "ß", are kept.
+func StripDiacritics(filename string) string {
+	decomposed := norm.NFD.String(filename)
+	var b strings.Builder
+	b.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
@@ -0,0 +1,208 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/organizer"
+	"sorta/internal/scanner"
+)
+
+// processedArchivesDir is the folder, next to an archive, that the archive is
+// moved to once its file has been extracted.
+const processedArchivesDir = "processed-archives"
+
+// maxArchiveMemberSize is the largest file extracted from an archive. The file
+// is held in memory while it is extracted, so larger ones stay in their
+// archive.
+const maxArchiveMemberSize = 512 << 20
+
+// archiveMember is the single file inside an archive that is extracted.
+type archiveMember struct {
+	name string // Base name of the file
+	data []byte // Uncompressed contents
+}
+
+// extractableMember returns the file inside the zip archive file when
+// cfg.ExtractArchives is set, the archive holds exactly one file, that file's
+// name is classified by a prefix rule and no file of that name exists next to
+// the archive yet. Archives already in a processed-archives folder are never
+// extracted again, nor are files larger than maxArchiveMemberSize or larger
+// than the archive says they are.
+func extractableMember(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) (*archiveMember, bool) {
+	if !cfg.ExtractArchives || !strings.EqualFold(filepath.Ext(file.Name), ".zip") || cfg.IsReadOnlySource(file.FullPath) {
+		return nil, false
+	}
+	if filepath.Base(filepath.Dir(file.FullPath)) == processedArchivesDir {
+		return nil, false
+	}
+
+	reader, closeArchive, err := openZip(fsys, file.FullPath)
+	if err != nil {
+		return nil, false
+	}
+	defer closeArchive()
+	var member *zip.File
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if member != nil {
+			return nil, false
+		}
+		member = f
+	}
+	if member == nil {
+		return nil, false
+	}
+
+	// Zip entries use forward slashes; only the file's own name is kept. A
+	// name that still holds a separator, such as `..\x` written on Windows,
+	// could escape the archive's directory and is refused
+	name := path.Base(member.Name)
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) || !classifyFile(name, cfg).IsClassified() {
+		return nil, false
+	}
+	if member.UncompressedSize64 > maxArchiveMemberSize {
+		return nil, false
+	}
+	if organizer.FileExistsWithFS(fsys, filepath.Join(filepath.Dir(file.FullPath), name)) {
+		return nil, false
+	}
+
+	rc, err := member.Open()
+	if err != nil {
+		return nil, false
+	}
+	defer rc.Close()
+	// Read one byte more than the archive claims, to catch a file that is
+	// larger than its header says
+	contents, err := io.ReadAll(io.LimitReader(rc, int64(member.UncompressedSize64)+1))
+	if err != nil || uint64(len(contents)) > member.UncompressedSize64 {
+		return nil, false
+	}
+	return &archiveMember{name: name, data: contents}, true
+}
+
+// openZip opens the zip archive at name. On the real filesystem only the
+// parts of the archive that are needed are read; other implementations read
+// the whole archive into memory. The returned function closes the archive.
+func openZip(fsys filesystem.FS, name string) (*zip.Reader, func() error, error) {
+	if _, ok := fsys.(filesystem.OS); ok {
+		rc, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &rc.Reader, rc.Close, nil
+	}
+
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, func() error { return nil }, nil
+}
+
+// processArchive extracts member next to the archive file, moves the archive
+// into the processed-archives folder of its directory and organizes the
+// extracted file. The extraction is recorded as EXTRACT and the archive's move
+// as MOVE, so undo moves the archive back and deletes the extracted file.
+func processArchive(fsys filesystem.FS, file scanner.FileEntry, member *archiveMember, cfg *config.Configuration, auditWriter *audit.AuditWriter, identityResolver *audit.IdentityResolver, options *Options) Result {
+	failed := func(err error) Result {
+		if auditWriter != nil {
+			auditWriter.RecordError(file.FullPath, "EXTRACT_FAILED", err.Error(), "extract_archive")
+		}
+		return Result{
+			SourcePath: file.FullPath,
+			Success:    false,
+			Error:      &MoveError{Path: file.FullPath, Op: OpExtractArchive, Err: err},
+			EventType:  "ERROR",
+		}
+	}
+
+	var archiveIdentity *audit.FileIdentity
+	if auditWriter != nil && identityResolver != nil {
+		var err error
+		if archiveIdentity, err = identityResolver.CaptureIdentity(file.FullPath); err != nil {
+			return failed(err)
+		}
+	}
+
+	dir := filepath.Dir(file.FullPath)
+	extracted := scanner.FileEntry{
+		Name:        member.name,
+		FullPath:    filepath.Join(dir, member.name),
+		RelativeDir: file.RelativeDir,
+	}
+
+	// A transactional run stages files bound for their destinations; the
+	// extracted file is a new source, so it is written in place
+	sourceFS := fsys
+	if staging, ok := fsys.(*stagingFS); ok {
+		sourceFS = staging.FS
+	}
+	if err := sourceFS.WriteFile(extracted.FullPath, member.data, 0644); err != nil {
+		return failed(err)
+	}
+
+	// The extracted file exists now, so its identity can be recorded
+	if auditWriter != nil {
+		var identity *audit.FileIdentity
+		if identityResolver != nil {
+			var err error
+			if identity, err = identityResolver.CaptureIdentity(extracted.FullPath); err != nil {
+				sourceFS.Remove(extracted.FullPath)
+				return failed(err)
+			}
+		}
+		if err := auditWriter.RecordExtract(file.FullPath, extracted.FullPath, identity); err != nil {
+			sourceFS.Remove(extracted.FullPath)
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+
+	// Record audit event BEFORE the move (Requirements: 11.4)
+	archiveDir := filepath.Join(dir, processedArchivesDir)
+	archiveName := file.Name
+	if organizer.FileExistsWithFS(fsys, filepath.Join(archiveDir, archiveName)) {
+		archiveName = organizer.DuplicateNameWithFS(fsys, archiveDir, archiveName, cfg)
+	}
+	if auditWriter != nil {
+		if err := auditWriter.RecordMove(file.FullPath, filepath.Join(archiveDir, archiveName), archiveIdentity); err != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+	if _, err := organizer.MoveFileWithFS(fsys, file.FullPath, archiveDir, archiveName); err != nil {
+		// The archive stays in place, so the extracted file would be a second
+		// copy of its contents
+		sourceFS.Remove(extracted.FullPath)
+		return failed(fmt.Errorf("failed to move archive to %s: %w", archiveDir, err))
+	}
+
+	result := processFileWithAudit(fsys, extracted, cfg, auditWriter, identityResolver, options)
+	result.Archive = file.FullPath
+	return result
+}
@@ -0,0 +1,181 @@
+package orchestrator
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// writeTestZip writes a zip archive at path holding the given files.
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, contents := range files {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s to archive: %v", name, err)
+		}
+		entry.Write([]byte(contents))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+}
+
+// TestExtractArchives verifies that a zip holding a single matching file is
+// extracted and organized, the zip is moved to processed-archives, other zips
+// are left alone, and undo reverses the extraction and both moves.
+func TestExtractArchives(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	archive := filepath.Join(sourceDir, "download.zip")
+	writeTestZip(t, archive, map[string]string{"docs/Invoice 2024-01-15 Acme.pdf": "invoice"})
+	multi := filepath.Join(sourceDir, "Invoice 2024-02-01 Bundle.zip")
+	writeTestZip(t, multi, map[string]string{
+		"Invoice 2024-02-01 A.pdf": "a",
+		"Invoice 2024-02-01 B.pdf": "b",
+	})
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		ExtractArchives: true,
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 2 || summary.ErrorCount != 0 {
+		t.Fatalf("Expected 2 moves and no errors, got %+v", summary.Results)
+	}
+
+	extracted := filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf")
+	if data, err := os.ReadFile(extracted); err != nil || string(data) != "invoice" {
+		t.Errorf("Expected the extracted file at %s, got %q, %v", extracted, data, err)
+	}
+	processed := filepath.Join(sourceDir, processedArchivesDir, "download.zip")
+	if _, err := os.Stat(processed); err != nil {
+		t.Errorf("Expected the archive in %s: %v", processed, err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(multi))); err != nil {
+		t.Errorf("Expected the multi-file archive to be organized as it is: %v", err)
+	}
+	for _, result := range summary.Results {
+		if result.DestinationPath == extracted && result.Archive != archive {
+			t.Errorf("Expected the result to name archive %s, got %q", archive, result.Archive)
+		}
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	result, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Failed != 0 {
+		t.Fatalf("Expected undo without failures, got %+v", result.FailureDetails)
+	}
+	entries, _ := os.ReadDir(sourceDir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if len(names) != 3 || names[0] != filepath.Base(multi) || names[1] != "download.zip" || names[2] != processedArchivesDir {
+		t.Errorf("Expected both archives back and the extracted file gone, got %v", names)
+	}
+}
+
+// TestExtractArchivesRefusesEscapingNames verifies that a member whose name
+// still holds a separator after its folders are dropped is not extracted.
+func TestExtractArchivesRefusesEscapingNames(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+
+	archive := filepath.Join(sourceDir, "download.zip")
+	writeTestZip(t, archive, map[string]string{`Invoice 2024-01-15 Acme\..\..\x.pdf`: "invoice"})
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		ExtractArchives: true,
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}
+	if _, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(sourceDir, processedArchivesDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected the archive not to be processed, got %v", err)
+	}
+	if _, err := os.Stat(invoiceDir); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing organized into %s, got %v", invoiceDir, err)
+	}
+}
+
+// TestExtractArchivesRemovesFileWhenArchiveMoveFails verifies that the
+// extracted file is removed again when the archive cannot be moved to
+// processed-archives, so the archive's contents are not left in two places.
+func TestExtractArchivesRemovesFileWhenArchiveMoveFails(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+
+	archive := filepath.Join(sourceDir, "download.zip")
+	writeTestZip(t, archive, map[string]string{"Invoice 2024-01-15 Acme.pdf": "invoice"})
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		ExtractArchives: true,
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:      &auditConfig,
+		FailurePredicate: GlobFailures("*.zip", errors.New("injected failure")),
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.ErrorCount != 1 {
+		t.Errorf("Expected the archive move to fail, got %+v", summary.Results)
+	}
+
+	if _, err := os.Stat(archive); err != nil {
+		t.Errorf("Expected the archive to stay in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "Invoice 2024-01-15 Acme.pdf")); !os.IsNotExist(err) {
+		t.Errorf("Expected the extracted file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(invoiceDir); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing organized into %s, got %v", invoiceDir, err)
+	}
+}
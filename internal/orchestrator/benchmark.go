@@ -0,0 +1,112 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// benchmarkFileSize is the size of each synthetic file generated by Benchmark.
+const benchmarkFileSize = 64 * 1024
+
+// BenchmarkResult reports the throughput of a synthetic organize run.
+type BenchmarkResult struct {
+	Files    int           // Number of files organized
+	Bytes    int64         // Total bytes moved
+	Duration time.Duration // Time spent in the organize pipeline (excludes setup and cleanup)
+	Errors   int           // Files that failed to organize
+}
+
+// FilesPerSecond returns the organize throughput in files per second.
+func (r *BenchmarkResult) FilesPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Files) / r.Duration.Seconds()
+}
+
+// MBPerSecond returns the organize throughput in megabytes (2^20 bytes) per second.
+func (r *BenchmarkResult) MBPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / (1024 * 1024) / r.Duration.Seconds()
+}
+
+// Benchmark generates count synthetic matching files in a temporary directory and
+// organizes them with the full pipeline (scan, classify, hash, move, audit) into a
+// temporary outbound directory. Everything, including the config and the audit
+// log, lives under the temporary directory, which is removed afterwards.
+func Benchmark(count int) (*BenchmarkResult, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("benchmark file count must be positive, got %d", count)
+	}
+
+	tempDir, err := os.MkdirTemp("", "sorta-benchmark-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create benchmark directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inboundDir := filepath.Join(tempDir, "inbound")
+	outboundDir := filepath.Join(tempDir, "outbound")
+	auditDir := filepath.Join(tempDir, "audit")
+	for _, dir := range []string{inboundDir, outboundDir, auditDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create benchmark directory: %w", err)
+		}
+	}
+
+	data := make([]byte, benchmarkFileSize)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	for i := 0; i < count; i++ {
+		// Vary the content so every file hashes differently, and the year so
+		// several destination folders are used
+		copy(data, fmt.Sprintf("%08d", i))
+		name := fmt.Sprintf("Bench %d-%02d-15 file-%06d.bin", 2020+i%5, 1+i%12, i)
+		if err := os.WriteFile(filepath.Join(inboundDir, name), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to generate benchmark file: %w", err)
+		}
+	}
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{inboundDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Bench", OutboundDirectory: outboundDir},
+		},
+	}
+	configPath := filepath.Join(tempDir, "sorta-config.json")
+	if err := config.Save(cfg, configPath); err != nil {
+		return nil, err
+	}
+
+	auditConfig := audit.DefaultAuditConfig()
+	auditConfig.LogDirectory = auditDir
+	options := &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "benchmark",
+		MachineID:   getMachineID(),
+	}
+
+	start := time.Now()
+	summary, err := RunWithOptions(configPath, options)
+	duration := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := summary.SuccessCount - summary.ReviewCount
+	return &BenchmarkResult{
+		Files:    moved,
+		Bytes:    int64(moved) * benchmarkFileSize,
+		Duration: duration,
+		Errors:   summary.ErrorCount,
+	}, nil
+}
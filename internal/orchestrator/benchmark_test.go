@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBenchmarkReportsThroughput runs a small benchmark and verifies that every
+// synthetic file is organized, throughput is nonzero, and nothing is left behind.
+func TestBenchmarkReportsThroughput(t *testing.T) {
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), "sorta-benchmark-*"))
+
+	result, err := Benchmark(20)
+	if err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+
+	if result.Files != 20 || result.Errors != 0 {
+		t.Errorf("Expected 20 files organized without errors, got %+v", result)
+	}
+	if result.Bytes != 20*benchmarkFileSize {
+		t.Errorf("Expected %d bytes, got %d", 20*benchmarkFileSize, result.Bytes)
+	}
+	if result.FilesPerSecond() <= 0 || result.MBPerSecond() <= 0 {
+		t.Errorf("Expected nonzero throughput, got %.2f files/s, %.2f MB/s", result.FilesPerSecond(), result.MBPerSecond())
+	}
+
+	after, _ := filepath.Glob(filepath.Join(os.TempDir(), "sorta-benchmark-*"))
+	if len(after) != len(before) {
+		t.Errorf("Expected benchmark directory to be removed, found %v", after)
+	}
+
+	if _, err := Benchmark(0); err == nil {
+		t.Error("Expected an error for a non-positive file count")
+	}
+}
@@ -0,0 +1,58 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/scanner"
+)
+
+// expectedContentTypes maps file extensions to the content types that
+// http.DetectContentType reports for files of that kind. Only formats that
+// start with a magic number are listed: text formats, and MP3s without an ID3
+// tag, are sniffed unreliably. Files with other extensions are not checked.
+var expectedContentTypes = map[string][]string{
+	".pdf":  {"application/pdf"},
+	".png":  {"image/png"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+	".bmp":  {"image/bmp"},
+	".zip":  {"application/zip"},
+	".docx": {"application/zip"}, // Office documents are zip archives
+	".xlsx": {"application/zip"},
+	".pptx": {"application/zip"},
+	".gz":   {"application/x-gzip"},
+	".mp4":  {"video/mp4"},
+}
+
+// contentMismatch reports whether cfg asks for content checks and the sniffed
+// content type of file differs from what its extension calls for, such as an
+// HTML error page saved as ".pdf". Empty and unreadable files, and files with
+// an extension not in expectedContentTypes, are not mismatched.
+func contentMismatch(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) bool {
+	if !cfg.VerifyExtensionMatchesContent {
+		return false
+	}
+	expected, ok := expectedContentTypes[strings.ToLower(filepath.Ext(file.Name))]
+	if !ok {
+		return false
+	}
+	// http.DetectContentType considers at most the first 512 bytes
+	head, err := filesystem.ReadHead(fsys, file.FullPath, 512)
+	if err != nil || len(head) == 0 {
+		return false
+	}
+	detected, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	for _, contentType := range expected {
+		if detected == contentType {
+			return false
+		}
+	}
+	return true
+}
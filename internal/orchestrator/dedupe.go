@@ -0,0 +1,167 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sorta/internal/audit"
+	"sorta/internal/classifier"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/scanner"
+)
+
+// DuplicateGroup is a set of files that share the same content hash.
+type DuplicateGroup struct {
+	ContentHash string   `json:"contentHash"`
+	Size        int64    `json:"size"`        // Size of each file in bytes
+	Paths       []string `json:"paths"`       // Sorted paths of every copy
+	Reclaimable int64    `json:"reclaimable"` // Bytes freed by keeping a single copy
+}
+
+// DedupeReport summarizes duplicate content across all inbound directories.
+type DedupeReport struct {
+	FilesScanned     int              `json:"filesScanned"`
+	Groups           []DuplicateGroup `json:"groups"`
+	TotalReclaimable int64            `json:"totalReclaimable"`
+}
+
+// DedupeReport hashes every candidate file in the configured inbound directories
+// and reports groups of files with identical content. Nothing is moved or deleted.
+func (o *Orchestrator) DedupeReport() (*DedupeReport, error) {
+	report := &DedupeReport{
+		Groups: []DuplicateGroup{},
+	}
+
+	scanOpts := scanner.DefaultScanOptions()
+	scanOpts.MaxDepth = o.config.GetScanDepth()
+	scanOpts.SymlinkPolicy = o.config.GetSymlinkPolicy()
+
+	resolver := audit.NewIdentityResolver()
+	byHash := make(map[string][]string)
+	sizes := make(map[string]int64)
+
+	for _, inboundDir := range o.config.InboundDirectories {
+		// Skip missing or unreadable directories, consistent with Status
+		if _, err := os.Stat(inboundDir); os.IsNotExist(err) {
+			continue
+		}
+
+		files, err := scanner.ScanWithOptions(inboundDir, scanOpts)
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			identity, err := resolver.CaptureIdentity(file.FullPath)
+			if err != nil {
+				continue
+			}
+			// Empty files all share one hash but are not copies of each other
+			if identity.Size == 0 {
+				continue
+			}
+			report.FilesScanned++
+			byHash[identity.ContentHash] = append(byHash[identity.ContentHash], file.FullPath)
+			sizes[identity.ContentHash] = identity.Size
+		}
+	}
+
+	for hash, paths := range byHash {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		size := sizes[hash]
+		group := DuplicateGroup{
+			ContentHash: hash,
+			Size:        size,
+			Paths:       paths,
+			Reclaimable: size * int64(len(paths)-1),
+		}
+		report.Groups = append(report.Groups, group)
+		report.TotalReclaimable += group.Reclaimable
+	}
+
+	// Largest savings first, then by hash for stable output
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Reclaimable != report.Groups[j].Reclaimable {
+			return report.Groups[i].Reclaimable > report.Groups[j].Reclaimable
+		}
+		return report.Groups[i].ContentHash < report.Groups[j].ContentHash
+	})
+
+	return report, nil
+}
+
+// DedupeReportFromPath is a convenience function that creates an orchestrator and runs DedupeReport.
+func DedupeReportFromPath(configPath string) (*DedupeReport, error) {
+	o, err := NewOrchestratorFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return o.DedupeReport()
+}
+
+// runDedupe tracks the files moved in one run by intended destination and
+// content hash, so a later file with the same content and the same target can
+// be skipped instead of being renamed as a duplicate.
+type runDedupe struct {
+	resolver *audit.IdentityResolver
+	options  *Options // Run options deciding destinations
+	moved    map[runDedupeKey]bool
+}
+
+// runDedupeKey identifies a file by where it would be moved and its content.
+type runDedupeKey struct {
+	destination string // Destination path before duplicate renaming
+	contentHash string
+}
+
+// newRunDedupe returns a runDedupe if options enable DedupeWithinRun, or nil.
+func newRunDedupe(options *Options) *runDedupe {
+	if options == nil || !options.DedupeWithinRun {
+		return nil
+	}
+	return &runDedupe{
+		resolver: audit.NewIdentityResolver(),
+		options:  options,
+		moved:    make(map[runDedupeKey]bool),
+	}
+}
+
+// key returns the dedupe key for file. It reports false when d is nil, when
+// the file would not be organized by a prefix or type rule, or when its
+// content cannot be hashed. Empty files are never tracked: they all share the
+// hash of empty content, which says nothing about whether they are the same
+// document.
+func (d *runDedupe) key(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) (runDedupeKey, bool) {
+	if d == nil {
+		return runDedupeKey{}, false
+	}
+
+	var destination string
+	classification := classifyFile(file.Name, cfg)
+	if classification.IsClassified() {
+		destDir, err := classifiedDestination(file, classification, cfg, d.options)
+		if err != nil {
+			return runDedupeKey{}, false
+		}
+		destination = filepath.Join(destDir, classification.NormalisedFilename)
+	} else if classification.Reason == classifier.NoPrefixMatch {
+		if destDir, _, ok := fallbackDestination(fsys, file, cfg, d.options); ok {
+			destination = filepath.Join(destDir, file.Name)
+		}
+	}
+	if destination == "" {
+		return runDedupeKey{}, false
+	}
+
+	identity, err := d.resolver.CaptureIdentity(file.FullPath)
+	if err != nil || identity.Size == 0 {
+		return runDedupeKey{}, false
+	}
+	return runDedupeKey{destination: destination, contentHash: identity.ContentHash}, true
+}
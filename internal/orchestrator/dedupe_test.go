@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// TestDedupeReportGroupsIdenticalFiles verifies that files sharing content are
+// reported as one group and that nothing is moved.
+func TestDedupeReportGroupsIdenticalFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	inboundA := filepath.Join(tempDir, "inboundA")
+	inboundB := filepath.Join(tempDir, "inboundB")
+	os.MkdirAll(inboundA, 0755)
+	os.MkdirAll(inboundB, 0755)
+
+	copyA := filepath.Join(inboundA, "scan.pdf")
+	copyB := filepath.Join(inboundB, "scan copy.pdf")
+	unique := filepath.Join(inboundA, "other.pdf")
+
+	os.WriteFile(copyA, []byte("same content"), 0644)
+	os.WriteFile(copyB, []byte("same content"), 0644)
+	os.WriteFile(unique, []byte("different content"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{inboundA, inboundB},
+	}
+
+	report, err := NewOrchestrator(cfg).DedupeReport()
+	if err != nil {
+		t.Fatalf("DedupeReport failed: %v", err)
+	}
+
+	if report.FilesScanned != 3 {
+		t.Errorf("Expected 3 files scanned, got %d", report.FilesScanned)
+	}
+
+	if len(report.Groups) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %d", len(report.Groups))
+	}
+
+	group := report.Groups[0]
+	if len(group.Paths) != 2 || group.Paths[0] != copyA || group.Paths[1] != copyB {
+		t.Errorf("Expected group paths [%s %s], got %v", copyA, copyB, group.Paths)
+	}
+	if group.Size != int64(len("same content")) {
+		t.Errorf("Expected group size %d, got %d", len("same content"), group.Size)
+	}
+	if report.TotalReclaimable != group.Size {
+		t.Errorf("Expected reclaimable %d, got %d", group.Size, report.TotalReclaimable)
+	}
+
+	for _, path := range []string{copyA, copyB, unique} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to remain in place: %v", path, err)
+		}
+	}
+}
+
+// TestDedupeWithinRunSkipsIdenticalFiles verifies that with DedupeWithinRun a
+// file identical to one already moved to the same destination in the run is
+// skipped with INTRA_RUN_DUPLICATE, while same-named files with different
+// content are still renamed.
+func TestDedupeWithinRunSkipsIdenticalFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	inboundA := filepath.Join(tempDir, "a")
+	inboundB := filepath.Join(tempDir, "b")
+	outboundDir := filepath.Join(tempDir, "out")
+	os.MkdirAll(inboundA, 0755)
+	os.MkdirAll(inboundB, 0755)
+
+	identical := "Invoice 2024-01-15 Acme.pdf"
+	differing := "Receipt 2024-02-01 Shop.pdf"
+	os.WriteFile(filepath.Join(inboundA, identical), []byte("same"), 0644)
+	os.WriteFile(filepath.Join(inboundB, identical), []byte("same"), 0644)
+	os.WriteFile(filepath.Join(inboundA, differing), []byte("first"), 0644)
+	os.WriteFile(filepath.Join(inboundB, differing), []byte("second"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{inboundA, inboundB},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: outboundDir},
+			{Prefix: "Receipt", OutboundDirectory: outboundDir},
+		},
+	})
+
+	summary, err := RunWithOptions(configPath, &Options{DedupeWithinRun: true})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if summary.SuccessCount != 3 || summary.SkippedCount != 1 || summary.DuplicateCount != 1 {
+		t.Errorf("Expected 3 moved (1 renamed) and 1 skipped, got %d moved, %d renamed, %d skipped",
+			summary.SuccessCount, summary.DuplicateCount, summary.SkippedCount)
+	}
+	for _, result := range summary.Results {
+		if result.EventType == "SKIP" && (result.SourcePath != filepath.Join(inboundB, identical) || result.ReasonCode != string(audit.ReasonIntraRunDuplicate)) {
+			t.Errorf("Unexpected skip: %+v", result)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(inboundB, identical)); err != nil {
+		t.Errorf("Expected identical file to stay in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outboundDir, "2024 Invoice", "Invoice 2024-01-15 Acme_duplicate.pdf")); !os.IsNotExist(err) {
+		t.Error("Expected no renamed copy of the identical file")
+	}
+	if _, err := os.Stat(filepath.Join(outboundDir, "2024 Receipt", "Receipt 2024-02-01 Shop_duplicate.pdf")); err != nil {
+		t.Errorf("Expected differing file to be renamed: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Operations reported by MoveError.
+const (
+	OpCaptureIdentity = "capture identity"
+	OpMove            = "move"
+	OpRouteToReview   = "route to review"
+
+	OpResolveDestination = "resolve destination"
+	OpCommitStaged       = "commit staged file"
+	OpExtractArchive     = "extract archive"
+)
+
+// MoveError reports a failure while processing a single file.
+// Use errors.As to inspect it from Result.Error.
+type MoveError struct {
+	Path string // Source file path
+	Op   string // Operation that failed (OpCaptureIdentity, OpMove, OpRouteToReview, OpResolveDestination, OpCommitStaged, OpExtractArchive)
+	Err  error  // Underlying error
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+// FailFastError reports that a run with FailFast stopped at the first file
+// that failed. Files processed before it keep their results.
+type FailFastError struct {
+	Path string // Source path of the file that failed
+	Err  error  // The file's error
+}
+
+func (e *FailFastError) Error() string {
+	return fmt.Sprintf("stopped at first error: %s: %v", e.Path, e.Err)
+}
+
+func (e *FailFastError) Unwrap() error {
+	return e.Err
+}
+
+// MaxErrorsError reports that a run with MaxErrors was aborted because that
+// many files failed. Files processed before then keep their outcome.
+type MaxErrorsError struct {
+	Limit int // The error threshold that was reached
+}
+
+func (e *MaxErrorsError) Error() string {
+	return fmt.Sprintf("run aborted after %d errors", e.Limit)
+}
+
+// OutboundNotWritableError reports that the check run before any file is
+// touched could not write to an outbound directory.
+type OutboundNotWritableError struct {
+	Dir string // Outbound directory, or the existing parent it would be created in
+	Err error  // Underlying error
+}
+
+func (e *OutboundNotWritableError) Error() string {
+	return fmt.Sprintf("outbound directory %s is not writable: %v", e.Dir, e.Err)
+}
+
+func (e *OutboundNotWritableError) Unwrap() error {
+	return e.Err
+}
+
+// ScanError reports a failure to scan an inbound directory.
+// Use errors.As to inspect it from Summary.ScanErrors.
+type ScanError struct {
+	Path string // Inbound directory path
+	Err  error  // Underlying error (os.ErrNotExist when the directory is missing)
+}
+
+func (e *ScanError) Error() string {
+	if errors.Is(e.Err, os.ErrNotExist) {
+		return fmt.Sprintf("inbound directory does not exist: %s", e.Path)
+	}
+	return fmt.Sprintf("failed to scan %s: %v", e.Path, e.Err)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// countPermissionDenied counts the scan errors caused by missing permissions.
+func countPermissionDenied(errs []error) int {
+	count := 0
+	for _, err := range errs {
+		var scanErr *ScanError
+		if errors.As(err, &scanErr) && errors.Is(err, os.ErrPermission) {
+			count++
+		}
+	}
+	return count
+}
+
+// ValidationError reports invalid run options detected before any file is processed.
+type ValidationError struct {
+	Field   string // Option that failed validation
+	Value   string // Offending value
+	Message string // Human-readable description
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
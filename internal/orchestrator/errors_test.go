@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/config"
+)
+
+// writeTestConfig writes cfg as JSON to tempDir and returns its path.
+func writeTestConfig(t *testing.T, tempDir string, cfg config.Configuration) string {
+	t.Helper()
+	configPath := filepath.Join(tempDir, "config.json")
+	configData, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return configPath
+}
+
+// TestMoveErrorIsExposedOnResult verifies that a failed move is reported as a *MoveError.
+func TestMoveErrorIsExposedOnResult(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+	testFile := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(testFile, []byte("content"), 0644)
+
+	// A regular file where the outbound directory should be makes the move fail
+	blockedTarget := filepath.Join(tempDir, "target")
+	os.WriteFile(blockedTarget, []byte("not a directory"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: blockedTarget},
+		},
+	})
+
+	summary, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if len(summary.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(summary.Results))
+	}
+
+	var moveErr *MoveError
+	if !errors.As(summary.Results[0].Error, &moveErr) {
+		t.Fatalf("Expected *MoveError, got %T: %v", summary.Results[0].Error, summary.Results[0].Error)
+	}
+	if moveErr.Path != testFile {
+		t.Errorf("Expected path %s, got %s", testFile, moveErr.Path)
+	}
+	if moveErr.Op != OpMove {
+		t.Errorf("Expected op %q, got %q", OpMove, moveErr.Op)
+	}
+	if moveErr.Err == nil {
+		t.Error("Expected wrapped underlying error")
+	}
+}
+
+// TestScanErrorIsExposedOnSummary verifies that a missing inbound directory is reported
+// as a *ScanError that also matches os.ErrNotExist.
+func TestScanErrorIsExposedOnSummary(t *testing.T) {
+	tempDir := t.TempDir()
+	missingDir := filepath.Join(tempDir, "missing")
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{missingDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "target")},
+		},
+	})
+
+	summary, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if len(summary.ScanErrors) != 1 {
+		t.Fatalf("Expected 1 scan error, got %d", len(summary.ScanErrors))
+	}
+
+	var scanErr *ScanError
+	if !errors.As(summary.ScanErrors[0], &scanErr) {
+		t.Fatalf("Expected *ScanError, got %T", summary.ScanErrors[0])
+	}
+	if scanErr.Path != missingDir {
+		t.Errorf("Expected path %s, got %s", missingDir, scanErr.Path)
+	}
+	if !errors.Is(summary.ScanErrors[0], os.ErrNotExist) {
+		t.Error("Expected scan error to match os.ErrNotExist")
+	}
+}
+
+// TestValidationErrorIsReturnedForInvalidOptions verifies that an unknown selected
+// prefix is rejected with a *ValidationError before any file is touched.
+func TestValidationErrorIsReturnedForInvalidOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "target")},
+		},
+	})
+
+	_, err := RunWithOptions(configPath, &Options{OnlyPrefixes: []string{"Receipt"}})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+	if validationErr.Field != "OnlyPrefixes" || validationErr.Value != "Receipt" {
+		t.Errorf("Unexpected validation error fields: %+v", validationErr)
+	}
+}
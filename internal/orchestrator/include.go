@@ -0,0 +1,112 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/scanner"
+)
+
+// ReadIncludeList reads a list of files to organize from path: one path per
+// line, absolute or relative to an inbound directory. Blank lines and lines
+// starting with "#" are ignored.
+func ReadIncludeList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read include list: %w", err)
+	}
+	defer file.Close()
+
+	var paths []string
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read include list: %w", err)
+	}
+	return paths, nil
+}
+
+// resolveIncludePaths turns the paths of an include list into absolute paths.
+// A relative path is resolved against the first inbound directory that has a
+// file by that name (or the first inbound directory if none has). It returns
+// an error naming the first absolute path that is not inside an inbound
+// directory.
+func resolveIncludePaths(fsys filesystem.FS, paths, inboundDirs []string) ([]string, error) {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if filepath.IsAbs(path) {
+			path = filepath.Clean(path)
+			if !insideInbound(path, inboundDirs) {
+				return nil, fmt.Errorf("included file %s is not inside an inbound directory", path)
+			}
+			resolved = append(resolved, path)
+			continue
+		}
+
+		if len(inboundDirs) == 0 {
+			return nil, fmt.Errorf("included file %s is relative but no inbound directory is configured", path)
+		}
+		candidate := filepath.Join(inboundDirs[0], path)
+		for _, dir := range inboundDirs {
+			if _, err := fsys.Stat(filepath.Join(dir, path)); err == nil {
+				candidate = filepath.Join(dir, path)
+				break
+			}
+		}
+		if !insideInbound(candidate, inboundDirs) {
+			return nil, fmt.Errorf("included file %s is not inside an inbound directory", path)
+		}
+		resolved = append(resolved, candidate)
+	}
+	return resolved, nil
+}
+
+// insideInbound reports whether path lies below one of inboundDirs.
+func insideInbound(path string, inboundDirs []string) bool {
+	for _, dir := range inboundDirs {
+		rel, err := filepath.Rel(filepath.Clean(dir), path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileEntries returns scanner entries for the regular files among paths.
+// Paths that do not exist or are directories are left out.
+func fileEntries(fsys filesystem.FS, paths, inboundDirs []string) []scanner.FileEntry {
+	var files []scanner.FileEntry
+	for _, path := range paths {
+		info, err := fsys.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, scanner.FileEntry{
+			Name:        info.Name(),
+			FullPath:    path,
+			RelativeDir: relativeDirInInbound(path, inboundDirs),
+		})
+	}
+	return files
+}
+
+// scannedDirectories returns the inbound directories to scan: none when
+// options lists the files to organize, the configured ones otherwise.
+func scannedDirectories(cfg *config.Configuration, options *Options) []string {
+	if options != nil && options.IncludePaths != nil {
+		return nil
+	}
+	return cfg.InboundDirectories
+}
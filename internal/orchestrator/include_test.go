@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sorta/internal/config"
+)
+
+// TestIncludePathsProcessOnlyListedFiles verifies that a run given an include
+// list organizes only the listed files, in dry-run and real mode, and that a
+// path outside the inbound directories is an error.
+func TestIncludePathsProcessOnlyListedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(filepath.Join(sourceDir, "sub"), 0755)
+
+	listedAbs := filepath.Join(sourceDir, "Invoice 2024-03-15 Listed.pdf")
+	listedRel := filepath.Join(sourceDir, "sub", "Invoice 2024-03-16 Listed.pdf")
+	unlisted := []string{
+		filepath.Join(sourceDir, "Invoice 2024-04-20 Other.pdf"),
+		filepath.Join(sourceDir, "sub", "Invoice 2024-04-21 Other.pdf"),
+	}
+	for _, path := range append([]string{listedAbs, listedRel}, unlisted...) {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	listPath := filepath.Join(tempDir, "list.txt")
+	list := "# files to organize\n" + listedAbs + "\n\nsub/Invoice 2024-03-16 Listed.pdf\n"
+	os.WriteFile(listPath, []byte(list), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	paths, err := ReadIncludeList(listPath)
+	if err != nil {
+		t.Fatalf("ReadIncludeList failed: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 listed paths, got %v", paths)
+	}
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, &Options{IncludePaths: paths})
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 2 {
+		t.Errorf("Expected 2 planned moves, got %d", len(dryRun.Moved))
+	}
+
+	summary, err := RunWithOptions(configPath, &Options{IncludePaths: paths})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.TotalFiles != 2 || summary.SuccessCount != 2 {
+		t.Fatalf("Expected 2 files processed and moved, got total=%d moved=%d", summary.TotalFiles, summary.SuccessCount)
+	}
+	for _, path := range []string{listedAbs, listedRel} {
+		if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(path))); err != nil {
+			t.Errorf("Expected listed file to be moved: %v", err)
+		}
+	}
+	for _, path := range unlisted {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected unlisted file to stay in place: %v", err)
+		}
+	}
+
+	outside := filepath.Join(tempDir, "Invoice 2024-05-01 Outside.pdf")
+	os.WriteFile(outside, []byte("outside"), 0644)
+	_, err = RunWithOptions(configPath, &Options{IncludePaths: []string{outside}})
+	if err == nil || !strings.Contains(err.Error(), "not inside an inbound directory") {
+		t.Errorf("Expected an error for a file outside the inbound directories, got %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected file outside the inbound directories to stay in place: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+
+	"sorta/internal/filesystem"
+)
+
+// FailurePredicate decides whether moving the file at path should fail. A
+// non-nil error makes the move fail with that error.
+type FailurePredicate func(path string) error
+
+// failingFS wraps an FS so that moving a file selected by fail returns the
+// predicate's error. Renames of selected files fail, and so does removing
+// them afterwards, so the copy-and-delete fallback fails too and cleans up
+// its copy. It is used to inject failures into the move step for tests and
+// manual QA.
+type failingFS struct {
+	filesystem.FS
+	fail   FailurePredicate
+	failed map[string]error // Sources whose rename was failed
+}
+
+// Rename fails with the predicate's error for selected source paths and
+// renames through the wrapped FS otherwise.
+func (f failingFS) Rename(oldpath, newpath string) error {
+	if err := f.fail(oldpath); err != nil {
+		f.failed[oldpath] = err
+		return err
+	}
+	return f.FS.Rename(oldpath, newpath)
+}
+
+// Remove fails for sources whose rename was failed and removes through the
+// wrapped FS otherwise.
+func (f failingFS) Remove(name string) error {
+	if err, ok := f.failed[name]; ok {
+		return err
+	}
+	return f.FS.Remove(name)
+}
+
+// Chmod changes the mode of name when the wrapped FS can.
+func (f failingFS) Chmod(name string, mode os.FileMode) error {
+	chmoder, ok := f.FS.(filesystem.Chmoder)
+	if !ok {
+		return nil
+	}
+	return chmoder.Chmod(name, mode)
+}
+
+// withFailures returns fsys with the failure predicate from options applied,
+// or fsys itself if none is set.
+func withFailures(fsys filesystem.FS, options *Options) filesystem.FS {
+	if options == nil || options.FailurePredicate == nil {
+		return fsys
+	}
+	return failingFS{FS: fsys, fail: options.FailurePredicate, failed: make(map[string]error)}
+}
+
+// GlobFailures returns a FailurePredicate that fails every file whose name or
+// full path matches pattern (see filepath.Match) with err.
+func GlobFailures(pattern string, err error) FailurePredicate {
+	return func(path string) error {
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return err
+		}
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return err
+		}
+		return nil
+	}
+}
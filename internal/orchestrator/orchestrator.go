@@ -2,13 +2,21 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"sorta/internal/audit"
 	"sorta/internal/classifier"
 	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/matcher"
 	"sorta/internal/organizer"
 	"sorta/internal/scanner"
 )
@@ -24,6 +32,9 @@ type Result struct {
 	EventType       string // Type of event: MOVE, ROUTE_TO_REVIEW, SKIP, ERROR
 	ReasonCode      string // Reason code for skip/review routing
 	Prefix          string // Matched prefix (for per-prefix breakdown in verbose mode)
+	Date            string // YYYY-MM-DD date parsed from the filename (empty if none)
+	Copied          bool   // True if the file was copied from a read-only source instead of moved
+	Archive         string // Archive the file was extracted from (empty if none)
 }
 
 // Summary represents the overall results of a Sorta run.
@@ -36,6 +47,12 @@ type Summary struct {
 	ReviewCount    int // Number of files routed to review
 	Results        []Result
 	ScanErrors     []error
+	RunID          string // Audit run ID (empty when auditing is disabled)
+	Aborted        bool   // Run stopped early because Options.MaxErrors files failed
+
+	// Unprocessed are the source paths of the files a run that stopped early
+	// (fail-fast, MaxErrors, an audit failure or cancellation) never reached.
+	Unprocessed []string
 }
 
 // ProgressCallback is called during file processing to report progress.
@@ -50,8 +67,64 @@ type Options struct {
 	ProgressCallback ProgressCallback   // Progress reporting callback (optional)
 	ScanDepth        *int               // Override scan depth (nil = use config default)
 	SymlinkPolicy    string             // Override symlink policy (empty = use config default)
+	OnlyPrefixes     []string           // Only organize files matching these prefixes (empty = all)
+	NormalizeSpaces  bool               // Collapse whitespace in destination names (overrides config when true)
+	StripDiacritics  bool               // Transliterate accented characters in destination names (overrides config when true)
+	CheckLocks       bool               // Skip files another process has locked instead of moving them
+	LockChecker      LockChecker        // Lock probe used with CheckLocks (nil = filesystem.IsLocked)
+	InboundDirectory string             // Process only this directory instead of the configured inbound directories (empty = use config)
+	MinModTime       time.Time          // Skip files last modified before this time (zero = no cutoff)
+	Stage            string             // Move files into a staging folder of this name below each destination (empty = no staging)
+	NoCreateDirs     bool               // Skip files whose destination directory does not exist instead of creating it
+	DirPermissions   string             // Octal mode for created destination directories, such as "0775" (empty = use config)
+	FailurePredicate FailurePredicate   // Make moves of selected files fail, for testing error paths (nil = no injection)
+	DedupeWithinRun  bool               // Skip files identical to one already moved to the same destination in this run
+	FailFast         bool               // Stop at the first file that fails and end the run as failed
+	MaxErrors        int                // Stop once this many files have failed and end the run as failed (0 = no limit)
+	IncludePaths     []string           // Organize only these files (absolute or inbound-relative) instead of scanning (nil = scan)
+	HashCache        *audit.HashCache   // Reuse content hashes of unchanged files when capturing identities (nil = always hash)
+
+	// DestinationResolver decides where files matched by a prefix rule go
+	// (nil = the configured <outbound>/<year> <prefix>/ layout)
+	DestinationResolver DestinationResolver
+
+	// SkipOutboundCheck skips writing a probe file to every outbound directory
+	// before the run, which otherwise stops the run if one is not writable
+	SkipOutboundCheck bool
+
+	// TransactionalStaging moves files into a staging area below the audit log
+	// directory first and to their destinations only once all files are
+	// processed. A run that dies in between is finished by ResumeStagedRuns.
+	// Requires AuditConfig.
+	TransactionalStaging bool
+
+	// VerifyAfterMove reads back each file copied to its destination, as on a
+	// move to another volume, before the source is removed. A copy that differs
+	// is removed, the source is kept, and the file is reported as an ERROR of
+	// type POST_MOVE_VERIFY_FAILED. Not supported with TransactionalStaging.
+	VerifyAfterMove bool
+
+	// Tracer records every matching and routing decision of the run, for
+	// debugging (nil = no trace)
+	Tracer *Tracer
+
+	// Settle skips files still being written when the run starts: each file
+	// is stat'ed again at least Settle after the run first stat'ed it, and
+	// skipped with reason STILL_WRITING if its size or modification time
+	// changed (0 = no check)
+	Settle time.Duration
+
+	// Context stops the run before the next file once it is done, ending the
+	// audit run as INTERRUPTED and returning its error (nil = run to the end)
+	Context context.Context
+
+	stopBeforeCommit bool                // For tests: return before committing staged files, as if the process died
+	settleSleep      func(time.Duration) // For tests: waits out Settle (nil = time.Sleep)
 }
 
+// LockChecker reports whether the file at path is locked by another process.
+type LockChecker func(path string) (bool, error)
+
 // RunOptions configures the run operation for dry-run and verbose modes.
 // Requirements: 1.1, 1.2, 1.3 - Dry run mode configuration
 type RunOptions struct {
@@ -93,6 +166,7 @@ func RunDryRunWithOptions(configPath string, opts RunOptions, options *Options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
+	cfg = applyConfigOverrides(cfg, options)
 
 	result := &RunResult{
 		Moved:     make([]FileOperation, 0),
@@ -115,18 +189,38 @@ func RunDryRunWithOptions(configPath string, opts RunOptions, options *Options)
 		}
 	}
 
+	var onlyPrefixes []string
+	if options != nil {
+		onlyPrefixes = options.OnlyPrefixes
+	}
+	if err := validateOnlyPrefixes(onlyPrefixes, cfg); err != nil {
+		return nil, err
+	}
+
 	// Scan all inbound directories and collect files
+	// Unreadable subdirectories are reported and skipped
+	scanOpts.OnPermissionDenied = func(err *scanner.ScanError) {
+		result.Errors = append(result.Errors, &ScanError{Path: err.Path, Err: err.Err})
+	}
+
 	var allFiles []scanner.FileEntry
-	for _, sourceDir := range cfg.InboundDirectories {
+	if options != nil && options.IncludePaths != nil {
+		paths, err := resolveIncludePaths(filesystem.Default, options.IncludePaths, cfg.InboundDirectories)
+		if err != nil {
+			return nil, err
+		}
+		allFiles = fileEntries(filesystem.Default, paths, cfg.InboundDirectories)
+	}
+	for _, sourceDir := range scannedDirectories(cfg, options) {
 		// Runtime path validation: check if directory exists before scanning
 		if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
-			result.Errors = append(result.Errors, fmt.Errorf("inbound directory does not exist: %s", sourceDir))
+			result.Errors = append(result.Errors, &ScanError{Path: sourceDir, Err: err})
 			continue
 		}
 
 		files, err := scanner.ScanWithOptions(sourceDir, scanOpts)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("failed to scan %s: %w", sourceDir, err))
+			result.Errors = append(result.Errors, &ScanError{Path: sourceDir, Err: err})
 			continue
 		}
 		allFiles = append(allFiles, files...)
@@ -144,8 +238,39 @@ func RunDryRunWithOptions(configPath string, opts RunOptions, options *Options)
 
 	// Dry-run mode: collect operations without executing
 	// Requirements: 1.1, 1.4, 1.5 - No filesystem modifications, no audit logging
+	trace := tracer(options)
+	trace.traceCandidates(allFiles)
 	for _, file := range allFiles {
-		op := classifyFileOperation(file, cfg)
+		if !prefixSelected(file, cfg, onlyPrefixes) {
+			result.Skipped = append(result.Skipped, FileOperation{
+				Source: file.FullPath,
+				Reason: string(audit.ReasonPrefixNotSelected),
+			})
+			continue
+		}
+		if modifiedBefore(filesystem.Default, file, options) {
+			result.Skipped = append(result.Skipped, FileOperation{
+				Source: file.FullPath,
+				Reason: string(audit.ReasonBeforeLastRun),
+			})
+			continue
+		}
+		switch zeroByteAction(filesystem.Default, file, cfg) {
+		case config.ZeroByteSkip:
+			result.Skipped = append(result.Skipped, skippedOperation(file, audit.ReasonZeroByte).operation)
+			continue
+		case config.ZeroByteReview:
+			result.ForReview = append(result.ForReview, reviewOperation(file, audit.ReasonZeroByte).operation)
+			continue
+		}
+		if tooSmall(filesystem.Default, file, cfg) {
+			result.Skipped = append(result.Skipped, skippedOperation(file, audit.ReasonTooSmall).operation)
+			continue
+		}
+
+		trace.traceDecisions(filesystem.Default, file, cfg, options)
+		op := classifyFileOperation(file, cfg, options)
+		trace.traceOperation(op)
 		switch op.category {
 		case "moved":
 			result.Moved = append(result.Moved, op.operation)
@@ -169,47 +294,138 @@ type classifiedOperation struct {
 
 // classifyFileOperation determines what would happen to a file without actually moving it.
 // This is used in dry-run mode to preview operations.
-func classifyFileOperation(file scanner.FileEntry, cfg *config.Configuration) classifiedOperation {
+func classifyFileOperation(file scanner.FileEntry, cfg *config.Configuration, options *Options) classifiedOperation {
+	// The file inside a single-file archive is planned as if it were already
+	// extracted next to the archive
+	if member, ok := extractableMember(filesystem.Default, file, cfg); ok {
+		extracted := scanner.FileEntry{
+			Name:        member.name,
+			FullPath:    filepath.Join(filepath.Dir(file.FullPath), member.name),
+			RelativeDir: file.RelativeDir,
+		}
+		op := classifyFileOperation(extracted, cfg, options)
+		op.operation.Source = file.FullPath
+		return op
+	}
+
+	if missingExtension(file, cfg) {
+		if cfg.IsReadOnlySource(file.FullPath) {
+			return skippedOperation(file, audit.ReasonReadOnlySource)
+		}
+		return reviewOperation(file, audit.ReasonNoExtension)
+	}
+	if contentMismatch(filesystem.Default, file, cfg) {
+		if cfg.IsReadOnlySource(file.FullPath) {
+			return skippedOperation(file, audit.ReasonReadOnlySource)
+		}
+		return reviewOperation(file, audit.ReasonContentMismatch)
+	}
+
 	// Classify the file
-	classification := classifier.Classify(file.Name, cfg.PrefixRules)
+	classification := classifyFile(file.Name, cfg)
+
+	if classification.Reason == classifier.NoPrefixMatch {
+		if destDir, reason, ok := fallbackDestination(filesystem.Default, file, cfg, options); ok {
+			if destDirMissing(filesystem.Default, destDir, options) {
+				return skippedOperation(file, audit.ReasonDestDirMissing)
+			}
+			if organizer.DestinationTooLong(destDir, file.Name) {
+				return reviewOperation(file, audit.ReasonPathTooLong)
+			}
+			destPath := filepath.Join(destDir, file.Name)
+			if alreadyOrganized(file, destPath) {
+				return skippedOperation(file, audit.ReasonAlreadyOrganized)
+			}
+			if organizer.FileExists(destPath) {
+				destPath = filepath.Join(destDir, organizer.DuplicateNameWithFS(filesystem.Default, destDir, file.Name, cfg))
+			}
+			return classifiedOperation{
+				category: "moved",
+				operation: FileOperation{
+					Source:      file.FullPath,
+					Destination: destPath,
+					Reason:      string(reason),
+				},
+			}
+		}
+	}
 
 	if classification.IsUnclassified() {
+		// Unclassified files in a read-only source are left in place
+		if cfg.IsReadOnlySource(file.FullPath) {
+			return skippedOperation(file, audit.ReasonReadOnlySource)
+		}
+
 		// File would go to for-review directory
-		destDir := organizer.GetForReviewPath(filepath.Dir(file.FullPath))
-		destPath := filepath.Join(destDir, file.Name)
+		return reviewOperation(file, audit.ReasonCode(classification.Reason))
+	}
 
+	// File is classified - would be moved to organized location
+	prefix := classification.Prefix
+	destDir, err := classifiedDestination(file, classification, cfg, options)
+	if err != nil {
 		return classifiedOperation{
-			category: "for_review",
-			operation: FileOperation{
-				Source:      file.FullPath,
-				Destination: destPath,
-				Prefix:      "", // Empty for for-review files
-				Reason:      string(classification.Reason),
-			},
+			category:  "error",
+			operation: FileOperation{Source: file.FullPath, Reason: err.Error()},
 		}
 	}
-
-	// File is classified - would be moved to organized location
-	prefix := extractPrefixFromNormalisedFilename(classification.NormalisedFilename)
-	subfolder := fmt.Sprintf("%d %s", classification.Year, prefix)
-	destDir := filepath.Join(classification.OutboundDirectory, subfolder)
+	if destDirMissing(filesystem.Default, destDir, options) {
+		return skippedOperation(file, audit.ReasonDestDirMissing)
+	}
+	classification, fits := fitDestination(classification, destDir, cfg)
+	if !fits {
+		return reviewOperation(file, audit.ReasonPathTooLong)
+	}
 	destFilename := classification.NormalisedFilename
 
 	// Check if this would be a duplicate (file already exists at destination)
 	destPath := filepath.Join(destDir, destFilename)
+	if alreadyOrganized(file, destPath) {
+		return skippedOperation(file, audit.ReasonAlreadyOrganized)
+	}
 	if organizer.FileExists(destPath) {
 		// In dry-run, we predict the duplicate name
-		destFilename = organizer.GenerateDuplicateName(destDir, destFilename)
+		destFilename = organizer.DuplicateNameWithFS(filesystem.Default, destDir, destFilename, cfg)
 		destPath = filepath.Join(destDir, destFilename)
 	}
 
+	reason := ""
+	if classification.IsUndated() {
+		reason = string(audit.ReasonMatchedNoDate)
+	}
+
 	return classifiedOperation{
 		category: "moved",
 		operation: FileOperation{
 			Source:      file.FullPath,
 			Destination: destPath,
 			Prefix:      prefix,
-			Reason:      "",
+			Reason:      reason,
+		},
+	}
+}
+
+// skippedOperation returns the dry-run operation for a file left in place for reason.
+func skippedOperation(file scanner.FileEntry, reason audit.ReasonCode) classifiedOperation {
+	return classifiedOperation{
+		category: "skipped",
+		operation: FileOperation{
+			Source: file.FullPath,
+			Reason: string(reason),
+		},
+	}
+}
+
+// reviewOperation returns the dry-run operation for a file routed to the
+// for-review folder within its source directory for reason.
+func reviewOperation(file scanner.FileEntry, reason audit.ReasonCode) classifiedOperation {
+	return classifiedOperation{
+		category: "for_review",
+		operation: FileOperation{
+			Source:      file.FullPath,
+			Destination: filepath.Join(organizer.GetForReviewPath(filepath.Dir(file.FullPath)), file.Name),
+			Prefix:      "", // Empty for for-review files
+			Reason:      string(reason),
 		},
 	}
 }
@@ -262,46 +478,31 @@ func Run(configPath string) (*Summary, error) {
 // If options.AuditConfig is provided, all file operations are logged to the audit trail.
 // Requirements: 11.1, 11.4 - Fail-fast on audit write failure, audit before move
 func RunWithOptions(configPath string, options *Options) (*Summary, error) {
-	// Load configuration
-	cfg, err := config.Load(configPath)
+	o, err := NewOrchestratorFromPath(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load configuration: %w", err)
+		return nil, err
 	}
+	return o.Run(options)
+}
+
+// Run executes the file organization workflow using the orchestrator's configuration
+// and filesystem. Options behave as in RunWithOptions. Audit logging and content
+// hashing always use the real filesystem, so leave options.AuditConfig nil when
+// running against an in-memory filesystem.
+func (o *Orchestrator) Run(options *Options) (*Summary, error) {
+	cfg := applyConfigOverrides(o.config, options)
 
 	summary := &Summary{
 		Results:    make([]Result, 0),
 		ScanErrors: make([]error, 0),
 	}
 
-	// Initialize audit writer if audit config is provided
-	var auditWriter *audit.AuditWriter
-	var runID audit.RunID
-	var identityResolver *audit.IdentityResolver
-
-	if options != nil && options.AuditConfig != nil {
-		auditWriter, err = audit.NewAuditWriter(*options.AuditConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
-		}
-		defer auditWriter.Close()
-
-		// Start the audit run before processing any files
-		// Requirements: 11.4 - audit record must be written before file operations
-		appVersion := options.AppVersion
-		if appVersion == "" {
-			appVersion = "unknown"
-		}
-		machineID := options.MachineID
-		if machineID == "" {
-			machineID = getMachineID()
-		}
-
-		runID, err = auditWriter.StartRun(appVersion, machineID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start audit run: %w", err)
-		}
-
-		identityResolver = audit.NewIdentityResolver()
+	var onlyPrefixes []string
+	if options != nil {
+		onlyPrefixes = options.OnlyPrefixes
+	}
+	if err := validateOnlyPrefixes(onlyPrefixes, cfg); err != nil {
+		return nil, err
 	}
 
 	// Scan all inbound directories and collect files
@@ -311,6 +512,7 @@ func RunWithOptions(configPath string, options *Options) (*Summary, error) {
 	// Use config values as defaults
 	scanOpts.MaxDepth = cfg.GetScanDepth()
 	scanOpts.SymlinkPolicy = cfg.GetSymlinkPolicy()
+	scanOpts.FS = o.fs
 
 	// Apply overrides from options
 	if options != nil {
@@ -322,33 +524,204 @@ func RunWithOptions(configPath string, options *Options) (*Summary, error) {
 		}
 	}
 
+	// Unreadable subdirectories are reported and skipped rather than aborting
+	// the scan of the whole inbound directory
+	scanOpts.OnPermissionDenied = func(err *scanner.ScanError) {
+		summary.ScanErrors = append(summary.ScanErrors, &ScanError{Path: err.Path, Err: err.Err})
+	}
+
 	var allFiles []scanner.FileEntry
-	for _, sourceDir := range cfg.InboundDirectories {
+	if options != nil && options.IncludePaths != nil {
+		paths, err := resolveIncludePaths(o.fs, options.IncludePaths, cfg.InboundDirectories)
+		if err != nil {
+			return nil, err
+		}
+		allFiles = fileEntries(o.fs, paths, cfg.InboundDirectories)
+	}
+	for _, sourceDir := range scannedDirectories(cfg, options) {
 		// Runtime path validation: check if directory exists before scanning
 		// Requirements: 4.1, 4.2 - validate inbound directories exist before processing
-		if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
-			summary.ScanErrors = append(summary.ScanErrors, fmt.Errorf("inbound directory does not exist: %s", sourceDir))
+		if _, err := o.fs.Stat(sourceDir); os.IsNotExist(err) {
+			summary.ScanErrors = append(summary.ScanErrors, &ScanError{Path: sourceDir, Err: err})
 			continue
 		}
 
 		files, err := scanner.ScanWithOptions(sourceDir, scanOpts)
 		if err != nil {
 			// Log error and continue with remaining directories (Requirement 2.2)
-			summary.ScanErrors = append(summary.ScanErrors, fmt.Errorf("failed to scan %s: %w", sourceDir, err))
+			summary.ScanErrors = append(summary.ScanErrors, &ScanError{Path: sourceDir, Err: err})
 			continue
 		}
 		allFiles = append(allFiles, files...)
 	}
 
+	return o.organizeFiles(cfg, allFiles, onlyPrefixes, summary, options)
+}
+
+// RunFiles organizes the given files as a single run, recording one audit run when
+// options.AuditConfig is set. Paths that no longer exist or are directories are
+// ignored, and no audit run is recorded if none remain. Watch mode uses it to
+// organize a batch of new files at once.
+func (o *Orchestrator) RunFiles(paths []string, options *Options) (*Summary, error) {
+	cfg := applyConfigOverrides(o.config, options)
+
+	summary := &Summary{
+		Results:    make([]Result, 0),
+		ScanErrors: make([]error, 0),
+	}
+
+	var onlyPrefixes []string
+	if options != nil {
+		onlyPrefixes = options.OnlyPrefixes
+	}
+	if err := validateOnlyPrefixes(onlyPrefixes, cfg); err != nil {
+		return nil, err
+	}
+
+	files := fileEntries(o.fs, paths, cfg.InboundDirectories)
+
+	// Nothing left to organize, so don't record an empty audit run
+	if len(files) == 0 {
+		return summary, nil
+	}
+
+	return o.organizeFiles(cfg, files, onlyPrefixes, summary, options)
+}
+
+// RunFilesWithOptions is a convenience function that loads configuration and runs RunFiles.
+func RunFilesWithOptions(configPath string, paths []string, options *Options) (*Summary, error) {
+	o, err := NewOrchestratorFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return o.RunFiles(paths, options)
+}
+
+// organizeFiles classifies and moves allFiles within a single audit run and fills
+// in summary. Requirements: 11.1, 11.4 - Fail-fast on audit write failure, audit before move
+func (o *Orchestrator) organizeFiles(cfg *config.Configuration, allFiles []scanner.FileEntry, onlyPrefixes []string, summary *Summary, options *Options) (*Summary, error) {
+	if options != nil && options.TransactionalStaging && options.AuditConfig == nil {
+		return nil, fmt.Errorf("transactional staging requires an audit log")
+	}
+	if options != nil && options.TransactionalStaging && options.VerifyAfterMove {
+		return nil, fmt.Errorf("verifying moves is not supported with transactional staging")
+	}
+
+	if err := checkDirectoryNesting(cfg, options); err != nil {
+		return nil, err
+	}
+
+	// Find an unwritable outbound directory before any file is touched
+	if options == nil || !options.SkipOutboundCheck {
+		if err := checkOutboundWritable(o.fs, cfg, options != nil && options.NoCreateDirs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize audit writer if audit config is provided
+	var auditWriter *audit.AuditWriter
+	var runID audit.RunID
+	var identityResolver *audit.IdentityResolver
+
+	if options != nil && options.AuditConfig != nil {
+		var err error
+		auditWriter, err = audit.NewAuditWriter(*options.AuditConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
+		}
+		defer auditWriter.Close()
+
+		// Start the audit run before processing any files
+		// Requirements: 11.4 - audit record must be written before file operations
+		appVersion := options.AppVersion
+		if appVersion == "" {
+			appVersion = "unknown"
+		}
+		machineID := options.MachineID
+		if machineID == "" {
+			machineID = getMachineID()
+		}
+
+		// The inbound directories let undo tell when it would restore files
+		// into an inbound directory this run did not scan
+		metadata := map[string]string{
+			"inboundDirectories": strings.Join(cfg.InboundDirectories, string(os.PathListSeparator)),
+		}
+		if options.Stage != "" {
+			metadata["stage"] = options.Stage
+		}
+		runID, err = auditWriter.StartRunWithMetadata(appVersion, machineID, metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start audit run: %w", err)
+		}
+		summary.RunID = string(runID)
+
+		identityResolver = audit.NewIdentityResolver()
+		if options.HashCache != nil {
+			identityResolver = audit.NewIdentityResolverWithCache(options.HashCache)
+		}
+	}
+
 	summary.TotalFiles = len(allFiles)
+	trace := tracer(options)
+	trace.traceCandidates(allFiles)
 
 	// Track if we need to fail-fast due to audit write failure
 	var auditError error
+	// Track if we stopped at the first failed file because of options.FailFast
+	var failFastError error
+	// Track if we stopped because options.MaxErrors files failed
+	var maxErrorsError error
+	// Track if the run was cancelled through options.Context
+	var cancelError error
+
+	fsys := withVerification(withFailures(o.fs, options), options)
+	var staging *stagingFS
+	if options != nil && options.TransactionalStaging {
+		staging = newStagingFS(fsys, options.AuditConfig.LogDirectory, runID)
+		fsys = staging
+	}
+	dedupe := newRunDedupe(options)
+	settle := newSettleCheck(o.fs, allFiles, options)
 
 	// Process each file
 	for i, file := range allFiles {
-		result := processFileWithAudit(file, cfg, auditWriter, identityResolver)
+		if options != nil && options.Context != nil && options.Context.Err() != nil {
+			cancelError = options.Context.Err()
+			break
+		}
+
+		var result Result
+		if !prefixSelected(file, cfg, onlyPrefixes) {
+			result = skipFile(file, audit.ReasonPrefixNotSelected, auditWriter)
+		} else if modifiedBefore(o.fs, file, options) {
+			result = skipFile(file, audit.ReasonBeforeLastRun, auditWriter)
+		} else if fileLocked(file, options) {
+			result = skipFile(file, audit.ReasonFileLocked, auditWriter)
+		} else if settle.stillWriting(file) {
+			result = skipFile(file, audit.ReasonStillWriting, auditWriter)
+		} else if action := zeroByteAction(o.fs, file, cfg); action == config.ZeroByteSkip {
+			result = skipFile(file, audit.ReasonZeroByte, auditWriter)
+		} else if action == config.ZeroByteReview {
+			result = routeToReview(fsys, file, audit.ReasonZeroByte, cfg, auditWriter)
+		} else if tooSmall(o.fs, file, cfg) {
+			result = skipFile(file, audit.ReasonTooSmall, auditWriter)
+		} else if key, tracked := dedupe.key(o.fs, file, cfg); tracked && dedupe.moved[key] {
+			result = skipFile(file, audit.ReasonIntraRunDuplicate, auditWriter)
+		} else {
+			trace.traceDecisions(fsys, file, cfg, options)
+			result = processFileWithAudit(fsys, file, cfg, auditWriter, identityResolver, options)
+			// The hash cached when the identity was captured now belongs to
+			// the destination, where undo looks the file up
+			if result.Success && result.DestinationPath != "" && options != nil && options.HashCache != nil {
+				options.HashCache.Moved(result.SourcePath, result.DestinationPath, result.Copied)
+			}
+			if tracked && result.Success {
+				dedupe.moved[key] = true
+			}
+		}
 		summary.Results = append(summary.Results, result)
+		trace.traceResult(result)
 
 		if result.Success {
 			summary.SuccessCount++
@@ -378,13 +751,45 @@ func RunWithOptions(configPath string, options *Options) (*Summary, error) {
 			auditError = result.Error
 			break
 		}
+
+		// Stop at the first failed file when fail-fast is requested
+		if result.EventType == "ERROR" && options != nil && options.FailFast {
+			failFastError = &FailFastError{Path: file.FullPath, Err: result.Error}
+			break
+		}
+
+		// Stop once the error threshold is reached
+		if result.EventType == "ERROR" && options != nil && options.MaxErrors > 0 && summary.ErrorCount >= options.MaxErrors {
+			summary.Aborted = true
+			maxErrorsError = &MaxErrorsError{Limit: options.MaxErrors}
+			break
+		}
+	}
+
+	// Results are appended in file order, so the rest were never reached
+	for _, file := range allFiles[len(summary.Results):] {
+		summary.Unprocessed = append(summary.Unprocessed, file.FullPath)
+	}
+
+	// Move the staged files to their destinations once all files are processed
+	var commitError error
+	if staging != nil {
+		if options.stopBeforeCommit {
+			staging.release()
+			return summary, nil
+		}
+		if _, errs := staging.commit(); len(errs) > 0 {
+			commitError = fmt.Errorf("failed to commit staged files: %w", errors.Join(errs...))
+		}
 	}
 
 	// End the audit run with summary
 	if auditWriter != nil {
 		runStatus := audit.RunStatusCompleted
-		if auditError != nil {
+		if auditError != nil || failFastError != nil || maxErrorsError != nil || commitError != nil {
 			runStatus = audit.RunStatusFailed
+		} else if cancelError != nil {
+			runStatus = audit.RunStatusInterrupted
 		} else if len(summary.ScanErrors) > 0 || summary.ErrorCount > 0 {
 			runStatus = audit.RunStatusCompleted // Still completed, just with errors
 		}
@@ -410,21 +815,226 @@ func RunWithOptions(configPath string, options *Options) (*Summary, error) {
 	if auditError != nil {
 		return summary, auditError
 	}
+	if failFastError != nil {
+		return summary, failFastError
+	}
+	if maxErrorsError != nil {
+		return summary, maxErrorsError
+	}
+	if commitError != nil {
+		return summary, commitError
+	}
+	if cancelError != nil {
+		return summary, cancelError
+	}
 
 	return summary, nil
 }
 
+// validateOnlyPrefixes checks that every selected prefix has a configured rule.
+func validateOnlyPrefixes(onlyPrefixes []string, cfg *config.Configuration) error {
+	for _, selected := range onlyPrefixes {
+		found := false
+		for _, rule := range cfg.PrefixRules {
+			if strings.EqualFold(rule.Prefix, selected) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ValidationError{
+				Field:   "OnlyPrefixes",
+				Value:   selected,
+				Message: fmt.Sprintf("selected prefix %q does not match any configured prefix rule", selected),
+			}
+		}
+	}
+	return nil
+}
+
+// prefixSelected reports whether a file should be organized given the selected prefixes.
+// With no selection every file is processed. Otherwise the file is matched against
+// all rules (so the longest prefix still wins) and is processed only if the matched
+// rule is one of the selected prefixes.
+func prefixSelected(file scanner.FileEntry, cfg *config.Configuration, onlyPrefixes []string) bool {
+	if len(onlyPrefixes) == 0 {
+		return true
+	}
+
+	match := matcher.MatchWithOptions(file.Name, cfg.PrefixRules, matcher.MatchOptions{
+		AllowExtraSpaces: cfg.NormalizeSpaces,
+	})
+	if !match.Matched {
+		return false
+	}
+
+	for _, selected := range onlyPrefixes {
+		if strings.EqualFold(match.Rule.Prefix, selected) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipFile records a file left untouched for reason (e.g. its prefix was not selected).
+func skipFile(file scanner.FileEntry, reason audit.ReasonCode, auditWriter *audit.AuditWriter) Result {
+	if auditWriter != nil {
+		if err := auditWriter.RecordSkip(file.FullPath, reason); err != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+
+	return Result{
+		SourcePath: file.FullPath,
+		Success:    false,
+		EventType:  "SKIP",
+		ReasonCode: string(reason),
+	}
+}
+
+// modifiedBefore reports whether a MinModTime cutoff is set and the file was
+// last modified before it. Files that cannot be stat'ed are not skipped.
+func modifiedBefore(fsys filesystem.FS, file scanner.FileEntry, options *Options) bool {
+	if options == nil || options.MinModTime.IsZero() {
+		return false
+	}
+	info, err := fsys.Stat(file.FullPath)
+	return err == nil && info.ModTime().Before(options.MinModTime)
+}
+
+// fileLocked reports whether lock checking is enabled and the file is locked.
+// The probe is best-effort: if it fails, the file is treated as unlocked.
+func fileLocked(file scanner.FileEntry, options *Options) bool {
+	if options == nil || !options.CheckLocks {
+		return false
+	}
+	checker := options.LockChecker
+	if checker == nil {
+		checker = filesystem.IsLocked
+	}
+	locked, err := checker(file.FullPath)
+	return err == nil && locked
+}
+
+// missingExtension reports whether cfg requires an extension and the name of
+// file has none (a trailing dot does not count as one).
+func missingExtension(file scanner.FileEntry, cfg *config.Configuration) bool {
+	ext := filepath.Ext(file.Name)
+	return cfg.RequireExtension && (ext == "" || ext == ".")
+}
+
+// zeroByteAction returns the configured zero-byte action if file is empty, or
+// "process" if it has content or cannot be read. Empty files in a read-only
+// source are never moved, so "review" becomes "skip" for them.
+func zeroByteAction(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) string {
+	action := cfg.GetZeroByteAction()
+	if action == config.ZeroByteProcess {
+		return action
+	}
+	info, err := fsys.Stat(file.FullPath)
+	if err != nil || info.Size() != 0 {
+		return config.ZeroByteProcess
+	}
+	if action == config.ZeroByteReview && cfg.IsReadOnlySource(file.FullPath) {
+		return config.ZeroByteSkip
+	}
+	return action
+}
+
+// tooSmall reports whether cfg sets a minimum file size and file is smaller.
+// Files that cannot be stat'ed are not skipped. It is checked after
+// zeroByteAction, which decides first for empty files unless it is "process".
+func tooSmall(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) bool {
+	if cfg.MinFileSize <= 0 {
+		return false
+	}
+	info, err := fsys.Stat(file.FullPath)
+	return err == nil && info.Size() < cfg.MinFileSize
+}
+
+// destDirMissing reports whether options disable directory creation for the
+// run and destDir does not exist yet. A staging folder is created as usual;
+// only the destination it is placed in has to exist.
+func destDirMissing(fsys filesystem.FS, destDir string, options *Options) bool {
+	if options == nil || !options.NoCreateDirs {
+		return false
+	}
+	if options.Stage != "" {
+		destDir = filepath.Dir(destDir)
+	}
+	info, err := fsys.Stat(destDir)
+	return err != nil || !info.IsDir()
+}
+
+// insufficientSpace reports whether err is the organizer refusing to copy a
+// file to a volume without enough free space (see config.CheckCopySpace).
+func insufficientSpace(err error) bool {
+	var moveErr *organizer.MoveError
+	return errors.As(err, &moveErr) && moveErr.Type == organizer.InsufficientSpace
+}
+
+// classifyFile classifies a filename using the rules and options from cfg.
+func classifyFile(filename string, cfg *config.Configuration) *classifier.Classification {
+	return classifier.ClassifyWithOptions(filename, cfg.PrefixRules, classifier.Options{
+		NormalizeSpaces:   cfg.NormalizeSpaces,
+		StripDiacritics:   cfg.StripDiacritics,
+		UndatedFolder:     cfg.UndatedFolder,
+		DateAnywhere:      cfg.GetDatePosition() == config.DatePositionAnywhere,
+		TwoDigitYears:     cfg.TwoDigitYears,
+		TwoDigitYearPivot: cfg.GetTwoDigitYearPivot(),
+	})
+}
+
+// applyConfigOverrides returns cfg with run options that override configuration
+// applied. cfg itself is never modified.
+func applyConfigOverrides(cfg *config.Configuration, options *Options) *config.Configuration {
+	if options == nil {
+		return cfg
+	}
+	normalize := options.NormalizeSpaces && !cfg.NormalizeSpaces
+	stripDiacritics := options.StripDiacritics && !cfg.StripDiacritics
+	if !normalize && !stripDiacritics && options.InboundDirectory == "" && options.DirPermissions == "" {
+		return cfg
+	}
+
+	overridden := *cfg
+	if normalize {
+		overridden.NormalizeSpaces = true
+	}
+	if stripDiacritics {
+		overridden.StripDiacritics = true
+	}
+	if options.InboundDirectory != "" {
+		overridden.InboundDirectories = []string{options.InboundDirectory}
+	}
+	if options.DirPermissions != "" {
+		overridden.DirPermissions = options.DirPermissions
+	}
+	return &overridden
+}
+
 // processFile classifies and organizes a single file.
 func processFile(file scanner.FileEntry, cfg *config.Configuration) Result {
-	return processFileWithAudit(file, cfg, nil, nil)
+	return processFileWithAudit(filesystem.Default, file, cfg, nil, nil, nil)
 }
 
 // processFileWithAudit classifies and organizes a single file with optional audit support.
 // If auditWriter is provided, it records audit events for each operation.
+// All file operations go through fsys.
 // Requirements: 11.4 - audit record must be durably written before file move
-func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, auditWriter *audit.AuditWriter, identityResolver *audit.IdentityResolver) Result {
+func processFileWithAudit(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration, auditWriter *audit.AuditWriter, identityResolver *audit.IdentityResolver, options *Options) Result {
+	// Single-file archives are unpacked and the file inside is organized
+	if member, ok := extractableMember(fsys, file, cfg); ok {
+		return processArchive(fsys, file, member, cfg, auditWriter, identityResolver, options)
+	}
+
 	// Classify the file
-	classification := classifier.Classify(file.Name, cfg.PrefixRules)
+	classification := classifyFile(file.Name, cfg)
 
 	// Capture file identity before any operation (if auditing is enabled)
 	var fileIdentity *audit.FileIdentity
@@ -445,74 +1055,84 @@ func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, aud
 			return Result{
 				SourcePath: file.FullPath,
 				Success:    false,
-				Error:      err,
+				Error:      &MoveError{Path: file.FullPath, Op: OpCaptureIdentity, Err: err},
 				EventType:  "ERROR",
 			}
 		}
 	}
 
+	// Files without an extension go to review when one is required, as do
+	// files whose content does not match their extension; files in a
+	// read-only source are left in place instead
+	if missingExtension(file, cfg) {
+		if cfg.IsReadOnlySource(file.FullPath) {
+			return skipFile(file, audit.ReasonReadOnlySource, auditWriter)
+		}
+		return routeToReview(fsys, file, audit.ReasonNoExtension, cfg, auditWriter)
+	}
+	if contentMismatch(fsys, file, cfg) {
+		if cfg.IsReadOnlySource(file.FullPath) {
+			return skipFile(file, audit.ReasonReadOnlySource, auditWriter)
+		}
+		return routeToReview(fsys, file, audit.ReasonContentMismatch, cfg, auditWriter)
+	}
+
+	// Files from read-only sources are copied and left in place
+	if cfg.IsReadOnlySource(file.FullPath) {
+		return processReadOnlyFile(fsys, file, classification, cfg, auditWriter, fileIdentity, options)
+	}
+
+	// Files that match no prefix may still be routed by their content type
+	// or collected by the catch-all rule
+	if classification.Reason == classifier.NoPrefixMatch {
+		if destDir, reason, ok := fallbackDestination(fsys, file, cfg, options); ok {
+			return processFallback(fsys, file, destDir, reason, cfg, auditWriter, fileIdentity, options)
+		}
+	}
+
 	// Handle unclassified files - route to review
 	if classification.IsUnclassified() {
 		// Determine reason code based on classification reason
 		reasonCode := mapClassificationReasonToAuditReason(classification.Reason)
+		return routeToReview(fsys, file, reasonCode, cfg, auditWriter)
+	}
 
-		// For unclassified files, we route to review directory
-		destDir := organizer.GetForReviewPath(filepath.Dir(file.FullPath))
-		destPath := filepath.Join(destDir, file.Name)
-
-		// Record audit event BEFORE the move (Requirements: 11.4)
-		if auditWriter != nil {
-			if err := auditWriter.RecordRouteToReview(file.FullPath, destPath, reasonCode); err != nil {
-				return Result{
-					SourcePath: file.FullPath,
-					Success:    false,
-					Error:      &AuditWriteError{Err: err},
-					EventType:  "ERROR",
-				}
-			}
-		}
+	// Handle classified files - move to destination
+	// We need to predict the destination path before the move
+	destDir, err := classifiedDestination(file, classification, cfg, options)
+	if err != nil {
+		return resolveFailed(file, err, auditWriter)
+	}
+	if destDirMissing(fsys, destDir, options) {
+		return skipFile(file, audit.ReasonDestDirMissing, auditWriter)
+	}
+	classification, fits := fitDestination(classification, destDir, cfg)
+	if !fits {
+		return routeToReview(fsys, file, audit.ReasonPathTooLong, cfg, auditWriter)
+	}
 
-		// Now perform the actual move
-		moveResult, err := organizer.Organize(file, classification, cfg)
-		if err != nil {
-			// Record error event
-			if auditWriter != nil {
-				auditWriter.RecordError(file.FullPath, "MOVE_FAILED", err.Error(), "organize")
-			}
-			return Result{
-				SourcePath: file.FullPath,
-				Success:    false,
-				Error:      err,
-				EventType:  "ERROR",
-			}
-		}
+	// A file that is already where it would be moved to is left alone, rather
+	// than being renamed as a duplicate of itself
+	if alreadyOrganized(file, filepath.Join(destDir, classification.NormalisedFilename)) {
+		return skipFile(file, audit.ReasonAlreadyOrganized, auditWriter)
+	}
 
-		return Result{
-			SourcePath:      moveResult.SourcePath,
-			DestinationPath: moveResult.DestinationPath,
-			Success:         true,
-			EventType:       "ROUTE_TO_REVIEW",
-			ReasonCode:      string(reasonCode),
-		}
+	// A move that would run out of space is skipped before it is recorded
+	if err := organizer.CheckCopySpaceWithFS(fsys, file.FullPath, destDir, cfg); err != nil {
+		return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
 	}
 
-	// Handle classified files - move to destination
 	// Record audit event BEFORE the move (Requirements: 11.4)
 	if auditWriter != nil {
-		// We need to predict the destination path before the move
-		// This is calculated the same way as in organizer.Organize
-		prefix := extractPrefixFromNormalisedFilename(classification.NormalisedFilename)
-		subfolder := fmt.Sprintf("%d %s", classification.Year, prefix)
-		destDir := filepath.Join(classification.OutboundDirectory, subfolder)
 		destFilename := classification.NormalisedFilename
 
 		// Check if this will be a duplicate
 		destPath := filepath.Join(destDir, destFilename)
-		isDuplicate := organizer.FileExists(destPath)
+		isDuplicate := organizer.FileExistsWithFS(fsys, destPath)
 
 		if isDuplicate {
 			// Generate the duplicate name to predict actual destination
-			actualFilename := organizer.GenerateDuplicateName(destDir, destFilename)
+			actualFilename := organizer.DuplicateNameWithFS(fsys, destDir, destFilename, cfg)
 			actualDestPath := filepath.Join(destDir, actualFilename)
 
 			// Record duplicate event
@@ -526,7 +1146,7 @@ func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, aud
 			}
 		} else {
 			// Record move event
-			if err := auditWriter.RecordMove(file.FullPath, destPath, fileIdentity); err != nil {
+			if err := auditWriter.RecordMoveWithMetadata(file.FullPath, destPath, fileIdentity.ForMove(file.FullPath, destDir), moveReason(classification), xattrMetadata(fsys, cfg, matchedRuleMetadata(classification, auditWriter))); err != nil {
 				return Result{
 					SourcePath: file.FullPath,
 					Success:    false,
@@ -538,16 +1158,19 @@ func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, aud
 	}
 
 	// Organize (move) the file
-	moveResult, err := organizer.Organize(file, classification, cfg)
+	moveResult, err := organizer.OrganizeIntoWithFS(fsys, file, destDir, classification.NormalisedFilename, cfg)
 	if err != nil {
+		if insufficientSpace(err) {
+			return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
+		}
 		// Record error event
 		if auditWriter != nil {
-			auditWriter.RecordError(file.FullPath, "MOVE_FAILED", err.Error(), "organize")
+			auditWriter.RecordError(file.FullPath, failureType(err, "MOVE_FAILED"), err.Error(), "organize")
 		}
 		return Result{
 			SourcePath: file.FullPath,
 			Success:    false,
-			Error:      err,
+			Error:      &MoveError{Path: file.FullPath, Op: OpMove, Err: err},
 			EventType:  "ERROR",
 		}
 	}
@@ -557,10 +1180,213 @@ func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, aud
 		eventType = "DUPLICATE_DETECTED"
 	}
 
-	// Extract prefix for per-prefix breakdown in verbose mode
+	// Matched prefix for per-prefix breakdown in verbose mode
 	// Requirements: 3.6 - Per-prefix breakdown in verbose mode
-	prefix := extractPrefixFromNormalisedFilename(classification.NormalisedFilename)
+	return Result{
+		SourcePath:      moveResult.SourcePath,
+		DestinationPath: moveResult.DestinationPath,
+		Success:         true,
+		IsDuplicate:     moveResult.IsDuplicate,
+		OriginalName:    moveResult.OriginalName,
+		EventType:       eventType,
+		ReasonCode:      string(moveReason(classification)),
+		Prefix:          classification.Prefix,
+		Date:            classification.Date,
+	}
+}
+
+// routeToReview moves a file into the for-review folder within its source
+// directory, recording ROUTE_TO_REVIEW with reasonCode.
+func routeToReview(fsys filesystem.FS, file scanner.FileEntry, reasonCode audit.ReasonCode, cfg *config.Configuration, auditWriter *audit.AuditWriter) Result {
+	destDir := organizer.GetForReviewPath(filepath.Dir(file.FullPath))
+	destPath := filepath.Join(destDir, file.Name)
+
+	// Record audit event BEFORE the move (Requirements: 11.4)
+	if auditWriter != nil {
+		if err := auditWriter.RecordRouteToReview(file.FullPath, destPath, reasonCode); err != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+
+	// Now perform the actual move
+	moveResult, err := organizer.OrganizeForReviewWithFS(fsys, file, cfg)
+	if err != nil {
+		// Record error event
+		if auditWriter != nil {
+			auditWriter.RecordError(file.FullPath, failureType(err, "MOVE_FAILED"), err.Error(), "organize")
+		}
+		return Result{
+			SourcePath: file.FullPath,
+			Success:    false,
+			Error:      &MoveError{Path: file.FullPath, Op: OpRouteToReview, Err: err},
+			EventType:  "ERROR",
+		}
+	}
 
+	return Result{
+		SourcePath:      moveResult.SourcePath,
+		DestinationPath: moveResult.DestinationPath,
+		Success:         true,
+		EventType:       "ROUTE_TO_REVIEW",
+		ReasonCode:      string(reasonCode),
+	}
+}
+
+// alreadyOrganized reports whether file is already at destPath, the path it
+// would be moved to.
+func alreadyOrganized(file scanner.FileEntry, destPath string) bool {
+	return filepath.Clean(file.FullPath) == filepath.Clean(destPath)
+}
+
+// resolveFailed records that the destination of file could not be resolved.
+func resolveFailed(file scanner.FileEntry, err error, auditWriter *audit.AuditWriter) Result {
+	if auditWriter != nil {
+		if auditErr := auditWriter.RecordError(file.FullPath, "RESOLVE_FAILED", err.Error(), "resolve_destination"); auditErr != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: auditErr},
+				EventType:  "ERROR",
+			}
+		}
+	}
+	return Result{
+		SourcePath: file.FullPath,
+		Success:    false,
+		Error:      &MoveError{Path: file.FullPath, Op: OpResolveDestination, Err: err},
+		EventType:  "ERROR",
+	}
+}
+
+// fitDestination reports whether a classified file fits in destDir under the
+// platform's path length limits, and returns the classification to organize
+// it with. With cfg.TruncateLongNames, a name that does not fit has the
+// description at its end shortened, leaving room for a duplicate suffix; the
+// shortened name is set on a copy, so classification itself is not changed.
+func fitDestination(classification *classifier.Classification, destDir string, cfg *config.Configuration) (*classifier.Classification, bool) {
+	name := classification.NormalisedFilename
+	if !organizer.DestinationTooLong(destDir, name) {
+		return classification, true
+	}
+	if !cfg.TruncateLongNames {
+		return classification, false
+	}
+	// Keep the prefix, the date and at least one character of description
+	shortened, ok := organizer.TruncateToFit(destDir, name, descriptionStart(classification)+1, organizer.DuplicateSuffixLength(cfg))
+	if !ok {
+		return classification, false
+	}
+	fitted := *classification
+	fitted.NormalisedFilename = shortened
+	return &fitted, true
+}
+
+// descriptionStart returns the byte offset of the description in a classified
+// file's normalised filename: just after the date, or after the prefix for
+// undated files.
+func descriptionStart(classification *classifier.Classification) int {
+	name := classification.NormalisedFilename
+	if classification.Date != "" {
+		if i := strings.Index(name, classification.Date); i >= 0 {
+			return i + len(classification.Date) + 1
+		}
+	}
+	return len(classification.Prefix) + 1
+}
+
+// matchTypeRule sniffs the content type of a file that matched no prefix rule
+// and returns the first type rule for it, or nil if none applies.
+func matchTypeRule(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) *config.TypeRule {
+	if len(cfg.TypeRules) == 0 {
+		return nil
+	}
+	// http.DetectContentType considers at most the first 512 bytes
+	head, err := filesystem.ReadHead(fsys, file.FullPath, 512)
+	if err != nil {
+		return nil
+	}
+	return cfg.FindTypeRule(http.DetectContentType(head))
+}
+
+// fallbackDestination returns the directory a file that matched no prefix rule
+// is moved into instead of for-review, and the reason recorded for the move:
+// the outbound directory of the type rule matching its content
+// (TYPE_FALLBACK), or else the catch-all folder when the catch-all mode
+// accepts the file (CATCH_ALL). The stage folder of options is added below
+// either. It reports false if neither applies.
+func fallbackDestination(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration, options *Options) (string, audit.ReasonCode, bool) {
+	if rule := matchTypeRule(fsys, file, cfg); rule != nil {
+		return stagedDir(rule.OutboundDirectory, options), audit.ReasonTypeFallback, true
+	}
+	if cfg.CatchAll != nil && (cfg.CatchAll.GetMode() == config.CatchAllEverything || classifier.HasDate(file.Name)) {
+		return stagedDir(cfg.CatchAll.OutboundDirectory, options), audit.ReasonCatchAll, true
+	}
+	return "", "", false
+}
+
+// processFallback moves a file that matched no prefix rule into destDir,
+// keeping its name, and records a MOVE with the given reason (TYPE_FALLBACK
+// or CATCH_ALL).
+func processFallback(fsys filesystem.FS, file scanner.FileEntry, destDir string, reason audit.ReasonCode, cfg *config.Configuration, auditWriter *audit.AuditWriter, fileIdentity *audit.FileIdentity, options *Options) Result {
+	if destDirMissing(fsys, destDir, options) {
+		return skipFile(file, audit.ReasonDestDirMissing, auditWriter)
+	}
+	if organizer.DestinationTooLong(destDir, file.Name) {
+		return routeToReview(fsys, file, audit.ReasonPathTooLong, cfg, auditWriter)
+	}
+	if alreadyOrganized(file, filepath.Join(destDir, file.Name)) {
+		return skipFile(file, audit.ReasonAlreadyOrganized, auditWriter)
+	}
+
+	if err := organizer.CheckCopySpaceWithFS(fsys, file.FullPath, destDir, cfg); err != nil {
+		return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
+	}
+
+	// Record audit event BEFORE the move (Requirements: 11.4)
+	if auditWriter != nil {
+		destPath := filepath.Join(destDir, file.Name)
+		var err error
+		if organizer.FileExistsWithFS(fsys, destPath) {
+			actualFilename := organizer.DuplicateNameWithFS(fsys, destDir, file.Name, cfg)
+			err = auditWriter.RecordDuplicate(file.FullPath, destPath, filepath.Join(destDir, actualFilename), audit.ReasonDuplicateRenamed)
+		} else {
+			err = auditWriter.RecordMoveWithMetadata(file.FullPath, destPath, fileIdentity.ForMove(file.FullPath, destDir), reason, xattrMetadata(fsys, cfg, nil))
+		}
+		if err != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+
+	moveResult, err := organizer.OrganizeByTypeWithFS(fsys, file, destDir, cfg)
+	if err != nil {
+		if insufficientSpace(err) {
+			return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
+		}
+		if auditWriter != nil {
+			auditWriter.RecordError(file.FullPath, failureType(err, "MOVE_FAILED"), err.Error(), "organize")
+		}
+		return Result{
+			SourcePath: file.FullPath,
+			Success:    false,
+			Error:      &MoveError{Path: file.FullPath, Op: OpMove, Err: err},
+			EventType:  "ERROR",
+		}
+	}
+
+	eventType := "MOVE"
+	if moveResult.IsDuplicate {
+		eventType = "DUPLICATE_DETECTED"
+	}
 	return Result{
 		SourcePath:      moveResult.SourcePath,
 		DestinationPath: moveResult.DestinationPath,
@@ -568,19 +1394,153 @@ func processFileWithAudit(file scanner.FileEntry, cfg *config.Configuration, aud
 		IsDuplicate:     moveResult.IsDuplicate,
 		OriginalName:    moveResult.OriginalName,
 		EventType:       eventType,
-		Prefix:          prefix,
+		ReasonCode:      string(reason),
 	}
 }
 
-// extractPrefixFromNormalisedFilename extracts the prefix portion from a normalised filename.
-// The prefix is everything before the first space.
-func extractPrefixFromNormalisedFilename(filename string) string {
-	for i, c := range filename {
-		if c == ' ' {
-			return filename[:i]
+// processReadOnlyFile copies a file from a read-only source to the destination
+// it would be moved to, recording a COPY event. Files that would be routed to
+// review are skipped with READ_ONLY_SOURCE, since the for-review folder would
+// have to be created inside the source, and files whose destination directory
+// already holds identical content, under their name or a duplicate name, are
+// skipped with ALREADY_COPIED so repeated runs do not copy them again. Files
+// whose destination path would be too long are skipped with PATH_TOO_LONG.
+func processReadOnlyFile(fsys filesystem.FS, file scanner.FileEntry, classification *classifier.Classification, cfg *config.Configuration, auditWriter *audit.AuditWriter, fileIdentity *audit.FileIdentity, options *Options) Result {
+	var destDir, destFilename string
+	var reason audit.ReasonCode
+	fallback := false
+	if classification.IsClassified() {
+		var err error
+		destDir, err = classifiedDestination(file, classification, cfg, options)
+		if err != nil {
+			return resolveFailed(file, err, auditWriter)
+		}
+		destFilename = classification.NormalisedFilename
+		reason = moveReason(classification)
+	} else {
+		if classification.Reason == classifier.NoPrefixMatch {
+			destDir, reason, fallback = fallbackDestination(fsys, file, cfg, options)
+		}
+		if !fallback {
+			return skipFile(file, audit.ReasonReadOnlySource, auditWriter)
+		}
+		destFilename = file.Name
+	}
+
+	if destDirMissing(fsys, destDir, options) {
+		return skipFile(file, audit.ReasonDestDirMissing, auditWriter)
+	}
+	if fallback {
+		if organizer.DestinationTooLong(destDir, destFilename) {
+			return skipFile(file, audit.ReasonPathTooLong, auditWriter)
+		}
+	} else {
+		var fits bool
+		classification, fits = fitDestination(classification, destDir, cfg)
+		if !fits {
+			return skipFile(file, audit.ReasonPathTooLong, auditWriter)
 		}
+		destFilename = classification.NormalisedFilename
+	}
+
+	// A taken name may hide an earlier copy under a duplicate name
+	destPath := filepath.Join(destDir, destFilename)
+	if organizer.FileExistsWithFS(fsys, destPath) && organizer.FindSameContentWithFS(fsys, file.FullPath, destDir) != "" {
+		return skipFile(file, audit.ReasonAlreadyCopied, auditWriter)
+	}
+
+	if err := organizer.CheckCopySpaceWithFS(fsys, file.FullPath, destDir, cfg); err != nil {
+		return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
+	}
+
+	// Record audit event BEFORE the copy (Requirements: 11.4)
+	if auditWriter != nil {
+		actualDestPath := destPath
+		if organizer.FileExistsWithFS(fsys, destPath) {
+			actualDestPath = filepath.Join(destDir, organizer.DuplicateNameWithFS(fsys, destDir, destFilename, cfg))
+		}
+		if err := auditWriter.RecordCopy(file.FullPath, destPath, actualDestPath, fileIdentity); err != nil {
+			return Result{
+				SourcePath: file.FullPath,
+				Success:    false,
+				Error:      &AuditWriteError{Err: err},
+				EventType:  "ERROR",
+			}
+		}
+	}
+
+	// Copy to the destination recorded above, which includes any stage folder
+	// or resolved directory
+	moveResult, err := organizer.OrganizeIntoWithFS(fsys, file, destDir, destFilename, cfg)
+	if err != nil {
+		if insufficientSpace(err) {
+			return skipFile(file, audit.ReasonInsufficientSpace, auditWriter)
+		}
+		if auditWriter != nil {
+			auditWriter.RecordError(file.FullPath, failureType(err, "COPY_FAILED"), err.Error(), "organize")
+		}
+		return Result{
+			SourcePath: file.FullPath,
+			Success:    false,
+			Error:      &MoveError{Path: file.FullPath, Op: OpMove, Err: err},
+			EventType:  "ERROR",
+		}
+	}
+
+	eventType := "MOVE"
+	if moveResult.IsDuplicate {
+		eventType = "DUPLICATE_DETECTED"
+	}
+	return Result{
+		SourcePath:      moveResult.SourcePath,
+		DestinationPath: moveResult.DestinationPath,
+		Success:         true,
+		IsDuplicate:     moveResult.IsDuplicate,
+		OriginalName:    moveResult.OriginalName,
+		EventType:       eventType,
+		ReasonCode:      string(reason),
+		Prefix:          classification.Prefix,
+		Date:            classification.Date,
+		Copied:          true,
 	}
-	return filename
+}
+
+// moveReason returns the audit reason recorded for a classified move:
+// ReasonMatchedNoDate for undated files, empty otherwise.
+func moveReason(classification *classifier.Classification) audit.ReasonCode {
+	if classification.IsUndated() {
+		return audit.ReasonMatchedNoDate
+	}
+	return ""
+}
+
+// matchedRuleMetadata returns the MOVE event metadata describing the rule a
+// classified file matched, or nil unless the audit log is configured with
+// recordMatchedRule.
+func matchedRuleMetadata(classification *classifier.Classification, auditWriter *audit.AuditWriter) map[string]string {
+	if !auditWriter.GetConfig().RecordMatchedRule {
+		return nil
+	}
+	return map[string]string{
+		"matchedPrefix":       classification.Prefix,
+		"matchedRuleOutbound": classification.OutboundDirectory,
+	}
+}
+
+// xattrMetadata adds "xattrsPreserved" to the MOVE event metadata when cfg
+// sets PreserveXattrs: "true" when the file keeps its extended attributes
+// even if the move falls back to copying it, "false" when fsys or the
+// platform cannot copy them.
+func xattrMetadata(fsys filesystem.FS, cfg *config.Configuration, metadata map[string]string) map[string]string {
+	if !cfg.PreserveXattrs {
+		return metadata
+	}
+	_, copier := fsys.(filesystem.XattrCopier)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata["xattrsPreserved"] = strconv.FormatBool(copier && filesystem.XattrsSupported)
+	return metadata
 }
 
 // mapClassificationReasonToAuditReason maps classifier reason to audit reason code.
@@ -592,6 +1552,8 @@ func mapClassificationReasonToAuditReason(reason classifier.UnclassifiedReason)
 		return audit.ReasonParseError
 	case classifier.InvalidDate:
 		return audit.ReasonInvalidDate
+	case classifier.ExtensionNotAllowed:
+		return audit.ReasonExtensionNotAllowed
 	default:
 		return audit.ReasonUnclassified
 	}
@@ -626,6 +1588,12 @@ func getMachineID() string {
 	return hostname
 }
 
+// PermissionDeniedDirs returns the number of directories skipped because they
+// could not be read for lack of permission.
+func (s *Summary) PermissionDeniedDirs() int {
+	return countPermissionDenied(s.ScanErrors)
+}
+
 // HasErrors returns true if there were any errors during the run.
 func (s *Summary) HasErrors() bool {
 	return s.ErrorCount > 0 || len(s.ScanErrors) > 0
@@ -670,8 +1638,9 @@ func ProcessSingleFile(configPath string, filePath string) (*Result, error) {
 
 	// Create a FileEntry for the file
 	file := scanner.FileEntry{
-		Name:     info.Name(),
-		FullPath: filePath,
+		Name:        info.Name(),
+		FullPath:    filePath,
+		RelativeDir: relativeDirInInbound(filePath, cfg.InboundDirectories),
 	}
 
 	// Process the file
@@ -679,3 +1648,20 @@ func ProcessSingleFile(configPath string, filePath string) (*Result, error) {
 
 	return &result, nil
 }
+
+// relativeDirInInbound returns the directory of filePath relative to the inbound
+// directory that contains it, or "" if the file sits directly in an inbound
+// directory or outside all of them.
+func relativeDirInInbound(filePath string, inboundDirs []string) string {
+	for _, inboundDir := range inboundDirs {
+		rel, err := filepath.Rel(inboundDir, filepath.Dir(filePath))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rel == "." {
+			return ""
+		}
+		return rel
+	}
+	return ""
+}
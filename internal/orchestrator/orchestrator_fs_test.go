@@ -0,0 +1,258 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// TestRunAgainstInMemoryFS runs a full organize through an injected filesystem
+// without touching disk.
+func TestRunAgainstInMemoryFS(t *testing.T) {
+	fsys := filesystem.NewMemFS()
+	fsys.MkdirAll("/inbound", 0755)
+	fsys.WriteFile("/inbound/invoice 2024-03-15 Acme.pdf", []byte("invoice"), 0644)
+	fsys.WriteFile("/inbound/notes.txt", []byte("notes"), 0644)
+	fsys.MkdirAll("/archive/2024 Invoice", 0755)
+	fsys.WriteFile("/archive/2024 Invoice/Invoice 2024-03-15 Acme.pdf", []byte("older"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/inbound"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+	}
+
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if summary.TotalFiles != 2 || summary.SuccessCount != 2 {
+		t.Fatalf("Expected 2 files processed successfully, got total=%d success=%d errors=%d",
+			summary.TotalFiles, summary.SuccessCount, summary.ErrorCount)
+	}
+	if summary.DuplicateCount != 1 || summary.ReviewCount != 1 {
+		t.Errorf("Expected 1 duplicate and 1 review, got %d and %d", summary.DuplicateCount, summary.ReviewCount)
+	}
+
+	data, err := fsys.ReadFile("/archive/2024 Invoice/Invoice 2024-03-15 Acme_duplicate.pdf")
+	if err != nil || string(data) != "invoice" {
+		t.Errorf("Expected classified file renamed as duplicate, got %q, %v", data, err)
+	}
+	if _, err := fsys.Stat("/inbound/for-review/notes.txt"); err != nil {
+		t.Errorf("Expected unclassified file in for-review: %v", err)
+	}
+	for _, path := range []string{"/inbound/invoice 2024-03-15 Acme.pdf", "/inbound/notes.txt"} {
+		if _, err := fsys.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat("/inbound"); !os.IsNotExist(err) {
+		t.Error("Run touched the real filesystem")
+	}
+}
+
+// TestRunAgainstInMemoryFSReportsMissingInbound verifies scan errors use the injected filesystem.
+func TestRunAgainstInMemoryFSReportsMissingInbound(t *testing.T) {
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/missing"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+	}
+
+	summary, err := NewOrchestratorWithFS(cfg, filesystem.NewMemFS()).Run(nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(summary.ScanErrors) != 1 {
+		t.Fatalf("Expected 1 scan error, got %d", len(summary.ScanErrors))
+	}
+	if !errors.Is(summary.ScanErrors[0], os.ErrNotExist) {
+		t.Errorf("Expected not-exist scan error, got %v", summary.ScanErrors[0])
+	}
+}
+
+// unreadableFS is a MemFS that refuses to list directories whose mode has no
+// read bits, as the real filesystem does for a 0000-mode directory.
+type unreadableFS struct {
+	*filesystem.MemFS
+}
+
+func (u unreadableFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if info, err := u.MemFS.Stat(name); err == nil && info.Mode().Perm()&0444 == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	return u.MemFS.ReadDir(name)
+}
+
+// TestRunSkipsPermissionDeniedSubdirectory verifies that an unreadable
+// subdirectory is reported in ScanErrors while the rest of the inbound
+// directory is still organized.
+func TestRunSkipsPermissionDeniedSubdirectory(t *testing.T) {
+	fsys := unreadableFS{filesystem.NewMemFS()}
+	fsys.MkdirAll("/inbound/open", 0755)
+	fsys.MkdirAll("/inbound/locked", 0000)
+	fsys.WriteFile("/inbound/Invoice 2024-03-15 Top.pdf", []byte("top"), 0644)
+	fsys.WriteFile("/inbound/open/Invoice 2024-04-20 Nested.pdf", []byte("nested"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/inbound"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+	}
+
+	depth := -1
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(&Options{ScanDepth: &depth})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if summary.TotalFiles != 2 || summary.SuccessCount != 2 {
+		t.Errorf("Expected both readable files organized, got total=%d success=%d",
+			summary.TotalFiles, summary.SuccessCount)
+	}
+	if len(summary.ScanErrors) != 1 || summary.PermissionDeniedDirs() != 1 {
+		t.Fatalf("Expected 1 permission-denied scan error, got %v", summary.ScanErrors)
+	}
+	var scanErr *ScanError
+	if !errors.As(summary.ScanErrors[0], &scanErr) || scanErr.Path != "/inbound/locked" {
+		t.Errorf("Expected scan error for /inbound/locked, got %v", summary.ScanErrors[0])
+	}
+
+	runSummary := GenerateSummary(ConvertSummaryToRunResult(summary), 0, false)
+	if runSummary.PermissionDeniedDirs != 1 {
+		t.Errorf("Expected run summary to report 1 skipped directory, got %d", runSummary.PermissionDeniedDirs)
+	}
+}
+
+// crossDeviceFS is a filesystem on which every rename fails as it does across
+// volumes, forcing the copy fallback. It reports free bytes of space, less
+// what is written once free is set.
+type crossDeviceFS struct {
+	filesystem.FS
+	free uint64
+}
+
+func (c *crossDeviceFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: errors.New("invalid cross-device link")}
+}
+
+func (c *crossDeviceFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	c.free -= min(c.free, uint64(len(data)))
+	return c.FS.WriteFile(name, data, perm)
+}
+
+func (c *crossDeviceFS) FreeSpace(path string) (uint64, error) {
+	return c.free, nil
+}
+
+func (c *crossDeviceFS) SameVolume(a, b string) (bool, error) {
+	return false, nil
+}
+
+// TestCheckCopySpaceSkipsFilesThatDoNotFit verifies that with checkCopySpace
+// a copy-fallback move is skipped with INSUFFICIENT_SPACE once the destination
+// volume no longer has room for the file plus the margin, and that the run
+// continues.
+func TestCheckCopySpaceSkipsFilesThatDoNotFit(t *testing.T) {
+	fsys := &crossDeviceFS{FS: filesystem.NewMemFS()}
+	fsys.MkdirAll("/inbound", 0755)
+	fsys.WriteFile("/inbound/Invoice 2024-01-15 First.pdf", make([]byte, 600), 0644)
+	fsys.WriteFile("/inbound/Invoice 2024-02-15 Second.pdf", make([]byte, 600), 0644)
+	fsys.free = 2100
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/inbound"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+		CheckCopySpace:       true,
+		CopySpaceMarginBytes: 1000,
+	}
+
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if summary.SuccessCount != 1 || summary.SkippedCount != 1 || summary.ErrorCount != 0 {
+		t.Fatalf("Expected 1 moved and 1 skipped, got success=%d skipped=%d errors=%d",
+			summary.SuccessCount, summary.SkippedCount, summary.ErrorCount)
+	}
+	for _, result := range summary.Results {
+		if result.SourcePath == "/inbound/Invoice 2024-02-15 Second.pdf" && result.ReasonCode != "INSUFFICIENT_SPACE" {
+			t.Errorf("Expected second file skipped with INSUFFICIENT_SPACE, got %s %s", result.EventType, result.ReasonCode)
+		}
+	}
+	if _, err := fsys.Stat("/archive/2024 Invoice/Invoice 2024-01-15 First.pdf"); err != nil {
+		t.Errorf("Expected first file copied to its destination: %v", err)
+	}
+	if _, err := fsys.Stat("/inbound/Invoice 2024-02-15 Second.pdf"); err != nil {
+		t.Errorf("Expected second file left in place: %v", err)
+	}
+	if _, err := fsys.Stat("/archive/2024 Invoice/Invoice 2024-02-15 Second.pdf"); !os.IsNotExist(err) {
+		t.Errorf("Expected no copy of the second file, got %v", err)
+	}
+}
+
+// TestCheckCopySpaceSkipsBeforeRecordingMove verifies that with an audit log a
+// file without room on the destination volume is recorded only as skipped
+// with INSUFFICIENT_SPACE, with no MOVE event for a move that never happened.
+func TestCheckCopySpaceSkipsBeforeRecordingMove(t *testing.T) {
+	tempDir := t.TempDir()
+	inboundDir := filepath.Join(tempDir, "inbound")
+	os.MkdirAll(inboundDir, 0755)
+	os.WriteFile(filepath.Join(inboundDir, "Invoice 2024-01-15 First.pdf"), make([]byte, 600), 0644)
+	os.WriteFile(filepath.Join(inboundDir, "photo.png"), append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 600)...), 0644)
+	fsys := &crossDeviceFS{FS: filesystem.OS{}, free: 1500}
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{inboundDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "archive")},
+		},
+		TypeRules: []config.TypeRule{
+			{Mime: "image/*", OutboundDirectory: filepath.Join(tempDir, "images")},
+		},
+		CheckCopySpace:       true,
+		CopySpaceMarginBytes: 1000,
+	}
+
+	auditDir := filepath.Join(tempDir, "audit")
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(&Options{AuditConfig: &auditConfig, AppVersion: "1.0.0", MachineID: "test-machine"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SkippedCount != 2 {
+		t.Fatalf("Expected both files skipped, got %+v", summary.Results)
+	}
+
+	events, err := audit.NewAuditReader(auditDir).GetRun(audit.RunID(summary.RunID))
+	if err != nil {
+		t.Fatalf("Failed to read run: %v", err)
+	}
+	skipped := 0
+	for _, event := range events {
+		switch event.EventType {
+		case audit.EventMove, audit.EventDuplicateDetected:
+			t.Errorf("Expected no %s event, got one for %s", event.EventType, event.SourcePath)
+		case audit.EventSkip:
+			if event.ReasonCode != audit.ReasonInsufficientSpace {
+				t.Errorf("Expected INSUFFICIENT_SPACE, got %s", event.ReasonCode)
+			}
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("Expected 2 SKIP events, got %d", skipped)
+	}
+}
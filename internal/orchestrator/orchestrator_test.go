@@ -3,13 +3,18 @@ package orchestrator
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"sorta/internal/audit"
 	"sorta/internal/config"
+	"sorta/internal/filesystem"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -797,3 +802,2066 @@ func itoa(i int) string {
 	}
 	return string(result)
 }
+
+// TestPreserveSourceSubpath verifies that files from nested inbound subfolders land
+// in mirrored subfolders under the destination, and that undo restores them.
+func TestPreserveSourceSubpath(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "inbox")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+
+	fileA := filepath.Join(sourceDir, "subA", "Invoice 2024-03-15 Alpha.pdf")
+	fileB := filepath.Join(sourceDir, "subB", "Invoice 2024-04-20 Beta.pdf")
+	for _, path := range []string{fileA, fileB} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	scanDepth := 1
+	cfg := config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: targetDir},
+		},
+		ScanDepth:             &scanDepth,
+		PreserveSourceSubpath: true,
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	configData, _ := json.Marshal(cfg)
+	os.WriteFile(configPath, configData, 0644)
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 2 {
+		t.Fatalf("Expected 2 successful moves, got %d", summary.SuccessCount)
+	}
+
+	destA := filepath.Join(targetDir, "2024 Invoice", "subA", "Invoice 2024-03-15 Alpha.pdf")
+	destB := filepath.Join(targetDir, "2024 Invoice", "subB", "Invoice 2024-04-20 Beta.pdf")
+	for _, path := range []string{destA, destB} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected file at mirrored destination %s: %v", path, err)
+		}
+	}
+
+	// Undo must restore to the original nested source locations
+	reader := audit.NewAuditReader(auditDir)
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	result, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != 2 {
+		t.Errorf("Expected 2 restored files, got %d: %+v", result.Restored, result.FailureDetails)
+	}
+	for _, path := range []string{fileA, fileB} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected file restored to %s: %v", path, err)
+		}
+	}
+}
+
+// TestOnlyPrefixSkipsUnselectedFiles verifies that with OnlyPrefixes set, only
+// matching files are moved and everything else is skipped rather than reviewed.
+func TestOnlyPrefixSkipsUnselectedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	receiptDir := filepath.Join(tempDir, "receipts")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	invoiceFile := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	receiptFile := filepath.Join(sourceDir, "Receipt 2024-04-20 Store.pdf")
+	otherFile := filepath.Join(sourceDir, "notes.txt")
+	for _, path := range []string{invoiceFile, receiptFile, otherFile} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	cfg := config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+			{Prefix: "Receipt", OutboundDirectory: receiptDir},
+		},
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	configData, _ := json.Marshal(cfg)
+	os.WriteFile(configPath, configData, 0644)
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:  &auditConfig,
+		AppVersion:   "1.0.0",
+		MachineID:    "test-machine",
+		OnlyPrefixes: []string{"Invoice"},
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if summary.SuccessCount != 1 || summary.ReviewCount != 0 || summary.SkippedCount != 2 {
+		t.Errorf("Expected 1 moved, 0 reviewed, 2 skipped; got %d, %d, %d",
+			summary.SuccessCount, summary.ReviewCount, summary.SkippedCount)
+	}
+
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-03-15 Acme.pdf")); err != nil {
+		t.Errorf("Expected invoice to be moved: %v", err)
+	}
+	for _, path := range []string{receiptFile, otherFile} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be left in place: %v", path, err)
+		}
+	}
+
+	for _, result := range summary.Results {
+		if result.EventType == "SKIP" && result.ReasonCode != string(audit.ReasonPrefixNotSelected) {
+			t.Errorf("Expected skip reason %s for %s, got %s",
+				audit.ReasonPrefixNotSelected, result.SourcePath, result.ReasonCode)
+		}
+	}
+
+	auditContent, err := os.ReadFile(filepath.Join(auditDir, "sorta-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if got := strings.Count(string(auditContent), `"reasonCode":"PREFIX_NOT_SELECTED"`); got != 2 {
+		t.Errorf("Expected 2 PREFIX_NOT_SELECTED audit events, got %d", got)
+	}
+}
+
+// TestCheckLocksSkipsLockedFiles verifies that with CheckLocks enabled, files the
+// lock probe reports as locked are skipped with FILE_LOCKED and left in place.
+func TestCheckLocksSkipsLockedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	lockedFile := filepath.Join(sourceDir, "Invoice 2024-03-15 Locked.pdf")
+	freeFile := filepath.Join(sourceDir, "Invoice 2024-04-20 Free.pdf")
+	for _, path := range []string{lockedFile, freeFile} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	var probed []string
+	locker := func(path string) (bool, error) {
+		probed = append(probed, path)
+		return path == lockedFile, nil
+	}
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+		CheckLocks:  true,
+		LockChecker: locker,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if len(probed) != 2 {
+		t.Errorf("Expected both files to be probed, got %v", probed)
+	}
+	if summary.SuccessCount != 1 || summary.SkippedCount != 1 || summary.ErrorCount != 0 {
+		t.Errorf("Expected 1 moved, 1 skipped, 0 errors; got %d, %d, %d",
+			summary.SuccessCount, summary.SkippedCount, summary.ErrorCount)
+	}
+	for _, result := range summary.Results {
+		if result.SourcePath == lockedFile && (result.EventType != "SKIP" || result.ReasonCode != string(audit.ReasonFileLocked)) {
+			t.Errorf("Expected locked file skipped with FILE_LOCKED, got %s %s", result.EventType, result.ReasonCode)
+		}
+	}
+
+	if _, err := os.Stat(lockedFile); err != nil {
+		t.Errorf("Expected locked file to be left in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-04-20 Free.pdf")); err != nil {
+		t.Errorf("Expected unlocked file to be moved: %v", err)
+	}
+
+	auditContent, err := os.ReadFile(filepath.Join(auditDir, "sorta-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if got := strings.Count(string(auditContent), `"reasonCode":"FILE_LOCKED"`); got != 1 {
+		t.Errorf("Expected 1 FILE_LOCKED audit event, got %d", got)
+	}
+
+	// Without CheckLocks the probe is never called
+	probed = nil
+	os.WriteFile(filepath.Join(sourceDir, "Invoice 2024-05-01 Later.pdf"), []byte("later"), 0644)
+	if _, err := RunWithOptions(configPath, &Options{LockChecker: locker}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if len(probed) != 0 {
+		t.Errorf("Expected no lock probes without CheckLocks, got %v", probed)
+	}
+}
+
+// TestNormalizeSpacesDestinationAndUndo verifies that a file with repeated spaces is
+// moved under a single-spaced name and that undo restores the original name.
+func TestNormalizeSpacesDestinationAndUndo(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	original := filepath.Join(sourceDir, "Invoice   2024-01-15   Acme.pdf")
+	os.WriteFile(original, []byte("invoice"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: targetDir},
+		},
+		NormalizeSpaces: true,
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || summary.ReviewCount != 0 {
+		t.Fatalf("Expected 1 classified move, got success=%d review=%d", summary.SuccessCount, summary.ReviewCount)
+	}
+
+	expected := filepath.Join(targetDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf")
+	if summary.Results[0].DestinationPath != expected {
+		t.Errorf("Expected destination %s, got %s", expected, summary.Results[0].DestinationPath)
+	}
+	if _, err := os.Stat(expected); err != nil {
+		t.Fatalf("Expected file at %s: %v", expected, err)
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	result, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != 1 {
+		t.Errorf("Expected 1 restored file, got %d: %+v", result.Restored, result.FailureDetails)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("Expected original name restored at %s: %v", original, err)
+	}
+}
+
+// TestOutboundDirectoryTokens verifies that a rule whose outbound directory has
+// date tokens moves files to the rendered path without the "<year> <prefix>"
+// folder, and that undo restores them.
+func TestOutboundDirectoryTokens(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	archiveDir := filepath.Join(tempDir, "archive")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	original := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(original, []byte("invoice"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(archiveDir, "{year}", "{month}")},
+		},
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 1 {
+		t.Fatalf("Expected 1 classified move, got success=%d review=%d errors=%d", summary.SuccessCount, summary.ReviewCount, summary.ErrorCount)
+	}
+
+	expected := filepath.Join(archiveDir, "2024", "03", "Invoice 2024-03-15 Acme.pdf")
+	if summary.Results[0].DestinationPath != expected {
+		t.Errorf("Expected destination %s, got %s", expected, summary.Results[0].DestinationPath)
+	}
+	if _, err := os.Stat(expected); err != nil {
+		t.Fatalf("Expected file at %s: %v", expected, err)
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	result, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != 1 {
+		t.Errorf("Expected 1 restored file, got %d: %+v", result.Restored, result.FailureDetails)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("Expected file restored at %s: %v", original, err)
+	}
+}
+
+// TestBucketByInitial verifies that a rule with BucketByInitial moves files
+// into a folder named after the first letter of their description, that status
+// reports the same folders and that undo restores the files.
+func TestBucketByInitial(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	yearDir := filepath.Join(targetDir, "2024 Invoice")
+	buckets := map[string]string{
+		"Invoice 2024-03-15 Acme.pdf":  filepath.Join(yearDir, "A"),
+		"Invoice 2024-03-16 beta.pdf":  filepath.Join(yearDir, "B"),
+		"Invoice 2024-03-17 3M Co.pdf": filepath.Join(yearDir, "#"),
+	}
+	for name := range buckets {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: targetDir, BucketByInitial: true},
+		},
+	})
+
+	status, err := StatusFromPath(configPath)
+	if err != nil {
+		t.Fatalf("StatusFromPath failed: %v", err)
+	}
+	byDestination := status.ByInbound[sourceDir].ByDestination
+	for name, bucket := range buckets {
+		if files := byDestination[bucket]; len(files) != 1 || filepath.Base(files[0]) != name {
+			t.Errorf("Expected status to list %s under %s, got %v", name, bucket, files)
+		}
+	}
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != len(buckets) {
+		t.Fatalf("Expected %d moves, got %+v", len(buckets), summary.Results)
+	}
+	for name, bucket := range buckets {
+		if _, err := os.Stat(filepath.Join(bucket, name)); err != nil {
+			t.Errorf("Expected %s in %s: %v", name, bucket, err)
+		}
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	result, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.Restored != len(buckets) {
+		t.Errorf("Expected %d restored files, got %d: %+v", len(buckets), result.Restored, result.FailureDetails)
+	}
+}
+
+// TestStripDiacriticsDestinationName verifies that accented characters are
+// transliterated in the destination name while the audit keeps the original path.
+func TestStripDiacriticsDestinationName(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	original := filepath.Join(sourceDir, "Café 2024-01-15 Menu.pdf")
+	os.WriteFile(original, []byte("menu"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Café", OutboundDirectory: targetDir},
+		},
+		StripDiacritics: true,
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 1 {
+		t.Fatalf("Expected 1 classified move, got success=%d review=%d", summary.SuccessCount, summary.ReviewCount)
+	}
+
+	if name := filepath.Base(summary.Results[0].DestinationPath); name != "Cafe 2024-01-15 Menu.pdf" {
+		t.Errorf("Expected destination name %q, got %q", "Cafe 2024-01-15 Menu.pdf", name)
+	}
+	if _, err := os.Stat(summary.Results[0].DestinationPath); err != nil {
+		t.Fatalf("Expected file at %s: %v", summary.Results[0].DestinationPath, err)
+	}
+
+	events, err := audit.NewAuditReader(auditDir).FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{
+		EventTypes: []audit.EventType{audit.EventMove},
+	})
+	if err != nil {
+		t.Fatalf("Failed to read audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].SourcePath != original {
+		t.Errorf("Expected one MOVE event from %s, got %+v", original, events)
+	}
+}
+
+// TestUndatedFolderRoutesPrefixMatchedFiles verifies that a prefix-matched file
+// without a date is moved to "<outbound>/undated <prefix>/" with reason MATCHED_NO_DATE.
+func TestUndatedFolderRoutesPrefixMatchedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	source := filepath.Join(sourceDir, "Invoice Acme.pdf")
+	os.WriteFile(source, []byte("invoice"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: targetDir},
+		},
+		UndatedFolder: "undated",
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if len(summary.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(summary.Results))
+	}
+
+	result := summary.Results[0]
+	expected := filepath.Join(targetDir, "undated Invoice", "Invoice Acme.pdf")
+	if result.EventType != "MOVE" || result.ReasonCode != string(audit.ReasonMatchedNoDate) {
+		t.Errorf("Expected MOVE/MATCHED_NO_DATE, got %s/%s", result.EventType, result.ReasonCode)
+	}
+	if result.DestinationPath != expected {
+		t.Errorf("Expected destination %s, got %s", expected, result.DestinationPath)
+	}
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Expected file at %s: %v", expected, err)
+	}
+
+	events, err := audit.NewAuditReader(auditDir).FilterAllEvents(audit.EventFilter{EventTypes: []audit.EventType{audit.EventMove}})
+	if err != nil {
+		t.Fatalf("Failed to read audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].ReasonCode != audit.ReasonMatchedNoDate {
+		t.Errorf("Expected one MOVE event with reason MATCHED_NO_DATE, got %+v", events)
+	}
+}
+
+// TestDuplicateRenameTemplate verifies that a colliding file is renamed with the
+// configured template and that undo restores it from the renamed path.
+func TestDuplicateRenameTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	yearDir := filepath.Join(invoiceDir, "2024 Invoice")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(yearDir, 0755)
+
+	filename := "Invoice 2024-03-15 Acme.pdf"
+	sourcePath := filepath.Join(sourceDir, filename)
+	os.WriteFile(filepath.Join(yearDir, filename), []byte("existing"), 0644)
+	os.WriteFile(sourcePath, []byte("incoming"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories:      []string{sourceDir},
+		PrefixRules:             []config.PrefixRule{{Prefix: "Invoice", OutboundDirectory: invoiceDir}},
+		DuplicateRenameTemplate: "{name}-copy{n}{ext}",
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.DuplicateCount != 1 {
+		t.Fatalf("Expected 1 duplicate, got %d", summary.DuplicateCount)
+	}
+
+	renamedPath := filepath.Join(yearDir, "Invoice 2024-03-15 Acme-copy1.pdf")
+	if data, err := os.ReadFile(renamedPath); err != nil || string(data) != "incoming" {
+		t.Fatalf("Expected duplicate renamed to %s, got %q (%v)", renamedPath, data, err)
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	events, err := reader.FilterAllEvents(audit.EventFilter{EventTypes: []audit.EventType{audit.EventDuplicateDetected}})
+	if err != nil {
+		t.Fatalf("Failed to read audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].DestinationPath != renamedPath {
+		t.Fatalf("Expected DUPLICATE_DETECTED event pointing at %s, got %+v", renamedPath, events)
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	undo, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Restored != 1 {
+		t.Errorf("Expected 1 restored file, got %d: %+v", undo.Restored, undo.FailureDetails)
+	}
+	if data, err := os.ReadFile(sourcePath); err != nil || string(data) != "incoming" {
+		t.Errorf("Expected duplicate restored to %s, got %q (%v)", sourcePath, data, err)
+	}
+}
+
+// TestDatePositionAnywhereRoutesTrailingDate verifies that with datePosition
+// "anywhere" a file whose date comes at the end is filed under its year.
+func TestDatePositionAnywhereRoutesTrailingDate(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	reportDir := filepath.Join(tempDir, "reports")
+	os.MkdirAll(sourceDir, 0755)
+	os.WriteFile(filepath.Join(sourceDir, "Report Acme 2024-01-15.pdf"), []byte("report"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules:        []config.PrefixRule{{Prefix: "Report", OutboundDirectory: reportDir}},
+		DatePosition:       config.DatePositionAnywhere,
+	})
+
+	summary, err := Run(configPath)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SuccessCount != 1 {
+		t.Fatalf("Expected 1 file moved, got %d", summary.SuccessCount)
+	}
+
+	expected := filepath.Join(reportDir, "2024 Report", "Report Acme 2024-01-15.pdf")
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Expected file at %s: %v", expected, err)
+	}
+}
+
+// TestInboundDirectoryOverride checks that Options.InboundDirectory processes
+// only the given folder with the configured rules, in both normal and dry-run
+// mode, and leaves the configured inbound directories alone.
+func TestInboundDirectoryOverride(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configuredDir := filepath.Join(tempDir, "inbound")
+	adHocDir := filepath.Join(tempDir, "adhoc")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(configuredDir, 0755)
+	os.MkdirAll(adHocDir, 0755)
+
+	configuredFile := filepath.Join(configuredDir, "Invoice 2024-01-10 Configured.pdf")
+	adHocFile := filepath.Join(adHocDir, "Invoice 2024-02-20 AdHoc.pdf")
+	for _, path := range []string{configuredFile, adHocFile} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{configuredDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, &Options{InboundDirectory: adHocDir})
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 1 || dryRun.Moved[0].Source != adHocFile {
+		t.Errorf("Expected dry run to plan only %s, got %+v", adHocFile, dryRun.Moved)
+	}
+
+	summary, err := RunWithOptions(configPath, &Options{InboundDirectory: adHocDir})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || len(summary.Results) != 1 || summary.Results[0].SourcePath != adHocFile {
+		t.Fatalf("Expected only the ad-hoc file to be processed, got %+v", summary.Results)
+	}
+
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-02-20 AdHoc.pdf")); err != nil {
+		t.Errorf("Expected ad-hoc file to be organized: %v", err)
+	}
+	if _, err := os.Stat(configuredFile); err != nil {
+		t.Errorf("Expected file in configured inbound to be left in place: %v", err)
+	}
+}
+
+// TestTypeRuleRoutesUnmatchedPDF checks that a file matching no prefix rule is
+// moved by its sniffed content type when a type rule matches, and routed to
+// review as before when none does.
+func TestTypeRuleRoutesUnmatchedPDF(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	documentsDir := filepath.Join(tempDir, "Documents")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	pdfFile := filepath.Join(sourceDir, "scan0001.pdf")
+	textFile := filepath.Join(sourceDir, "notes.txt")
+	os.WriteFile(pdfFile, []byte("%PDF-1.4\n%test document\n"), 0644)
+	os.WriteFile(textFile, []byte("plain text"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		TypeRules: []config.TypeRule{
+			{Mime: "application/pdf", OutboundDirectory: documentsDir},
+		},
+	})
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 1 || dryRun.Moved[0].Reason != string(audit.ReasonTypeFallback) {
+		t.Errorf("Expected dry run to plan a TYPE_FALLBACK move, got %+v", dryRun.Moved)
+	}
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	expectedPath := filepath.Join(documentsDir, "scan0001.pdf")
+	for _, result := range summary.Results {
+		switch result.SourcePath {
+		case pdfFile:
+			if result.EventType != "MOVE" || result.ReasonCode != string(audit.ReasonTypeFallback) || result.DestinationPath != expectedPath {
+				t.Errorf("Expected PDF moved to %s with TYPE_FALLBACK, got %s %s %s",
+					expectedPath, result.EventType, result.ReasonCode, result.DestinationPath)
+			}
+		case textFile:
+			if result.EventType != "ROUTE_TO_REVIEW" {
+				t.Errorf("Expected text file routed to review, got %s", result.EventType)
+			}
+		}
+	}
+	if _, err := os.Stat(expectedPath); err != nil {
+		t.Errorf("Expected PDF at %s: %v", expectedPath, err)
+	}
+
+	auditContent, err := os.ReadFile(filepath.Join(auditDir, "sorta-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if got := strings.Count(string(auditContent), `"reasonCode":"TYPE_FALLBACK"`); got != 1 {
+		t.Errorf("Expected 1 TYPE_FALLBACK audit event, got %d", got)
+	}
+}
+
+// TestCatchAll verifies that the catch-all rule collects files that match no
+// prefix rule with reason CATCH_ALL: only dated ones in dated-only mode, every
+// one in everything mode. Files that match a prefix rule are organized by it.
+func TestCatchAll(t *testing.T) {
+	tests := []struct {
+		mode      string
+		collected []string // Files expected in the catch-all folder
+		reviewed  []string // Files expected in for-review
+	}{
+		{
+			mode:      config.CatchAllDatedOnly,
+			collected: []string{"Statement 2024-02-01 Bank.pdf"},
+			reviewed:  []string{"notes.txt"},
+		},
+		{
+			mode:      config.CatchAllEverything,
+			collected: []string{"Statement 2024-02-01 Bank.pdf", "notes.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			tempDir := t.TempDir()
+			sourceDir := filepath.Join(tempDir, "source")
+			invoiceDir := filepath.Join(tempDir, "invoices")
+			catchAllDir := filepath.Join(tempDir, "misc")
+			os.MkdirAll(sourceDir, 0755)
+			for _, name := range []string{"Invoice 2024-01-15 Acme.pdf", "Statement 2024-02-01 Bank.pdf", "notes.txt"} {
+				os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+			}
+
+			configPath := writeTestConfig(t, tempDir, config.Configuration{
+				InboundDirectories: []string{sourceDir},
+				PrefixRules: []config.PrefixRule{
+					{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+				},
+				CatchAll: &config.CatchAll{OutboundDirectory: catchAllDir, Mode: tt.mode},
+			})
+
+			dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+			if err != nil {
+				t.Fatalf("RunDryRunWithOptions failed: %v", err)
+			}
+			var planned int
+			for _, op := range dryRun.Moved {
+				if op.Reason == string(audit.ReasonCatchAll) {
+					planned++
+				}
+			}
+			if planned != len(tt.collected) {
+				t.Errorf("Expected dry run to plan %d CATCH_ALL moves, got %+v", len(tt.collected), dryRun.Moved)
+			}
+
+			summary, err := RunWithOptions(configPath, &Options{
+				AuditConfig: &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")},
+			})
+			if err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+			var caught int
+			for _, result := range summary.Results {
+				if result.ReasonCode == string(audit.ReasonCatchAll) {
+					caught++
+				}
+			}
+			if caught != len(tt.collected) {
+				t.Errorf("Expected %d CATCH_ALL results, got %+v", len(tt.collected), summary.Results)
+			}
+
+			if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf")); err != nil {
+				t.Errorf("Expected the invoice organized by its prefix rule: %v", err)
+			}
+			for _, name := range tt.collected {
+				if _, err := os.Stat(filepath.Join(catchAllDir, name)); err != nil {
+					t.Errorf("Expected %s in the catch-all folder: %v", name, err)
+				}
+			}
+			for _, name := range tt.reviewed {
+				if _, err := os.Stat(filepath.Join(sourceDir, "for-review", name)); err != nil {
+					t.Errorf("Expected %s in for-review: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+// TestMinModTimeSkipsFilesBeforeLastRun records a completed run, then checks
+// that using its end time as MinModTime skips older files with BEFORE_LAST_RUN
+// and organizes newer ones.
+func TestMinModTimeSkipsFilesBeforeLastRun(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	options := &Options{AuditConfig: &auditConfig, AppVersion: "1.0.0", MachineID: "test-machine"}
+
+	// Without a prior run there is no cutoff
+	reader := audit.NewAuditReader(auditDir)
+	if run, err := reader.GetLatestCompletedRun(); err != nil || run != nil {
+		t.Fatalf("Expected no completed run, got %+v (%v)", run, err)
+	}
+
+	if _, err := RunWithOptions(configPath, options); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	lastRun, err := reader.GetLatestCompletedRun()
+	if err != nil || lastRun == nil {
+		t.Fatalf("Expected a completed run, got %+v (%v)", lastRun, err)
+	}
+	cutoff := *lastRun.EndTime
+
+	oldFile := filepath.Join(sourceDir, "Invoice 2024-01-10 Old.pdf")
+	newFile := filepath.Join(sourceDir, "Invoice 2024-02-20 New.pdf")
+	for path, modTime := range map[string]time.Time{
+		oldFile: cutoff.Add(-time.Hour),
+		newFile: cutoff.Add(time.Hour),
+	} {
+		os.WriteFile(path, []byte(path), 0644)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+
+	options.MinModTime = cutoff
+	summary, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || summary.SkippedCount != 1 {
+		t.Fatalf("Expected 1 moved and 1 skipped, got %d and %d", summary.SuccessCount, summary.SkippedCount)
+	}
+	for _, result := range summary.Results {
+		if result.SourcePath == oldFile && (result.EventType != "SKIP" || result.ReasonCode != string(audit.ReasonBeforeLastRun)) {
+			t.Errorf("Expected old file skipped with BEFORE_LAST_RUN, got %s %s", result.EventType, result.ReasonCode)
+		}
+	}
+	if _, err := os.Stat(oldFile); err != nil {
+		t.Errorf("Expected old file to be left in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-02-20 New.pdf")); err != nil {
+		t.Errorf("Expected new file to be moved: %v", err)
+	}
+}
+
+// TestReadOnlyInboundCopiesFiles verifies that files from an inbound listed in
+// readOnlyInbounds are copied rather than moved, that unclassified files there
+// are left alone, that a second run does not copy them again, and that undo
+// deletes the copies while leaving the originals in place.
+func TestReadOnlyInboundCopiesFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	shareDir := filepath.Join(tempDir, "share")
+	localDir := filepath.Join(tempDir, "local")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(shareDir, 0755)
+	os.MkdirAll(localDir, 0755)
+
+	sharedFile := filepath.Join(shareDir, "Invoice 2024-01-15 Acme.pdf")
+	sharedNotes := filepath.Join(shareDir, "notes.txt")
+	localFile := filepath.Join(localDir, "Invoice 2024-02-20 Beta.pdf")
+	for _, path := range []string{sharedFile, sharedNotes, localFile} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{shareDir, localDir},
+		ReadOnlyInbounds:   []string{shareDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	options := &Options{AuditConfig: &auditConfig, AppVersion: "1.0.0", MachineID: "test-machine"}
+
+	summary, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SuccessCount != 2 || summary.SkippedCount != 1 {
+		t.Fatalf("Expected 2 organized and 1 skipped, got %d and %d", summary.SuccessCount, summary.SkippedCount)
+	}
+
+	copied := filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf")
+	if data, err := os.ReadFile(copied); err != nil || string(data) != sharedFile {
+		t.Errorf("Expected shared file copied to %s, got %q (%v)", copied, data, err)
+	}
+	for _, path := range []string{sharedFile, sharedNotes} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s left in the read-only inbound: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(shareDir, "for-review")); !os.IsNotExist(err) {
+		t.Error("Expected no for-review folder inside the read-only inbound")
+	}
+	if _, err := os.Stat(localFile); !os.IsNotExist(err) {
+		t.Error("Expected file from the writable inbound to be moved")
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	copies, err := reader.FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventCopy}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	if len(copies) != 1 || copies[0].SourcePath != sharedFile || copies[0].DestinationPath != copied {
+		t.Fatalf("Expected one COPY event for the shared file, got %+v", copies)
+	}
+
+	// The copy is already in place, so a second run leaves it alone
+	second, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	for _, result := range second.Results {
+		if result.SourcePath == sharedFile && result.ReasonCode != string(audit.ReasonAlreadyCopied) {
+			t.Errorf("Expected shared file skipped with ALREADY_COPIED, got %s %s", result.EventType, result.ReasonCode)
+		}
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	undo, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoRun(audit.RunID(summary.RunID), nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Restored != 2 {
+		t.Errorf("Expected 2 undone operations, got %d: %+v", undo.Restored, undo.FailureDetails)
+	}
+	if _, err := os.Stat(copied); !os.IsNotExist(err) {
+		t.Error("Expected undo to delete the copy")
+	}
+	if _, err := os.Stat(sharedFile); err != nil {
+		t.Errorf("Expected original to remain after undo: %v", err)
+	}
+	if _, err := os.Stat(localFile); err != nil {
+		t.Errorf("Expected moved file restored: %v", err)
+	}
+}
+
+// TestReadOnlyInboundSkipsCopyUnderDuplicateName verifies that a file copied
+// under a duplicate name, because its destination name held other content, is
+// not copied again by the next run.
+func TestReadOnlyInboundSkipsCopyUnderDuplicateName(t *testing.T) {
+	tempDir := t.TempDir()
+
+	shareDir := filepath.Join(tempDir, "share")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	yearDir := filepath.Join(invoiceDir, "2024 Invoice")
+	os.MkdirAll(shareDir, 0755)
+	os.MkdirAll(yearDir, 0755)
+
+	sharedFile := filepath.Join(shareDir, "Invoice 2024-01-15 Acme.pdf")
+	os.WriteFile(sharedFile, []byte("shared"), 0644)
+	os.WriteFile(filepath.Join(yearDir, "Invoice 2024-01-15 Acme.pdf"), []byte("other"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{shareDir},
+		ReadOnlyInbounds:   []string{shareDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	first, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if first.DuplicateCount != 1 {
+		t.Fatalf("Expected the file copied as a duplicate, got %+v", first.Results)
+	}
+
+	second, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if len(second.Results) != 1 || second.Results[0].ReasonCode != string(audit.ReasonAlreadyCopied) {
+		t.Fatalf("Expected the file skipped with ALREADY_COPIED, got %+v", second.Results)
+	}
+	entries, _ := os.ReadDir(yearDir)
+	if len(entries) != 2 {
+		t.Errorf("Expected the original and one copy, got %d files", len(entries))
+	}
+}
+
+// TestReadOnlyInboundCopiesIntoStage verifies that a file from a read-only
+// inbound is copied into the stage folder its COPY event records, so undo
+// finds the copy there.
+func TestReadOnlyInboundCopiesIntoStage(t *testing.T) {
+	tempDir := t.TempDir()
+
+	shareDir := filepath.Join(tempDir, "share")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(shareDir, 0755)
+	sharedFile := filepath.Join(shareDir, "Invoice 2024-01-15 Acme.pdf")
+	os.WriteFile(sharedFile, []byte("shared"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{shareDir},
+		ReadOnlyInbounds:   []string{shareDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig, Stage: "batch1"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SuccessCount != 1 {
+		t.Fatalf("Expected the file copied, got %+v", summary.Results)
+	}
+
+	staged := filepath.Join(invoiceDir, "2024 Invoice", "batch1", "Invoice 2024-01-15 Acme.pdf")
+	if data, err := os.ReadFile(staged); err != nil || string(data) != "shared" {
+		t.Fatalf("Expected the copy in the stage folder at %s, got %q (%v)", staged, data, err)
+	}
+	reader := audit.NewAuditReader(auditDir)
+	copies, err := reader.FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventCopy}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	if len(copies) != 1 || copies[0].DestinationPath != staged {
+		t.Fatalf("Expected one COPY event to %s, got %+v", staged, copies)
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+	undo, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoRun(audit.RunID(summary.RunID), nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Failed != 0 {
+		t.Errorf("Expected undo without failures, got %+v", undo.FailureDetails)
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Error("Expected undo to delete the copy")
+	}
+	if _, err := os.Stat(sharedFile); err != nil {
+		t.Errorf("Expected the original to remain: %v", err)
+	}
+}
+
+// TestNoCreateDirsSkipsMissingDestinations verifies that with NoCreateDirs a
+// file whose year folder does not exist is skipped with DEST_DIR_MISSING and
+// the folder is not created, while a file whose folder exists is moved.
+func TestNoCreateDirsSkipsMissingDestinations(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(filepath.Join(invoiceDir, "2024 Invoice"), 0755)
+
+	existingYear := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	missingYear := filepath.Join(sourceDir, "Invoice 2023-04-20 Beta.pdf")
+	for _, path := range []string{existingYear, missingYear} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, &Options{NoCreateDirs: true})
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 1 || len(dryRun.Skipped) != 1 || dryRun.Skipped[0].Reason != string(audit.ReasonDestDirMissing) {
+		t.Errorf("Expected dry run to move 1 file and skip 1 with DEST_DIR_MISSING, got %+v", dryRun)
+	}
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:  &auditConfig,
+		AppVersion:   "1.0.0",
+		MachineID:    "test-machine",
+		NoCreateDirs: true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if summary.SuccessCount != 1 || summary.SkippedCount != 1 {
+		t.Errorf("Expected 1 moved and 1 skipped file, got %d moved, %d skipped", summary.SuccessCount, summary.SkippedCount)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(existingYear))); err != nil {
+		t.Errorf("Expected file with existing year folder to be moved: %v", err)
+	}
+	if _, err := os.Stat(missingYear); err != nil {
+		t.Errorf("Expected file with missing year folder to stay in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2023 Invoice")); !os.IsNotExist(err) {
+		t.Error("Expected missing year folder not to be created")
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	skips, err := reader.FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventSkip}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	if len(skips) != 1 || skips[0].SourcePath != missingYear || skips[0].ReasonCode != audit.ReasonDestDirMissing {
+		t.Errorf("Expected one DEST_DIR_MISSING skip for %s, got %+v", missingYear, skips)
+	}
+}
+
+// TestFailurePredicateForcesMoveError verifies that a file selected by the
+// failure predicate fails with an ERROR event and stays in place, while other
+// files are moved as usual.
+func TestFailurePredicateForcesMoveError(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	failing := filepath.Join(sourceDir, "Invoice 2024-03-15 Fail.pdf")
+	passing := filepath.Join(sourceDir, "Invoice 2024-04-20 Pass.pdf")
+	for _, path := range []string{failing, passing} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	injected := errors.New("injected failure")
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:      &auditConfig,
+		AppVersion:       "1.0.0",
+		MachineID:        "test-machine",
+		FailurePredicate: GlobFailures("*Fail.pdf", injected),
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if summary.SuccessCount != 1 || summary.ErrorCount != 1 {
+		t.Fatalf("Expected 1 moved and 1 failed file, got %d moved, %d errors", summary.SuccessCount, summary.ErrorCount)
+	}
+	for _, result := range summary.Results {
+		if result.SourcePath == failing && !errors.Is(result.Error, injected) {
+			t.Errorf("Expected injected error for %s, got %v", failing, result.Error)
+		}
+	}
+	if _, err := os.Stat(failing); err != nil {
+		t.Errorf("Expected failing file to stay in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(failing))); !os.IsNotExist(err) {
+		t.Error("Expected no copy of the failing file at its destination")
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(passing))); err != nil {
+		t.Errorf("Expected other file to be moved: %v", err)
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	errorEvents, err := reader.FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventError}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	if len(errorEvents) != 1 || errorEvents[0].SourcePath != failing {
+		t.Errorf("Expected one ERROR event for %s, got %+v", failing, errorEvents)
+	}
+}
+
+// TestFailFastStopsAtFirstError verifies that with FailFast a run stops at the
+// first failed file, leaves later files unprocessed, and ends as FAILED.
+func TestFailFastStopsAtFirstError(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	names := []string{"Invoice 2024-01-01 A.pdf", "Invoice 2024-02-01 B.pdf", "Invoice 2024-03-01 C.pdf"}
+	for _, name := range names {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:      &auditConfig,
+		AppVersion:       "1.0.0",
+		MachineID:        "test-machine",
+		FailurePredicate: GlobFailures(names[0], errors.New("injected failure")),
+		FailFast:         true,
+	})
+
+	var failFastErr *FailFastError
+	if !errors.As(err, &failFastErr) || failFastErr.Path != filepath.Join(sourceDir, names[0]) {
+		t.Fatalf("Expected FailFastError for %s, got %v", names[0], err)
+	}
+	if len(summary.Results) != 1 || summary.ErrorCount != 1 || summary.SuccessCount != 0 {
+		t.Errorf("Expected only the failed file to be processed, got %d results", len(summary.Results))
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(sourceDir, name)); err != nil {
+			t.Errorf("Expected %s to stay in place: %v", name, err)
+		}
+	}
+
+	run, err := audit.NewAuditReader(auditDir).GetRunByID(audit.RunID(summary.RunID))
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if run.Status != audit.RunStatusFailed {
+		t.Errorf("Expected run status FAILED, got %s", run.Status)
+	}
+}
+
+// TestMaxErrorsAbortsRun verifies that a run with MaxErrors stops after the
+// Nth failed file, keeps the files moved before it and ends as FAILED.
+func TestMaxErrorsAbortsRun(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	names := []string{
+		"Invoice 2024-01-01 Pass.pdf",
+		"Invoice 2024-01-02 Fail.pdf",
+		"Invoice 2024-01-03 Pass.pdf",
+		"Invoice 2024-01-04 Fail.pdf",
+		"Invoice 2024-01-05 Fail.pdf",
+		"Invoice 2024-01-06 Pass.pdf",
+		"Invoice 2024-01-07 Fail.pdf",
+	}
+	for _, name := range names {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:      &auditConfig,
+		FailurePredicate: GlobFailures("*Fail.pdf", errors.New("injected failure")),
+		MaxErrors:        3,
+	})
+
+	var maxErrorsErr *MaxErrorsError
+	if !errors.As(err, &maxErrorsErr) || maxErrorsErr.Limit != 3 {
+		t.Fatalf("Expected MaxErrorsError with limit 3, got %v", err)
+	}
+	var failFastErr *FailFastError
+	if errors.As(err, &failFastErr) {
+		t.Errorf("Expected the abort not to be reported as fail-fast, got %v", err)
+	}
+	if !summary.Aborted || len(summary.Results) != 5 || summary.ErrorCount != 3 || summary.SuccessCount != 2 {
+		t.Errorf("Expected the run to stop after the third error with 2 files moved, got aborted=%v results=%d errors=%d moved=%d",
+			summary.Aborted, len(summary.Results), summary.ErrorCount, summary.SuccessCount)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", names[2])); err != nil {
+		t.Errorf("Expected file moved before the abort to stay moved: %v", err)
+	}
+	for _, name := range names[5:] {
+		if _, err := os.Stat(filepath.Join(sourceDir, name)); err != nil {
+			t.Errorf("Expected %s not to be processed: %v", name, err)
+		}
+	}
+
+	run, err := audit.NewAuditReader(auditDir).GetRunByID(audit.RunID(summary.RunID))
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if run.Status != audit.RunStatusFailed {
+		t.Errorf("Expected run status FAILED, got %s", run.Status)
+	}
+}
+
+// longPath returns a path below base that is exactly length bytes long, made
+// of components short enough to be created.
+func longPath(base string, length int) string {
+	path := base
+	for len(path) < length {
+		n := min(200, length-len(path)-1)
+		if n < 1 {
+			// Leave room for a separator and at least one character
+			path = path[:len(path)-1]
+			n = 2
+		}
+		path = filepath.Join(path, strings.Repeat("d", n))
+	}
+	return path
+}
+
+// TestDestinationPathLengthLimit verifies that a file whose destination path
+// would exceed the platform limit is routed to review with PATH_TOO_LONG, and
+// that with TruncateLongNames its description is shortened so it is moved,
+// leaving room for a duplicate suffix when a second copy arrives.
+func TestDestinationPathLengthLimit(t *testing.T) {
+	name := "Invoice 2024-01-15 Acme Corporation Quarterly Statement.pdf"
+	const excess = 10
+
+	for _, truncate := range []bool{false, true} {
+		tempDir := t.TempDir()
+		sourceDir := filepath.Join(tempDir, "source")
+		os.MkdirAll(sourceDir, 0755)
+		os.WriteFile(filepath.Join(sourceDir, name), []byte("invoice"), 0644)
+
+		yearFolder := string(filepath.Separator) + "2024 Invoice" + string(filepath.Separator)
+		outboundDir := longPath(filepath.Join(tempDir, "out"), filesystem.MaxPathLength-len(yearFolder)-len(name)+excess)
+
+		configPath := writeTestConfig(t, tempDir, config.Configuration{
+			InboundDirectories: []string{sourceDir},
+			PrefixRules:        []config.PrefixRule{{Prefix: "Invoice", OutboundDirectory: outboundDir}},
+			TruncateLongNames:  truncate,
+		})
+
+		summary, err := RunWithOptions(configPath, nil)
+		if err != nil {
+			t.Fatalf("RunWithOptions failed: %v", err)
+		}
+		if len(summary.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(summary.Results))
+		}
+		result := summary.Results[0]
+
+		if !truncate {
+			if result.EventType != "ROUTE_TO_REVIEW" || result.ReasonCode != string(audit.ReasonPathTooLong) {
+				t.Errorf("Expected route to review with PATH_TOO_LONG, got %s %s", result.EventType, result.ReasonCode)
+			}
+			if _, err := os.Stat(filepath.Join(sourceDir, "for-review", name)); err != nil {
+				t.Errorf("Expected file in for-review: %v", err)
+			}
+			continue
+		}
+
+		want := "Invoice 2024-01-15 Acme Corporat.pdf"
+		if result.EventType != "MOVE" || filepath.Base(result.DestinationPath) != want {
+			t.Fatalf("Expected move as %q, got %s to %s", want, result.EventType, result.DestinationPath)
+		}
+		if len(result.DestinationPath) > filesystem.MaxPathLength {
+			t.Errorf("Expected destination within %d bytes, got %d", filesystem.MaxPathLength, len(result.DestinationPath))
+		}
+		if _, err := os.Stat(result.DestinationPath); err != nil {
+			t.Errorf("Expected shortened file at destination: %v", err)
+		}
+
+		os.WriteFile(filepath.Join(sourceDir, name), []byte("another invoice"), 0644)
+		summary, err = RunWithOptions(configPath, nil)
+		if err != nil {
+			t.Fatalf("Second run failed: %v", err)
+		}
+		if summary.DuplicateCount != 1 {
+			t.Fatalf("Expected the second copy moved as a duplicate, got %+v", summary.Results)
+		}
+		if path := summary.Results[0].DestinationPath; len(path) > filesystem.MaxPathLength {
+			t.Errorf("Expected duplicate within %d bytes, got %d", filesystem.MaxPathLength, len(path))
+		}
+	}
+}
+
+// TestRecordMatchedRuleInMoveEvents verifies that with recordMatchedRule the
+// MOVE event of a classified file carries the matched rule's prefix and
+// outbound directory, and that it is left out otherwise.
+func TestRecordMatchedRuleInMoveEvents(t *testing.T) {
+	for _, record := range []bool{true, false} {
+		tempDir := t.TempDir()
+		sourceDir := filepath.Join(tempDir, "source")
+		invoiceDir := filepath.Join(tempDir, "invoices")
+		auditDir := filepath.Join(tempDir, "audit")
+		os.MkdirAll(sourceDir, 0755)
+		source := filepath.Join(sourceDir, "invoice 2024-03-15 Acme.pdf")
+		os.WriteFile(source, []byte("invoice"), 0644)
+
+		configPath := writeTestConfig(t, tempDir, config.Configuration{
+			InboundDirectories: []string{sourceDir},
+			PrefixRules: []config.PrefixRule{
+				{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+			},
+		})
+
+		auditConfig := audit.AuditConfig{LogDirectory: auditDir, RecordMatchedRule: record}
+		summary, err := RunWithOptions(configPath, &Options{
+			AuditConfig: &auditConfig,
+			AppVersion:  "1.0.0",
+			MachineID:   "test-machine",
+		})
+		if err != nil {
+			t.Fatalf("RunWithOptions failed: %v", err)
+		}
+
+		reader := audit.NewAuditReader(auditDir)
+		moves, err := reader.FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventMove}})
+		if err != nil {
+			t.Fatalf("Failed to read events: %v", err)
+		}
+		if len(moves) != 1 {
+			t.Fatalf("Expected one MOVE event, got %d", len(moves))
+		}
+
+		metadata := moves[0].Metadata
+		if !record {
+			if _, ok := metadata["matchedPrefix"]; ok {
+				t.Errorf("Expected no matched rule without recordMatchedRule, got %v", metadata)
+			}
+			continue
+		}
+		if metadata["matchedPrefix"] != "Invoice" || metadata["matchedRuleOutbound"] != invoiceDir {
+			t.Errorf("Expected matched rule Invoice -> %s in metadata, got %v", invoiceDir, metadata)
+		}
+	}
+}
+
+// TestZeroByteAction verifies that empty files are organized, skipped or
+// routed to review according to zeroByteAction, and that with "process" two
+// empty files are not treated as identical by --dedupe-within-run.
+func TestZeroByteAction(t *testing.T) {
+	const emptyName = "Invoice 2024-03-15 Empty.pdf"
+	const fullName = "Invoice 2024-03-16 Full.pdf"
+
+	tests := []struct {
+		action       string
+		moved        int
+		review       int
+		skipped      int
+		emptyDestDir func(inbound, invoiceDir string) string // Where empty files end up (empty = left in place)
+	}{
+		{
+			action: config.ZeroByteProcess, moved: 3,
+			emptyDestDir: func(inbound, invoiceDir string) string { return filepath.Join(invoiceDir, "2024 Invoice") },
+		},
+		{
+			action: config.ZeroByteSkip, moved: 1, skipped: 2,
+			emptyDestDir: func(inbound, invoiceDir string) string { return "" },
+		},
+		{
+			action: config.ZeroByteReview, moved: 3, review: 2,
+			emptyDestDir: func(inbound, invoiceDir string) string { return filepath.Join(inbound, "for-review") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			tempDir := t.TempDir()
+			inboundA := filepath.Join(tempDir, "a")
+			inboundB := filepath.Join(tempDir, "b")
+			invoiceDir := filepath.Join(tempDir, "invoices")
+			auditDir := filepath.Join(tempDir, "audit")
+			os.MkdirAll(inboundA, 0755)
+			os.MkdirAll(inboundB, 0755)
+
+			os.WriteFile(filepath.Join(inboundA, emptyName), nil, 0644)
+			os.WriteFile(filepath.Join(inboundB, emptyName), nil, 0644)
+			os.WriteFile(filepath.Join(inboundA, fullName), []byte("content"), 0644)
+
+			configPath := writeTestConfig(t, tempDir, config.Configuration{
+				InboundDirectories: []string{inboundA, inboundB},
+				PrefixRules: []config.PrefixRule{
+					{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+				},
+				ZeroByteAction: tt.action,
+			})
+
+			dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+			if err != nil {
+				t.Fatalf("RunDryRunWithOptions failed: %v", err)
+			}
+			if len(dryRun.Moved) != 3-tt.skipped-tt.review || len(dryRun.ForReview) != tt.review || len(dryRun.Skipped) != tt.skipped {
+				t.Errorf("Unexpected dry run: %d moved, %d review, %d skipped", len(dryRun.Moved), len(dryRun.ForReview), len(dryRun.Skipped))
+			}
+
+			auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+			summary, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig, DedupeWithinRun: true})
+			if err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+			if summary.SuccessCount != tt.moved || summary.ReviewCount != tt.review || summary.SkippedCount != tt.skipped {
+				t.Errorf("Expected %d moved, %d review and %d skipped, got %d, %d and %d",
+					tt.moved, tt.review, tt.skipped, summary.SuccessCount, summary.ReviewCount, summary.SkippedCount)
+			}
+			for _, result := range summary.Results {
+				if (result.EventType == "SKIP" || result.EventType == "ROUTE_TO_REVIEW") && result.ReasonCode != string(audit.ReasonZeroByte) {
+					t.Errorf("Expected reason ZERO_BYTE for %s, got %q", result.SourcePath, result.ReasonCode)
+				}
+			}
+
+			for _, inbound := range []string{inboundA, inboundB} {
+				destDir := tt.emptyDestDir(inbound, invoiceDir)
+				if destDir == "" {
+					destDir = inbound
+				}
+				entries, _ := os.ReadDir(destDir)
+				found := false
+				for _, entry := range entries {
+					found = found || strings.HasPrefix(entry.Name(), "Invoice 2024-03-15 Empty")
+				}
+				if !found {
+					t.Errorf("Expected an empty file in %s", destDir)
+				}
+			}
+		})
+	}
+}
+
+// TestMinFileSize verifies that with a 1KB minFileSize only the larger file
+// is organized and the smaller one is skipped as TOO_SMALL, in dry-run and
+// real mode, and that an empty file goes by zeroByteAction unless it is
+// "process".
+func TestMinFileSize(t *testing.T) {
+	const largeName = "Invoice 2024-03-15 Large.pdf"
+	const smallName = "Invoice 2024-03-16 Small.url"
+	const emptyName = "Invoice 2024-03-17 Empty.pdf"
+
+	tests := []struct {
+		zeroByteAction string
+		emptyReason    audit.ReasonCode
+		emptyEvent     string
+	}{
+		{zeroByteAction: config.ZeroByteProcess, emptyReason: audit.ReasonTooSmall, emptyEvent: "SKIP"},
+		{zeroByteAction: config.ZeroByteReview, emptyReason: audit.ReasonZeroByte, emptyEvent: "ROUTE_TO_REVIEW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zeroByteAction, func(t *testing.T) {
+			tempDir := t.TempDir()
+			sourceDir := filepath.Join(tempDir, "source")
+			invoiceDir := filepath.Join(tempDir, "invoices")
+			os.MkdirAll(sourceDir, 0755)
+			os.WriteFile(filepath.Join(sourceDir, largeName), make([]byte, 2048), 0644)
+			os.WriteFile(filepath.Join(sourceDir, smallName), make([]byte, 100), 0644)
+			os.WriteFile(filepath.Join(sourceDir, emptyName), nil, 0644)
+
+			configPath := writeTestConfig(t, tempDir, config.Configuration{
+				InboundDirectories: []string{sourceDir},
+				PrefixRules: []config.PrefixRule{
+					{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+				},
+				MinFileSize:    1024,
+				ZeroByteAction: tt.zeroByteAction,
+			})
+
+			dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+			if err != nil {
+				t.Fatalf("RunDryRunWithOptions failed: %v", err)
+			}
+			if len(dryRun.Moved) != 1 || dryRun.Moved[0].Source != filepath.Join(sourceDir, largeName) {
+				t.Errorf("Expected the dry run to move only %s, got %+v", largeName, dryRun.Moved)
+			}
+
+			summary, err := RunWithOptions(configPath, &Options{AuditConfig: &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}})
+			if err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+			want := map[string]struct {
+				event  string
+				reason audit.ReasonCode
+			}{
+				largeName: {"MOVE", ""},
+				smallName: {"SKIP", audit.ReasonTooSmall},
+				emptyName: {tt.emptyEvent, tt.emptyReason},
+			}
+			if len(summary.Results) != len(want) {
+				t.Fatalf("Expected %d results, got %+v", len(want), summary.Results)
+			}
+			for _, result := range summary.Results {
+				w := want[filepath.Base(result.SourcePath)]
+				if result.EventType != w.event || result.ReasonCode != string(w.reason) {
+					t.Errorf("Expected %s %s for %s, got %s %q", w.event, w.reason, result.SourcePath, result.EventType, result.ReasonCode)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", largeName)); err != nil {
+				t.Errorf("Expected the large file to be organized: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(sourceDir, smallName)); err != nil {
+				t.Errorf("Expected the small file to stay in place: %v", err)
+			}
+		})
+	}
+}
+
+// TestMultiWordPrefixDestination verifies that a file matched by a prefix
+// with a space is filed under "<year> <full prefix>", in dry-run and real mode.
+func TestMultiWordPrefixDestination(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	statementDir := filepath.Join(tempDir, "statements")
+	os.MkdirAll(sourceDir, 0755)
+
+	source := filepath.Join(sourceDir, "bank statement 2024-01-15 Chase.pdf")
+	os.WriteFile(source, []byte("statement"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Bank", OutboundDirectory: filepath.Join(tempDir, "bank")},
+			{Prefix: "Bank Statement", OutboundDirectory: statementDir},
+		},
+	})
+	want := filepath.Join(statementDir, "2024 Bank Statement", "Bank Statement 2024-01-15 Chase.pdf")
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 1 || dryRun.Moved[0].Destination != want || dryRun.Moved[0].Prefix != "Bank Statement" {
+		t.Errorf("Expected a planned move to %s with prefix \"Bank Statement\", got %+v", want, dryRun.Moved)
+	}
+
+	summary, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || summary.Results[0].DestinationPath != want {
+		t.Errorf("Expected the file to be moved to %s, got %+v", want, summary.Results)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected file at %s: %v", want, err)
+	}
+}
+
+// TestExtensionlessFiles verifies that a matching file without an extension
+// is moved under its name by default and routed to review with NO_EXTENSION
+// when requireExtension is set.
+func TestExtensionlessFiles(t *testing.T) {
+	const name = "Invoice 2024-01-15 Acme"
+
+	for _, requireExtension := range []bool{false, true} {
+		t.Run(fmt.Sprintf("requireExtension=%v", requireExtension), func(t *testing.T) {
+			tempDir := t.TempDir()
+			sourceDir := filepath.Join(tempDir, "source")
+			invoiceDir := filepath.Join(tempDir, "invoices")
+			os.MkdirAll(sourceDir, 0755)
+			os.WriteFile(filepath.Join(sourceDir, name), []byte("invoice"), 0644)
+
+			configPath := writeTestConfig(t, tempDir, config.Configuration{
+				InboundDirectories: []string{sourceDir},
+				PrefixRules: []config.PrefixRule{
+					{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+				},
+				RequireExtension: requireExtension,
+			})
+
+			want := filepath.Join(invoiceDir, "2024 Invoice", name)
+			wantReason := ""
+			if requireExtension {
+				want = filepath.Join(sourceDir, "for-review", name)
+				wantReason = string(audit.ReasonNoExtension)
+			}
+
+			dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+			if err != nil {
+				t.Fatalf("RunDryRunWithOptions failed: %v", err)
+			}
+			planned := append(dryRun.Moved, dryRun.ForReview...)
+			if len(planned) != 1 || planned[0].Destination != want || planned[0].Reason != wantReason {
+				t.Errorf("Expected a planned move to %s with reason %q, got %+v", want, wantReason, planned)
+			}
+
+			summary, err := RunWithOptions(configPath, nil)
+			if err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+			if len(summary.Results) != 1 || summary.Results[0].DestinationPath != want || summary.Results[0].ReasonCode != wantReason {
+				t.Errorf("Expected the file to go to %s with reason %q, got %+v", want, wantReason, summary.Results)
+			}
+			if _, err := os.Stat(want); err != nil {
+				t.Errorf("Expected file at %s: %v", want, err)
+			}
+		})
+	}
+}
+
+// TestExtensionContentMismatch verifies that with
+// verifyExtensionMatchesContent a ".pdf" file holding HTML is routed to review
+// with EXTENSION_CONTENT_MISMATCH, while a real PDF is moved, as is an MP3
+// without an ID3 tag, whose type cannot be told from its first bytes.
+func TestExtensionContentMismatch(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+	htmlName := "Invoice 2024-01-15 Acme.pdf"
+	pdfName := "Invoice 2024-01-16 Beta.pdf"
+	os.WriteFile(filepath.Join(sourceDir, htmlName), []byte("<!DOCTYPE html><html><body>502 Bad Gateway</body></html>"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, pdfName), []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), 0644)
+	mp3Name := "Invoice 2024-01-17 Gamma.mp3"
+	os.WriteFile(filepath.Join(sourceDir, mp3Name), append([]byte("\xff\xfb\x90\x64"), make([]byte, 64)...), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		VerifyExtensionMatchesContent: true,
+	})
+	wantReview := filepath.Join(sourceDir, "for-review", htmlName)
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.ForReview) != 1 || dryRun.ForReview[0].Destination != wantReview || dryRun.ForReview[0].Reason != string(audit.ReasonContentMismatch) {
+		t.Errorf("Expected a planned review of %s with %s, got %+v", htmlName, audit.ReasonContentMismatch, dryRun.ForReview)
+	}
+
+	summary, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.ReviewCount != 1 || summary.SuccessCount != 3 {
+		t.Fatalf("Expected 1 file routed to review and 2 moved, got %+v", summary.Results)
+	}
+	for _, result := range summary.Results {
+		if result.EventType == "ROUTE_TO_REVIEW" && (result.DestinationPath != wantReview || result.ReasonCode != string(audit.ReasonContentMismatch)) {
+			t.Errorf("Expected %s to go to %s with %s, got %+v", htmlName, wantReview, audit.ReasonContentMismatch, result)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", pdfName)); err != nil {
+		t.Errorf("Expected the real PDF to be moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", mp3Name)); err != nil {
+		t.Errorf("Expected the MP3 to be moved: %v", err)
+	}
+}
+
+// TestRuleExtensions verifies that a file matching a rule limited to certain
+// extensions is routed to review when its extension is not one of them, in
+// dry-run and real mode, while allowed extensions match case-insensitively.
+func TestRuleExtensions(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	photoDir := filepath.Join(tempDir, "photos")
+	os.MkdirAll(sourceDir, 0755)
+	docxName := "Invoice 2024-01-15 x.docx"
+	for _, name := range []string{docxName, "Invoice 2024-01-16 y.PDF", "Photo 2024-01-17 z.jpg"} {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir, Extensions: []string{".pdf"}},
+			{Prefix: "Photo", OutboundDirectory: photoDir, Extensions: []string{"jpg", "png"}},
+		},
+	})
+	wantReview := filepath.Join(sourceDir, "for-review", docxName)
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.ForReview) != 1 || dryRun.ForReview[0].Destination != wantReview || dryRun.ForReview[0].Reason != string(audit.ReasonExtensionNotAllowed) {
+		t.Errorf("Expected a planned review of %s with %s, got %+v", docxName, audit.ReasonExtensionNotAllowed, dryRun.ForReview)
+	}
+
+	summary, err := RunWithOptions(configPath, nil)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.ReviewCount != 1 || summary.SuccessCount != 3 {
+		t.Fatalf("Expected 1 file routed to review and 2 moved, got %+v", summary.Results)
+	}
+	for _, result := range summary.Results {
+		if result.EventType == "ROUTE_TO_REVIEW" && (result.DestinationPath != wantReview || result.ReasonCode != string(audit.ReasonExtensionNotAllowed)) {
+			t.Errorf("Expected %s to go to %s with %s, got %+v", docxName, wantReview, audit.ReasonExtensionNotAllowed, result)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-01-16 y.PDF")); err != nil {
+		t.Errorf("Expected the PDF to be moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(photoDir, "2024 Photo", "Photo 2024-01-17 z.jpg")); err != nil {
+		t.Errorf("Expected the photo to be moved: %v", err)
+	}
+}
+
+// TestAlreadyOrganized verifies that a file already at its destination is
+// skipped with ALREADY_ORGANIZED instead of being renamed as a duplicate of
+// itself.
+func TestAlreadyOrganized(t *testing.T) {
+	tempDir := t.TempDir()
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	yearDir := filepath.Join(invoiceDir, "2024 Invoice")
+	os.MkdirAll(yearDir, 0755)
+	path := filepath.Join(yearDir, "Invoice 2024-01-15 Acme.pdf")
+	os.WriteFile(path, []byte("invoice"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{yearDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Skipped) != 1 || dryRun.Skipped[0].Reason != string(audit.ReasonAlreadyOrganized) {
+		t.Errorf("Expected a planned skip with %s, got %+v", audit.ReasonAlreadyOrganized, dryRun.Skipped)
+	}
+
+	auditDir := filepath.Join(tempDir, "audit")
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &audit.AuditConfig{LogDirectory: auditDir}})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SkippedCount != 1 || summary.Results[0].ReasonCode != string(audit.ReasonAlreadyOrganized) {
+		t.Fatalf("Expected the file to be skipped with %s, got %+v", audit.ReasonAlreadyOrganized, summary.Results)
+	}
+	entries, _ := os.ReadDir(yearDir)
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(path) {
+		t.Errorf("Expected only the original file in %s, got %v", yearDir, entries)
+	}
+
+	run, err := audit.NewAuditReader(auditDir).GetLatestRun()
+	if err != nil {
+		t.Fatalf("GetLatestRun failed: %v", err)
+	}
+	if run.Summary.Moved != 0 || run.Summary.Skipped != 1 {
+		t.Errorf("Expected the run to record 1 skip and no moves, got %+v", run.Summary)
+	}
+}
+
+// TestDirPermissions verifies that destination directories a run creates get
+// the configured dirPermissions regardless of the umask, that the option
+// overrides the configuration, and that existing directories are left alone.
+func TestDirPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	receiptDir := filepath.Join(tempDir, "receipts")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(receiptDir, 0700)
+	os.WriteFile(filepath.Join(sourceDir, "Invoice 2024-01-15 Acme.pdf"), []byte("invoice"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "Receipt 2024-02-01 Shop.pdf"), []byte("receipt"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(targetDir, "invoices")},
+			{Prefix: "Receipt", OutboundDirectory: receiptDir},
+		},
+		DirPermissions: "0775",
+	})
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 2 {
+		t.Fatalf("Expected 2 files moved, got %+v", summary.Results)
+	}
+
+	for _, dir := range []string{
+		targetDir,
+		filepath.Join(targetDir, "invoices"),
+		filepath.Join(targetDir, "invoices", "2024 Invoice"),
+		filepath.Join(receiptDir, "2024 Receipt"),
+	} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatalf("Expected %s to be created: %v", dir, err)
+		}
+		if info.Mode().Perm() != 0775 {
+			t.Errorf("Expected %s to have mode 0775, got %o", dir, info.Mode().Perm())
+		}
+	}
+	if info, _ := os.Stat(receiptDir); info.Mode().Perm() != 0700 {
+		t.Errorf("Expected existing %s to keep mode 0700, got %o", receiptDir, info.Mode().Perm())
+	}
+
+	os.WriteFile(filepath.Join(sourceDir, "Invoice 2025-01-15 Acme.pdf"), []byte("invoice"), 0644)
+	if _, err := RunWithOptions(configPath, &Options{
+		AuditConfig:    &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")},
+		DirPermissions: "0770",
+	}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	yearDir := filepath.Join(targetDir, "invoices", "2025 Invoice")
+	if info, err := os.Stat(yearDir); err != nil || info.Mode().Perm() != 0770 {
+		t.Errorf("Expected %s to have the overriding mode 0770, got %v, %v", yearDir, info, err)
+	}
+}
+
+// TestDirPermissionsThroughWrappedFS verifies that dirPermissions is applied
+// exactly when files are staged with TransactionalStaging or failures are
+// injected, both of which wrap the filesystem.
+func TestDirPermissionsThroughWrappedFS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{"transactional", Options{TransactionalStaging: true}},
+		{"injected failures", Options{FailurePredicate: GlobFailures("*.none", errors.New("injected failure"))}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			sourceDir := filepath.Join(tempDir, "source")
+			targetDir := filepath.Join(tempDir, "target")
+			os.MkdirAll(sourceDir, 0755)
+			os.WriteFile(filepath.Join(sourceDir, "Invoice 2024-01-15 Acme.pdf"), []byte("invoice"), 0644)
+
+			configPath := writeTestConfig(t, tempDir, config.Configuration{
+				InboundDirectories: []string{sourceDir},
+				PrefixRules: []config.PrefixRule{
+					{Prefix: "Invoice", OutboundDirectory: filepath.Join(targetDir, "invoices")},
+				},
+				DirPermissions: "0775",
+			})
+			options := tc.options
+			options.AuditConfig = &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}
+			summary, err := RunWithOptions(configPath, &options)
+			if err != nil {
+				t.Fatalf("RunWithOptions failed: %v", err)
+			}
+			if summary.SuccessCount != 1 {
+				t.Fatalf("Expected 1 file moved, got %+v", summary.Results)
+			}
+
+			for _, dir := range []string{
+				targetDir,
+				filepath.Join(targetDir, "invoices"),
+				filepath.Join(targetDir, "invoices", "2024 Invoice"),
+			} {
+				info, err := os.Stat(dir)
+				if err != nil {
+					t.Fatalf("Expected %s to be created: %v", dir, err)
+				}
+				if info.Mode().Perm() != 0775 {
+					t.Errorf("Expected %s to have mode 0775, got %o", dir, info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
+
+// TestHashCacheFollowsMovedFile verifies that the hash cached when a file's
+// identity is captured is kept under its destination once it is moved, so it
+// survives saving the cache and is found by undo.
+func TestHashCacheFollowsMovedFile(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+	source := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(source, []byte("invoice"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	cache, err := audit.LoadHashCache(auditDir)
+	if err != nil {
+		t.Fatalf("LoadHashCache failed: %v", err)
+	}
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	if _, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig, HashCache: cache}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded, err := audit.LoadHashCache(auditDir)
+	if err != nil {
+		t.Fatalf("LoadHashCache failed: %v", err)
+	}
+	dest := filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-03-15 Acme.pdf")
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("Expected the file at %s: %v", dest, err)
+	}
+	identity, err := audit.NewIdentityResolver().CaptureIdentity(dest)
+	if err != nil {
+		t.Fatalf("CaptureIdentity failed: %v", err)
+	}
+	if hash, ok := reloaded.Lookup(dest, info); !ok || hash != identity.ContentHash {
+		t.Errorf("Expected the hash cached under %s, got %q (%v)", dest, hash, ok)
+	}
+}
@@ -0,0 +1,82 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// checkDirectoryNesting rejects outbound directories inside an inbound
+// directory unless the run scans only the top level of each inbound directory
+// (scan depth 0), where organized files are never picked up again.
+func checkDirectoryNesting(cfg *config.Configuration, options *Options) error {
+	depth := cfg.GetScanDepth()
+	if options != nil && options.ScanDepth != nil {
+		depth = *options.ScanDepth
+	}
+	if depth == 0 {
+		return nil
+	}
+	return cfg.ValidateDirectoryNesting()
+}
+
+// checkOutboundWritable writes and removes a probe file in each distinct
+// outbound directory of cfg's prefix and type rules and its catch-all folder,
+// and returns an OutboundNotWritableError for the first one that cannot be
+// written. A directory that does not exist yet is checked at its nearest
+// existing parent, where the run would create it; with noCreateDirs it is
+// left out, since its files are skipped anyway.
+func checkOutboundWritable(fsys filesystem.FS, cfg *config.Configuration, noCreateDirs bool) error {
+	var dirs []string
+	for _, rule := range cfg.PrefixRules {
+		dirs = append(dirs, config.OutboundRoot(rule.OutboundDirectory))
+	}
+	for _, rule := range cfg.TypeRules {
+		dirs = append(dirs, config.OutboundRoot(rule.OutboundDirectory))
+	}
+	if cfg.CatchAll != nil {
+		dirs = append(dirs, config.OutboundRoot(cfg.CatchAll.OutboundDirectory))
+	}
+
+	probeName := fmt.Sprintf(".sorta-write-check-%d", os.Getpid())
+	checked := make(map[string]bool)
+	for _, outbound := range dirs {
+		outbound = filepath.Clean(outbound)
+		dir := existingDir(fsys, outbound)
+		if dir == "" || checked[dir] || (noCreateDirs && dir != outbound) {
+			continue
+		}
+		checked[dir] = true
+
+		probe := filepath.Join(dir, probeName)
+		if err := fsys.WriteFile(probe, nil, 0644); err != nil {
+			return &OutboundNotWritableError{Dir: dir, Err: err}
+		}
+		if err := fsys.Remove(probe); err != nil {
+			return &OutboundNotWritableError{Dir: dir, Err: err}
+		}
+	}
+	return nil
+}
+
+// existingDir returns dir if it is an existing directory, otherwise its
+// nearest existing parent directory, or "" if there is none.
+func existingDir(fsys filesystem.FS, dir string) string {
+	for {
+		if info, err := fsys.Stat(dir); err == nil {
+			if info.IsDir() {
+				return dir
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// readOnlyFS refuses to write files below dir. Permission bits alone do not
+// stop a test running as root, so the read-only directory is simulated.
+type readOnlyFS struct {
+	filesystem.FS
+	dir string
+}
+
+func (f readOnlyFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if strings.HasPrefix(name, f.dir+string(filepath.Separator)) {
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	return f.FS.WriteFile(name, data, perm)
+}
+
+// TestOutboundCheckStopsRunBeforeAnyFile verifies that a run stops with an
+// error naming a read-only outbound directory before any file is moved, and
+// that SkipOutboundCheck bypasses the check.
+func TestOutboundCheckStopsRunBeforeAnyFile(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	receiptDir := filepath.Join(tempDir, "receipts")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(invoiceDir, 0755)
+	os.MkdirAll(receiptDir, 0755)
+
+	invoice := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	receipt := filepath.Join(sourceDir, "Receipt 2024-03-16 Shop.pdf")
+	for _, path := range []string{invoice, receipt} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+			{Prefix: "Receipt", OutboundDirectory: receiptDir},
+		},
+	}
+	o := NewOrchestratorWithFS(cfg, readOnlyFS{FS: filesystem.OS{}, dir: receiptDir})
+
+	_, err := o.Run(nil)
+	var notWritable *OutboundNotWritableError
+	if !errors.As(err, &notWritable) || notWritable.Dir != receiptDir {
+		t.Fatalf("Expected an OutboundNotWritableError for %s, got %v", receiptDir, err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Expected the permission error to be wrapped, got %v", err)
+	}
+	for _, path := range []string{invoice, receipt} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to stay in place: %v", path, err)
+		}
+	}
+
+	summary, err := o.Run(&Options{SkipOutboundCheck: true})
+	if err != nil {
+		t.Fatalf("Run with SkipOutboundCheck failed: %v", err)
+	}
+	if summary.TotalFiles != 2 {
+		t.Errorf("Expected both files to be processed, got %d", summary.TotalFiles)
+	}
+	entries, _ := os.ReadDir(invoiceDir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".sorta-write-check") {
+			t.Errorf("Expected the probe file to be removed, found %s", entry.Name())
+		}
+	}
+}
+
+// TestOutboundCheckCoversCatchAll verifies that a read-only catch-all folder
+// stops the run before any file is moved.
+func TestOutboundCheckCoversCatchAll(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	catchAllDir := filepath.Join(tempDir, "misc")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(catchAllDir, 0755)
+	other := filepath.Join(sourceDir, "Statement 2024-03-15.pdf")
+	os.WriteFile(other, []byte("statement"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+		CatchAll: &config.CatchAll{OutboundDirectory: catchAllDir},
+	}
+	o := NewOrchestratorWithFS(cfg, readOnlyFS{FS: filesystem.OS{}, dir: catchAllDir})
+
+	_, err := o.Run(nil)
+	var notWritable *OutboundNotWritableError
+	if !errors.As(err, &notWritable) || notWritable.Dir != catchAllDir {
+		t.Fatalf("Expected an OutboundNotWritableError for %s, got %v", catchAllDir, err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected %s to stay in place: %v", other, err)
+	}
+}
+
+// TestDirectoryNestingCheckDependsOnScanDepth verifies that a run refuses an
+// outbound directory inside the inbound directory when it scans recursively,
+// and organizes normally when it scans only the top level.
+func TestDirectoryNestingCheckDependsOnScanDepth(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(sourceDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+
+	invoice := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(invoice, []byte("invoice"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	}
+	o := NewOrchestrator(cfg)
+
+	depth := -1
+	_, err := o.Run(&Options{ScanDepth: &depth})
+	if err == nil || !strings.Contains(err.Error(), invoiceDir) {
+		t.Fatalf("Expected a nesting error naming %s, got %v", invoiceDir, err)
+	}
+	if _, err := os.Stat(invoice); err != nil {
+		t.Errorf("Expected %s to stay in place: %v", invoice, err)
+	}
+
+	summary, err := o.Run(nil)
+	if err != nil {
+		t.Fatalf("Run with scan depth 0 failed: %v", err)
+	}
+	if summary.SuccessCount != 1 {
+		t.Errorf("Expected the invoice to be moved, got %d moved", summary.SuccessCount)
+	}
+}
@@ -0,0 +1,182 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/organizer"
+)
+
+// PromoteResult reports the outcome of promoting a staged run.
+type PromoteResult struct {
+	RunID        audit.RunID // Staged run whose files were promoted
+	PromoteRunID audit.RunID // Audit run recording the promotion (empty if nothing was moved)
+	Stage        string      // Staging folder name recorded for the run
+	Promoted     int         // Number of files moved out of the staging folder
+	Missing      []string    // Staged paths that no longer exist
+	Errors       []error     // Per-file move failures (promotion continues past them)
+}
+
+// ValidateStage checks that stage can be used as a staging folder name: it must
+// be a single path element and not "." or "..".
+func ValidateStage(stage string) error {
+	if strings.TrimSpace(stage) == "" {
+		return fmt.Errorf("stage folder must not be empty")
+	}
+	if stage == "." || stage == ".." || strings.ContainsAny(stage, `/\`) {
+		return fmt.Errorf("invalid stage folder %q: must be a single folder name", stage)
+	}
+	return nil
+}
+
+// PromoteRun moves the files that the staged run runID left in its staging
+// folders up one level, into the destination they would have had without
+// --stage, and removes the emptied staging folders. The run is read from the
+// audit log in options.AuditConfig, and each move is recorded in a new audit
+// run so the promotion can be reverted with undo. Files already at the
+// promoted location are kept; colliding files are given a duplicate name.
+func (o *Orchestrator) PromoteRun(runID audit.RunID, options *Options) (*PromoteResult, error) {
+	if options == nil || options.AuditConfig == nil {
+		return nil, fmt.Errorf("promote requires an audit log")
+	}
+
+	reader := audit.NewAuditReader(options.AuditConfig.LogDirectory)
+	run, err := reader.GetRunByID(runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Stage == "" {
+		return nil, fmt.Errorf("run %s was not staged", runID)
+	}
+	events, err := reader.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.PathRoot != "" {
+		events = audit.ResolvePaths(events, run.PathRoot)
+	}
+
+	result := &PromoteResult{RunID: runID, Stage: run.Stage}
+
+	var staged []string
+	for _, event := range events {
+		if event.EventType != audit.EventMove && event.EventType != audit.EventDuplicateDetected {
+			continue
+		}
+		if event.Status != audit.StatusSuccess || event.DestinationPath == "" {
+			continue
+		}
+		if filepath.Base(filepath.Dir(event.DestinationPath)) != run.Stage {
+			continue
+		}
+		if _, err := o.fs.Stat(event.DestinationPath); err != nil {
+			result.Missing = append(result.Missing, event.DestinationPath)
+			continue
+		}
+		staged = append(staged, event.DestinationPath)
+	}
+	if len(staged) == 0 {
+		return result, nil
+	}
+
+	auditWriter, err := audit.NewAuditWriter(*options.AuditConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
+	}
+	defer auditWriter.Close()
+
+	appVersion := options.AppVersion
+	if appVersion == "" {
+		appVersion = "unknown"
+	}
+	machineID := options.MachineID
+	if machineID == "" {
+		machineID = getMachineID()
+	}
+
+	promoteRunID, err := auditWriter.StartRunWithMetadata(appVersion, machineID, map[string]string{"promotedRun": string(runID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start audit run: %w", err)
+	}
+	result.PromoteRunID = promoteRunID
+	identityResolver := audit.NewIdentityResolver()
+
+	var auditError error
+	duplicates := 0
+	stageDirs := make(map[string]bool)
+
+	for _, src := range staged {
+		stageDir := filepath.Dir(src)
+		destDir := filepath.Dir(stageDir)
+		stageDirs[stageDir] = true
+
+		identity, err := identityResolver.CaptureIdentity(src)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			if err := auditWriter.RecordError(src, "IDENTITY_CAPTURE_FAILED", err.Error(), "capture_identity"); err != nil {
+				auditError = &AuditWriteError{Err: err}
+				break
+			}
+			continue
+		}
+
+		moved, err := organizer.MoveFileWithFS(o.fs, src, destDir, filepath.Base(src))
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			if err := auditWriter.RecordError(src, "MOVE_FAILED", err.Error(), "promote"); err != nil {
+				auditError = &AuditWriteError{Err: err}
+				break
+			}
+			continue
+		}
+		result.Promoted++
+
+		if moved.IsDuplicate {
+			duplicates++
+			err = auditWriter.RecordDuplicate(src, filepath.Join(destDir, filepath.Base(src)), moved.DestinationPath, audit.ReasonDuplicateRenamed)
+		} else {
+			err = auditWriter.RecordMove(src, moved.DestinationPath, identity.ForMove(src, destDir))
+		}
+		if err != nil {
+			auditError = &AuditWriteError{Err: err}
+			break
+		}
+	}
+
+	for stageDir := range stageDirs {
+		o.removeEmptyDirs(stageDir)
+	}
+
+	runStatus := audit.RunStatusCompleted
+	if auditError != nil {
+		runStatus = audit.RunStatusFailed
+	}
+	auditSummary := audit.RunSummary{
+		TotalFiles: len(staged),
+		Moved:      result.Promoted - duplicates,
+		Duplicates: duplicates,
+		Errors:     len(result.Errors),
+	}
+	if err := auditWriter.EndRun(promoteRunID, runStatus, auditSummary); err != nil && auditError == nil {
+		auditError = fmt.Errorf("failed to end audit run: %w", err)
+	}
+
+	if auditError != nil {
+		return result, auditError
+	}
+	return result, nil
+}
+
+// PromoteFromPath loads the configuration and promotes the staged run runID
+// with PromoteRun.
+func PromoteFromPath(configPath string, runID audit.RunID, options *Options) (*PromoteResult, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewOrchestrator(cfg).PromoteRun(runID, options)
+}
@@ -0,0 +1,153 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// TestStagedRunAndPromote verifies that a staged run moves files into the
+// staging folder and records the staged paths, that promote moves them up one
+// level, and that undoing the promotion puts them back in the staging folder.
+func TestStagedRunAndPromote(t *testing.T) {
+	tempDir := t.TempDir()
+	inboundDir := filepath.Join(tempDir, "inbound")
+	outboundDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(inboundDir, 0755)
+
+	names := []string{"Invoice 2024-01-15 Acme.pdf", "Invoice 2024-03-02 Beta.pdf"}
+	for _, name := range names {
+		os.WriteFile(filepath.Join(inboundDir, name), []byte(name), 0644)
+	}
+	// A file already at the promoted location gets a duplicate name on promotion
+	existing := filepath.Join(outboundDir, "2024 Invoice", names[1])
+	os.MkdirAll(filepath.Dir(existing), 0755)
+	os.WriteFile(existing, []byte("existing"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{inboundDir},
+		PrefixRules:        []config.PrefixRule{{Prefix: "Invoice", OutboundDirectory: outboundDir}},
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	options := &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0-test",
+		MachineID:   "test-machine",
+		Stage:       "batch-1",
+	}
+
+	summary, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 2 {
+		t.Fatalf("Expected 2 files moved, got %d", summary.SuccessCount)
+	}
+
+	stageDir := filepath.Join(outboundDir, "2024 Invoice", "batch-1")
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err != nil {
+			t.Errorf("Expected %s in staging folder: %v", name, err)
+		}
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	run, err := reader.GetRunByID(audit.RunID(summary.RunID))
+	if err != nil {
+		t.Fatalf("Failed to get staged run: %v", err)
+	}
+	if run.Stage != "batch-1" {
+		t.Errorf("Expected run stage batch-1, got %q", run.Stage)
+	}
+	events, err := reader.FilterEvents(run.RunID, audit.EventFilter{EventTypes: []audit.EventType{audit.EventMove}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	for _, event := range events {
+		if filepath.Dir(event.DestinationPath) != stageDir {
+			t.Errorf("Expected staged destination in audit log, got %s", event.DestinationPath)
+		}
+	}
+
+	result, err := PromoteFromPath(configPath, run.RunID, options)
+	if err != nil {
+		t.Fatalf("PromoteFromPath failed: %v", err)
+	}
+	if result.Promoted != 2 || len(result.Missing) != 0 || len(result.Errors) != 0 {
+		t.Fatalf("Expected 2 files promoted, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(outboundDir, "2024 Invoice", names[0])); err != nil {
+		t.Errorf("Expected %s promoted: %v", names[0], err)
+	}
+	if _, err := os.Stat(filepath.Join(outboundDir, "2024 Invoice", "Invoice 2024-03-02 Beta_duplicate.pdf")); err != nil {
+		t.Errorf("Expected colliding file promoted with a duplicate name: %v", err)
+	}
+	if _, err := os.Stat(stageDir); !os.IsNotExist(err) {
+		t.Error("Expected emptied staging folder to be removed")
+	}
+
+	// Promoting again finds nothing left in the staging folder
+	again, err := PromoteFromPath(configPath, run.RunID, options)
+	if err != nil {
+		t.Fatalf("Second promote failed: %v", err)
+	}
+	if again.Promoted != 0 || len(again.Missing) != 2 {
+		t.Errorf("Expected 2 missing staged files on second promote, got %+v", again)
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	undo, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoRun(result.PromoteRunID, nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Restored != 2 {
+		t.Errorf("Expected 2 restored files, got %d: %+v", undo.Restored, undo.FailureDetails)
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(stageDir, name)); err != nil {
+			t.Errorf("Expected %s back in staging folder: %v", name, err)
+		}
+	}
+}
+
+// TestPromoteRejectsUnstagedRun verifies that promote refuses a run that was
+// not staged and that invalid stage names are rejected.
+func TestPromoteRejectsUnstagedRun(t *testing.T) {
+	tempDir := t.TempDir()
+	inboundDir := filepath.Join(tempDir, "inbound")
+	os.MkdirAll(inboundDir, 0755)
+	os.WriteFile(filepath.Join(inboundDir, "Invoice 2024-01-15 Acme.pdf"), []byte("acme"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{inboundDir},
+		PrefixRules:        []config.PrefixRule{{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")}},
+	})
+	options := &Options{AuditConfig: &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}}
+
+	summary, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if _, err := PromoteFromPath(configPath, audit.RunID(summary.RunID), options); err == nil {
+		t.Error("Expected an error promoting an unstaged run")
+	}
+
+	for _, stage := range []string{"", ".", "..", "a/b", `a\b`} {
+		if err := ValidateStage(stage); err == nil {
+			t.Errorf("Expected stage %q to be rejected", stage)
+		}
+	}
+	if err := ValidateStage("batch-1"); err != nil {
+		t.Errorf("Expected batch-1 to be valid: %v", err)
+	}
+}
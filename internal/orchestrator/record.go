@@ -0,0 +1,142 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sorta/internal/audit"
+)
+
+// MovePlan is the JSON document read by ReadMovePlan. It lists moves that
+// were made outside Sorta so they can be recorded in the audit log.
+type MovePlan struct {
+	Moves []PlannedMove `json:"moves"`
+}
+
+// PlannedMove is a single move in a MovePlan.
+type PlannedMove struct {
+	Source      string `json:"source"`      // Where the file was before the move
+	Destination string `json:"destination"` // Where the file is now
+}
+
+// RecordResult reports the outcome of recording moves in the audit log.
+type RecordResult struct {
+	RunID    audit.RunID // Audit run the moves were recorded in
+	Recorded int         // Number of MOVE events written
+	Errors   []error     // Moves that could not be recorded (recording continues past them)
+}
+
+// ReadMovePlan returns the moves listed in the plan file at path. Every move
+// must name both a source and a destination.
+func ReadMovePlan(path string) ([]PlannedMove, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan MovePlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	for i, move := range plan.Moves {
+		if move.Source == "" || move.Destination == "" {
+			return nil, fmt.Errorf("invalid plan %s: move %d needs a source and a destination", path, i+1)
+		}
+	}
+	return plan.Moves, nil
+}
+
+// RecordMoves writes a MOVE event for each of moves into a new audit run
+// without touching any file, so that moves made by hand can be undone like
+// those of an organize run. The identity of each file is captured at its
+// destination; a move whose destination cannot be read is recorded as an
+// error. Relative paths are made absolute against the working directory, so
+// the moves can be undone from anywhere. The audit log is the one in
+// options.AuditConfig.
+func RecordMoves(moves []PlannedMove, options *Options) (*RecordResult, error) {
+	if options == nil || options.AuditConfig == nil {
+		return nil, fmt.Errorf("recording moves requires an audit log")
+	}
+
+	auditWriter, err := audit.NewAuditWriter(*options.AuditConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
+	}
+	defer auditWriter.Close()
+
+	appVersion := options.AppVersion
+	if appVersion == "" {
+		appVersion = "unknown"
+	}
+	machineID := options.MachineID
+	if machineID == "" {
+		machineID = getMachineID()
+	}
+
+	runID, err := auditWriter.StartRunWithMetadata(appVersion, machineID, map[string]string{"recorded": "true"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start audit run: %w", err)
+	}
+	result := &RecordResult{RunID: runID}
+	identityResolver := audit.NewIdentityResolver()
+
+	var auditError error
+	for _, move := range moves {
+		move, err := absoluteMove(move)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			if err := auditWriter.RecordError(move.Source, "INVALID_PATH", err.Error(), "record_move"); err != nil {
+				auditError = &AuditWriteError{Err: err}
+				break
+			}
+			continue
+		}
+		identity, err := identityResolver.CaptureIdentity(move.Destination)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", move.Destination, err))
+			if err := auditWriter.RecordError(move.Source, "IDENTITY_CAPTURE_FAILED", err.Error(), "capture_identity"); err != nil {
+				auditError = &AuditWriteError{Err: err}
+				break
+			}
+			continue
+		}
+		if err := auditWriter.RecordMove(move.Source, move.Destination, identity); err != nil {
+			auditError = &AuditWriteError{Err: err}
+			break
+		}
+		result.Recorded++
+	}
+
+	runStatus := audit.RunStatusCompleted
+	if auditError != nil {
+		runStatus = audit.RunStatusFailed
+	}
+	auditSummary := audit.RunSummary{
+		TotalFiles: len(moves),
+		Moved:      result.Recorded,
+		Errors:     len(result.Errors),
+	}
+	if err := auditWriter.EndRun(runID, runStatus, auditSummary); err != nil && auditError == nil {
+		auditError = fmt.Errorf("failed to end audit run: %w", err)
+	}
+
+	if auditError != nil {
+		return result, auditError
+	}
+	return result, nil
+}
+
+// absoluteMove returns move with its source and destination made absolute.
+func absoluteMove(move PlannedMove) (PlannedMove, error) {
+	source, err := filepath.Abs(move.Source)
+	if err != nil {
+		return move, fmt.Errorf("%s: %w", move.Source, err)
+	}
+	destination, err := filepath.Abs(move.Destination)
+	if err != nil {
+		return move, fmt.Errorf("%s: %w", move.Destination, err)
+	}
+	return PlannedMove{Source: source, Destination: destination}, nil
+}
@@ -0,0 +1,120 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+)
+
+// TestRecordMovesFromPlan verifies that recording a plan writes a MOVE event
+// with the identity captured at each destination without moving any file,
+// that an unreadable destination is recorded as an error, and that the
+// recorded run can be undone.
+func TestRecordMovesFromPlan(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices", "2024 Invoice")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(invoiceDir, 0755)
+
+	moved := filepath.Join(invoiceDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(moved, []byte("acme invoice"), 0644)
+	sum := sha256.Sum256([]byte("acme invoice"))
+
+	planPath := filepath.Join(tempDir, "plan.json")
+	plan := `{"moves": [
+		{"source": "` + filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf") + `", "destination": "` + moved + `"},
+		{"source": "` + filepath.Join(sourceDir, "Invoice 2024-03-16 Gone.pdf") + `", "destination": "` + filepath.Join(invoiceDir, "Invoice 2024-03-16 Gone.pdf") + `"}
+	]}`
+	os.WriteFile(planPath, []byte(plan), 0644)
+
+	moves, err := ReadMovePlan(planPath)
+	if err != nil {
+		t.Fatalf("ReadMovePlan failed: %v", err)
+	}
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	result, err := RecordMoves(moves, &Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("RecordMoves failed: %v", err)
+	}
+	if result.Recorded != 1 || len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 recorded move and 1 error, got %d and %v", result.Recorded, result.Errors)
+	}
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("Expected recorded file to stay at its destination: %v", err)
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	events, err := reader.GetRun(result.RunID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	var moveEvents, errorEvents int
+	for _, event := range events {
+		switch event.EventType {
+		case audit.EventMove:
+			moveEvents++
+			if event.SourcePath != moves[0].Source || event.DestinationPath != moved {
+				t.Errorf("Unexpected MOVE event %s -> %s", event.SourcePath, event.DestinationPath)
+			}
+			if event.FileIdentity == nil || event.FileIdentity.ContentHash != hex.EncodeToString(sum[:]) {
+				t.Errorf("Expected the destination's content hash, got %+v", event.FileIdentity)
+			}
+		case audit.EventError:
+			errorEvents++
+		}
+	}
+	if moveEvents != 1 || errorEvents != 1 {
+		t.Errorf("Expected 1 MOVE and 1 ERROR event, got %d and %d", moveEvents, errorEvents)
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("NewAuditWriter failed: %v", err)
+	}
+	defer writer.Close()
+	undo, err := audit.NewUndoEngine(reader, writer, "test", "test").UndoRun(result.RunID, nil)
+	if err != nil {
+		t.Fatalf("UndoRun failed: %v", err)
+	}
+	if undo.Restored != 1 {
+		t.Errorf("Expected undo to restore 1 file, got %d", undo.Restored)
+	}
+	if _, err := os.Stat(moves[0].Source); err != nil {
+		t.Errorf("Expected undo to move the file back to its source: %v", err)
+	}
+}
+
+// TestRecordMovesMakesPathsAbsolute verifies that relative paths in a plan
+// are recorded as absolute paths against the working directory.
+func TestRecordMovesMakesPathsAbsolute(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	os.MkdirAll(filepath.Join(tempDir, "invoices"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "invoices", "Invoice 2024-03-15 Acme.pdf"), []byte("acme invoice"), 0644)
+	t.Chdir(tempDir)
+
+	auditConfig := audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}
+	moves := []PlannedMove{{Source: "Invoice 2024-03-15 Acme.pdf", Destination: filepath.Join("invoices", "Invoice 2024-03-15 Acme.pdf")}}
+	result, err := RecordMoves(moves, &Options{AuditConfig: &auditConfig})
+	if err != nil || result.Recorded != 1 {
+		t.Fatalf("Expected 1 recorded move, got %+v, %v", result, err)
+	}
+
+	events, err := audit.NewAuditReader(auditConfig.LogDirectory).FilterEvents(result.RunID, audit.EventFilter{EventTypes: []audit.EventType{audit.EventMove}})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Expected 1 MOVE event, got %v, %v", events, err)
+	}
+	wantSource := filepath.Join(tempDir, "Invoice 2024-03-15 Acme.pdf")
+	wantDestination := filepath.Join(tempDir, "invoices", "Invoice 2024-03-15 Acme.pdf")
+	if events[0].SourcePath != wantSource || events[0].DestinationPath != wantDestination {
+		t.Errorf("Expected %s -> %s, got %s -> %s", wantSource, wantDestination, events[0].SourcePath, events[0].DestinationPath)
+	}
+}
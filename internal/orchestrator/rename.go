@@ -0,0 +1,300 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/organizer"
+)
+
+// RelocatedFolder describes one prefix folder moved by a rule rename.
+type RelocatedFolder struct {
+	From  string // Folder path under the old outbound directory
+	To    string // Folder path under the new outbound directory
+	Files int    // Number of files moved out of the folder
+}
+
+// RenameRuleResult reports the outcome of renaming a prefix rule's outbound directory.
+type RenameRuleResult struct {
+	Prefix       string            // Canonical prefix of the updated rule
+	OldDirectory string            // Outbound directory before the change
+	NewDirectory string            // Outbound directory after the change
+	Relocated    bool              // True if existing folders were relocated
+	Folders      []RelocatedFolder // Folders whose files were moved
+	Moved        int               // Total number of files moved
+	Errors       []error           // Per-file move failures (relocation continues past them)
+}
+
+// RenameRule points the rule for prefix at newDir in the orchestrator's
+// configuration and returns the previous outbound directory. Files already
+// organized under the old directory are left in place; see RelocatePrefixFolders.
+func (o *Orchestrator) RenameRule(prefix, newDir string) (string, error) {
+	if newDir == "" {
+		return "", fmt.Errorf("new outbound directory must not be empty")
+	}
+	rule, err := o.findRule(prefix)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(rule.OutboundDirectory) == filepath.Clean(newDir) {
+		return "", fmt.Errorf("prefix %q already uses outbound directory %s", rule.Prefix, newDir)
+	}
+
+	oldDir := rule.OutboundDirectory
+	rule.OutboundDirectory = newDir
+	return oldDir, nil
+}
+
+// RelocatePrefixFolders moves the "<year> <prefix>" folders (and the undated
+// folder, when configured) for prefix from oldDir to newDir. Files are moved one
+// at a time and each move is recorded as a MOVE event in its own audit run, so
+// the relocation can be reverted with undo. Existing files at the destination
+// are kept; colliding files are given a duplicate name.
+func (o *Orchestrator) RelocatePrefixFolders(prefix, oldDir, newDir string, options *Options) (*RenameRuleResult, error) {
+	result := &RenameRuleResult{
+		Prefix:       prefix,
+		OldDirectory: oldDir,
+		NewDirectory: newDir,
+		Relocated:    true,
+		Folders:      []RelocatedFolder{},
+	}
+
+	folders, err := o.prefixFolders(prefix, oldDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditWriter *audit.AuditWriter
+	var runID audit.RunID
+	var identityResolver *audit.IdentityResolver
+
+	if options != nil && options.AuditConfig != nil {
+		auditWriter, err = audit.NewAuditWriter(*options.AuditConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
+		}
+		defer auditWriter.Close()
+
+		appVersion := options.AppVersion
+		if appVersion == "" {
+			appVersion = "unknown"
+		}
+		machineID := options.MachineID
+		if machineID == "" {
+			machineID = getMachineID()
+		}
+
+		runID, err = auditWriter.StartRun(appVersion, machineID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start audit run: %w", err)
+		}
+		identityResolver = audit.NewIdentityResolver()
+	}
+
+	var auditError error
+	total := 0
+
+	for _, name := range folders {
+		from := filepath.Join(oldDir, name)
+		to := filepath.Join(newDir, name)
+
+		files, err := o.listFiles(from)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+		total += len(files)
+
+		folder := RelocatedFolder{From: from, To: to}
+		for _, src := range files {
+			rel, _ := filepath.Rel(from, src)
+			destDir := filepath.Join(to, filepath.Dir(rel))
+
+			var identity *audit.FileIdentity
+			if identityResolver != nil {
+				identity, err = identityResolver.CaptureIdentity(src)
+				if err != nil {
+					result.Errors = append(result.Errors, err)
+					if err := auditWriter.RecordError(src, "IDENTITY_CAPTURE_FAILED", err.Error(), "capture_identity"); err != nil {
+						auditError = &AuditWriteError{Err: err}
+						break
+					}
+					continue
+				}
+			}
+
+			moved, err := organizer.MoveFileWithFS(o.fs, src, destDir, filepath.Base(src))
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+				if auditWriter != nil {
+					if err := auditWriter.RecordError(src, "MOVE_FAILED", err.Error(), "relocate"); err != nil {
+						auditError = &AuditWriteError{Err: err}
+						break
+					}
+				}
+				continue
+			}
+
+			folder.Files++
+			result.Moved++
+
+			if auditWriter != nil {
+				if err := auditWriter.RecordMove(src, moved.DestinationPath, identity.ForMove(src, destDir)); err != nil {
+					auditError = &AuditWriteError{Err: err}
+					break
+				}
+			}
+		}
+
+		if folder.Files > 0 {
+			result.Folders = append(result.Folders, folder)
+		}
+		o.removeEmptyDirs(from)
+
+		if auditError != nil {
+			break
+		}
+	}
+
+	if auditWriter != nil {
+		runStatus := audit.RunStatusCompleted
+		if auditError != nil {
+			runStatus = audit.RunStatusFailed
+		}
+		auditSummary := audit.RunSummary{
+			TotalFiles: total,
+			Moved:      result.Moved,
+			Errors:     len(result.Errors),
+		}
+		if err := auditWriter.EndRun(runID, runStatus, auditSummary); err != nil && auditError == nil {
+			auditError = fmt.Errorf("failed to end audit run: %w", err)
+		}
+	}
+
+	if auditError != nil {
+		return result, auditError
+	}
+	return result, nil
+}
+
+// RenameRuleFromPath loads the configuration, points the rule for prefix at
+// newDir and saves it. When relocate is true, existing folders for the prefix
+// are then moved from the old outbound directory with RelocatePrefixFolders.
+// The configuration is saved before relocating so that a partial relocation
+// never leaves the rule pointing at the old directory.
+func RenameRuleFromPath(configPath, prefix, newDir string, relocate bool, options *Options) (*RenameRuleResult, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	o := NewOrchestrator(cfg)
+	oldDir, err := o.RenameRule(prefix, newDir)
+	if err != nil {
+		return nil, err
+	}
+	canonical := cfg.FindPrefixRule(prefix).Prefix
+
+	if err := config.Save(cfg, configPath); err != nil {
+		return nil, err
+	}
+
+	if !relocate {
+		return &RenameRuleResult{
+			Prefix:       canonical,
+			OldDirectory: oldDir,
+			NewDirectory: newDir,
+			Folders:      []RelocatedFolder{},
+		}, nil
+	}
+	return o.RelocatePrefixFolders(canonical, oldDir, newDir, options)
+}
+
+// prefixFolders returns the names of the folders in dir that were created for
+// prefix: "<year> <prefix>" and, when configured, "<undatedFolder> <prefix>".
+func (o *Orchestrator) prefixFolders(prefix, dir string) ([]string, error) {
+	entries, err := o.fs.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read outbound directory %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		label, folderPrefix, ok := strings.Cut(entry.Name(), " ")
+		if !ok || !strings.EqualFold(folderPrefix, prefix) {
+			continue
+		}
+		if isYearLabel(label) || (o.config.UndatedFolder != "" && label == o.config.UndatedFolder) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isYearLabel reports whether s is a four-digit year.
+func isYearLabel(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// listFiles returns every regular file below dir, in sorted order.
+func (o *Orchestrator) listFiles(dir string) ([]string, error) {
+	entries, err := o.fs.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			nested, err := o.listFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, nested...)
+			continue
+		}
+		if entry.Type().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// removeEmptyDirs removes dir and any subdirectories left empty after relocation.
+// Directories that still contain files are kept.
+func (o *Orchestrator) removeEmptyDirs(dir string) {
+	entries, err := o.fs.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			o.removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		}
+	}
+	if entries, err := o.fs.ReadDir(dir); err == nil && len(entries) == 0 {
+		o.fs.Remove(dir)
+	}
+}
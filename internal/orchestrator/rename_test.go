@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// TestRenameRuleConfigOnly verifies that without relocation only the rule's
+// outbound directory changes and organized files stay where they are.
+func TestRenameRuleConfigOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir := filepath.Join(tempDir, "invoices")
+	newDir := filepath.Join(tempDir, "archive", "invoices")
+
+	existing := filepath.Join(oldDir, "2023 Invoice", "Invoice 2023-05-01 Acme.pdf")
+	os.MkdirAll(filepath.Dir(existing), 0755)
+	os.WriteFile(existing, []byte("acme"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{filepath.Join(tempDir, "source")},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: oldDir},
+			{Prefix: "Receipt", OutboundDirectory: filepath.Join(tempDir, "receipts")},
+		},
+	})
+
+	result, err := RenameRuleFromPath(configPath, "invoice", newDir, false, nil)
+	if err != nil {
+		t.Fatalf("RenameRuleFromPath failed: %v", err)
+	}
+	if result.Prefix != "Invoice" || result.OldDirectory != oldDir || result.Relocated || result.Moved != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if got := cfg.FindPrefixRule("Invoice").OutboundDirectory; got != newDir {
+		t.Errorf("Expected Invoice rule to point at %s, got %s", newDir, got)
+	}
+	if got := cfg.FindPrefixRule("Receipt").OutboundDirectory; got != filepath.Join(tempDir, "receipts") {
+		t.Errorf("Receipt rule should be unchanged, got %s", got)
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("Organized file should not move without relocation: %v", err)
+	}
+
+	if _, err := RenameRuleFromPath(configPath, "Statement", newDir, false, nil); err == nil {
+		t.Error("Expected an error for an unknown prefix")
+	}
+}
+
+// TestRenameRuleRelocatesFolders verifies that relocation moves only the
+// prefix's year folders, records MOVE events, and can be undone.
+func TestRenameRuleRelocatesFolders(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir := filepath.Join(tempDir, "docs")
+	newDir := filepath.Join(tempDir, "archive")
+	auditDir := filepath.Join(tempDir, "audit")
+
+	files := []string{
+		filepath.Join(oldDir, "2023 Invoice", "Invoice 2023-05-01 Acme.pdf"),
+		filepath.Join(oldDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf"),
+		filepath.Join(oldDir, "2024 Invoice", "sub", "Invoice 2024-02-20 Beta.pdf"),
+	}
+	// Folders that belong to other prefixes or are not year folders stay put
+	untouched := []string{
+		filepath.Join(oldDir, "2024 Receipt", "Receipt 2024-03-01 Shop.pdf"),
+		filepath.Join(oldDir, "Invoice drafts", "draft.pdf"),
+	}
+	for _, path := range append(append([]string{}, files...), untouched...) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{filepath.Join(tempDir, "source")},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: oldDir},
+			{Prefix: "Receipt", OutboundDirectory: oldDir},
+		},
+	})
+
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+	options := &Options{
+		AuditConfig: &auditConfig,
+		AppVersion:  "1.0.0-test",
+		MachineID:   "test-machine",
+	}
+
+	result, err := RenameRuleFromPath(configPath, "Invoice", newDir, true, options)
+	if err != nil {
+		t.Fatalf("RenameRuleFromPath failed: %v", err)
+	}
+	if result.Moved != 3 || len(result.Folders) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("Expected 3 files moved from 2 folders, got %+v", result)
+	}
+
+	for _, path := range files {
+		rel, _ := filepath.Rel(oldDir, path)
+		if _, err := os.Stat(filepath.Join(newDir, rel)); err != nil {
+			t.Errorf("Expected %s in new directory: %v", rel, err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved away", path)
+		}
+	}
+	for _, path := range untouched {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to stay in place: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(oldDir, "2024 Invoice")); !os.IsNotExist(err) {
+		t.Error("Expected emptied year folder to be removed")
+	}
+
+	reader := audit.NewAuditReader(auditDir)
+	events, err := reader.FilterAllEvents(audit.EventFilter{EventTypes: []audit.EventType{audit.EventMove}})
+	if err != nil {
+		t.Fatalf("Failed to read audit events: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 MOVE events, got %d", len(events))
+	}
+
+	writer, err := audit.NewAuditWriter(auditConfig)
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+
+	undo, err := audit.NewUndoEngine(reader, writer, "1.0.0", "test-machine").UndoLatest(nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Restored != 3 {
+		t.Errorf("Expected 3 restored files, got %d: %+v", undo.Restored, undo.FailureDetails)
+	}
+	for _, path := range files {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s restored: %v", path, err)
+		}
+	}
+}
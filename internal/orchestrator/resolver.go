@@ -0,0 +1,96 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"path/filepath"
+
+	"sorta/internal/classifier"
+	"sorta/internal/config"
+	"sorta/internal/organizer"
+	"sorta/internal/scanner"
+)
+
+// ParsedFile describes a file matched by a prefix rule, as passed to a
+// DestinationResolver.
+type ParsedFile struct {
+	Path          string // Full source path
+	Name          string // Normalised file name (canonical prefix casing)
+	Prefix        string // Canonical prefix of the matched rule
+	Date          string // YYYY-MM-DD date from the file name (empty when undated)
+	Year          int    // Year of Date (0 when undated)
+	UndatedFolder string // Configured folder label for undated files (empty when dated)
+	RelativeDir   string // Directory of the file below its inbound root (empty at the top level)
+}
+
+// DestinationResolver decides where a file matched by a prefix rule is moved.
+// Resolve returns the full destination path, including the file name. Sorta
+// still creates the directory, renames duplicates, adds the --stage folder
+// and records the move for undo. An error fails the file without moving it.
+type DestinationResolver interface {
+	Resolve(parsed ParsedFile, rule config.PrefixRule) (string, error)
+}
+
+// DefaultDestinationResolver files documents as <outbound>/<year> <prefix>/<name>,
+// the layout used when Options.DestinationResolver is nil. Outbound directory
+// tokens and the undated folder are honoured; preserveSourceSubpath is not,
+// since it is read from the configuration.
+type DefaultDestinationResolver struct{}
+
+// Resolve implements DestinationResolver.
+func (DefaultDestinationResolver) Resolve(parsed ParsedFile, rule config.PrefixRule) (string, error) {
+	classification := &classifier.Classification{
+		Type:               "CLASSIFIED",
+		Prefix:             parsed.Prefix,
+		Date:               parsed.Date,
+		Year:               parsed.Year,
+		NormalisedFilename: parsed.Name,
+		OutboundDirectory:  rule.OutboundDirectory,
+		UndatedFolder:      parsed.UndatedFolder,
+	}
+	file := scanner.FileEntry{Name: parsed.Name, FullPath: parsed.Path, RelativeDir: parsed.RelativeDir}
+	return filepath.Join(organizer.ClassifiedDestinationDir(file, classification, nil), parsed.Name), nil
+}
+
+// classifiedDestination returns the directory a classified file is moved
+// into. Without a resolver in options it is the configured layout; otherwise
+// the resolver's path is used, and classification takes the resolved file
+// name. The stage folder of options is added below either.
+func classifiedDestination(file scanner.FileEntry, classification *classifier.Classification, cfg *config.Configuration, options *Options) (string, error) {
+	resolver := destinationResolver(options)
+	if resolver == nil {
+		return stagedDir(organizer.ClassifiedDestinationDir(file, classification, cfg), options), nil
+	}
+
+	path, err := resolver.Resolve(ParsedFile{
+		Path:          file.FullPath,
+		Name:          classification.NormalisedFilename,
+		Prefix:        classification.Prefix,
+		Date:          classification.Date,
+		Year:          classification.Year,
+		UndatedFolder: classification.UndatedFolder,
+		RelativeDir:   file.RelativeDir,
+	}, config.PrefixRule{Prefix: classification.Prefix, OutboundDirectory: classification.OutboundDirectory})
+	if err != nil {
+		return "", err
+	}
+
+	classification.NormalisedFilename = filepath.Base(path)
+	return stagedDir(filepath.Dir(filepath.Clean(path)), options), nil
+}
+
+// stagedDir returns destDir with the staging folder of options.Stage below it,
+// or destDir itself when the run is not staged.
+func stagedDir(destDir string, options *Options) string {
+	if options == nil || options.Stage == "" {
+		return destDir
+	}
+	return filepath.Join(destDir, options.Stage)
+}
+
+// destinationResolver returns the resolver set in options, or nil.
+func destinationResolver(options *Options) DestinationResolver {
+	if options == nil {
+		return nil
+	}
+	return options.DestinationResolver
+}
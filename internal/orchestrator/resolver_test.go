@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/config"
+)
+
+// letterResolver files documents by the first letter of their prefix.
+type letterResolver struct {
+	root string
+}
+
+func (r letterResolver) Resolve(parsed ParsedFile, rule config.PrefixRule) (string, error) {
+	if parsed.Prefix == "Secret" {
+		return "", errors.New("secret documents have no destination")
+	}
+	return filepath.Join(r.root, parsed.Prefix[:1], parsed.Name), nil
+}
+
+// TestDestinationResolver verifies that a custom resolver decides where
+// matched files go, in dry-run and real mode, that a resolver error fails
+// only that file, and that DefaultDestinationResolver matches the layout
+// used without a resolver.
+func TestDestinationResolver(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	archiveDir := filepath.Join(tempDir, "archive")
+	os.MkdirAll(sourceDir, 0755)
+
+	invoice := filepath.Join(sourceDir, "invoice 2024-03-15 Acme.pdf")
+	receipt := filepath.Join(sourceDir, "Receipt 2023-07-01 Shop.pdf")
+	secret := filepath.Join(sourceDir, "Secret 2024-01-01 Plans.pdf")
+	for _, path := range []string{invoice, receipt, secret} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")},
+			{Prefix: "Receipt", OutboundDirectory: filepath.Join(tempDir, "receipts")},
+			{Prefix: "Secret", OutboundDirectory: filepath.Join(tempDir, "secrets")},
+		},
+	})
+	options := &Options{DestinationResolver: letterResolver{root: archiveDir}}
+	want := map[string]string{
+		invoice: filepath.Join(archiveDir, "I", "Invoice 2024-03-15 Acme.pdf"),
+		receipt: filepath.Join(archiveDir, "R", "Receipt 2023-07-01 Shop.pdf"),
+	}
+
+	dryRun, err := RunDryRunWithOptions(configPath, RunOptions{DryRun: true}, options)
+	if err != nil {
+		t.Fatalf("RunDryRunWithOptions failed: %v", err)
+	}
+	if len(dryRun.Moved) != 2 || len(dryRun.Errors) != 1 {
+		t.Fatalf("Expected 2 planned moves and 1 error, got %+v", dryRun)
+	}
+	for _, op := range dryRun.Moved {
+		if op.Destination != want[op.Source] {
+			t.Errorf("Expected %s to be planned for %s, got %s", op.Source, want[op.Source], op.Destination)
+		}
+	}
+
+	summary, err := RunWithOptions(configPath, options)
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.SuccessCount != 2 || summary.ErrorCount != 1 {
+		t.Errorf("Expected 2 moved and 1 failed, got %d moved, %d failed", summary.SuccessCount, summary.ErrorCount)
+	}
+	for source, dest := range want {
+		if _, err := os.Stat(dest); err != nil {
+			t.Errorf("Expected %s at %s: %v", source, dest, err)
+		}
+	}
+	for _, result := range summary.Results {
+		var moveErr *MoveError
+		if result.SourcePath == secret && (!errors.As(result.Error, &moveErr) || moveErr.Op != OpResolveDestination) {
+			t.Errorf("Expected a resolve error for %s, got %v", secret, result.Error)
+		}
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Errorf("Expected file without a destination to stay in place: %v", err)
+	}
+
+	// The default resolver reproduces the configured layout
+	path, err := DefaultDestinationResolver{}.Resolve(
+		ParsedFile{Name: "Invoice 2024-03-15 Acme.pdf", Prefix: "Invoice", Date: "2024-03-15", Year: 2024},
+		config.PrefixRule{Prefix: "Invoice", OutboundDirectory: "/invoices"},
+	)
+	if err != nil || path != filepath.Join("/invoices", "2024 Invoice", "Invoice 2024-03-15 Acme.pdf") {
+		t.Errorf("Unexpected default destination %q (%v)", path, err)
+	}
+}
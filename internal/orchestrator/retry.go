@@ -0,0 +1,61 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RetryFile is the JSON document written by WriteRetryFile. It lists the
+// source paths of files that failed in a run, or that a run stopped before
+// reaching, so they can be organized again with "run --retry-from".
+type RetryFile struct {
+	RunID string   `json:"runId,omitempty"` // Run the failures were recorded in
+	Files []string `json:"files"`
+}
+
+// FailedPaths returns the source paths of the files that ended in an error.
+func (s *Summary) FailedPaths() []string {
+	paths := make([]string, 0)
+	for _, result := range s.Results {
+		if !result.Success && result.EventType == "ERROR" {
+			paths = append(paths, result.SourcePath)
+		}
+	}
+	return paths
+}
+
+// WriteRetryFile writes the files that failed in summary, followed by those
+// the run never reached, to path, replacing any previous contents. A complete
+// run without failures writes an empty list.
+func WriteRetryFile(path string, summary *Summary) error {
+	retry := RetryFile{RunID: summary.RunID, Files: append(summary.FailedPaths(), summary.Unprocessed...)}
+	data, err := json.MarshalIndent(retry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry file: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write retry file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write retry file: %w", err)
+	}
+	return nil
+}
+
+// ReadRetryFile returns the source paths listed in the retry file at path.
+func ReadRetryFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry file: %w", err)
+	}
+	var retry RetryFile
+	if err := json.Unmarshal(data, &retry); err != nil {
+		return nil, fmt.Errorf("invalid retry file %s: %w", path, err)
+	}
+	return retry.Files, nil
+}
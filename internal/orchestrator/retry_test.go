@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// TestRetryFileRoundTrip verifies that a retry file lists only the files that
+// failed, that retrying from it processes exactly those files, and that it is
+// rewritten with the files that still fail.
+func TestRetryFileRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	retryPath := filepath.Join(tempDir, "failed.json")
+	os.MkdirAll(sourceDir, 0755)
+
+	lockedA := filepath.Join(sourceDir, "Invoice 2024-03-15 LockedA.pdf")
+	lockedB := filepath.Join(sourceDir, "Invoice 2024-03-16 LockedB.pdf")
+	passing := filepath.Join(sourceDir, "Invoice 2024-04-20 Pass.pdf")
+	for _, path := range []string{lockedA, lockedB, passing} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:      &auditConfig,
+		FailurePredicate: GlobFailures("*Locked*.pdf", errors.New("file is locked")),
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if err := WriteRetryFile(retryPath, summary); err != nil {
+		t.Fatalf("WriteRetryFile failed: %v", err)
+	}
+
+	paths, err := ReadRetryFile(retryPath)
+	if err != nil {
+		t.Fatalf("ReadRetryFile failed: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != lockedA || paths[1] != lockedB {
+		t.Fatalf("Expected retry file to list %s and %s, got %v", lockedA, lockedB, paths)
+	}
+
+	// Put a new file in the inbound directory; a retry must not pick it up
+	newFile := filepath.Join(sourceDir, "Invoice 2024-05-01 New.pdf")
+	os.WriteFile(newFile, []byte("new"), 0644)
+
+	// One of the files is still locked
+	summary, err = RunFilesWithOptions(configPath, paths, &Options{
+		AuditConfig:      &auditConfig,
+		FailurePredicate: GlobFailures("*LockedB.pdf", errors.New("file is locked")),
+	})
+	if err != nil {
+		t.Fatalf("RunFilesWithOptions failed: %v", err)
+	}
+	if summary.TotalFiles != 2 || summary.SuccessCount != 1 || summary.ErrorCount != 1 {
+		t.Fatalf("Expected 2 retried files with 1 moved and 1 failed, got total=%d moved=%d errors=%d",
+			summary.TotalFiles, summary.SuccessCount, summary.ErrorCount)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", filepath.Base(lockedA))); err != nil {
+		t.Errorf("Expected retried file to be moved: %v", err)
+	}
+	if _, err := os.Stat(newFile); err != nil {
+		t.Errorf("Expected file not in the retry file to stay in place: %v", err)
+	}
+
+	if err := WriteRetryFile(retryPath, summary); err != nil {
+		t.Fatalf("WriteRetryFile failed: %v", err)
+	}
+	paths, err = ReadRetryFile(retryPath)
+	if err != nil {
+		t.Fatalf("ReadRetryFile failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != lockedB {
+		t.Errorf("Expected retry file to list only %s, got %v", lockedB, paths)
+	}
+}
+
+// TestRetryFileListsUnreachedFiles verifies that a run stopped by fail-fast
+// lists the failed file and every file it never reached in the retry file.
+func TestRetryFileListsUnreachedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	retryPath := filepath.Join(tempDir, "failed.json")
+	os.MkdirAll(sourceDir, 0755)
+
+	locked := filepath.Join(sourceDir, "Invoice 2024-03-15 Locked.pdf")
+	second := filepath.Join(sourceDir, "Invoice 2024-03-16 Second.pdf")
+	third := filepath.Join(sourceDir, "Invoice 2024-04-20 Third.pdf")
+	for _, path := range []string{locked, second, third} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	summary, err := RunWithOptions(configPath, &Options{
+		FailFast:         true,
+		FailurePredicate: GlobFailures("*Locked.pdf", errors.New("file is locked")),
+	})
+	var failFast *FailFastError
+	if !errors.As(err, &failFast) {
+		t.Fatalf("Expected a FailFastError, got %v", err)
+	}
+	if err := WriteRetryFile(retryPath, summary); err != nil {
+		t.Fatalf("WriteRetryFile failed: %v", err)
+	}
+
+	paths, err := ReadRetryFile(retryPath)
+	if err != nil {
+		t.Fatalf("ReadRetryFile failed: %v", err)
+	}
+	if len(paths) != 3 || paths[0] != locked || paths[1] != second || paths[2] != third {
+		t.Errorf("Expected retry file to list %s, %s and %s, got %v", locked, second, third, paths)
+	}
+}
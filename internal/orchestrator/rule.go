@@ -0,0 +1,115 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sorta/internal/config"
+	"sorta/internal/matcher"
+	"sorta/internal/scanner"
+)
+
+// RuleDetails describes a single prefix rule and the files it has organized so far.
+type RuleDetails struct {
+	Prefix            string   `json:"prefix"`
+	OutboundDirectory string   `json:"outboundDirectory"`
+	UndatedFolder     string   `json:"undatedFolder,omitempty"` // Configured undated folder label, if any
+	Folders           []string `json:"folders"`                 // Existing prefix folders under the outbound directory
+	OrganizedFiles    int      `json:"organizedFiles"`          // Files found in those folders
+}
+
+// ShowRule returns the rule for prefix (case-insensitive) together with a count
+// of the files already organized into its folders under the outbound directory.
+func (o *Orchestrator) ShowRule(prefix string) (*RuleDetails, error) {
+	rule, err := o.findRule(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &RuleDetails{
+		Prefix:            rule.Prefix,
+		OutboundDirectory: rule.OutboundDirectory,
+		UndatedFolder:     o.config.UndatedFolder,
+		Folders:           []string{},
+	}
+
+	folders, err := o.prefixFolders(rule.Prefix, rule.OutboundDirectory)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range folders {
+		files, err := o.listFiles(filepath.Join(rule.OutboundDirectory, name))
+		if err != nil {
+			continue
+		}
+		details.Folders = append(details.Folders, name)
+		details.OrganizedFiles += len(files)
+	}
+
+	return details, nil
+}
+
+// RuleCount is the number of pending files in the inbound directories that a
+// prefix rule matches.
+type RuleCount struct {
+	Prefix            string `json:"prefix"`
+	OutboundDirectory string `json:"outboundDirectory"`
+	PendingFiles      int    `json:"pendingFiles"`
+}
+
+// RuleCounts scans the inbound directories like Status and returns, for every
+// prefix rule in configuration order, how many of the files found there start
+// with its prefix. Files are only matched, not classified or moved, so a file
+// is counted even if its date turns out to be invalid.
+func (o *Orchestrator) RuleCounts() ([]RuleCount, error) {
+	counts := make([]RuleCount, len(o.config.PrefixRules))
+	index := make(map[string]int, len(o.config.PrefixRules))
+	for i, rule := range o.config.PrefixRules {
+		counts[i] = RuleCount{Prefix: rule.Prefix, OutboundDirectory: rule.OutboundDirectory}
+		index[strings.ToLower(rule.Prefix)] = i
+	}
+
+	scanOpts := scanner.DefaultScanOptions()
+	scanOpts.MaxDepth = o.config.GetScanDepth()
+	scanOpts.SymlinkPolicy = o.config.GetSymlinkPolicy()
+	scanOpts.FS = o.fs
+	matchOpts := matcher.MatchOptions{AllowExtraSpaces: o.config.NormalizeSpaces}
+
+	for _, inboundDir := range o.config.InboundDirectories {
+		if _, err := o.fs.Stat(inboundDir); os.IsNotExist(err) {
+			continue
+		}
+		files, err := scanner.ScanWithOptions(inboundDir, scanOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", inboundDir, err)
+		}
+		for _, file := range files {
+			match := matcher.MatchWithOptions(file.Name, o.config.PrefixRules, matchOpts)
+			if match.Matched {
+				counts[index[strings.ToLower(match.Rule.Prefix)]].PendingFiles++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// ShowRuleFromPath loads the configuration and returns the details of the rule for prefix.
+func ShowRuleFromPath(configPath, prefix string) (*RuleDetails, error) {
+	o, err := NewOrchestratorFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return o.ShowRule(prefix)
+}
+
+// findRule returns the configured rule for prefix or an error naming the prefix.
+func (o *Orchestrator) findRule(prefix string) (*config.PrefixRule, error) {
+	rule := o.config.FindPrefixRule(prefix)
+	if rule == nil {
+		return nil, fmt.Errorf("no rule configured for prefix %q", prefix)
+	}
+	return rule, nil
+}
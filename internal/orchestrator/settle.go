@@ -0,0 +1,73 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"os"
+	"time"
+
+	"sorta/internal/filesystem"
+	"sorta/internal/scanner"
+)
+
+// settleCheck spots files that are still being written when a run starts.
+// Every candidate is stat'ed once before the first file is processed and
+// again, at least Settle later, just before it is processed; a file whose
+// size or modification time differs between the two is still being written.
+// Taking the first stats up front means the run waits for Settle at most once
+// rather than once per file.
+type settleCheck struct {
+	fsys    filesystem.FS
+	settle  time.Duration
+	sleep   func(time.Duration)
+	taken   time.Time
+	settled bool // Settle has passed since the first stats were taken
+	first   map[string]os.FileInfo
+}
+
+// newSettleCheck takes the first stats of files when options.Settle is set,
+// or returns nil, which finds no file still being written.
+func newSettleCheck(fsys filesystem.FS, files []scanner.FileEntry, options *Options) *settleCheck {
+	if options == nil || options.Settle <= 0 {
+		return nil
+	}
+	check := &settleCheck{
+		fsys:   fsys,
+		settle: options.Settle,
+		sleep:  options.settleSleep,
+		taken:  time.Now(),
+		first:  make(map[string]os.FileInfo, len(files)),
+	}
+	if check.sleep == nil {
+		check.sleep = time.Sleep
+	}
+	for _, file := range files {
+		if info, err := fsys.Stat(file.FullPath); err == nil {
+			check.first[file.FullPath] = info
+		}
+	}
+	return check
+}
+
+// stillWriting reports whether file changed size or modification time since
+// its first stat, waiting until Settle has passed since then if needed. Files
+// that cannot be stat'ed are not reported, so the run deals with them as usual.
+func (c *settleCheck) stillWriting(file scanner.FileEntry) bool {
+	if c == nil {
+		return false
+	}
+	first, ok := c.first[file.FullPath]
+	if !ok {
+		return false
+	}
+	if !c.settled {
+		if wait := c.settle - time.Since(c.taken); wait > 0 {
+			c.sleep(wait)
+		}
+		c.settled = true
+	}
+	info, err := c.fsys.Stat(file.FullPath)
+	if err != nil {
+		return false
+	}
+	return info.Size() != first.Size() || !info.ModTime().Equal(first.ModTime())
+}
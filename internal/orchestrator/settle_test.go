@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// TestSettleSkipsGrowingFiles verifies that with Settle a file that grows
+// between its two stats is skipped as STILL_WRITING and left in place, while
+// a file that does not change is organized, and that the run waits for the
+// settle interval only once.
+func TestSettleSkipsGrowingFiles(t *testing.T) {
+	fsys := filesystem.NewMemFS()
+	fsys.MkdirAll("/inbound", 0755)
+	growing := "/inbound/Invoice 2024-03-15 Download.pdf"
+	fsys.WriteFile(growing, []byte("part"), 0644)
+	fsys.WriteFile("/inbound/Invoice 2024-03-16 Acme.pdf", []byte("invoice"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/inbound"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+	}
+
+	// The download carries on while the run waits for the files to settle
+	var waits []time.Duration
+	sleep := func(d time.Duration) {
+		waits = append(waits, d)
+		fsys.WriteFile(growing, []byte("part and the rest"), 0644)
+	}
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(&Options{Settle: 2 * time.Second, settleSleep: sleep})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(waits) != 1 || waits[0] <= 0 || waits[0] > 2*time.Second {
+		t.Errorf("Expected a single wait of at most 2s, got %v", waits)
+	}
+	if summary.SuccessCount != 1 || summary.SkippedCount != 1 {
+		t.Fatalf("Expected 1 file moved and 1 skipped, got %+v", summary.Results)
+	}
+	for _, result := range summary.Results {
+		if result.SourcePath == growing && result.ReasonCode != string(audit.ReasonStillWriting) {
+			t.Errorf("Expected the growing file to be skipped as STILL_WRITING, got %+v", result)
+		}
+	}
+	if _, err := fsys.Stat(growing); err != nil {
+		t.Errorf("Expected the growing file to stay in place: %v", err)
+	}
+	if _, err := fsys.Stat("/archive/2024 Invoice/Invoice 2024-03-16 Acme.pdf"); err != nil {
+		t.Errorf("Expected the settled file to be organized: %v", err)
+	}
+
+	// Without Settle the growing file is organized like any other
+	fsys.WriteFile(growing, []byte("complete"), 0644)
+	summary, err = NewOrchestratorWithFS(cfg, fsys).Run(&Options{settleSleep: sleep})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || len(waits) != 1 {
+		t.Errorf("Expected the file moved without waiting, got %+v after %v", summary.Results, waits)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"os"
 	"path/filepath"
 
-	"sorta/internal/classifier"
 	"sorta/internal/config"
+	"sorta/internal/filesystem"
 	"sorta/internal/organizer"
 	"sorta/internal/scanner"
 )
@@ -24,6 +24,7 @@ type StatusResult struct {
 type InboundStatus struct {
 	Directory     string              // The inbound directory path
 	ByDestination map[string][]string // destination -> list of file paths
+	Reasons       map[string]string   // file path -> reason code, for files that would be routed to review
 	Total         int                 // Total files in this inbound directory
 }
 
@@ -42,6 +43,7 @@ func (o *Orchestrator) Status() (*StatusResult, error) {
 	scanOpts := scanner.DefaultScanOptions()
 	scanOpts.MaxDepth = o.config.GetScanDepth()
 	scanOpts.SymlinkPolicy = o.config.GetSymlinkPolicy()
+	scanOpts.FS = o.fs
 
 	// Scan all configured inbound directories
 	// Requirements: 2.1 - Scan all configured inbound directories
@@ -49,11 +51,12 @@ func (o *Orchestrator) Status() (*StatusResult, error) {
 		inboundStatus := &InboundStatus{
 			Directory:     inboundDir,
 			ByDestination: make(map[string][]string),
+			Reasons:       make(map[string]string),
 			Total:         0,
 		}
 
 		// Check if directory exists before scanning
-		if _, err := os.Stat(inboundDir); os.IsNotExist(err) {
+		if _, err := o.fs.Stat(inboundDir); os.IsNotExist(err) {
 			// Skip non-existent directories but still include them in results
 			// with empty status (consistent with error handling approach)
 			result.ByInbound[inboundDir] = inboundStatus
@@ -71,11 +74,14 @@ func (o *Orchestrator) Status() (*StatusResult, error) {
 		// Classify each file and group by destination
 		// Requirements: 2.2 - Group files by destination (matched prefix or for-review)
 		for _, file := range files {
-			destination := classifyFileDestination(file, o.config)
+			destination, reason := classifyFileDestination(file, o.config)
 			inboundStatus.ByDestination[destination] = append(
 				inboundStatus.ByDestination[destination],
 				file.FullPath,
 			)
+			if reason != "" {
+				inboundStatus.Reasons[file.FullPath] = reason
+			}
 			inboundStatus.Total++
 		}
 
@@ -88,33 +94,42 @@ func (o *Orchestrator) Status() (*StatusResult, error) {
 }
 
 // classifyFileDestination determines the destination for a file without moving it.
-// Returns the destination directory path (either organized location or for-review).
+// Returns the destination directory path (either organized location or for-review)
+// and, for files routed to review, the reason code.
 // Requirements: 2.2 - Classify files to determine destination
-func classifyFileDestination(file scanner.FileEntry, cfg *config.Configuration) string {
+func classifyFileDestination(file scanner.FileEntry, cfg *config.Configuration) (string, string) {
 	// Classify the file using existing classifier
-	classification := classifier.Classify(file.Name, cfg.PrefixRules)
+	classification := classifyFile(file.Name, cfg)
 
 	if classification.IsUnclassified() {
 		// File would go to for-review directory
-		return organizer.GetForReviewPath(filepath.Dir(file.FullPath))
+		return organizer.GetForReviewPath(filepath.Dir(file.FullPath)), string(classification.Reason)
 	}
 
 	// File is classified - would be moved to organized location
-	prefix := extractPrefixFromNormalisedFilename(classification.NormalisedFilename)
-	subfolder := fmt.Sprintf("%d %s", classification.Year, prefix)
-	return filepath.Join(classification.OutboundDirectory, subfolder)
+	return organizer.ClassifiedDestinationDir(file, classification, cfg), ""
 }
 
-// Orchestrator wraps configuration for status operations.
-// This provides a cleaner API for the Status method.
+// Orchestrator runs Sorta operations against a loaded configuration.
+// All scanning and file moves go through its filesystem, so callers embedding
+// Sorta (or tests) can supply an in-memory implementation.
 type Orchestrator struct {
 	config *config.Configuration
+	fs     filesystem.FS
 }
 
-// NewOrchestrator creates a new Orchestrator with the given configuration.
+// NewOrchestrator creates a new Orchestrator with the given configuration
+// operating on the real filesystem.
 func NewOrchestrator(cfg *config.Configuration) *Orchestrator {
+	return NewOrchestratorWithFS(cfg, filesystem.Default)
+}
+
+// NewOrchestratorWithFS creates a new Orchestrator that performs all file
+// operations through fsys. A nil fsys uses the real filesystem.
+func NewOrchestratorWithFS(cfg *config.Configuration, fsys filesystem.FS) *Orchestrator {
 	return &Orchestrator{
 		config: cfg,
+		fs:     filesystem.OrDefault(fsys),
 	}
 }
 
@@ -124,7 +139,7 @@ func NewOrchestratorFromPath(configPath string) (*Orchestrator, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
-	return &Orchestrator{config: cfg}, nil
+	return NewOrchestrator(cfg), nil
 }
 
 // StatusFromPath is a convenience function that creates an orchestrator and runs Status.
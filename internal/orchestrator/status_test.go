@@ -609,3 +609,46 @@ func TestStatusWithMultipleYears(t *testing.T) {
 		t.Errorf("Expected total of 2, got %d", inboundStatus.Total)
 	}
 }
+
+// TestRuleCounts verifies that RuleCounts reports the pending files of each
+// rule in configuration order, including rules that match nothing.
+func TestRuleCounts(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+	for _, name := range []string{
+		"Invoice 2024-03-15 Doc1.pdf",
+		"invoice 2024-04-20 Doc2.pdf",
+		"Invoice 2024-02-30 Bad date.pdf",
+		"Receipt 2024-05-10 Doc3.pdf",
+		"notes.txt",
+	} {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{sourceDir, filepath.Join(tempDir, "missing")},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")},
+			{Prefix: "Statement", OutboundDirectory: filepath.Join(tempDir, "statements")},
+			{Prefix: "Receipt", OutboundDirectory: filepath.Join(tempDir, "receipts")},
+		},
+	}
+
+	counts, err := NewOrchestrator(cfg).RuleCounts()
+	if err != nil {
+		t.Fatalf("RuleCounts failed: %v", err)
+	}
+	want := map[string]int{"Invoice": 3, "Statement": 0, "Receipt": 1}
+	if len(counts) != len(cfg.PrefixRules) {
+		t.Fatalf("Expected %d counts, got %+v", len(cfg.PrefixRules), counts)
+	}
+	for i, count := range counts {
+		if count.Prefix != cfg.PrefixRules[i].Prefix || count.PendingFiles != want[count.Prefix] {
+			t.Errorf("Expected %s with %d pending files at position %d, got %+v", cfg.PrefixRules[i].Prefix, want[cfg.PrefixRules[i].Prefix], i, count)
+		}
+	}
+	if entries, _ := os.ReadDir(sourceDir); len(entries) != 5 {
+		t.Errorf("Expected RuleCounts to leave the inbound directory alone, got %d entries", len(entries))
+	}
+}
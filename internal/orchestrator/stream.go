@@ -0,0 +1,43 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import "context"
+
+// RunStream organizes files like RunWithOptions, but sends each file's Result
+// on the returned channel as soon as the file has been processed, so callers
+// can report progress without a ProgressCallback. Both channels are closed
+// when the run ends; the error channel first receives the run's error, if
+// any. ctx replaces options.Context: cancelling it stops the run before the
+// next file, ends its audit run as INTERRUPTED and reports ctx.Err().
+//
+// The caller must keep receiving from the results channel (or cancel ctx)
+// until it is closed, or the run blocks.
+func RunStream(ctx context.Context, configPath string, options *Options) (<-chan Result, <-chan error) {
+	results := make(chan Result)
+	errs := make(chan error, 1)
+
+	var streamOptions Options
+	if options != nil {
+		streamOptions = *options
+	}
+	streamOptions.Context = ctx
+	callback := streamOptions.ProgressCallback
+	streamOptions.ProgressCallback = func(current, total int, file string, result *Result) {
+		if callback != nil {
+			callback(current, total, file, result)
+		}
+		select {
+		case results <- *result:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+		if _, err := RunWithOptions(configPath, &streamOptions); err != nil {
+			errs <- err
+		}
+	}()
+	return results, errs
+}
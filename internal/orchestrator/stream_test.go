@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+)
+
+// TestRunStreamSendsOneResultPerFile verifies that RunStream sends a Result
+// for every file and closes both channels without an error.
+func TestRunStreamSendsOneResultPerFile(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+
+	files := map[string]bool{}
+	for i := 1; i <= 3; i++ {
+		path := filepath.Join(sourceDir, fmt.Sprintf("Invoice 2024-03-1%d Doc.pdf", i))
+		os.WriteFile(path, []byte(path), 0644)
+		files[path] = true
+	}
+	unmatched := filepath.Join(sourceDir, "notes.txt")
+	os.WriteFile(unmatched, []byte("notes"), 0644)
+	files[unmatched] = true
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	results, errs := RunStream(context.Background(), configPath, nil)
+	seen := map[string]bool{}
+	for result := range results {
+		if !files[result.SourcePath] || seen[result.SourcePath] {
+			t.Errorf("Unexpected result for %s", result.SourcePath)
+		}
+		seen[result.SourcePath] = true
+	}
+	if err, ok := <-errs; ok || err != nil {
+		t.Errorf("Expected the error channel to close without an error, got %v", err)
+	}
+	if len(seen) != len(files) {
+		t.Errorf("Expected %d results, got %d", len(files), len(seen))
+	}
+}
+
+// TestRunStreamStopsWhenCancelled verifies that cancelling the context stops
+// the run before the remaining files and marks its audit run as interrupted.
+func TestRunStreamStopsWhenCancelled(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	const fileCount = 10
+	for i := 0; i < fileCount; i++ {
+		path := filepath.Join(sourceDir, fmt.Sprintf("Invoice 2024-03-%02d Doc.pdf", i+1))
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, errs := RunStream(ctx, configPath, &Options{AuditConfig: &auditConfig})
+
+	received := 0
+	for range results {
+		received++
+		cancel()
+	}
+	if received == 0 || received >= fileCount {
+		t.Errorf("Expected the run to stop after the first file, got %d results", received)
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	run, err := audit.NewAuditReader(auditDir).GetLatestRun()
+	if err != nil {
+		t.Fatalf("GetLatestRun failed: %v", err)
+	}
+	if run.Status != audit.RunStatusInterrupted {
+		t.Errorf("Expected run status %s, got %s", audit.RunStatusInterrupted, run.Status)
+	}
+}
+
+// TestRunWithCancelledContext verifies that a run given an already cancelled
+// Context processes no files and reports them all as unprocessed.
+func TestRunWithCancelledContext(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(sourceDir, fmt.Sprintf("Invoice 2024-03-%02d Doc.pdf", i+1))
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := RunWithOptions(configPath, &Options{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(summary.Results) != 0 || len(summary.Unprocessed) != 3 {
+		t.Errorf("Expected no results and 3 unprocessed files, got %d and %d", len(summary.Results), len(summary.Unprocessed))
+	}
+}
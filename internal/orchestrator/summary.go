@@ -2,6 +2,9 @@
 package orchestrator
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"time"
 )
 
@@ -14,6 +17,10 @@ type RunSummary struct {
 	Errors    int            // Errors encountered
 	Duration  time.Duration  // Total processing time
 	ByPrefix  map[string]int // Per-prefix counts (only populated in verbose mode)
+
+	PermissionDeniedDirs int // Directories skipped because they could not be read (included in Errors)
+
+	AbortedAtErrors int // Error threshold the run was aborted at (0 = ran to the end)
 }
 
 // GenerateSummary creates a summary from a run result.
@@ -33,6 +40,8 @@ func GenerateSummary(result *RunResult, duration time.Duration, verbose bool) *R
 		Skipped:   len(result.Skipped),
 		Errors:    len(result.Errors),
 		Duration:  duration,
+
+		PermissionDeniedDirs: countPermissionDenied(result.Errors),
 	}
 
 	// Only populate ByPrefix in verbose mode
@@ -48,3 +57,47 @@ func GenerateSummary(result *RunResult, duration time.Duration, verbose bool) *R
 
 	return summary
 }
+
+// SummaryFile is the JSON form of a run summary that WriteSummaryFile writes
+// for dashboards and CI.
+type SummaryFile struct {
+	RunID      string    `json:"runId,omitempty"` // Audit run ID (empty when auditing is disabled)
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	DurationMs int64     `json:"durationMs"`
+	Moved      int       `json:"moved"`
+	ForReview  int       `json:"forReview"`
+	Skipped    int       `json:"skipped"`
+	Errors     int       `json:"errors"`
+	HasErrors  bool      `json:"hasErrors"`
+}
+
+// WriteSummaryFile writes summary of the run runID, which started at start,
+// to path as JSON, replacing any previous contents.
+func WriteSummaryFile(path, runID string, start time.Time, summary *RunSummary) error {
+	file := SummaryFile{
+		RunID:      runID,
+		StartTime:  start.UTC(),
+		EndTime:    start.Add(summary.Duration).UTC(),
+		DurationMs: summary.Duration.Milliseconds(),
+		Moved:      summary.Moved,
+		ForReview:  summary.ForReview,
+		Skipped:    summary.Skipped,
+		Errors:     summary.Errors,
+		HasErrors:  summary.Errors > 0,
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary file: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,14 @@
 package orchestrator
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
 )
 
 // TestGenerateSummary_NilResult tests that GenerateSummary handles nil result gracefully.
@@ -300,3 +306,59 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+// TestWriteSummaryFile tests that the summary file of a run holds the run ID,
+// timestamps and counts of its RunSummary.
+func TestWriteSummaryFile(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+	os.WriteFile(filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf"), []byte("invoice"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "notes.txt"), []byte("notes"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")},
+		},
+	})
+
+	start := time.Now()
+	summary, err := RunWithOptions(configPath, &Options{AuditConfig: &audit.AuditConfig{LogDirectory: filepath.Join(tempDir, "audit")}})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	runSummary := GenerateSummary(ConvertSummaryToRunResult(summary), time.Since(start), false)
+
+	path := filepath.Join(tempDir, "summary.json")
+	if err := WriteSummaryFile(path, summary.RunID, start, runSummary); err != nil {
+		t.Fatalf("WriteSummaryFile failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read summary file: %v", err)
+	}
+	var file SummaryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Invalid summary file: %v", err)
+	}
+
+	expected := SummaryFile{
+		RunID:      summary.RunID,
+		StartTime:  start.UTC(),
+		EndTime:    start.Add(runSummary.Duration).UTC(),
+		DurationMs: runSummary.Duration.Milliseconds(),
+		Moved:      1,
+		ForReview:  1,
+		Skipped:    0,
+		Errors:     0,
+		HasErrors:  false,
+	}
+	if !file.StartTime.Equal(expected.StartTime) || !file.EndTime.Equal(expected.EndTime) {
+		t.Errorf("Expected run from %v to %v, got %v to %v", expected.StartTime, expected.EndTime, file.StartTime, file.EndTime)
+	}
+	file.StartTime, file.EndTime = expected.StartTime, expected.EndTime
+	if file != expected {
+		t.Errorf("Expected summary file %+v, got %+v", expected, file)
+	}
+}
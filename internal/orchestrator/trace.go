@@ -0,0 +1,136 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+	"sorta/internal/matcher"
+	"sorta/internal/organizer"
+	"sorta/internal/scanner"
+)
+
+// Tracer writes one line for every decision a run makes about a file:
+// the candidates found, each prefix rule tried, the rule selected, the
+// destination collision check and the outcome. Lines are a timestamp followed
+// by key=value pairs, e.g.
+//
+//	2024-01-15T10:30:00.000Z event=pattern file="Invoice 2024-01-15 Acme.pdf" rule=Invoice matched=true
+//
+// A nil *Tracer traces nothing.
+type Tracer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewTracer returns a Tracer writing to w.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{w: w, now: time.Now}
+}
+
+// Trace writes an event line with the given key/value pairs. Values with
+// blanks, quotes or "=" are quoted.
+func (t *Tracer) Trace(event string, keyValues ...any) {
+	if t == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(t.now().UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	b.WriteString(" event=")
+	b.WriteString(event)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", keyValues[i], traceValue(fmt.Sprint(keyValues[i+1])))
+	}
+	b.WriteString("\n")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	io.WriteString(t.w, b.String())
+}
+
+// traceValue returns s quoted if it is empty or holds blanks, quotes or "=".
+func traceValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// traceCandidates traces the files a run is about to process.
+func (t *Tracer) traceCandidates(files []scanner.FileEntry) {
+	if t == nil {
+		return
+	}
+	t.Trace("candidates", "count", len(files))
+	for _, file := range files {
+		t.Trace("candidate", "path", file.FullPath, "relativeDir", file.RelativeDir)
+	}
+}
+
+// traceDecisions traces how file is matched against each prefix rule, which
+// rule is selected and, for a classified file, whether its destination is
+// already taken.
+func (t *Tracer) traceDecisions(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration, options *Options) {
+	if t == nil {
+		return
+	}
+	for _, rule := range cfg.PrefixRules {
+		match := matcher.MatchWithOptions(file.Name, []config.PrefixRule{rule}, matcher.MatchOptions{
+			AllowExtraSpaces: cfg.NormalizeSpaces,
+		})
+		t.Trace("pattern", "file", file.Name, "rule", rule.Prefix, "matched", match.Matched)
+	}
+
+	classification := classifyFile(file.Name, cfg)
+	if classification.IsUnclassified() {
+		t.Trace("rule", "file", file.Name, "selected", "none", "reason", classification.Reason)
+		return
+	}
+	t.Trace("rule", "file", file.Name, "selected", classification.Prefix, "date", classification.Date, "outbound", classification.OutboundDirectory)
+
+	destDir, err := classifiedDestination(file, classification, cfg, options)
+	if err != nil {
+		t.Trace("destination", "file", file.Name, "error", err)
+		return
+	}
+	dest := filepath.Join(destDir, classification.NormalisedFilename)
+	t.Trace("collision", "file", file.Name, "dest", dest, "exists", organizer.FileExistsWithFS(fsys, dest))
+}
+
+// traceResult traces what was done with a file.
+func (t *Tracer) traceResult(result Result) {
+	if t == nil {
+		return
+	}
+	keyValues := []any{"path", result.SourcePath, "outcome", result.EventType, "success", result.Success,
+		"reason", result.ReasonCode, "dest", result.DestinationPath}
+	if result.Error != nil {
+		keyValues = append(keyValues, "error", result.Error)
+	}
+	t.Trace("result", keyValues...)
+}
+
+// traceOperation traces what a dry run would do with a file.
+func (t *Tracer) traceOperation(op classifiedOperation) {
+	if t == nil {
+		return
+	}
+	t.Trace("result", "path", op.operation.Source, "outcome", op.category, "reason", op.operation.Reason,
+		"dest", op.operation.Destination)
+}
+
+// tracer returns the tracer set in options, or nil.
+func tracer(options *Options) *Tracer {
+	if options == nil {
+		return nil
+	}
+	return options.Tracer
+}
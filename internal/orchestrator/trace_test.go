@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// TestTracerRecordsDecisions verifies that the trace of a run lists the
+// candidates, the rules tried and selected, the collision check and the
+// outcome of a matched and an unmatched file.
+func TestTracerRecordsDecisions(t *testing.T) {
+	fsys := filesystem.NewMemFS()
+	fsys.MkdirAll("/inbound", 0755)
+	fsys.WriteFile("/inbound/Invoice 2024-03-15 Acme.pdf", []byte("invoice"), 0644)
+	fsys.WriteFile("/inbound/notes.txt", []byte("notes"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{"/inbound"},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Receipt", OutboundDirectory: "/receipts"},
+			{Prefix: "Invoice", OutboundDirectory: "/archive"},
+		},
+	}
+
+	var buf bytes.Buffer
+	tracer := NewTracer(&buf)
+	tracer.now = func() time.Time { return time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) }
+	if _, err := NewOrchestratorWithFS(cfg, fsys).Run(&Options{Tracer: tracer}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	trace := buf.String()
+
+	for _, want := range []string{
+		"2024-01-15T10:30:00.000Z event=candidates count=2\n",
+		`event=candidate path="/inbound/Invoice 2024-03-15 Acme.pdf"`,
+		`event=pattern file="Invoice 2024-03-15 Acme.pdf" rule=Receipt matched=false`,
+		`event=pattern file="Invoice 2024-03-15 Acme.pdf" rule=Invoice matched=true`,
+		`event=rule file="Invoice 2024-03-15 Acme.pdf" selected=Invoice date=2024-03-15 outbound=/archive`,
+		`event=collision file="Invoice 2024-03-15 Acme.pdf" dest="/archive/2024 Invoice/Invoice 2024-03-15 Acme.pdf" exists=false`,
+		`event=result path="/inbound/Invoice 2024-03-15 Acme.pdf" outcome=MOVE success=true`,
+		`event=pattern file=notes.txt rule=Invoice matched=false`,
+		`event=rule file=notes.txt selected=none reason=NO_PREFIX_MATCH`,
+		`event=result path=/inbound/notes.txt outcome=ROUTE_TO_REVIEW success=true`,
+	} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("Expected trace to contain %q, got:\n%s", want, trace)
+		}
+	}
+	if strings.Contains(trace, "event=collision file=notes.txt") {
+		t.Errorf("Expected no collision check for the unmatched file, got:\n%s", trace)
+	}
+}
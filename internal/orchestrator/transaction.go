@@ -0,0 +1,424 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sorta/internal/audit"
+	"sorta/internal/filesystem"
+	"sorta/internal/organizer"
+)
+
+// stagingDirName is the directory below the audit log directory that holds
+// the staging area of each transactional run.
+const stagingDirName = "staging"
+
+// stagingManifestName is the name of the manifest in a run's staging area.
+const stagingManifestName = "manifest.jsonl"
+
+// stagingLockName is the name of the file in a run's staging area that the
+// run holds a lock on until its commit, so the run is not resumed while it
+// is still staging files.
+const stagingLockName = "lock"
+
+// StagingManifest lists the files a transactional run has staged and where
+// each of them goes. On disk it is a journal with one StagedEntry per line:
+// each entry is appended before its file is staged, so a run that dies
+// before its commit can be finished with ResumeStagedRuns.
+type StagingManifest struct {
+	RunID   string
+	Entries []StagedEntry
+}
+
+// StagedEntry is a single staged file in a StagingManifest.
+type StagedEntry struct {
+	Source      string      `json:"source,omitempty"`  // Where the file was moved from (empty for copies)
+	Staged      string      `json:"staged"`            // Where the file waits in the staging area
+	Destination string      `json:"destination"`       // Where the commit moves the file
+	DirMode     os.FileMode `json:"dirMode,omitempty"` // Exact mode of destination directories the commit creates (0 = default, subject to the umask)
+	Removed     bool        `json:"removed,omitempty"` // In the journal, marks the file at Staged as removed again
+}
+
+// ResumeResult reports the outcome of ResumeStagedRuns.
+type ResumeResult struct {
+	Runs   []string // IDs of the runs whose staged files were committed
+	Moved  int      // Number of files moved to their destinations
+	Errors []error  // Files that could not be moved; they stay staged
+}
+
+// stagingFS wraps an FS so that files moved or copied to their destination
+// land in a per-run staging area instead. To the organizer a staged file
+// looks as if it were at its destination already, so duplicate names are
+// chosen as usual. Destination directories are only created by commit,
+// which moves the staged files to their destinations.
+type stagingFS struct {
+	filesystem.FS
+	logDir   string
+	dir      string
+	manifest *StagingManifest
+	pending  map[string]int         // Destination -> index into manifest.Entries
+	dirs     map[string]os.FileMode // Destination directory -> exact mode it is created with
+	slots    int                    // Number of slots handed out in the staging area
+	unlock   func() error           // Releases the lock on the staging area, once taken
+}
+
+// newStagingFS returns a stagingFS for runID with its staging area below
+// logDir.
+func newStagingFS(fsys filesystem.FS, logDir string, runID audit.RunID) *stagingFS {
+	return &stagingFS{
+		FS:       fsys,
+		logDir:   logDir,
+		dir:      filepath.Join(logDir, stagingDirName, string(runID)),
+		manifest: &StagingManifest{RunID: string(runID), Entries: make([]StagedEntry, 0)},
+		pending:  make(map[string]int),
+		dirs:     make(map[string]os.FileMode),
+	}
+}
+
+// Rename moves oldpath into the staging area and records newpath as its
+// destination. The entry is in the manifest before the file is moved.
+func (s *stagingFS) Rename(oldpath, newpath string) error {
+	entry, err := s.stage(StagedEntry{Source: oldpath, Destination: newpath})
+	if err != nil {
+		return err
+	}
+	if err := s.FS.Rename(oldpath, entry.Staged); err != nil {
+		s.FS.Remove(filepath.Dir(entry.Staged))
+		return err
+	}
+	s.add(entry)
+	return nil
+}
+
+// WriteFile writes the contents of a copied file into the staging area and
+// records name as its destination. The entry is in the manifest before the
+// file is written.
+func (s *stagingFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	entry, err := s.stage(StagedEntry{Destination: name})
+	if err != nil {
+		return err
+	}
+	if err := s.FS.WriteFile(entry.Staged, data, perm); err != nil {
+		s.FS.Remove(filepath.Dir(entry.Staged))
+		return err
+	}
+	s.add(entry)
+	return nil
+}
+
+// MkdirAll creates directories in the staging area. Any other directory is
+// a destination directory, which commit creates.
+func (s *stagingFS) MkdirAll(path string, perm os.FileMode) error {
+	if s.inStagingArea(path) {
+		return s.FS.MkdirAll(path, perm)
+	}
+	return nil
+}
+
+// Chmod changes the mode of a file or directory when the wrapped FS can. A
+// destination directory that does not exist yet has mode noted instead, and
+// commit creates it with exactly that mode.
+func (s *stagingFS) Chmod(name string, mode os.FileMode) error {
+	name = s.resolve(name)
+	if !s.inStagingArea(name) {
+		if _, err := s.FS.Stat(name); os.IsNotExist(err) {
+			s.dirs[filepath.Clean(name)] = mode
+			return nil
+		}
+	}
+	chmoder, ok := s.FS.(filesystem.Chmoder)
+	if !ok {
+		return nil
+	}
+	return chmoder.Chmod(name, mode)
+}
+
+// inStagingArea reports whether path is the staging area or below it.
+func (s *stagingFS) inStagingArea(path string) bool {
+	path = filepath.Clean(path)
+	return path == s.dir || strings.HasPrefix(path, s.dir+string(filepath.Separator))
+}
+
+// Remove removes the staged file of a pending destination, or name itself.
+func (s *stagingFS) Remove(name string) error {
+	i, ok := s.pending[name]
+	if !ok {
+		return s.FS.Remove(name)
+	}
+	staged := s.manifest.Entries[i].Staged
+	if err := s.FS.Remove(staged); err != nil {
+		return err
+	}
+	s.FS.Remove(filepath.Dir(staged))
+	delete(s.pending, name)
+	s.manifest.Entries[i].Destination = ""
+	return s.record(StagedEntry{Staged: staged, Removed: true})
+}
+
+// Stat reports on the staged file of a pending destination, or name itself.
+func (s *stagingFS) Stat(name string) (os.FileInfo, error) {
+	return s.FS.Stat(s.resolve(name))
+}
+
+// Lstat reports on the staged file of a pending destination, or name itself.
+func (s *stagingFS) Lstat(name string) (os.FileInfo, error) {
+	return s.FS.Lstat(s.resolve(name))
+}
+
+// ReadFile reads the staged file of a pending destination, or name itself.
+func (s *stagingFS) ReadFile(name string) ([]byte, error) {
+	return s.FS.ReadFile(s.resolve(name))
+}
+
+// CopyXattrs copies the extended attributes of src to the staged file of a
+// pending destination dst when the wrapped FS can copy them.
+func (s *stagingFS) CopyXattrs(src, dst string) error {
+	copier, ok := s.FS.(filesystem.XattrCopier)
+	if !ok {
+		return nil
+	}
+	return copier.CopyXattrs(s.resolve(src), s.resolve(dst))
+}
+
+// resolve returns the staged path for a pending destination, name otherwise.
+func (s *stagingFS) resolve(name string) string {
+	if i, ok := s.pending[name]; ok {
+		return s.manifest.Entries[i].Staged
+	}
+	return name
+}
+
+// stage creates a new slot in the staging area for entry's destination and
+// appends entry, with its staged path filled in, to the manifest. Each file
+// gets its own folder so files with the same name do not collide; a slot is
+// never handed out twice, so a manifest entry whose file was not staged
+// after all names no file.
+func (s *stagingFS) stage(entry StagedEntry) (StagedEntry, error) {
+	if err := s.lock(); err != nil {
+		return entry, err
+	}
+	s.slots++
+	slot := filepath.Join(s.dir, strconv.Itoa(s.slots))
+	if err := s.FS.MkdirAll(slot, 0755); err != nil {
+		return entry, fmt.Errorf("failed to create staging area: %w", err)
+	}
+	entry.Staged = filepath.Join(slot, filepath.Base(entry.Destination))
+	entry.DirMode = s.dirs[filepath.Dir(entry.Destination)]
+	return entry, s.record(entry)
+}
+
+// lock creates the staging area and takes the lock on it the first time a
+// file is staged. The lock lives on the real filesystem, like the audit log.
+func (s *stagingFS) lock() error {
+	if s.unlock != nil {
+		return nil
+	}
+	if err := s.FS.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create staging area: %w", err)
+	}
+	unlock, err := filesystem.Lock(filepath.Join(s.dir, stagingLockName))
+	if err != nil {
+		return fmt.Errorf("failed to lock staging area: %w", err)
+	}
+	s.unlock = unlock
+	return nil
+}
+
+// release releases the lock on the staging area, if it was taken, so the
+// run can be resumed.
+func (s *stagingFS) release() {
+	if s.unlock != nil {
+		s.unlock()
+		s.unlock = nil
+	}
+}
+
+// record appends entry to the manifest in the staging area.
+func (s *stagingFS) record(entry StagedEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal staging manifest entry: %w", err)
+	}
+	if err := filesystem.AppendFile(s.FS, filepath.Join(s.dir, stagingManifestName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write staging manifest: %w", err)
+	}
+	return nil
+}
+
+// add records a staged entry as pending.
+func (s *stagingFS) add(entry StagedEntry) {
+	s.pending[entry.Destination] = len(s.manifest.Entries)
+	s.manifest.Entries = append(s.manifest.Entries, entry)
+}
+
+// staged returns the manifest without the entries whose file was removed
+// again.
+func (s *stagingFS) staged() *StagingManifest {
+	manifest := &StagingManifest{RunID: s.manifest.RunID, Entries: make([]StagedEntry, 0, len(s.pending))}
+	for _, entry := range s.manifest.Entries {
+		if entry.Destination != "" {
+			manifest.Entries = append(manifest.Entries, entry)
+		}
+	}
+	return manifest
+}
+
+// commit moves every staged file to its destination and removes the staging
+// area. It holds the audit log directory lock, so a concurrent
+// ResumeStagedRuns does not move the same files. See commitStaged.
+func (s *stagingFS) commit() (int, []error) {
+	if s.unlock == nil {
+		return 0, nil
+	}
+	unlock, err := audit.LockLogDirectory(s.logDir)
+	if err != nil {
+		return 0, []error{err}
+	}
+	defer unlock()
+	s.release()
+	return commitStaged(s.FS, s.dir, s.staged())
+}
+
+// commitStaged moves the staged files of manifest to their destinations and
+// removes the staging area dir once all of them are in place. Files that are
+// no longer staged were committed before and are left alone. Files that
+// cannot be moved stay staged, together with the manifest, so the commit can
+// be tried again. Missing destination directories are created with the mode
+// recorded for them.
+func commitStaged(fsys filesystem.FS, dir string, manifest *StagingManifest) (int, []error) {
+	moved := 0
+	var errs []error
+	for _, entry := range manifest.Entries {
+		if _, err := fsys.Stat(entry.Staged); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := fsys.Stat(entry.Destination); err == nil {
+			errs = append(errs, &MoveError{Path: entry.Staged, Op: OpCommitStaged, Err: fmt.Errorf("%s already exists", entry.Destination)})
+			continue
+		}
+		destDir, name := filepath.Split(entry.Destination)
+		if entry.DirMode != 0 {
+			if err := organizer.MkdirAllWithFS(fsys, destDir, entry.DirMode); err != nil {
+				errs = append(errs, &MoveError{Path: entry.Staged, Op: OpCommitStaged, Err: err})
+				continue
+			}
+		}
+		// The staging area may be on another volume, so the organizer's
+		// copy fallback is used for the final move
+		if _, err := organizer.MoveFileWithFS(fsys, entry.Staged, destDir, name); err != nil {
+			errs = append(errs, &MoveError{Path: entry.Staged, Op: OpCommitStaged, Err: err})
+			continue
+		}
+		moved++
+		fsys.Remove(filepath.Dir(entry.Staged))
+	}
+	if len(errs) > 0 {
+		return moved, errs
+	}
+
+	fsys.Remove(filepath.Join(dir, stagingManifestName))
+	fsys.Remove(filepath.Join(dir, stagingLockName))
+	fsys.Remove(dir)
+	fsys.Remove(filepath.Dir(dir))
+	return moved, nil
+}
+
+// readStagingManifest reads the manifest journal in the staging area dir.
+// Entries whose file was removed again are left out. A last line cut short
+// by a crash while it was appended is ignored.
+func readStagingManifest(fsys filesystem.FS, dir string) (*StagingManifest, error) {
+	data, err := fsys.ReadFile(filepath.Join(dir, stagingManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging manifest: %w", err)
+	}
+
+	var entries []StagedEntry
+	index := make(map[string]int) // Staged -> index into entries
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry StagedEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("invalid staging manifest in %s: line %d: %w", dir, i+1, err)
+		}
+		if entry.Removed {
+			if j, ok := index[entry.Staged]; ok {
+				entries[j].Destination = ""
+			}
+			continue
+		}
+		index[entry.Staged] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	manifest := &StagingManifest{RunID: filepath.Base(dir), Entries: make([]StagedEntry, 0, len(entries))}
+	for _, entry := range entries {
+		if entry.Destination != "" {
+			manifest.Entries = append(manifest.Entries, entry)
+		}
+	}
+	return manifest, nil
+}
+
+// ResumeStagedRuns finishes transactional runs that stopped before their
+// commit: the files in the staging area of each such run below the audit log
+// in options.AuditConfig are moved to the destinations in its manifest. It
+// holds the audit log directory lock throughout and leaves alone runs that
+// are still staging files.
+func ResumeStagedRuns(options *Options) (*ResumeResult, error) {
+	if options == nil || options.AuditConfig == nil {
+		return nil, fmt.Errorf("resuming staged runs requires an audit log")
+	}
+	fsys := filesystem.Default
+
+	unlock, err := audit.LockLogDirectory(options.AuditConfig.LogDirectory)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	root := filepath.Join(options.AuditConfig.LogDirectory, stagingDirName)
+	entries, err := fsys.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ResumeResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging area: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	result := &ResumeResult{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if locked, _ := filesystem.IsLocked(filepath.Join(dir, stagingLockName)); locked {
+			continue
+		}
+		manifest, err := readStagingManifest(fsys, dir)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+
+		moved, errs := commitStaged(fsys, dir, manifest)
+		result.Runs = append(result.Runs, manifest.RunID)
+		result.Moved += moved
+		result.Errors = append(result.Errors, errs...)
+	}
+	return result, nil
+}
@@ -0,0 +1,187 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// TestTransactionalStagingResume verifies that a transactional run that dies
+// after staging its files leaves them in the staging area, that resuming
+// moves them to their destinations, and that a run that is not interrupted
+// commits by itself.
+func TestTransactionalStagingResume(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+
+	invoice := filepath.Join(sourceDir, "Invoice 2024-03-15 Acme.pdf")
+	other := filepath.Join(sourceDir, "notes.txt")
+	for _, path := range []string{invoice, other} {
+		os.WriteFile(path, []byte(path), 0644)
+	}
+	// An existing file makes the second invoice a duplicate
+	os.MkdirAll(filepath.Join(invoiceDir, "2024 Invoice"), 0755)
+	existing := filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-03-16 Shop.pdf")
+	os.WriteFile(existing, []byte("existing"), 0644)
+	duplicate := filepath.Join(sourceDir, "Invoice 2024-03-16 Shop.pdf")
+	os.WriteFile(duplicate, []byte("new"), 0644)
+
+	configPath := writeTestConfig(t, tempDir, config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+		},
+	})
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+
+	summary, err := RunWithOptions(configPath, &Options{
+		AuditConfig:          &auditConfig,
+		TransactionalStaging: true,
+		stopBeforeCommit:     true,
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	want := map[string]string{
+		invoice:   filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-03-15 Acme.pdf"),
+		duplicate: filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-03-16 Shop_duplicate.pdf"),
+		other:     filepath.Join(sourceDir, "for-review", "notes.txt"),
+	}
+	for _, result := range summary.Results {
+		if result.DestinationPath != want[result.SourcePath] {
+			t.Errorf("Expected %s to be bound for %s, got %s", result.SourcePath, want[result.SourcePath], result.DestinationPath)
+		}
+	}
+	for source, dest := range want {
+		if _, err := os.Stat(source); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved out of the inbound directory", source)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be at its destination before the commit", dest)
+		}
+	}
+	stagingDir := filepath.Join(auditDir, stagingDirName, summary.RunID)
+	if _, err := os.Stat(filepath.Join(stagingDir, stagingManifestName)); err != nil {
+		t.Fatalf("Expected a staging manifest: %v", err)
+	}
+
+	result, err := ResumeStagedRuns(&Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("ResumeStagedRuns failed: %v", err)
+	}
+	if result.Moved != 3 || len(result.Errors) != 0 || len(result.Runs) != 1 || result.Runs[0] != summary.RunID {
+		t.Fatalf("Expected 3 files of run %s moved, got %+v", summary.RunID, result)
+	}
+	contents := map[string]string{invoice: invoice, duplicate: "new", other: other}
+	for source, dest := range want {
+		if data, err := os.ReadFile(dest); err != nil || string(data) != contents[source] {
+			t.Errorf("Expected %s at %s, got %q (%v)", source, dest, data, err)
+		}
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "existing" {
+		t.Errorf("Expected the existing file to be kept, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(auditDir, stagingDirName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the staging area to be removed after resuming")
+	}
+
+	// A run that is not interrupted commits by itself
+	next := filepath.Join(sourceDir, "Invoice 2024-04-01 Next.pdf")
+	os.WriteFile(next, []byte("next"), 0644)
+	if _, err := RunWithOptions(configPath, &Options{AuditConfig: &auditConfig, TransactionalStaging: true}); err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-04-01 Next.pdf")); err != nil {
+		t.Errorf("Expected the file to be committed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(auditDir, stagingDirName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the staging area to be removed after the commit")
+	}
+}
+
+// TestStagingManifestJournal verifies that staged files are appended to the
+// manifest journal, that removed files and a last line cut short are left
+// out when it is read back, that destination directories are only created
+// by the commit, and that a run still holding its staging area is not
+// resumed.
+func TestStagingManifestJournal(t *testing.T) {
+	tempDir := t.TempDir()
+	auditDir := filepath.Join(tempDir, "audit")
+	destDir := filepath.Join(tempDir, "invoices", "2024 Invoice")
+	source := filepath.Join(tempDir, "Invoice 2024-03-15 Acme.pdf")
+	os.WriteFile(source, []byte("invoice"), 0644)
+	auditConfig := audit.AuditConfig{LogDirectory: auditDir}
+
+	s := newStagingFS(filesystem.OS{}, auditDir, "run-1")
+	if err := s.MkdirAll(destDir, 0700); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := s.Chmod(destDir, 0700); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	if _, err := os.Stat(destDir); !os.IsNotExist(err) {
+		t.Fatalf("Expected %s not to be created before the commit", destDir)
+	}
+	dest := filepath.Join(destDir, "Invoice 2024-03-15 Acme.pdf")
+	if err := s.Rename(source, dest); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	copied := filepath.Join(destDir, "copy.pdf")
+	if err := s.WriteFile(copied, []byte("copy"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := s.Remove(copied); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	journal := filepath.Join(auditDir, stagingDirName, "run-1", stagingManifestName)
+	file, err := os.OpenFile(journal, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Expected a staging manifest: %v", err)
+	}
+	file.WriteString(`{"staged":`)
+	file.Close()
+
+	manifest, err := readStagingManifest(filesystem.OS{}, filepath.Dir(journal))
+	if err != nil {
+		t.Fatalf("readStagingManifest failed: %v", err)
+	}
+	if manifest.RunID != "run-1" || len(manifest.Entries) != 1 {
+		t.Fatalf("Expected one entry of run-1, got %+v", manifest)
+	}
+	if entry := manifest.Entries[0]; entry.Source != source || entry.Destination != dest || entry.DirMode != 0700 {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+
+	// The run still holds its staging area
+	result, err := ResumeStagedRuns(&Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("ResumeStagedRuns failed: %v", err)
+	}
+	if result.Moved != 0 || len(result.Runs) != 0 {
+		t.Fatalf("Expected a run in progress to be left alone, got %+v", result)
+	}
+
+	s.release()
+	result, err = ResumeStagedRuns(&Options{AuditConfig: &auditConfig})
+	if err != nil {
+		t.Fatalf("ResumeStagedRuns failed: %v", err)
+	}
+	if result.Moved != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Expected 1 file moved, got %+v", result)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "invoice" {
+		t.Errorf("Expected the invoice at %s, got %q (%v)", dest, data, err)
+	}
+	if info, err := os.Stat(destDir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected %s to be created with mode 0700, got %v (%v)", destDir, info, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package orchestrator coordinates the file organization workflow for Sorta.
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"sorta/internal/filesystem"
+)
+
+// ErrorPostMoveVerifyFailed is the error type recorded when a file copied to
+// its destination differs from the source's contents.
+const ErrorPostMoveVerifyFailed = "POST_MOVE_VERIFY_FAILED"
+
+// verifyError reports that the file written to Path does not hold the
+// contents it was written with.
+type verifyError struct {
+	Path string
+	Err  error // Error reading the file back (nil = contents differ)
+}
+
+func (e *verifyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("failed to read back %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("content of %s does not match the source", e.Path)
+}
+
+func (e *verifyError) Unwrap() error {
+	return e.Err
+}
+
+// verifyingFS wraps an FS so that every file written through it is read back
+// and compared with the data written. A file that differs is removed and the
+// write fails with a *verifyError. Moves to another volume copy the file with
+// WriteFile before removing the source, so a bad copy fails the move and the
+// source is kept. A rename keeps the file itself and is not checked.
+type verifyingFS struct {
+	filesystem.FS
+}
+
+// WriteFile writes data to name through the wrapped FS and checks that name
+// then holds exactly data.
+func (f verifyingFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if err := f.FS.WriteFile(name, data, perm); err != nil {
+		return err
+	}
+	written, err := f.FS.ReadFile(name)
+	if err == nil && bytes.Equal(written, data) {
+		return nil
+	}
+	f.FS.Remove(name)
+	return &verifyError{Path: name, Err: err}
+}
+
+// FreeSpace reports the free space of the wrapped FS, if it can tell.
+func (f verifyingFS) FreeSpace(path string) (uint64, error) {
+	reporter, ok := f.FS.(filesystem.SpaceReporter)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+	return reporter.FreeSpace(path)
+}
+
+// SameVolume asks the wrapped FS whether a and b are on the same volume, if
+// it can tell.
+func (f verifyingFS) SameVolume(a, b string) (bool, error) {
+	reporter, ok := f.FS.(filesystem.VolumeReporter)
+	if !ok {
+		return false, errors.ErrUnsupported
+	}
+	return reporter.SameVolume(a, b)
+}
+
+// CopyXattrs copies extended attributes when the wrapped FS can copy them.
+func (f verifyingFS) CopyXattrs(src, dst string) error {
+	copier, ok := f.FS.(filesystem.XattrCopier)
+	if !ok {
+		return nil
+	}
+	return copier.CopyXattrs(src, dst)
+}
+
+// Chmod changes the mode of name when the wrapped FS needs it set apart.
+func (f verifyingFS) Chmod(name string, mode os.FileMode) error {
+	chmoder, ok := f.FS.(filesystem.Chmoder)
+	if !ok {
+		return nil
+	}
+	return chmoder.Chmod(name, mode)
+}
+
+// withVerification returns fsys wrapped in a verifyingFS when
+// options.VerifyAfterMove is set, or fsys itself otherwise.
+func withVerification(fsys filesystem.FS, options *Options) filesystem.FS {
+	if options == nil || !options.VerifyAfterMove {
+		return fsys
+	}
+	return verifyingFS{FS: fsys}
+}
+
+// failureType returns the error type to record for a move or copy that
+// failed with err: POST_MOVE_VERIFY_FAILED if the copy did not match its
+// source, or fallback otherwise.
+func failureType(err error, fallback string) string {
+	var verifyErr *verifyError
+	if errors.As(err, &verifyErr) {
+		return ErrorPostMoveVerifyFailed
+	}
+	return fallback
+}
@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"sorta/internal/audit"
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// corruptingFS wraps an FS so that the file named corrupt cannot be renamed,
+// as on a move to another volume, and its copy is written with other
+// contents, like a copy to unreliable storage.
+type corruptingFS struct {
+	filesystem.FS
+	corrupt string
+}
+
+func (f corruptingFS) Rename(oldpath, newpath string) error {
+	if filepath.Base(oldpath) == f.corrupt {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	return f.FS.Rename(oldpath, newpath)
+}
+
+func (f corruptingFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	if filepath.Base(name) == f.corrupt {
+		data = []byte("corrupted")
+	}
+	return f.FS.WriteFile(name, data, perm)
+}
+
+// TestVerifyAfterMoveKeepsSourceOfCorruptedCopy verifies that a file whose
+// copy differs from it is recorded as POST_MOVE_VERIFY_FAILED, its source is
+// kept and the copy removed, while other files are moved as usual, and that
+// undo leaves the kept source alone.
+func TestVerifyAfterMoveKeepsSourceOfCorruptedCopy(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	targetDir := filepath.Join(tempDir, "target")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+	bad := filepath.Join(sourceDir, "Invoice 2024-03-15 Bad.pdf")
+	os.WriteFile(bad, []byte("bad"), 0644)
+	os.WriteFile(filepath.Join(sourceDir, "Invoice 2024-03-16 Good.pdf"), []byte("good"), 0644)
+
+	cfg := &config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: targetDir},
+		},
+	}
+	fsys := corruptingFS{FS: filesystem.Default, corrupt: filepath.Base(bad)}
+
+	summary, err := NewOrchestratorWithFS(cfg, fsys).Run(&Options{
+		VerifyAfterMove: true,
+		AuditConfig:     &audit.AuditConfig{LogDirectory: auditDir},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.SuccessCount != 1 || summary.ErrorCount != 1 {
+		t.Fatalf("Expected 1 move and 1 error, got %+v", summary.Results)
+	}
+	for _, result := range summary.Results {
+		var verifyErr *verifyError
+		if !result.Success && !errors.As(result.Error, &verifyErr) {
+			t.Errorf("Expected a verification error, got %v", result.Error)
+		}
+	}
+
+	// The source of the corrupted copy is untouched; the good file is organized
+	if data, err := os.ReadFile(bad); err != nil || string(data) != "bad" {
+		t.Errorf("Expected the source kept as it was, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "2024 Invoice", filepath.Base(bad))); !os.IsNotExist(err) {
+		t.Errorf("Expected the corrupted copy removed, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(targetDir, "2024 Invoice", "Invoice 2024-03-16 Good.pdf")); err != nil || string(data) != "good" {
+		t.Errorf("Expected the good file organized, got %q, %v", data, err)
+	}
+
+	events, err := audit.NewAuditReader(auditDir).FilterEvents(audit.RunID(summary.RunID), audit.EventFilter{EventTypes: []audit.EventType{audit.EventError}})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	if len(events) != 1 || events[0].ErrorDetails.ErrorType != ErrorPostMoveVerifyFailed || events[0].SourcePath != bad {
+		t.Errorf("Expected one %s error for %s, got %+v", ErrorPostMoveVerifyFailed, bad, events)
+	}
+
+	// Undo restores the good file and has nothing to do for the bad one
+	writer, err := audit.NewAuditWriter(audit.AuditConfig{LogDirectory: auditDir})
+	if err != nil {
+		t.Fatalf("Failed to create audit writer: %v", err)
+	}
+	defer writer.Close()
+	undo, err := audit.NewUndoEngine(audit.NewAuditReader(auditDir), writer, "1.0.0", "test-machine").UndoRun(audit.RunID(summary.RunID), nil)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.Failed != 0 {
+		t.Errorf("Expected undo without failures, got %+v", undo.FailureDetails)
+	}
+	if data, err := os.ReadFile(bad); err != nil || string(data) != "bad" {
+		t.Errorf("Expected the source still in place after undo, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "Invoice 2024-03-16 Good.pdf")); err != nil {
+		t.Errorf("Expected the good file restored: %v", err)
+	}
+}
@@ -2,11 +2,15 @@
 package organizer
 
 import (
-	"os"
+	"bytes"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
 )
 
 // duplicatePattern matches filenames with _duplicate or _duplicate_N suffix before extension
@@ -14,10 +18,63 @@ var duplicatePattern = regexp.MustCompile(`^(.+)_duplicate(?:_(\d+))?(\.[^.]+)?$
 
 // FileExists checks if a file exists at the given path.
 func FileExists(path string) bool {
-	_, err := os.Stat(path)
+	return FileExistsWithFS(filesystem.Default, path)
+}
+
+// FileExistsWithFS checks if a file exists at the given path in fsys.
+func FileExistsWithFS(fsys filesystem.FS, path string) bool {
+	_, err := fsys.Stat(path)
 	return err == nil
 }
 
+// SameContentWithFS reports whether the files at a and b in fsys both exist
+// and have identical contents.
+func SameContentWithFS(fsys filesystem.FS, a, b string) bool {
+	infoA, err := fsys.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := fsys.Stat(b)
+	if err != nil || infoA.Size() != infoB.Size() {
+		return false
+	}
+	dataA, err := fsys.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	dataB, err := fsys.ReadFile(b)
+	return err == nil && bytes.Equal(dataA, dataB)
+}
+
+// FindSameContentWithFS returns the path of a file directly in dir of fsys
+// with the same contents as src, or "" if there is none. Only files of the
+// same size are read, so files renamed as duplicates under any template are
+// found without reading the whole directory.
+func FindSameContentWithFS(fsys filesystem.FS, src, dir string) string {
+	srcInfo, err := fsys.Stat(src)
+	if err != nil {
+		return ""
+	}
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() != srcInfo.Size() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if SameContentWithFS(fsys, src, path) {
+			return path
+		}
+	}
+	return ""
+}
+
 // GenerateDuplicateName creates a unique filename for duplicates.
 // If the destination file exists, it appends "_duplicate" before the extension.
 // If "_duplicate" already exists, it appends "_duplicate_2", "_duplicate_3", etc.
@@ -27,10 +84,15 @@ func FileExists(path string) bool {
 //   - "file_duplicate.pdf" -> "file_duplicate_2.pdf" (if file_duplicate.pdf exists)
 //   - "file_duplicate_2.pdf" -> "file_duplicate_3.pdf" (if file_duplicate_2.pdf exists)
 func GenerateDuplicateName(destDir, filename string) string {
+	return GenerateDuplicateNameWithFS(filesystem.Default, destDir, filename)
+}
+
+// GenerateDuplicateNameWithFS is GenerateDuplicateName checking for existing files in fsys.
+func GenerateDuplicateNameWithFS(fsys filesystem.FS, destDir, filename string) string {
 	destPath := filepath.Join(destDir, filename)
 
 	// If file doesn't exist, return original filename
-	if !FileExists(destPath) {
+	if !FileExistsWithFS(fsys, destPath) {
 		return filename
 	}
 
@@ -61,7 +123,7 @@ func GenerateDuplicateName(destDir, filename string) string {
 		for {
 			newFilename := originalBase + "_duplicate_" + strconv.Itoa(nextNum) + originalExt
 			newPath := filepath.Join(destDir, newFilename)
-			if !FileExists(newPath) {
+			if !FileExistsWithFS(fsys, newPath) {
 				return newFilename
 			}
 			nextNum++
@@ -71,7 +133,7 @@ func GenerateDuplicateName(destDir, filename string) string {
 	// No duplicate suffix yet, try adding _duplicate
 	duplicateFilename := baseName + "_duplicate" + ext
 	duplicatePath := filepath.Join(destDir, duplicateFilename)
-	if !FileExists(duplicatePath) {
+	if !FileExistsWithFS(fsys, duplicatePath) {
 		return duplicateFilename
 	}
 
@@ -79,8 +141,73 @@ func GenerateDuplicateName(destDir, filename string) string {
 	for n := 2; ; n++ {
 		numberedFilename := baseName + "_duplicate_" + strconv.Itoa(n) + ext
 		numberedPath := filepath.Join(destDir, numberedFilename)
-		if !FileExists(numberedPath) {
+		if !FileExistsWithFS(fsys, numberedPath) {
 			return numberedFilename
 		}
 	}
 }
+
+// DuplicateNameWithFS returns a unique filename for a duplicate in destDir,
+// rendering cfg.DuplicateRenameTemplate when one is configured and falling back
+// to GenerateDuplicateNameWithFS otherwise.
+func DuplicateNameWithFS(fsys filesystem.FS, destDir, filename string, cfg *config.Configuration) string {
+	if cfg == nil || cfg.DuplicateRenameTemplate == "" {
+		return GenerateDuplicateNameWithFS(fsys, destDir, filename)
+	}
+	return GenerateTemplatedDuplicateNameWithFS(fsys, destDir, filename, cfg.DuplicateRenameTemplate, time.Now())
+}
+
+// DuplicateSuffixLength returns how many bytes renaming a file as a duplicate
+// under cfg may add to its name, assuming fewer than 100 duplicates.
+func DuplicateSuffixLength(cfg *config.Configuration) int {
+	if cfg == nil || cfg.DuplicateRenameTemplate == "" {
+		return len("_duplicate_99")
+	}
+	rendered := strings.NewReplacer(
+		"{name}", "",
+		"{ext}", "",
+		"{n}", "99",
+		"{date}", "2006-01-02",
+	).Replace(cfg.DuplicateRenameTemplate)
+	// A rendered name that is taken gets the default suffix on top
+	return len(rendered) + len("_duplicate_99")
+}
+
+// GenerateTemplatedDuplicateNameWithFS creates a unique filename for a duplicate
+// by rendering template. Supported tokens are {name} (filename without
+// extension), {ext} (extension including the dot), {n} (counter starting at 1)
+// and {date} (date as YYYY-MM-DD). {n} is incremented until the rendered name
+// is free; a template without {n} that renders to an existing name falls back
+// to the default _duplicate suffix on the rendered name.
+//
+// Examples with "file.pdf" existing:
+//   - "{name} ({date}){ext}" -> "file (2024-01-15).pdf"
+//   - "{name}-copy{ext}" -> "file-copy.pdf"
+//   - "{name} ({n}){ext}" -> "file (1).pdf", then "file (2).pdf", ...
+func GenerateTemplatedDuplicateNameWithFS(fsys filesystem.FS, destDir, filename, template string, date time.Time) string {
+	if !FileExistsWithFS(fsys, filepath.Join(destDir, filename)) {
+		return filename
+	}
+
+	ext := filepath.Ext(filename)
+	replacer := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(filename, ext),
+		"{ext}", ext,
+		"{date}", date.Format("2006-01-02"),
+	)
+	rendered := replacer.Replace(template)
+
+	if !strings.Contains(rendered, "{n}") {
+		if rendered != filename && !FileExistsWithFS(fsys, filepath.Join(destDir, rendered)) {
+			return rendered
+		}
+		return GenerateDuplicateNameWithFS(fsys, destDir, rendered)
+	}
+
+	for n := 1; ; n++ {
+		candidate := strings.ReplaceAll(rendered, "{n}", strconv.Itoa(n))
+		if candidate != filename && !FileExistsWithFS(fsys, filepath.Join(destDir, candidate)) {
+			return candidate
+		}
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+
+	"sorta/internal/filesystem"
 )
 
 func TestFileExists(t *testing.T) {
@@ -184,6 +187,40 @@ func genNumExistingDuplicates() gopter.Gen {
 	return gen.IntRange(0, 5)
 }
 
+func TestGenerateTemplatedDuplicateName(t *testing.T) {
+	date := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		existing []string
+		expected string
+	}{
+		{"date suffix", "{name} ({date}){ext}", []string{"document.pdf"}, "document (2024-01-15).pdf"},
+		{"fixed suffix", "{name}-copy{ext}", []string{"document.pdf"}, "document-copy.pdf"},
+		{"counter", "{name} ({n}){ext}", []string{"document.pdf"}, "document (1).pdf"},
+		{"counter increments", "{name} ({n}){ext}", []string{"document.pdf", "document (1).pdf", "document (2).pdf"}, "document (3).pdf"},
+		{"taken without counter", "{name}-copy{ext}", []string{"document.pdf", "document-copy.pdf"}, "document-copy_duplicate.pdf"},
+		{"no conflict", "{name}-copy{ext}", nil, "document.pdf"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			for _, name := range tc.existing {
+				if err := os.WriteFile(filepath.Join(tempDir, name), []byte("test"), 0644); err != nil {
+					t.Fatalf("Failed to create test file: %v", err)
+				}
+			}
+
+			result := GenerateTemplatedDuplicateNameWithFS(filesystem.Default, tempDir, "document.pdf", tc.template, date)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestDuplicateFileNaming_Property(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 20
@@ -0,0 +1,46 @@
+// Package organizer handles file movement and organization for Sorta.
+package organizer
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"sorta/internal/filesystem"
+)
+
+// DestinationTooLong reports whether moving a file into destDir as filename
+// would exceed the platform's path length limit or the file name length limit.
+func DestinationTooLong(destDir, filename string) bool {
+	return len(filepath.Join(destDir, filename)) > filesystem.MaxPathLength ||
+		len(filename) > filesystem.MaxNameLength
+}
+
+// TruncateToFit shortens the end of filename's base name so that it fits in
+// destDir under the length limits with reserve bytes to spare, keeping its
+// extension. At least the first keep bytes of the base name are kept; if that
+// is not enough, TruncateToFit returns false. A filename that already fits is
+// returned unchanged.
+func TruncateToFit(destDir, filename string, keep, reserve int) (string, bool) {
+	excess := max(len(filepath.Join(destDir, filename))-filesystem.MaxPathLength,
+		len(filename)-filesystem.MaxNameLength) + reserve
+	if excess <= reserve {
+		return filename, true
+	}
+
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	cut := len(base) - excess
+	if cut < keep {
+		return "", false
+	}
+	// Never split a multi-byte character
+	for cut > 0 && !utf8.RuneStart(base[cut]) {
+		cut--
+	}
+	base = strings.TrimRight(base[:cut], " ")
+	if len(base) < keep {
+		return "", false
+	}
+	return base + ext, true
+}
@@ -8,6 +8,7 @@ import (
 
 	"sorta/internal/classifier"
 	"sorta/internal/config"
+	"sorta/internal/filesystem"
 	"sorta/internal/scanner"
 )
 
@@ -21,6 +22,8 @@ const (
 	DestinationExists MoveErrorType = "DESTINATION_EXISTS"
 	// PermissionDenied indicates insufficient permissions for the operation.
 	PermissionDenied MoveErrorType = "PERMISSION_DENIED"
+	// InsufficientSpace indicates the destination volume has no room for a copy.
+	InsufficientSpace MoveErrorType = "INSUFFICIENT_SPACE"
 )
 
 // MoveError represents an error that occurred during file movement.
@@ -47,6 +50,7 @@ type MoveResult struct {
 	DestinationPath string
 	IsDuplicate     bool   // True if the file was renamed due to a duplicate
 	OriginalName    string // Original filename before duplicate renaming (empty if not a duplicate)
+	Copied          bool   // True if the file was copied from a read-only source and left in place
 }
 
 // Organize moves a file to its appropriate destination based on classification.
@@ -54,25 +58,94 @@ type MoveResult struct {
 // For UNCLASSIFIED files: moves to for-review subdirectory within the source directory
 // If a file with the same name exists at the destination, it will be renamed with a duplicate suffix.
 func Organize(file scanner.FileEntry, classification *classifier.Classification, cfg *config.Configuration) (*MoveResult, error) {
-	var destDir string
-	var destFilename string
-
-	if classification.IsClassified() {
-		// Build destination path: <targetDir>/<year> <prefix>/
-		// Extract the canonical prefix from the normalised filename
-		// The normalised filename starts with the canonical prefix
-		prefix := extractPrefixFromNormalisedFilename(classification.NormalisedFilename)
-		subfolder := fmt.Sprintf("%d %s", classification.Year, prefix)
-		destDir = filepath.Join(classification.OutboundDirectory, subfolder)
-		destFilename = classification.NormalisedFilename
-	} else {
+	return OrganizeWithFS(filesystem.Default, file, classification, cfg)
+}
+
+// OrganizeWithFS is Organize performing all file operations through fsys.
+func OrganizeWithFS(fsys filesystem.FS, file scanner.FileEntry, classification *classifier.Classification, cfg *config.Configuration) (*MoveResult, error) {
+	if !classification.IsClassified() {
 		// Move to for-review subdirectory within the source directory
-		destDir = GetForReviewPath(filepath.Dir(file.FullPath))
-		destFilename = file.Name
+		return OrganizeForReviewWithFS(fsys, file, cfg)
+	}
+
+	destDir := ClassifiedDestinationDir(file, classification, cfg)
+	return moveFileWithFS(fsys, file.FullPath, destDir, classification.NormalisedFilename, cfg)
+}
+
+// OrganizeIntoWithFS moves a classified file into destDir as destFilename,
+// for callers that have already worked out its destination.
+func OrganizeIntoWithFS(fsys filesystem.FS, file scanner.FileEntry, destDir, destFilename string, cfg *config.Configuration) (*MoveResult, error) {
+	return moveFileWithFS(fsys, file.FullPath, destDir, destFilename, cfg)
+}
+
+// OrganizeForReviewWithFS moves a file into the for-review subdirectory
+// within its source directory, keeping its name.
+func OrganizeForReviewWithFS(fsys filesystem.FS, file scanner.FileEntry, cfg *config.Configuration) (*MoveResult, error) {
+	return moveFileWithFS(fsys, file.FullPath, GetForReviewPath(filepath.Dir(file.FullPath)), file.Name, cfg)
+}
+
+// OrganizeByTypeWithFS moves a file matched by a type rule into destDir,
+// keeping its name. Duplicates are renamed as for classified files.
+func OrganizeByTypeWithFS(fsys filesystem.FS, file scanner.FileEntry, destDir string, cfg *config.Configuration) (*MoveResult, error) {
+	return moveFileWithFS(fsys, file.FullPath, destDir, file.Name, cfg)
+}
+
+// MoveFile moves the file at src into destDir as filename, creating destDir if
+// needed. An existing file at the destination is kept and the moved file is
+// given a duplicate name instead.
+func MoveFile(src, destDir, filename string) (*MoveResult, error) {
+	return MoveFileWithFS(filesystem.Default, src, destDir, filename)
+}
+
+// MoveFileWithFS is MoveFile performing all file operations through fsys.
+func MoveFileWithFS(fsys filesystem.FS, src, destDir, destFilename string) (*MoveResult, error) {
+	return moveFileWithFS(fsys, src, destDir, destFilename, nil)
+}
+
+// mkdirDestination creates destDir and any missing parents. With cfg's
+// DirPermissions set, every directory it creates is given exactly that mode,
+// whatever the umask.
+func mkdirDestination(fsys filesystem.FS, destDir string, cfg *config.Configuration) error {
+	if cfg == nil || cfg.DirPermissions == "" {
+		return fsys.MkdirAll(destDir, config.DefaultDirPermissions)
+	}
+	return MkdirAllWithFS(fsys, destDir, cfg.GetDirPermissions())
+}
+
+// MkdirAllWithFS creates dir and any missing parents in fsys. When fsys is a
+// filesystem.Chmoder, every directory it creates is given exactly mode,
+// whatever the umask.
+func MkdirAllWithFS(fsys filesystem.FS, dir string, mode os.FileMode) error {
+	// Note the directories that do not exist yet, deepest first
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := fsys.Stat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := fsys.MkdirAll(dir, mode); err != nil {
+		return err
 	}
 
+	chmoder, ok := fsys.(filesystem.Chmoder)
+	if !ok {
+		return nil
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := chmoder.Chmod(missing[i], mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveFileWithFS implements MoveFileWithFS, naming duplicates with cfg's
+// DuplicateRenameTemplate when cfg is non-nil. Files from a source cfg marks
+// read-only are copied and left in place.
+func moveFileWithFS(fsys filesystem.FS, src, destDir, destFilename string, cfg *config.Configuration) (*MoveResult, error) {
 	// Create destination directory if it doesn't exist
-	if err := os.MkdirAll(destDir, 0755); err != nil {
+	if err := mkdirDestination(fsys, destDir, cfg); err != nil {
 		if os.IsPermission(err) {
 			return nil, &MoveError{
 				Type: PermissionDenied,
@@ -84,10 +157,11 @@ func Organize(file scanner.FileEntry, classification *classifier.Classification,
 	}
 
 	// Check if source exists
-	if _, err := os.Stat(file.FullPath); os.IsNotExist(err) {
+	srcInfo, err := fsys.Stat(src)
+	if os.IsNotExist(err) {
 		return nil, &MoveError{
 			Type: SourceNotFound,
-			Path: file.FullPath,
+			Path: src,
 			Err:  err,
 		}
 	}
@@ -95,30 +169,52 @@ func Organize(file scanner.FileEntry, classification *classifier.Classification,
 	// Handle duplicate files - generate unique name if destination exists
 	originalFilename := destFilename
 	isDuplicate := false
-	if FileExists(filepath.Join(destDir, destFilename)) {
-		destFilename = GenerateDuplicateName(destDir, destFilename)
+	if FileExistsWithFS(fsys, filepath.Join(destDir, destFilename)) {
+		destFilename = DuplicateNameWithFS(fsys, destDir, destFilename, cfg)
 		isDuplicate = true
 	}
 
 	destPath := filepath.Join(destDir, destFilename)
 
+	if cfg != nil && cfg.IsReadOnlySource(src) {
+		if err := checkCopySpace(fsys, destDir, srcInfo, cfg); err != nil {
+			return nil, err
+		}
+		if err := copyFile(fsys, src, destPath, cfg); err != nil {
+			return nil, err
+		}
+		result := &MoveResult{
+			SourcePath:      src,
+			DestinationPath: destPath,
+			IsDuplicate:     isDuplicate,
+			Copied:          true,
+		}
+		if isDuplicate {
+			result.OriginalName = originalFilename
+		}
+		return result, nil
+	}
+
 	// Move the file (rename)
-	if err := os.Rename(file.FullPath, destPath); err != nil {
+	if err := fsys.Rename(src, destPath); err != nil {
 		if os.IsPermission(err) {
 			return nil, &MoveError{
 				Type: PermissionDenied,
-				Path: file.FullPath,
+				Path: src,
 				Err:  err,
 			}
 		}
 		// If rename fails (e.g., cross-device), fall back to copy+delete
-		if err := copyAndDelete(file.FullPath, destPath); err != nil {
+		if err := checkCopySpace(fsys, destDir, srcInfo, cfg); err != nil {
+			return nil, err
+		}
+		if err := copyAndDelete(fsys, src, destPath, cfg); err != nil {
 			return nil, err
 		}
 	}
 
 	result := &MoveResult{
-		SourcePath:      file.FullPath,
+		SourcePath:      src,
 		DestinationPath: destPath,
 		IsDuplicate:     isDuplicate,
 	}
@@ -129,22 +225,135 @@ func Organize(file scanner.FileEntry, classification *classifier.Classification,
 	return result, nil
 }
 
-// extractPrefixFromNormalisedFilename extracts the prefix portion from a normalised filename.
-// The prefix is everything before the first space.
-func extractPrefixFromNormalisedFilename(filename string) string {
-	for i, c := range filename {
-		if c == ' ' {
-			return filename[:i]
+// ClassifiedDestinationDir returns the directory a classified file is moved into:
+// <targetDir>/<year> <prefix>/ (or <targetDir>/<undated folder> <prefix>/ for
+// undated files), followed by the initial-letter bucket for rules with
+// BucketByInitial, by the file's directory relative to its inbound root when
+// PreserveSourceSubpath is enabled. A target directory with {year}, {month},
+// {day} or {prefix} tokens is rendered and used in place of
+// <targetDir>/<year> <prefix>/; undated files then go to
+// <undated folder> <prefix>/ under the part before the first token.
+func ClassifiedDestinationDir(file scanner.FileEntry, classification *classifier.Classification, cfg *config.Configuration) string {
+	// The canonical prefix of the matched rule, which may contain spaces
+	prefix := classification.Prefix
+	var destDir string
+	switch {
+	case classification.IsUndated():
+		destDir = filepath.Join(config.OutboundRoot(classification.OutboundDirectory), fmt.Sprintf("%s %s", classification.UndatedFolder, prefix))
+	case config.HasOutboundTokens(classification.OutboundDirectory):
+		destDir = filepath.Clean(config.RenderOutboundDirectory(classification.OutboundDirectory, prefix, classification.Date))
+	default:
+		destDir = filepath.Join(classification.OutboundDirectory, fmt.Sprintf("%d %s", classification.Year, prefix))
+	}
+	if classification.Bucket != "" {
+		destDir = filepath.Join(destDir, classification.Bucket)
+	}
+
+	if cfg != nil && cfg.PreserveSourceSubpath && file.RelativeDir != "" {
+		destDir = filepath.Join(destDir, file.RelativeDir)
+	}
+	return destDir
+}
+
+// CheckCopySpaceWithFS returns the InsufficientSpace MoveError that moving src
+// into destDir would fail with, so the file can be skipped before its move is
+// recorded. Only moves known to copy are checked: from a read-only source, or
+// to another volume when fsys can tell (see filesystem.VolumeReporter).
+func CheckCopySpaceWithFS(fsys filesystem.FS, src, destDir string, cfg *config.Configuration) error {
+	if cfg == nil || !cfg.CheckCopySpace {
+		return nil
+	}
+	dir := nearestExistingDir(fsys, destDir)
+	if dir == "" {
+		return nil
+	}
+	if !cfg.IsReadOnlySource(src) {
+		reporter, ok := fsys.(filesystem.VolumeReporter)
+		if !ok {
+			return nil
+		}
+		if same, err := reporter.SameVolume(src, dir); err != nil || same {
+			return nil
+		}
+	}
+	srcInfo, err := fsys.Stat(src)
+	if err != nil {
+		return nil
+	}
+	return checkCopySpace(fsys, dir, srcInfo, cfg)
+}
+
+// nearestExistingDir returns dir if it exists in fsys, otherwise its nearest
+// existing parent, or "" if there is none.
+func nearestExistingDir(fsys filesystem.FS, dir string) string {
+	for {
+		if _, err := fsys.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
 		}
+		dir = parent
 	}
-	return filename
+}
+
+// checkCopySpace returns an InsufficientSpace MoveError when cfg asks for copy
+// space checks and the volume holding destDir lacks room for the source file
+// plus the configured margin. Filesystems that cannot report free space, and
+// volumes whose free space cannot be determined, are not checked.
+func checkCopySpace(fsys filesystem.FS, destDir string, srcInfo os.FileInfo, cfg *config.Configuration) error {
+	if cfg == nil || !cfg.CheckCopySpace || srcInfo == nil {
+		return nil
+	}
+	reporter, ok := fsys.(filesystem.SpaceReporter)
+	if !ok {
+		return nil
+	}
+	free, err := reporter.FreeSpace(destDir)
+	if err != nil {
+		return nil
+	}
+	needed := uint64(srcInfo.Size()) + uint64(cfg.GetCopySpaceMargin())
+	if free < needed {
+		return &MoveError{
+			Type: InsufficientSpace,
+			Path: destDir,
+			Err:  fmt.Errorf("%d bytes free, %d needed", free, needed),
+		}
+	}
+	return nil
 }
 
 // copyAndDelete copies a file to a new location and deletes the original.
-// Used as a fallback when os.Rename fails (e.g., cross-device moves).
-func copyAndDelete(src, dst string) error {
+// Used as a fallback when Rename fails (e.g., cross-device moves).
+func copyAndDelete(fsys filesystem.FS, src, dst string, cfg *config.Configuration) error {
+	if err := copyFile(fsys, src, dst, cfg); err != nil {
+		return err
+	}
+
+	// Delete source
+	if err := fsys.Remove(src); err != nil {
+		// If we can't delete source, try to clean up destination
+		fsys.Remove(dst)
+		if os.IsPermission(err) {
+			return &MoveError{
+				Type: PermissionDenied,
+				Path: src,
+				Err:  err,
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// copyFile copies src to dst with the source's permissions, and with its
+// extended attributes when cfg sets PreserveXattrs and fsys can copy them.
+func copyFile(fsys filesystem.FS, src, dst string, cfg *config.Configuration) error {
 	// Read source file
-	data, err := os.ReadFile(src)
+	data, err := fsys.ReadFile(src)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &MoveError{
@@ -164,13 +373,13 @@ func copyAndDelete(src, dst string) error {
 	}
 
 	// Get source file permissions
-	srcInfo, err := os.Stat(src)
+	srcInfo, err := fsys.Stat(src)
 	if err != nil {
 		return err
 	}
 
 	// Write to destination
-	if err := os.WriteFile(dst, data, srcInfo.Mode()); err != nil {
+	if err := fsys.WriteFile(dst, data, srcInfo.Mode()); err != nil {
 		if os.IsPermission(err) {
 			return &MoveError{
 				Type: PermissionDenied,
@@ -181,18 +390,13 @@ func copyAndDelete(src, dst string) error {
 		return err
 	}
 
-	// Delete source
-	if err := os.Remove(src); err != nil {
-		// If we can't delete source, try to clean up destination
-		os.Remove(dst)
-		if os.IsPermission(err) {
-			return &MoveError{
-				Type: PermissionDenied,
-				Path: src,
-				Err:  err,
+	if cfg != nil && cfg.PreserveXattrs {
+		if copier, ok := fsys.(filesystem.XattrCopier); ok {
+			if err := copier.CopyXattrs(src, dst); err != nil {
+				fsys.Remove(dst)
+				return fmt.Errorf("failed to copy extended attributes of %s: %w", src, err)
 			}
 		}
-		return err
 	}
 
 	return nil
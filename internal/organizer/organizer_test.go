@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -190,7 +191,7 @@ func TestFileContentIntegrity(t *testing.T) {
 			}
 
 			// Extract prefix from filename (everything before first space)
-			prefix := extractPrefixFromNormalisedFilename(filename)
+			prefix, _, _ := strings.Cut(filename, " ")
 
 			// Create config with matching prefix rule
 			cfg := &config.Configuration{
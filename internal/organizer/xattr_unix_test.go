@@ -0,0 +1,63 @@
+//go:build linux || darwin
+
+package organizer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"sorta/internal/config"
+	"sorta/internal/filesystem"
+)
+
+// crossDeviceFS is the real filesystem with every rename failing as if it
+// crossed volumes, so moves fall back to copy-and-delete.
+type crossDeviceFS struct {
+	filesystem.OS
+}
+
+func (crossDeviceFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+}
+
+// TestCopyFallbackPreservesXattrs verifies that a move falling back to
+// copy-and-delete keeps the file's extended attributes when PreserveXattrs
+// is set.
+func TestCopyFallbackPreservesXattrs(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "Invoice 2024-03-15 Acme.pdf")
+	destDir := filepath.Join(tempDir, "dest")
+	if err := os.WriteFile(src, []byte("invoice"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A user attribute, which Linux copies and macOS accepts as any name
+	const attr, value = "user.sorta-test", "kept"
+	if err := unix.Setxattr(src, attr, []byte(value), 0); errors.Is(err, unix.ENOTSUP) {
+		t.Skip("the temporary directory does not support extended attributes")
+	} else if err != nil {
+		t.Fatalf("Setxattr failed: %v", err)
+	}
+
+	cfg := &config.Configuration{PreserveXattrs: true}
+	result, err := moveFileWithFS(crossDeviceFS{}, src, destDir, filepath.Base(src), cfg)
+	if err != nil {
+		t.Fatalf("moveFileWithFS failed: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be removed, got %v", err)
+	}
+
+	buf := make([]byte, 64)
+	n, err := unix.Getxattr(result.DestinationPath, attr, buf)
+	if err != nil {
+		t.Fatalf("Expected %s on the moved file: %v", attr, err)
+	}
+	if got := string(buf[:n]); got != value {
+		t.Errorf("Expected %s=%q, got %q", attr, value, got)
+	}
+}
@@ -0,0 +1,101 @@
+// Package output handles CLI output formatting including verbose mode and progress indicators.
+package output
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"sorta/internal/orchestrator"
+)
+
+// FormatExplainLine returns a one-line rationale for a processed file:
+//
+//	"<source>" rule=<prefix|none> date=<YYYY-MM-DD|none> dest="<path>" decision=<decision>
+//
+// The decision is moved (copied for read-only sources), renamed-duplicate (with the name that collided),
+// review, skipped or error, followed by the reason where there is one.
+func FormatExplainLine(result *orchestrator.Result) string {
+	return FormatExplainLineWidth(result, 0)
+}
+
+// FormatExplainLineWidth is FormatExplainLine fitted to width columns: when
+// the line is too long, the source and destination paths share the columns
+// left by the rest of the line and are shortened with an ellipsis. A width of
+// 0 or less means no limit.
+func FormatExplainLineWidth(result *orchestrator.Result, width int) string {
+	line := explainLine(result, result.SourcePath, result.DestinationPath)
+	if width <= 0 || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	// Columns left for the paths once the rest of the line is laid out
+	room := width - utf8.RuneCountInString(explainLine(result, "", ""))
+	if result.DestinationPath == "" {
+		return explainLine(result, Ellipsize(result.SourcePath, max(room, minExplainPath)), "")
+	}
+	sourceRoom := max(room/2, minExplainPath)
+	destRoom := max(room-room/2, minExplainPath)
+	return explainLine(result, Ellipsize(result.SourcePath, sourceRoom), Ellipsize(result.DestinationPath, destRoom))
+}
+
+// minExplainPath is the fewest characters a path is shortened to in an
+// explain line, however narrow the terminal.
+const minExplainPath = 12
+
+// explainLine formats an explain line showing source and dest as the file's
+// paths.
+func explainLine(result *orchestrator.Result, source, dest string) string {
+	rule := result.Prefix
+	if rule == "" {
+		rule = "none"
+	}
+	date := result.Date
+	if date == "" {
+		date = "none"
+	}
+	destField := "-"
+	if result.DestinationPath != "" {
+		destField = fmt.Sprintf("%q", dest)
+	}
+
+	return fmt.Sprintf("%q rule=%s date=%s dest=%s decision=%s",
+		source, rule, date, destField, explainDecision(result))
+}
+
+// explainDecision describes what was done with the file and why.
+func explainDecision(result *orchestrator.Result) string {
+	var decision string
+	switch result.EventType {
+	case "MOVE":
+		decision = "moved"
+		if result.Copied {
+			decision = "copied"
+		}
+	case "DUPLICATE_DETECTED":
+		return fmt.Sprintf("renamed-duplicate(collided with %q)", result.OriginalName)
+	case "ROUTE_TO_REVIEW":
+		decision = "review"
+	case "SKIP":
+		decision = "skipped"
+	case "ERROR":
+		if result.Error != nil {
+			return fmt.Sprintf("error(%q)", result.Error.Error())
+		}
+		return "error"
+	default:
+		decision = result.EventType
+	}
+
+	if result.ReasonCode != "" {
+		return fmt.Sprintf("%s(%s)", decision, result.ReasonCode)
+	}
+	return decision
+}
+
+// PrintExplainLine prints the one-line rationale for a processed file to stdout.
+func (o *Output) PrintExplainLine(result *orchestrator.Result) {
+	if result == nil {
+		return
+	}
+	o.Info("%s", FormatExplainLineWidth(result, o.config.Width))
+}
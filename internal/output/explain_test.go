@@ -0,0 +1,75 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sorta/internal/config"
+	"sorta/internal/orchestrator"
+)
+
+// TestExplainPrintsOneLinePerFile runs an organize through the progress callback,
+// as run --explain does, and checks each file gets exactly one explain line with
+// its rule, date, destination and decision.
+func TestExplainPrintsOneLinePerFile(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	os.MkdirAll(sourceDir, 0755)
+	os.MkdirAll(filepath.Join(invoiceDir, "2024 Invoice"), 0755)
+	os.WriteFile(filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-02-20 Beta.pdf"), []byte("existing"), 0644)
+	for _, name := range []string{"Invoice 2024-01-15 Acme.pdf", "Invoice 2024-02-20 Beta.pdf", "notes.txt"} {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	cfg := config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules:        []config.PrefixRule{{Prefix: "Invoice", OutboundDirectory: invoiceDir}},
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	if err := config.Save(&cfg, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	var buf bytes.Buffer
+	out := New(Config{Writer: &buf, ErrWriter: &buf, IsTTY: false})
+	_, err := orchestrator.RunWithOptions(configPath, &orchestrator.Options{
+		ProgressCallback: func(current, total int, file string, result *orchestrator.Result) {
+			out.PrintExplainLine(result)
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	yearDir := filepath.Join(invoiceDir, "2024 Invoice")
+	expected := map[string]string{
+		"Invoice 2024-01-15 Acme.pdf": fmt.Sprintf("rule=Invoice date=2024-01-15 dest=%q decision=moved",
+			filepath.Join(yearDir, "Invoice 2024-01-15 Acme.pdf")),
+		"Invoice 2024-02-20 Beta.pdf": fmt.Sprintf("rule=Invoice date=2024-02-20 dest=%q decision=renamed-duplicate(collided with %q)",
+			filepath.Join(yearDir, "Invoice 2024-02-20 Beta_duplicate.pdf"), "Invoice 2024-02-20 Beta.pdf"),
+		"notes.txt": fmt.Sprintf("rule=none date=none dest=%q decision=review(UNCLASSIFIED)",
+			filepath.Join(sourceDir, "for-review", "notes.txt")),
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d explain lines, got %d:\n%s", len(expected), len(lines), buf.String())
+	}
+	for name, fields := range expected {
+		want := fmt.Sprintf("%q %s", filepath.Join(sourceDir, name), fields)
+		count := 0
+		for _, line := range lines {
+			if line == want {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Expected exactly one line %q, got:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package output handles CLI output formatting including verbose mode and progress indicators.
+package output
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"sorta/internal/orchestrator"
+)
+
+// NotificationTitle is the title of the desktop notification sent after a run.
+const NotificationTitle = "Sorta"
+
+// Notifier sends a desktop notification.
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// CommandNotifier sends notifications with the platform's notification tool:
+// notify-send on Linux and BSD, osascript on macOS and PowerShell on Windows.
+type CommandNotifier struct {
+	GOOS string // Target platform; empty = runtime.GOOS
+}
+
+// Notify runs the platform's notification command and waits for it to exit.
+func (n CommandNotifier) Notify(title, message string) error {
+	goos := n.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	args, err := notifyCommand(goos, title, message)
+	if err != nil {
+		return err
+	}
+	if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, text)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// notifyCommand returns the command line that shows a notification on goos.
+func notifyCommand(goos, title, message string) ([]string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return []string{"osascript", "-e", script}, nil
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+			`$n.ShowBalloonTip(5000, %s, %s, 'Info'); Start-Sleep -Seconds 5; $n.Dispose()`,
+			powerShellString(title), powerShellString(message))
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return []string{"notify-send", title, message}, nil
+	default:
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string literal.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// FormatNotification returns the notification text for a run summary, e.g.
+// "Moved 5, review 2, skipped 1". Errors are included only when there are any.
+func FormatNotification(summary *orchestrator.RunSummary) string {
+	message := fmt.Sprintf("Moved %d, review %d, skipped %d", summary.Moved, summary.ForReview, summary.Skipped)
+	if summary.Errors > 0 {
+		message += fmt.Sprintf(", errors %d", summary.Errors)
+	}
+	return message
+}
+
+// NotifyRunSummary sends a desktop notification summarizing a run. It is best
+// effort: a failure is printed as a warning and otherwise ignored.
+func (o *Output) NotifyRunSummary(notifier Notifier, summary *orchestrator.RunSummary) {
+	if notifier == nil || summary == nil {
+		return
+	}
+	if err := notifier.Notify(NotificationTitle, FormatNotification(summary)); err != nil {
+		o.Error("Warning: could not send notification: %v", err)
+	}
+}
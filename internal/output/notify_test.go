@@ -0,0 +1,56 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"sorta/internal/orchestrator"
+)
+
+// stubNotifier records notifications instead of showing them.
+type stubNotifier struct {
+	titles   []string
+	messages []string
+	err      error
+}
+
+func (n *stubNotifier) Notify(title, message string) error {
+	n.titles = append(n.titles, title)
+	n.messages = append(n.messages, message)
+	return n.err
+}
+
+// TestNotifyRunSummary verifies that the notifier receives the run counts and
+// that a failing notifier only produces a warning.
+func TestNotifyRunSummary(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	out := New(Config{Writer: &stdoutBuf, ErrWriter: &stderrBuf})
+	summary := &orchestrator.RunSummary{Moved: 5, ForReview: 2, Skipped: 1}
+
+	notifier := &stubNotifier{}
+	out.NotifyRunSummary(notifier, summary)
+	if len(notifier.messages) != 1 {
+		t.Fatalf("Expected one notification, got %d", len(notifier.messages))
+	}
+	if notifier.titles[0] != NotificationTitle {
+		t.Errorf("Expected title %q, got %q", NotificationTitle, notifier.titles[0])
+	}
+	if want := "Moved 5, review 2, skipped 1"; notifier.messages[0] != want {
+		t.Errorf("Expected message %q, got %q", want, notifier.messages[0])
+	}
+
+	summary.Errors = 3
+	failing := &stubNotifier{err: errors.New("notify-send: not found")}
+	out.NotifyRunSummary(failing, summary)
+	if want := "Moved 5, review 2, skipped 1, errors 3"; len(failing.messages) != 1 || failing.messages[0] != want {
+		t.Errorf("Expected message %q, got %v", want, failing.messages)
+	}
+	if !strings.Contains(stderrBuf.String(), "could not send notification") {
+		t.Errorf("Expected a warning on stderr, got %q", stderrBuf.String())
+	}
+	if stdoutBuf.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", stdoutBuf.String())
+	}
+}
@@ -2,6 +2,7 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +10,8 @@ import (
 	"sorta/internal/orchestrator"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
@@ -19,6 +22,15 @@ type Config struct {
 	Writer    io.Writer // Output destination (default: os.Stdout)
 	ErrWriter io.Writer // Error output destination (default: os.Stderr)
 	IsTTY     bool      // Whether output is a terminal
+	Width     int       // Columns available to progress and explain lines; 0 = no limit
+
+	// JSONErrors makes Error write each message as a JSON object,
+	// {"level":"error","msg":"..."}, on its own line instead of plain text.
+	JSONErrors bool
+
+	// ReasonPhrases overrides the phrases shown for reason codes in human
+	// output (see DefaultReasonPhrases). Unset codes use the default phrase.
+	ReasonPhrases map[string]string
 }
 
 // Output handles formatted output with verbose and progress support.
@@ -27,7 +39,9 @@ type Output struct {
 	progressActive  bool
 	progressTotal   int
 	progressCurrent int
+	progressLen     int // Length of the progress line last written
 	progressMu      sync.Mutex
+	progressSink    *ProgressSink
 }
 
 // New creates a new Output instance with the given configuration.
@@ -44,13 +58,19 @@ func New(config Config) *Output {
 }
 
 // DefaultConfig returns a Config with sensible defaults and TTY detection.
+// On a terminal, Width is set to the terminal width.
 func DefaultConfig() Config {
 	isTTY := term.IsTerminal(int(os.Stdout.Fd()))
+	width := 0
+	if isTTY {
+		width = TerminalWidth()
+	}
 	return Config{
 		Verbose:   false,
 		Writer:    os.Stdout,
 		ErrWriter: os.Stderr,
 		IsTTY:     isTTY,
+		Width:     width,
 	}
 }
 
@@ -77,10 +97,22 @@ func (o *Output) Info(format string, args ...interface{}) {
 	fmt.Fprint(o.config.Writer, msg)
 }
 
-// Error prints an error message to stderr.
+// errorLine is an error message as written with Config.JSONErrors.
+type errorLine struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// Error prints an error message to stderr, as a JSON object when
+// Config.JSONErrors is set.
 func (o *Output) Error(format string, args ...interface{}) {
 	o.clearProgressLine()
 	msg := fmt.Sprintf(format, args...)
+	if o.config.JSONErrors {
+		data, _ := json.Marshal(errorLine{Level: "error", Msg: strings.TrimSuffix(msg, "\n")})
+		fmt.Fprintf(o.config.ErrWriter, "%s\n", data)
+		return
+	}
 	if !strings.HasSuffix(msg, "\n") {
 		msg += "\n"
 	}
@@ -93,12 +125,25 @@ func (o *Output) clearProgressLine() {
 	defer o.progressMu.Unlock()
 	if o.progressActive && o.config.IsTTY {
 		// Clear the line with spaces and return to start
-		fmt.Fprint(o.config.Writer, "\r"+strings.Repeat(" ", 60)+"\r")
+		fmt.Fprint(o.config.Writer, "\r"+strings.Repeat(" ", o.progressClearLen())+"\r")
 	}
 }
 
+// progressClearLen returns how many spaces clear the progress line.
+func (o *Output) progressClearLen() int {
+	return max(60, o.progressLen)
+}
+
+// SetProgressSink sends every progress update to sink as well as the TTY indicator.
+func (o *Output) SetProgressSink(sink *ProgressSink) {
+	o.progressSink = sink
+}
+
 // StartProgress begins a progress indicator session.
 func (o *Output) StartProgress(total int) {
+	if o.progressSink != nil {
+		o.progressSink.Start(total)
+	}
 	// Suppress progress when not TTY or when verbose mode is enabled
 	if !o.config.IsTTY || o.config.Verbose {
 		return
@@ -112,6 +157,16 @@ func (o *Output) StartProgress(total int) {
 
 // UpdateProgress updates the progress indicator.
 func (o *Output) UpdateProgress(current int, message string) {
+	o.UpdateProgressPath(current, message, "")
+}
+
+// UpdateProgressPath updates the progress indicator, showing path after the
+// count. The path is shortened with an ellipsis so the line fits in
+// Config.Width. Only message is sent to the progress sink.
+func (o *Output) UpdateProgressPath(current int, message, path string) {
+	if o.progressSink != nil {
+		o.progressSink.Update(current, message)
+	}
 	// Suppress progress when not TTY or when verbose mode is enabled
 	if !o.config.IsTTY || o.config.Verbose {
 		return
@@ -123,11 +178,23 @@ func (o *Output) UpdateProgress(current int, message string) {
 	}
 	o.progressCurrent = current
 	// Use carriage return for in-place updates
-	progressMsg := fmt.Sprintf("\rProcessing file %d/%d...", current, o.progressTotal)
-	if message != "" {
-		progressMsg = fmt.Sprintf("\r%s %d/%d...", message, current, o.progressTotal)
+	if message == "" {
+		message = "Processing file"
+	}
+	progressMsg := fmt.Sprintf("%s %d/%d...", message, current, o.progressTotal)
+	if path != "" {
+		room := 0 // No limit
+		if o.config.Width > 0 {
+			// Less the separating space and the last column, left free so the line does not wrap
+			room = max(1, o.config.Width-utf8.RuneCountInString(progressMsg)-2)
+		}
+		progressMsg += " " + Ellipsize(path, room)
 	}
-	fmt.Fprint(o.config.Writer, progressMsg)
+	// Pad over the remains of a longer previous line
+	length := utf8.RuneCountInString(progressMsg)
+	padding := max(0, o.progressLen-length)
+	o.progressLen = length
+	fmt.Fprint(o.config.Writer, "\r"+progressMsg+strings.Repeat(" ", padding))
 }
 
 // EndProgress clears the progress indicator.
@@ -143,7 +210,8 @@ func (o *Output) EndProgress() {
 	}
 	o.progressActive = false
 	// Clear the line with spaces and return to start
-	fmt.Fprint(o.config.Writer, "\r"+strings.Repeat(" ", 60)+"\r")
+	fmt.Fprint(o.config.Writer, "\r"+strings.Repeat(" ", o.progressClearLen())+"\r")
+	o.progressLen = 0
 }
 
 // IsVerbose returns whether verbose mode is enabled.
@@ -183,7 +251,7 @@ func (o *Output) PrintDryRunResult(result *orchestrator.RunResult) {
 		for _, op := range result.ForReview {
 			o.Info("  %s → %s", op.Source, op.Destination)
 			if o.config.Verbose && op.Reason != "" {
-				o.Verbose("    Reason: %s", op.Reason)
+				o.Verbose("    Reason: %s", o.ReasonPhrase(op.Reason))
 			}
 		}
 		o.Info("")
@@ -195,7 +263,7 @@ func (o *Output) PrintDryRunResult(result *orchestrator.RunResult) {
 		for _, op := range result.Skipped {
 			o.Info("  %s", op.Source)
 			if op.Reason != "" {
-				o.Info("    Reason: %s", op.Reason)
+				o.Info("    Reason: %s", o.ReasonPhrase(op.Reason))
 			}
 		}
 		o.Info("")
@@ -255,7 +323,11 @@ func (o *Output) PrintStatusResult(result *orchestrator.StatusResult) {
 			o.Info("  → %s (%d files)", dest, len(files))
 			if o.config.Verbose {
 				for _, file := range files {
-					o.Verbose("      %s", file)
+					if reason := status.Reasons[file]; reason != "" {
+						o.Verbose("      %s (%s)", file, o.ReasonPhrase(reason))
+					} else {
+						o.Verbose("      %s", file)
+					}
 				}
 			}
 		}
@@ -267,6 +339,74 @@ func (o *Output) PrintStatusResult(result *orchestrator.StatusResult) {
 	o.Info("Total pending files: %d", result.GrandTotal)
 }
 
+// PrintDedupeReport prints groups of files that share identical content.
+func (o *Output) PrintDedupeReport(report *orchestrator.DedupeReport) {
+	if report == nil {
+		return
+	}
+
+	if len(report.Groups) == 0 {
+		o.Info("No duplicate files found (%d files scanned).", report.FilesScanned)
+		return
+	}
+
+	for _, group := range report.Groups {
+		o.Info("%d copies, %s each (hash %s)", len(group.Paths), FormatBytes(group.Size), group.ContentHash)
+		for _, path := range group.Paths {
+			o.Info("  %s", path)
+		}
+		o.Info("")
+	}
+
+	o.Info("Files scanned:       %d", report.FilesScanned)
+	o.Info("Duplicate groups:    %d", len(report.Groups))
+	o.Info("Reclaimable space:   %s", FormatBytes(report.TotalReclaimable))
+}
+
+// PrintRuleDetails prints a single prefix rule and how many files it has organized.
+func (o *Output) PrintRuleDetails(details *orchestrator.RuleDetails) {
+	if details == nil {
+		return
+	}
+
+	o.Info("Prefix:            %s", details.Prefix)
+	o.Info("Outbound:          %s", details.OutboundDirectory)
+	if details.UndatedFolder != "" {
+		o.Info("Undated folder:    %s %s", details.UndatedFolder, details.Prefix)
+	}
+	o.Info("Organized files:   %d", details.OrganizedFiles)
+	if len(details.Folders) > 0 {
+		o.Info("Folders:")
+		for _, folder := range details.Folders {
+			o.Info("  %s", folder)
+		}
+	}
+}
+
+// FormatBytes formats a byte count using binary units (e.g. "1.5 MiB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatTimestamp formats t with layout in loc (UTC if loc is nil), followed
+// by "Z" for UTC or the zone offset (e.g. "+02:00"), so displayed times are
+// unambiguous.
+func FormatTimestamp(t time.Time, layout string, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(layout + "Z07:00")
+}
+
 // PrintSummary prints operation summary counts.
 // Requirements: 1.6 - Display summary count of files that would be moved, reviewed, and skipped
 func (o *Output) PrintSummary(moved, forReview, skipped int) {
@@ -291,6 +431,22 @@ func (o *Output) PrintSummary(moved, forReview, skipped int) {
 	o.Info("Summary: %d files (%s)", total, strings.Join(parts, ", "))
 }
 
+// FormatStatusLine returns the single machine-readable result line for a run:
+// "SORTA_RESULT moved=N review=N skipped=N errors=N runId=ID". The keys and their
+// order are stable so scripts can parse the last line of output.
+func FormatStatusLine(summary *orchestrator.RunSummary, runID string) string {
+	return fmt.Sprintf("SORTA_RESULT moved=%d review=%d skipped=%d errors=%d runId=%s",
+		summary.Moved, summary.ForReview, summary.Skipped, summary.Errors, runID)
+}
+
+// PrintStatusLine prints the machine-readable result line to stdout.
+func (o *Output) PrintStatusLine(summary *orchestrator.RunSummary, runID string) {
+	if summary == nil {
+		return
+	}
+	o.Info("%s", FormatStatusLine(summary, runID))
+}
+
 // PrintRunSummary prints the run summary statistics.
 // Requirements: 3.1, 3.2, 3.3, 3.4, 3.5, 3.6 - Run summary statistics display
 func (o *Output) PrintRunSummary(summary *orchestrator.RunSummary) {
@@ -304,7 +460,13 @@ func (o *Output) PrintRunSummary(summary *orchestrator.RunSummary) {
 	o.Info("  For Review: %d files", summary.ForReview)
 	o.Info("  Skipped: %d files", summary.Skipped)
 	o.Info("  Errors: %d", summary.Errors)
+	if summary.PermissionDeniedDirs > 0 {
+		o.Info("  Unreadable directories skipped: %d", summary.PermissionDeniedDirs)
+	}
 	o.Info("  Duration: %.2fs", summary.Duration.Seconds())
+	if summary.AbortedAtErrors > 0 {
+		o.Info("  Aborted: reached the limit of %d errors; remaining files were not processed", summary.AbortedAtErrors)
+	}
 
 	// Show per-prefix breakdown in verbose mode
 	// Requirements: 3.6 - Per-prefix breakdown in verbose mode
@@ -2,12 +2,18 @@ package output
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sorta/internal/audit"
+	"sorta/internal/config"
 	"sorta/internal/orchestrator"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -99,6 +105,36 @@ func TestErrorOutputGoesToErrWriter(t *testing.T) {
 	}
 }
 
+func TestErrorOutputAsJSON(t *testing.T) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	out := New(Config{
+		Writer:     &stdoutBuf,
+		ErrWriter:  &stderrBuf,
+		JSONErrors: true,
+	})
+
+	out.Error("Error: cannot read %q", "a \"quoted\" name")
+	out.Error("second error\n")
+
+	if stdoutBuf.Len() > 0 {
+		t.Errorf("expected no stdout output for Error, got: %q", stdoutBuf.String())
+	}
+	lines := strings.Split(strings.TrimSuffix(stderrBuf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per error, got: %q", stderrBuf.String())
+	}
+	want := []string{`Error: cannot read "a \"quoted\" name"`, "second error"}
+	for i, line := range lines {
+		var parsed map[string]string
+		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
+			t.Fatalf("expected valid JSON, got %q: %v", line, err)
+		}
+		if len(parsed) != 2 || parsed["level"] != "error" || parsed["msg"] != want[i] {
+			t.Errorf("expected level error and msg %q, got: %v", want[i], parsed)
+		}
+	}
+}
+
 // Test progress format matches "Processing file N/M..." pattern
 // Requirements: 5.4
 
@@ -2125,3 +2161,197 @@ func TestVerboseMode_OutputLengthComparison(t *testing.T) {
 			verboseBuf.Len(), nonVerboseBuf.Len())
 	}
 }
+
+// TestStatusLineMatchesRun verifies the exact key=value format of the status line
+// and that its counts and run ID match the run that produced it.
+func TestStatusLineMatchesRun(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	auditDir := filepath.Join(tempDir, "audit")
+	os.MkdirAll(sourceDir, 0755)
+	for _, name := range []string{"Invoice 2024-01-15 Acme.pdf", "Invoice 2024-02-20 Beta.pdf", "notes.txt"} {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	cfg := config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")},
+		},
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	if err := config.Save(&cfg, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	summary, err := orchestrator.RunWithOptions(configPath, &orchestrator.Options{
+		AuditConfig: &audit.AuditConfig{LogDirectory: auditDir},
+		AppVersion:  "1.0.0-test",
+		MachineID:   "test-machine",
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if summary.RunID == "" {
+		t.Fatal("Expected the summary to carry the audit run ID")
+	}
+
+	runSummary := orchestrator.GenerateSummary(orchestrator.ConvertSummaryToRunResult(summary), 0, false)
+
+	var buf bytes.Buffer
+	out := New(Config{Writer: &buf, ErrWriter: &buf, IsTTY: false})
+	out.PrintRunSummary(runSummary)
+	out.PrintStatusLine(runSummary, summary.RunID)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	last := lines[len(lines)-1]
+	expected := "SORTA_RESULT moved=2 review=1 skipped=0 errors=0 runId=" + summary.RunID
+	if last != expected {
+		t.Errorf("Expected last line %q, got %q", expected, last)
+	}
+	if !regexp.MustCompile(`^SORTA_RESULT moved=\d+ review=\d+ skipped=\d+ errors=\d+ runId=\S+$`).MatchString(last) {
+		t.Errorf("Status line %q does not match the documented format", last)
+	}
+}
+
+// TestPrintRuleDetailsShowsMatchingRule verifies that show-rule prints only the
+// requested rule with its organized file count, and errors for an unknown prefix.
+func TestPrintRuleDetailsShowsMatchingRule(t *testing.T) {
+	tempDir := t.TempDir()
+	invoiceDir := filepath.Join(tempDir, "invoices")
+	for _, path := range []string{
+		filepath.Join(invoiceDir, "2023 Invoice", "Invoice 2023-05-01 Acme.pdf"),
+		filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-01-15 Acme.pdf"),
+		filepath.Join(invoiceDir, "2024 Invoice", "Invoice 2024-02-20 Beta.pdf"),
+		filepath.Join(invoiceDir, "Invoice drafts", "draft.pdf"),
+	} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(path), 0644)
+	}
+
+	cfg := config.Configuration{
+		InboundDirectories: []string{filepath.Join(tempDir, "source")},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: invoiceDir},
+			{Prefix: "Receipt", OutboundDirectory: filepath.Join(tempDir, "receipts")},
+		},
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	if err := config.Save(&cfg, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	details, err := orchestrator.ShowRuleFromPath(configPath, "INVOICE")
+	if err != nil {
+		t.Fatalf("ShowRuleFromPath failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	out := New(Config{Writer: &buf, ErrWriter: &buf, IsTTY: false})
+	out.PrintRuleDetails(details)
+
+	expected := "Prefix:            Invoice\n" +
+		"Outbound:          " + invoiceDir + "\n" +
+		"Organized files:   3\n" +
+		"Folders:\n" +
+		"  2023 Invoice\n" +
+		"  2024 Invoice\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	if _, err := orchestrator.ShowRuleFromPath(configPath, "Statement"); err == nil {
+		t.Error("Expected an error for an unknown prefix")
+	}
+}
+
+// TestReasonPhrasesInHumanOutput verifies that dry-run and status output show
+// the friendly phrase for a reason code, that configured phrases override the
+// defaults, and that JSON keeps the raw code.
+func TestReasonPhrasesInHumanOutput(t *testing.T) {
+	result := &orchestrator.RunResult{
+		Skipped: []orchestrator.FileOperation{
+			{Source: "/inbound/locked.pdf", Reason: string(audit.ReasonFileLocked)},
+			{Source: "/inbound/other.pdf", Reason: "SOME_NEW_CODE"},
+		},
+	}
+
+	var buf bytes.Buffer
+	New(Config{Writer: &buf, ErrWriter: &buf}).PrintDryRunResult(result)
+	human := buf.String()
+	if !strings.Contains(human, "Reason: file is locked by another process") {
+		t.Errorf("Expected friendly reason in output, got: %q", human)
+	}
+	if strings.Contains(human, string(audit.ReasonFileLocked)) {
+		t.Errorf("Expected raw reason code not to appear in human output, got: %q", human)
+	}
+	if !strings.Contains(human, "Reason: SOME_NEW_CODE") {
+		t.Errorf("Expected unknown code to be shown as is, got: %q", human)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	if !strings.Contains(string(data), `"Reason":"FILE_LOCKED"`) {
+		t.Errorf("Expected raw reason code in JSON, got: %s", data)
+	}
+
+	buf.Reset()
+	out := New(Config{Writer: &buf, Verbose: true, ReasonPhrases: map[string]string{"NO_PREFIX_MATCH": "unknown document"}})
+	out.PrintStatusResult(&orchestrator.StatusResult{
+		ByInbound: map[string]*orchestrator.InboundStatus{
+			"/inbound": {
+				Directory:     "/inbound",
+				ByDestination: map[string][]string{"/inbound/for-review": {"/inbound/scan.pdf"}},
+				Reasons:       map[string]string{"/inbound/scan.pdf": "NO_PREFIX_MATCH"},
+				Total:         1,
+			},
+		},
+		GrandTotal: 1,
+	})
+	if !strings.Contains(buf.String(), "/inbound/scan.pdf (unknown document)") {
+		t.Errorf("Expected configured phrase in status output, got: %q", buf.String())
+	}
+}
+
+// TestFormatBytesUsesBinaryUnits verifies the human-readable sizes shown for
+// file identities and dedupe reports.
+func TestFormatBytesUsesBinaryUnits(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{12345, "12.1 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{1536 * 1024 * 1024, "1.5 GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.size); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
+
+// TestFormatTimestampLabelsZone verifies that UTC timestamps end in "Z" and
+// converted timestamps carry their zone offset.
+func TestFormatTimestampLabelsZone(t *testing.T) {
+	stored := time.Date(2024, 1, 15, 23, 30, 0, 0, time.UTC)
+
+	if got := FormatTimestamp(stored, "2006-01-02 15:04:05", nil); got != "2024-01-15 23:30:00Z" {
+		t.Errorf("Expected UTC timestamp with Z suffix, got %q", got)
+	}
+
+	est := time.FixedZone("EST", -5*60*60)
+	if got := FormatTimestamp(stored, "2006-01-02 15:04:05", est); got != "2024-01-15 18:30:00-05:00" {
+		t.Errorf("Expected EST timestamp with offset, got %q", got)
+	}
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	if got := FormatTimestamp(stored, "15:04:05", ist); got != "05:00:00+05:30" {
+		t.Errorf("Expected IST time with offset, got %q", got)
+	}
+}
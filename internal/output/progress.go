@@ -0,0 +1,52 @@
+// Package output handles CLI output formatting including verbose mode and progress indicators.
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ProgressEvent is a structured progress update, written as one JSON line.
+type ProgressEvent struct {
+	Current int    `json:"current"`
+	Total   int    `json:"total"`
+	Message string `json:"message"`
+	Phase   string `json:"phase"` // Command emitting progress: "run", "discover" or "undo"
+}
+
+// ProgressSink writes progress events as JSON lines, e.g. to a file or fifo read
+// by a GUI. Unlike the TTY indicator it is never suppressed.
+type ProgressSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	phase   string
+	total   int
+}
+
+// NewProgressSink creates a ProgressSink writing to w for the given phase.
+func NewProgressSink(w io.Writer, phase string) *ProgressSink {
+	return &ProgressSink{
+		encoder: json.NewEncoder(w),
+		phase:   phase,
+	}
+}
+
+// Start sets the total used by subsequent events.
+func (s *ProgressSink) Start(total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total = total
+}
+
+// Update writes one progress event.
+func (s *ProgressSink) Update(current int, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(ProgressEvent{
+		Current: current,
+		Total:   s.total,
+		Message: message,
+		Phase:   s.phase,
+	})
+}
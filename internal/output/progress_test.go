@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sorta/internal/config"
+	"sorta/internal/orchestrator"
+)
+
+// TestProgressSinkWritesOneLinePerUpdate runs an organize over several files and
+// verifies the progress file gets one JSON line per update, even without a TTY.
+func TestProgressSinkWritesOneLinePerUpdate(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	os.MkdirAll(sourceDir, 0755)
+	for _, name := range []string{"Invoice 2024-01-01 A.pdf", "Invoice 2024-02-01 B.pdf", "notes.txt"} {
+		os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644)
+	}
+
+	cfg := config.Configuration{
+		InboundDirectories: []string{sourceDir},
+		PrefixRules: []config.PrefixRule{
+			{Prefix: "Invoice", OutboundDirectory: filepath.Join(tempDir, "invoices")},
+		},
+	}
+	configData, _ := json.Marshal(cfg)
+	configPath := filepath.Join(tempDir, "config.json")
+	os.WriteFile(configPath, configData, 0644)
+
+	progressPath := filepath.Join(tempDir, "progress.jsonl")
+	progressFile, err := os.Create(progressPath)
+	if err != nil {
+		t.Fatalf("Failed to create progress file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	out := New(Config{Writer: &buf, ErrWriter: &buf, IsTTY: false})
+	out.SetProgressSink(NewProgressSink(progressFile, "run"))
+
+	started := false
+	_, err = orchestrator.RunWithOptions(configPath, &orchestrator.Options{
+		ProgressCallback: func(current, total int, file string, result *orchestrator.Result) {
+			if !started {
+				out.StartProgress(total)
+				started = true
+			}
+			out.UpdateProgress(current, "Processing file")
+		},
+	})
+	progressFile.Close()
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no TTY progress output, got %q", buf.String())
+	}
+
+	data, err := os.ReadFile(progressPath)
+	if err != nil {
+		t.Fatalf("Failed to read progress file: %v", err)
+	}
+
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 progress lines, got %d: %s", len(events), data)
+	}
+	for i, event := range events {
+		expected := ProgressEvent{Current: i + 1, Total: 3, Message: "Processing file", Phase: "run"}
+		if event != expected {
+			t.Errorf("Line %d: expected %+v, got %+v", i+1, expected, event)
+		}
+	}
+}
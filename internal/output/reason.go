@@ -0,0 +1,58 @@
+// Package output handles CLI output formatting including verbose mode and progress indicators.
+package output
+
+import (
+	"sorta/internal/audit"
+	"sorta/internal/classifier"
+)
+
+// DefaultReasonPhrases maps the reason codes recorded for skipped, reviewed and
+// moved files to the phrases shown in human-readable output. Codes stay as
+// they are in the audit log and in JSON output.
+var DefaultReasonPhrases = map[string]string{
+	string(audit.ReasonNoMatch):              "no matching prefix rule",
+	string(audit.ReasonInvalidDate):          "date in filename is not a valid date",
+	string(audit.ReasonAlreadyProcessed):     "already processed",
+	string(audit.ReasonPrefixNotSelected):    "prefix not selected with --only-prefix",
+	string(audit.ReasonFileLocked):           "file is locked by another process",
+	string(audit.ReasonStillWriting):         "file is still being written",
+	string(audit.ReasonBeforeLastRun):        "not modified since the last run",
+	string(audit.ReasonAlreadyCopied):        "already copied to its destination",
+	string(audit.ReasonAlreadyOrganized):     "already at its destination",
+	string(audit.ReasonReadOnlySource):       "no destination for a file in a read-only source",
+	string(audit.ReasonDestDirMissing):       "destination folder does not exist",
+	string(audit.ReasonIntraRunDuplicate):    "identical to a file already moved in this run",
+	string(audit.ReasonInsufficientSpace):    "not enough free space on the destination volume",
+	string(audit.ReasonZeroByte):             "file is empty",
+	string(audit.ReasonTooSmall):             "file is smaller than minFileSize",
+	string(audit.ReasonUnclassified):         "could not be classified",
+	string(audit.ReasonParseError):           "filename could not be parsed",
+	string(audit.ReasonValidationError):      "filename failed validation",
+	string(audit.ReasonPathTooLong):          "destination path would be too long",
+	string(audit.ReasonNoExtension):          "file name has no extension",
+	string(audit.ReasonContentMismatch):      "content does not match the file extension",
+	string(audit.ReasonExtensionNotAllowed):  "extension not allowed by the matching prefix rule",
+	string(audit.ReasonDuplicateRenamed):     "renamed to avoid overwriting an existing file",
+	string(audit.ReasonMatchedNoDate):        "matched a prefix rule but has no date",
+	string(audit.ReasonTypeFallback):         "matched by content type",
+	string(classifier.NoPrefixMatch):         "no matching prefix rule",
+	string(classifier.MissingDelimiter):      "no space between prefix and date",
+	string(audit.ReasonNoOpEvent):            "nothing to undo",
+	string(audit.ReasonIdentityMismatch):     "file has changed since it was moved",
+	string(audit.ReasonDestinationOccupied):  "original location is occupied",
+	string(audit.ReasonSourceNotFound):       "file is no longer where it was moved",
+	string(audit.ReasonConflictWithLaterRun): "file was changed by a later run",
+}
+
+// ReasonPhrase returns the human-readable phrase for a reason code. Phrases
+// in Config.ReasonPhrases take precedence over DefaultReasonPhrases; codes
+// without a phrase are returned unchanged.
+func (o *Output) ReasonPhrase(code string) string {
+	if phrase, ok := o.config.ReasonPhrases[code]; ok {
+		return phrase
+	}
+	if phrase, ok := DefaultReasonPhrases[code]; ok {
+		return phrase
+	}
+	return code
+}
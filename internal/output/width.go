@@ -0,0 +1,44 @@
+// Package output handles CLI output formatting including verbose mode and progress indicators.
+package output
+
+import (
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ellipsis replaces the part of a path that is cut to fit a line.
+const ellipsis = "…"
+
+// TerminalWidth returns the width of the terminal on stdout in columns. When
+// it cannot be detected, the COLUMNS environment variable is used; 0 means
+// the width is unknown.
+func TerminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// Ellipsize shortens s to at most width characters by replacing its middle
+// with "…". More of the end is kept than of the start, so a shortened path
+// still shows its file name. s is returned unchanged if it fits or width is
+// 0 or less.
+func Ellipsize(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width == 1 {
+		return ellipsis
+	}
+	runes := []rune(s)
+	keep := width - 1
+	head := keep / 3
+	tail := keep - head
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+}
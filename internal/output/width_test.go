@@ -0,0 +1,51 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"sorta/internal/orchestrator"
+)
+
+// TestNarrowWidthEllipsizesLongPaths verifies that with a narrow width, long
+// paths in explain and progress lines are shortened with an ellipsis so each
+// line fits, keeping the file name.
+func TestNarrowWidthEllipsizesLongPaths(t *testing.T) {
+	const width = 100
+	source := "/home/user/inbound/" + strings.Repeat("deeply/nested/", 6) + "Invoice 2024-01-15 Acme.pdf"
+	dest := "/home/user/archive/" + strings.Repeat("Invoices/", 6) + "2024 Invoice/Invoice 2024-01-15 Acme.pdf"
+	result := &orchestrator.Result{
+		SourcePath:      source,
+		DestinationPath: dest,
+		EventType:       "MOVE",
+		Prefix:          "Invoice",
+		Date:            "2024-01-15",
+	}
+
+	var buf bytes.Buffer
+	out := New(Config{Writer: &buf, ErrWriter: &buf, IsTTY: true, Width: width})
+	out.PrintExplainLine(result)
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if n := utf8.RuneCountInString(line); n > width {
+		t.Errorf("Expected explain line to fit in %d columns, got %d: %s", width, n, line)
+	}
+	if strings.Count(line, "…") != 2 || !strings.Contains(line, "Acme.pdf\" rule=Invoice") || !strings.HasSuffix(line, "decision=moved") {
+		t.Errorf("Expected both paths shortened with the rest of the line intact, got %s", line)
+	}
+	if full := FormatExplainLine(result); !strings.Contains(full, source) || !strings.Contains(full, dest) {
+		t.Errorf("Expected no shortening without a width, got %s", full)
+	}
+
+	buf.Reset()
+	out.StartProgress(10)
+	out.UpdateProgressPath(3, "Processing file", source)
+	progress := strings.TrimPrefix(buf.String(), "\r")
+	if n := utf8.RuneCountInString(progress); n >= width {
+		t.Errorf("Expected progress line narrower than %d columns, got %d: %q", width, n, progress)
+	}
+	if !strings.HasPrefix(progress, "Processing file 3/10... /home/") || !strings.Contains(progress, "…") || !strings.HasSuffix(progress, "Acme.pdf") {
+		t.Errorf("Expected progress line with shortened path, got %q", progress)
+	}
+}
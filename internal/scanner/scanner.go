@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"sorta/internal/filesystem"
 )
 
 // ScanErrorType represents the type of scanning error.
@@ -43,8 +45,14 @@ func (e *ScanError) Unwrap() error {
 
 // ScanOptions configures scanning behavior.
 type ScanOptions struct {
-	MaxDepth      int    // Maximum depth to scan (0 = immediate only, -1 = unlimited)
-	SymlinkPolicy string // "follow", "skip", or "error"
+	MaxDepth      int           // Maximum depth to scan (0 = immediate only, -1 = unlimited)
+	SymlinkPolicy string        // "follow", "skip", or "error"
+	FS            filesystem.FS // Filesystem to scan (nil = real filesystem)
+
+	// OnPermissionDenied, if set, is called for each subdirectory that cannot be
+	// read for lack of permission, and the scan continues without it. If nil,
+	// such a subdirectory aborts the scan with a PermissionDenied ScanError.
+	OnPermissionDenied func(err *ScanError)
 }
 
 // DefaultScanOptions returns the default scan options.
@@ -57,8 +65,9 @@ func DefaultScanOptions() ScanOptions {
 
 // FileEntry represents a file found during scanning.
 type FileEntry struct {
-	Name     string // Filename only
-	FullPath string // Absolute path
+	Name        string // Filename only
+	FullPath    string // Absolute path
+	RelativeDir string // Directory relative to the scanned root ("" for files directly in it)
 }
 
 // Scan enumerates files in the given directory without recursion.
@@ -70,8 +79,10 @@ func Scan(directory string) ([]FileEntry, error) {
 
 // ScanWithOptions scans directory with configurable options.
 func ScanWithOptions(directory string, opts ScanOptions) ([]FileEntry, error) {
+	fsys := filesystem.OrDefault(opts.FS)
+
 	// Check if directory exists
-	info, err := os.Lstat(directory)
+	info, err := fsys.Lstat(directory)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, &ScanError{
@@ -103,7 +114,7 @@ func ScanWithOptions(directory string, opts ScanOptions) ([]FileEntry, error) {
 			return []FileEntry{}, nil
 		case SymlinkPolicyFollow:
 			// Follow the symlink
-			info, err = os.Stat(directory)
+			info, err = fsys.Stat(directory)
 			if err != nil {
 				return nil, err
 			}
@@ -119,13 +130,14 @@ func ScanWithOptions(directory string, opts ScanOptions) ([]FileEntry, error) {
 		}
 	}
 
-	return scanDirectory(directory, opts, 0)
+	return scanDirectory(fsys, directory, "", opts, 0)
 }
 
 // scanDirectory recursively scans a directory up to the specified depth.
-func scanDirectory(directory string, opts ScanOptions, currentDepth int) ([]FileEntry, error) {
+// relativeDir is the path of directory relative to the scan root.
+func scanDirectory(fsys filesystem.FS, directory, relativeDir string, opts ScanOptions, currentDepth int) ([]FileEntry, error) {
 	// Read directory entries
-	entries, err := os.ReadDir(directory)
+	entries, err := fsys.ReadDir(directory)
 	if err != nil {
 		if os.IsPermission(err) {
 			return nil, &ScanError{
@@ -146,7 +158,7 @@ func scanDirectory(directory string, opts ScanOptions, currentDepth int) ([]File
 		}
 
 		// Check if entry is a symlink
-		info, err := os.Lstat(fullPath)
+		info, err := fsys.Lstat(fullPath)
 		if err != nil {
 			continue // Skip entries we can't stat
 		}
@@ -165,7 +177,7 @@ func scanDirectory(directory string, opts ScanOptions, currentDepth int) ([]File
 				continue // Skip this entry
 			case SymlinkPolicyFollow:
 				// Follow the symlink to get the target info
-				info, err = os.Stat(fullPath)
+				info, err = fsys.Stat(fullPath)
 				if err != nil {
 					continue // Skip broken symlinks
 				}
@@ -176,7 +188,12 @@ func scanDirectory(directory string, opts ScanOptions, currentDepth int) ([]File
 			// Check if we should recurse into subdirectories
 			// MaxDepth of -1 means unlimited, 0 means immediate only
 			if opts.MaxDepth == -1 || currentDepth < opts.MaxDepth {
-				subFiles, err := scanDirectory(fullPath, opts, currentDepth+1)
+				subFiles, err := scanDirectory(fsys, fullPath, filepath.Join(relativeDir, entry.Name()), opts, currentDepth+1)
+				var scanErr *ScanError
+				if errors.As(err, &scanErr) && scanErr.Type == PermissionDenied && opts.OnPermissionDenied != nil {
+					opts.OnPermissionDenied(scanErr)
+					continue
+				}
 				if err != nil {
 					return nil, err
 				}
@@ -186,8 +203,9 @@ func scanDirectory(directory string, opts ScanOptions, currentDepth int) ([]File
 		}
 
 		files = append(files, FileEntry{
-			Name:     entry.Name(),
-			FullPath: absPath,
+			Name:        entry.Name(),
+			FullPath:    absPath,
+			RelativeDir: relativeDir,
 		})
 	}
 
@@ -0,0 +1,125 @@
+// Package watcher provides file system monitoring for automatic file organization.
+package watcher
+
+import (
+	"time"
+)
+
+// Batch mode defaults used when only one of the batch limits is given.
+const (
+	DefaultBatchWindow = 10 * time.Second
+	DefaultBatchMax    = 100
+)
+
+// Clock provides the time a batch starts. Tests inject a fake clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the Clock backed by time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// BatchResult reports the outcome of processing one batch of files.
+type BatchResult struct {
+	Organized int
+	Reviewed  int
+	Skipped   int
+}
+
+// BatchHandler processes a batch of files in a single organizer run.
+type BatchHandler func(paths []string) BatchResult
+
+// Batcher groups file paths into batches. A batch is flushed when it reaches
+// the maximum size, or on the first tick at least the batch window after the
+// batch's first file arrived.
+type Batcher struct {
+	window  time.Duration
+	max     int
+	clock   Clock
+	flush   func(paths []string)
+	pending []string
+	seen    map[string]bool
+	started time.Time
+}
+
+// NewBatcher creates a Batcher. A max of 0 or less means no size limit; a nil
+// clock uses the real time.
+func NewBatcher(window time.Duration, max int, clock Clock, flush func(paths []string)) *Batcher {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Batcher{
+		window: window,
+		max:    max,
+		clock:  clock,
+		flush:  flush,
+		seen:   make(map[string]bool),
+	}
+}
+
+// Run consumes paths from events until the channel is closed, flushing batches as
+// they fill up or age out on ticks. Repeated paths within a batch are coalesced.
+// Any pending files are flushed before Run returns.
+func (b *Batcher) Run(events <-chan string, ticks <-chan time.Time) {
+	for {
+		select {
+		case path, ok := <-events:
+			if !ok {
+				b.Flush()
+				return
+			}
+			b.Add(path)
+		case now := <-ticks:
+			if len(b.pending) > 0 && now.Sub(b.started) >= b.window {
+				b.Flush()
+			}
+		}
+	}
+}
+
+// Add appends a path to the current batch, flushing it if it is full.
+func (b *Batcher) Add(path string) {
+	if b.seen[path] {
+		return
+	}
+	if len(b.pending) == 0 {
+		b.started = b.clock.Now()
+	}
+	b.seen[path] = true
+	b.pending = append(b.pending, path)
+
+	if b.max > 0 && len(b.pending) >= b.max {
+		b.Flush()
+	}
+}
+
+// Flush passes the current batch to the flush callback, if it is not empty.
+func (b *Batcher) Flush() {
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	b.seen = make(map[string]bool)
+
+	if b.flush != nil {
+		b.flush(batch)
+	}
+}
+
+// PendingCount returns the number of files in the current batch.
+// This is primarily useful for testing.
+func (b *Batcher) PendingCount() int {
+	return len(b.pending)
+}
+
+// batchTickInterval returns how often the batch window is checked.
+func batchTickInterval(window time.Duration) time.Duration {
+	interval := window / 4
+	if interval < 50*time.Millisecond {
+		interval = 50 * time.Millisecond
+	}
+	return interval
+}
@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when advanced by the test.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// TestBatcherGroupsFilesIntoRuns verifies that files are flushed when a batch is full,
+// when the window has elapsed on a tick, and when the event source closes.
+func TestBatcherGroupsFilesIntoRuns(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var runs [][]string
+
+	batcher := NewBatcher(10*time.Second, 3, clock, func(paths []string) {
+		runs = append(runs, paths)
+	})
+
+	events := make(chan string)
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		batcher.Run(events, ticks)
+		close(done)
+	}()
+
+	// The first send only returns once the batcher has handled the previous event,
+	// so the clock is never advanced while a file is being added.
+	tick := func(elapsed time.Duration) {
+		ticks <- clock.Now()
+		ticks <- clock.Advance(elapsed)
+	}
+
+	// Full batch flushes immediately
+	events <- "/in/a"
+	events <- "/in/b"
+	events <- "/in/c"
+
+	// Partial batch waits for the window to elapse
+	events <- "/in/d"
+	tick(5 * time.Second)
+	events <- "/in/e"
+	events <- "/in/d" // repeated event for a pending file is coalesced
+	tick(5 * time.Second)
+
+	// Remaining files are flushed when the event source closes
+	events <- "/in/f"
+	tick(time.Second)
+	close(events)
+	<-done
+
+	expected := [][]string{
+		{"/in/a", "/in/b", "/in/c"},
+		{"/in/d", "/in/e"},
+		{"/in/f"},
+	}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("Expected runs %v, got %v", expected, runs)
+	}
+}
@@ -11,9 +11,11 @@ import (
 
 // WatchConfig contains watcher settings.
 type WatchConfig struct {
-	DebounceSeconds   int      // Delay before processing (default: 2)
-	StableThresholdMs int      // File size stability threshold in milliseconds (default: 1000)
-	IgnorePatterns    []string // Glob patterns to ignore (e.g., "*.tmp", "*.part", "*.download")
+	DebounceSeconds   int           // Delay before processing (default: 2)
+	StableThresholdMs int           // File size stability threshold in milliseconds (default: 1000)
+	IgnorePatterns    []string      // Glob patterns to ignore (e.g., "*.tmp", "*.part", "*.download")
+	BatchWindow       time.Duration // Maximum time a batch waits before it is processed (batch mode)
+	BatchMax          int           // Maximum files per batch, 0 = unlimited (batch mode)
 }
 
 // DefaultWatchConfig returns a WatchConfig with sensible defaults.
@@ -30,6 +32,7 @@ type WatchSummary struct {
 	FilesOrganized int
 	FilesReviewed  int
 	FilesSkipped   int
+	Batches        int // Number of batches processed (batch mode only)
 	Duration       time.Duration
 }
 
@@ -50,11 +53,17 @@ type Watcher struct {
 	wg          sync.WaitGroup
 	startTime   time.Time
 
+	// Batch mode: files are queued on batchEvents and processed by batchHandler
+	batchHandler BatchHandler
+	batchEvents  chan string
+	batchWG      sync.WaitGroup
+
 	// Statistics tracking
 	mu             sync.Mutex
 	filesOrganized int
 	filesReviewed  int
 	filesSkipped   int
+	batches        int
 }
 
 // New creates a new Watcher with the given configuration.
@@ -72,6 +81,14 @@ func New(config *WatchConfig, fileHandler FileHandler) *Watcher {
 	}
 }
 
+// NewWithBatchHandler creates a Watcher that groups new files into batches using
+// config.BatchWindow and config.BatchMax, and calls batchHandler once per batch.
+func NewWithBatchHandler(config *WatchConfig, batchHandler BatchHandler) *Watcher {
+	w := New(config, nil)
+	w.batchHandler = batchHandler
+	return w
+}
+
 // Start begins watching the specified directories for file changes.
 // It returns an error if the watcher cannot be initialized.
 // The watcher runs until Stop() is called.
@@ -98,6 +115,19 @@ func (w *Watcher) Start(dirs []string) error {
 	w.startTime = time.Now()
 	w.done = make(chan struct{})
 
+	// Start the batcher before any events can be queued
+	if w.batchHandler != nil {
+		w.batchEvents = make(chan string)
+		batcher := NewBatcher(w.config.BatchWindow, w.config.BatchMax, nil, w.processBatch)
+		ticker := time.NewTicker(batchTickInterval(w.config.BatchWindow))
+		w.batchWG.Add(1)
+		go func() {
+			defer w.batchWG.Done()
+			defer ticker.Stop()
+			batcher.Run(w.batchEvents, ticker.C)
+		}()
+	}
+
 	// Start the event processing goroutine
 	w.wg.Add(1)
 	go w.processEvents()
@@ -113,6 +143,13 @@ func (w *Watcher) Stop() *WatchSummary {
 	// Wait for the goroutine to finish
 	w.wg.Wait()
 
+	// Flush any pending batch once no more events can arrive
+	if w.batchEvents != nil {
+		close(w.batchEvents)
+		w.batchWG.Wait()
+		w.batchEvents = nil
+	}
+
 	// Close the fsnotify watcher
 	if w.fsWatcher != nil {
 		w.fsWatcher.Close()
@@ -126,6 +163,7 @@ func (w *Watcher) Stop() *WatchSummary {
 		FilesOrganized: w.filesOrganized,
 		FilesReviewed:  w.filesReviewed,
 		FilesSkipped:   w.filesSkipped,
+		Batches:        w.batches,
 		Duration:       time.Since(w.startTime),
 	}
 }
@@ -168,6 +206,12 @@ func (w *Watcher) handleFileEvent(path string) {
 		return
 	}
 
+	// In batch mode, queue the file for the next batch
+	if w.batchEvents != nil {
+		w.batchEvents <- path
+		return
+	}
+
 	// Call the file handler if provided
 	if w.fileHandler != nil {
 		organized, reviewed, err := w.fileHandler(path)
@@ -191,6 +235,18 @@ func (w *Watcher) handleFileEvent(path string) {
 	w.mu.Unlock()
 }
 
+// processBatch runs the batch handler and records its counts.
+func (w *Watcher) processBatch(paths []string) {
+	result := w.batchHandler(paths)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches++
+	w.filesOrganized += result.Organized
+	w.filesReviewed += result.Reviewed
+	w.filesSkipped += result.Skipped
+}
+
 // shouldIgnore checks if a file path matches any of the ignore patterns.
 func (w *Watcher) shouldIgnore(path string) bool {
 	return w.fileFilter.ShouldIgnore(path)